@@ -0,0 +1,98 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package atomicfile replaces files atomically: new content is written
+// to a temporary file next to the replaced one, flushed to the disk
+// and renamed over it, so the file is never left truncated or half written.
+package atomicfile
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// WriteFile atomically replaces fileName by the content written by fill.
+// The file keeps its permissions, a new file is created with 0600 ones.
+func WriteFile(fileName string, fill func(io.Writer) error) error {
+	tmpName, err := Temp(fileName, fill)
+	if err != nil {
+		return err
+	}
+	return Replace(tmpName, fileName)
+}
+
+// Temp creates a temporary file next to fileName, fills it by fill
+// and flushes it to the disk. It returns the name of temporary file,
+// which must be passed to Replace or removed.
+func Temp(fileName string, fill func(io.Writer) error) (tmpName string, err error) {
+	dir, base := filepath.Split(fileName)
+	if dir == "" {
+		dir = "."
+	}
+
+	file, err := ioutil.TempFile(dir, "."+base+".tmp")
+	if err != nil {
+		return "", err
+	}
+	name := file.Name()
+	defer func() {
+		if err != nil {
+			file.Close()
+			os.Remove(name)
+		}
+	}()
+
+	if info, errS := os.Stat(fileName); errS == nil {
+		if err = file.Chmod(info.Mode()); err != nil {
+			return "", err
+		}
+	}
+
+	if err = fill(file); err != nil {
+		return "", err
+	}
+	if err = file.Sync(); err != nil {
+		return "", err
+	}
+	if err = file.Close(); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// Replace atomically renames tmpName to fileName
+// and flushes the directory entry to the disk.
+// The temporary file is removed, if it can't be renamed.
+func Replace(tmpName, fileName string) error {
+	if err := os.Rename(tmpName, fileName); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return SyncDir(filepath.Dir(fileName))
+}
+
+// SyncDir flushes entries of directory dir to the disk.
+func SyncDir(dir string) error {
+	file, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return file.Sync()
+}
@@ -0,0 +1,105 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package atomicfile_test
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/yagoggame/grpc_server/atomicfile"
+)
+
+var errFill = errors.New("fill error")
+
+var writeFileTests = []struct {
+	caseName string
+	old      string
+	fill     func(io.Writer) error
+	want     string
+	wantErr  error
+}{
+	{caseName: "new file", want: "new",
+		fill: func(writer io.Writer) error { _, err := io.WriteString(writer, "new"); return err }},
+	{caseName: "replaced file", old: "old", want: "new",
+		fill: func(writer io.Writer) error { _, err := io.WriteString(writer, "new"); return err }},
+	{caseName: "failed fill", old: "old", want: "old", wantErr: errFill,
+		fill: func(writer io.Writer) error { io.WriteString(writer, "new"); return errFill }},
+}
+
+func TestWriteFile(t *testing.T) {
+	for _, test := range writeFileTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "atomicfile")
+			if err != nil {
+				t.Fatalf("Unexpected TempDir err: %v", err)
+			}
+			defer os.RemoveAll(dir)
+
+			fileName := filepath.Join(dir, "file")
+			if test.old != "" {
+				if err := ioutil.WriteFile(fileName, []byte(test.old), 0640); err != nil {
+					t.Fatalf("Unexpected WriteFile err: %v", err)
+				}
+			}
+
+			if err := WriteFile(fileName, test.fill); err != test.wantErr {
+				t.Fatalf("Unexpected WriteFile err:\nwant: %v,\ngot: %v.", test.wantErr, err)
+			}
+
+			got, err := ioutil.ReadFile(fileName)
+			if err != nil {
+				t.Fatalf("Unexpected ReadFile err: %v", err)
+			}
+			if string(got) != test.want {
+				t.Errorf("Unexpected content:\nwant: %q,\ngot: %q.", test.want, got)
+			}
+			testMode(t, fileName, test.old != "")
+			testNoTemp(t, dir)
+		})
+	}
+}
+
+// testMode checks, that replaced file keeps it's permissions
+// and new one is accessible by the owner only.
+func testMode(t *testing.T, fileName string, replaced bool) {
+	info, err := os.Stat(fileName)
+	if err != nil {
+		t.Fatalf("Unexpected Stat err: %v", err)
+	}
+	want := os.FileMode(0600)
+	if replaced {
+		want = 0640
+	}
+	if info.Mode().Perm() != want {
+		t.Errorf("Unexpected mode:\nwant: %v,\ngot: %v.", want, info.Mode().Perm())
+	}
+}
+
+// testNoTemp checks, that no temporary files are left in dir.
+func testNoTemp(t *testing.T, dir string) {
+	names, err := filepath.Glob(filepath.Join(dir, ".*"))
+	if err != nil {
+		t.Fatalf("Unexpected Glob err: %v", err)
+	}
+	if len(names) != 0 {
+		t.Errorf("Unexpected temporary files: %v.", names)
+	}
+}
@@ -0,0 +1,224 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package cache provides caching decorator of interfaces.Authorizator interface
+package cache
+
+import (
+	"container/list"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yagoggame/grpc_server/interfaces"
+)
+
+// ErrSize occurs when cache of non positive size requested
+var ErrSize = errors.New("non positive cache size")
+
+// Stats contains counters of cache usage
+type Stats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+type entry struct {
+	login    string
+	password [sha256.Size]byte
+	id       int
+	expires  time.Time
+}
+
+// Authorizator implements interfaces.Authorizator interface
+// caching successful authorizations of underlying Authorizator
+// for a ttl in a LRU cache of limited size.
+type Authorizator struct {
+	authorizator interfaces.Authorizator
+	ttl          time.Duration
+	size         int
+	salt         []byte
+	entries      map[string]*list.Element
+	lru          *list.List
+	mutex        sync.Mutex
+	hits         uint64
+	misses       uint64
+	// generation changes on every invalidation, so that authorization
+	// performed concurrently with it is not cached.
+	generation uint64
+}
+
+// New constructs new Authorizator decorating authorizator
+func New(authorizator interfaces.Authorizator, ttl time.Duration, size int) (*Authorizator, error) {
+	if size < 1 {
+		return nil, ErrSize
+	}
+
+	// passwords are kept as salted hashes only.
+	salt := make([]byte, sha256.Size)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	return &Authorizator{
+		authorizator: authorizator,
+		ttl:          ttl,
+		size:         size,
+		salt:         salt,
+		entries:      make(map[string]*list.Element, size),
+		lru:          list.New(),
+	}, nil
+}
+
+// Authorize returns cached id of user, if the same requisites were
+// authorized less than ttl ago, or authorizes him by underlying Authorizator
+func (authorizator *Authorizator) Authorize(requisites *interfaces.Requisites) (id int, err error) {
+	password := authorizator.hash(requisites.Password)
+
+	id, generation, ok := authorizator.lookup(requisites.Login, password)
+	if ok {
+		atomic.AddUint64(&authorizator.hits, 1)
+		return id, nil
+	}
+	atomic.AddUint64(&authorizator.misses, 1)
+
+	id, err = authorizator.authorizator.Authorize(requisites)
+	if err != nil {
+		return 0, err
+	}
+
+	authorizator.store(&entry{
+		login:    requisites.Login,
+		password: password,
+		id:       id,
+		expires:  time.Now().Add(authorizator.ttl),
+	}, generation)
+	return id, nil
+}
+
+// Identify returns the id of user with login by underlying Authorizator,
+// if it is interfaces.Identifier. Identifications are not cached.
+func (authorizator *Authorizator) Identify(login string) (id int, err error) {
+	identifier, ok := authorizator.authorizator.(interfaces.Identifier)
+	if !ok {
+		return 0, interfaces.ErrNotIdentifier
+	}
+	return identifier.Identify(login)
+}
+
+// Register registers a new user by underlying Authorizator
+func (authorizator *Authorizator) Register(requisites *interfaces.Requisites) error {
+	authorizator.invalidate(requisites.Login)
+	return authorizator.authorizator.Register(requisites)
+}
+
+// Remove removes a user by underlying Authorizator and forgets him
+func (authorizator *Authorizator) Remove(requisites *interfaces.Requisites) error {
+	defer authorizator.invalidate(requisites.Login)
+	return authorizator.authorizator.Remove(requisites)
+}
+
+// ChangeRequisites changes requisites of user by underlying Authorizator
+// and forgets both old and new logins
+func (authorizator *Authorizator) ChangeRequisites(requisitesOld, requisitesNew *interfaces.Requisites) error {
+	defer authorizator.invalidate(requisitesOld.Login, requisitesNew.Login)
+	return authorizator.authorizator.ChangeRequisites(requisitesOld, requisitesNew)
+}
+
+// Stats returns counters of cache usage
+func (authorizator *Authorizator) Stats() Stats {
+	return Stats{
+		Hits:   atomic.LoadUint64(&authorizator.hits),
+		Misses: atomic.LoadUint64(&authorizator.misses),
+	}
+}
+
+// Len returns number of cached users
+func (authorizator *Authorizator) Len() int {
+	authorizator.mutex.Lock()
+	defer authorizator.mutex.Unlock()
+
+	return authorizator.lru.Len()
+}
+
+func (authorizator *Authorizator) hash(password string) (sum [sha256.Size]byte) {
+	mac := hmac.New(sha256.New, authorizator.salt)
+	mac.Write([]byte(password))
+	copy(sum[:], mac.Sum(nil))
+	return sum
+}
+
+func (authorizator *Authorizator) lookup(login string, password [sha256.Size]byte) (id int, generation uint64, ok bool) {
+	authorizator.mutex.Lock()
+	defer authorizator.mutex.Unlock()
+
+	generation = authorizator.generation
+	element, ok := authorizator.entries[login]
+	if !ok {
+		return 0, generation, false
+	}
+	cached := element.Value.(*entry)
+	if time.Now().After(cached.expires) {
+		authorizator.remove(element)
+		return 0, generation, false
+	}
+	if !hmac.Equal(cached.password[:], password[:]) {
+		return 0, generation, false
+	}
+
+	authorizator.lru.MoveToFront(element)
+	return cached.id, generation, true
+}
+
+func (authorizator *Authorizator) store(cached *entry, generation uint64) {
+	authorizator.mutex.Lock()
+	defer authorizator.mutex.Unlock()
+
+	if generation != authorizator.generation {
+		return
+	}
+
+	if element, ok := authorizator.entries[cached.login]; ok {
+		element.Value = cached
+		authorizator.lru.MoveToFront(element)
+		return
+	}
+
+	authorizator.entries[cached.login] = authorizator.lru.PushFront(cached)
+	for authorizator.lru.Len() > authorizator.size {
+		authorizator.remove(authorizator.lru.Back())
+	}
+}
+
+func (authorizator *Authorizator) invalidate(logins ...string) {
+	authorizator.mutex.Lock()
+	defer authorizator.mutex.Unlock()
+
+	authorizator.generation++
+	for _, login := range logins {
+		if element, ok := authorizator.entries[login]; ok {
+			authorizator.remove(element)
+		}
+	}
+}
+
+func (authorizator *Authorizator) remove(element *list.Element) {
+	authorizator.lru.Remove(element)
+	delete(authorizator.entries, element.Value.(*entry).login)
+}
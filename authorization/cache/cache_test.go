@@ -0,0 +1,233 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package cache_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/yagoggame/grpc_server/authorization/cache"
+	"github.com/yagoggame/grpc_server/interfaces"
+	"github.com/yagoggame/grpc_server/interfaces/mocks"
+)
+
+const (
+	longTTL   = time.Hour
+	shortTTL  = 10 * time.Millisecond
+	cacheSize = 2
+)
+
+var (
+	joe     = interfaces.Requisites{Login: "Joe", Password: "aaa"}
+	joeBad  = interfaces.Requisites{Login: "Joe", Password: "ababab"}
+	joeNew  = interfaces.Requisites{Login: "Joe", Password: "ccc"}
+	nick    = interfaces.Requisites{Login: "Nick", Password: "bbb"}
+	piter   = interfaces.Requisites{Login: "Piter", Password: "ppp"}
+	userIDs = map[string]int{"Joe": 2, "Nick": 3, "Piter": 4}
+)
+
+// step is an action on cache with expected count of underlying Authorize calls
+type step struct {
+	action    func(authorizator *Authorizator) error
+	wantCalls int
+	wantErr   error
+}
+
+var cacheTests = []struct {
+	caseName  string
+	ttl       time.Duration
+	steps     []step
+	wantStats Stats
+}{
+	{
+		caseName: "repeated authorization",
+		ttl:      longTTL,
+		steps: []step{
+			{action: authorize(joe), wantCalls: 1},
+			{action: authorize(joe), wantCalls: 0},
+			{action: authorize(joe), wantCalls: 0},
+		},
+		wantStats: Stats{Hits: 2, Misses: 1},
+	},
+	{
+		caseName: "wrong password is not cached",
+		ttl:      longTTL,
+		steps: []step{
+			{action: authorize(joe), wantCalls: 1},
+			{action: authorize(joeBad), wantCalls: 1, wantErr: interfaces.ErrPassword},
+			{action: authorize(joeBad), wantCalls: 1, wantErr: interfaces.ErrPassword},
+			{action: authorize(joe), wantCalls: 0},
+		},
+		wantStats: Stats{Hits: 1, Misses: 3},
+	},
+	{
+		caseName: "expiration",
+		ttl:      shortTTL,
+		steps: []step{
+			{action: authorize(joe), wantCalls: 1},
+			{action: sleep(2 * shortTTL), wantCalls: 0},
+			{action: authorize(joe), wantCalls: 1},
+		},
+		wantStats: Stats{Hits: 0, Misses: 2},
+	},
+	{
+		caseName: "invalidation on Remove",
+		ttl:      longTTL,
+		steps: []step{
+			{action: authorize(joe), wantCalls: 1},
+			{action: remove(joe), wantCalls: 0},
+			{action: authorize(joe), wantCalls: 1, wantErr: interfaces.ErrLogin},
+		},
+		wantStats: Stats{Hits: 0, Misses: 2},
+	},
+	{
+		caseName: "invalidation on ChangeRequisites",
+		ttl:      longTTL,
+		steps: []step{
+			{action: authorize(joe), wantCalls: 1},
+			{action: change(joe, joeNew), wantCalls: 0},
+			{action: authorize(joe), wantCalls: 1, wantErr: interfaces.ErrPassword},
+			{action: authorize(joeNew), wantCalls: 1},
+		},
+		wantStats: Stats{Hits: 0, Misses: 3},
+	},
+	{
+		caseName: "least recently used eviction",
+		ttl:      longTTL,
+		steps: []step{
+			{action: authorize(joe), wantCalls: 1},
+			{action: authorize(nick), wantCalls: 1},
+			{action: authorize(joe), wantCalls: 0},
+			{action: authorize(piter), wantCalls: 1},
+			{action: authorize(joe), wantCalls: 0},
+			{action: authorize(nick), wantCalls: 1},
+		},
+		wantStats: Stats{Hits: 2, Misses: 4},
+	},
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(nil, longTTL, 0)
+	if !errors.Is(err, ErrSize) {
+		t.Errorf("Unexpected err:\nwant: %v,\ngot: %v.", ErrSize, err)
+	}
+}
+
+func TestCache(t *testing.T) {
+	for _, test := range cacheTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			underlying := newFakeAuthorizator(controller)
+			authorizator, err := New(underlying.mock, test.ttl, cacheSize)
+			if err != nil {
+				t.Fatalf("Unexpected New() err: %v", err)
+			}
+
+			for i, step := range test.steps {
+				underlying.calls = 0
+				err := step.action(authorizator)
+				if !errors.Is(err, step.wantErr) {
+					t.Errorf("Unexpected err on step %d:\nwant: %v,\ngot: %v.", i, step.wantErr, err)
+				}
+				if underlying.calls != step.wantCalls {
+					t.Errorf("Unexpected underlying Authorize calls on step %d:\nwant: %d,\ngot: %d.", i, step.wantCalls, underlying.calls)
+				}
+			}
+
+			if stats := authorizator.Stats(); stats != test.wantStats {
+				t.Errorf("Unexpected stats:\nwant: %+v,\ngot: %+v.", test.wantStats, stats)
+			}
+			if authorizator.Len() > cacheSize {
+				t.Errorf("Unexpected cache length:\nwant: <= %d,\ngot: %d.", cacheSize, authorizator.Len())
+			}
+		})
+	}
+}
+
+// fakeAuthorizator backs the mock with a map of users and counts Authorize calls
+type fakeAuthorizator struct {
+	mock  *mocks.MockAuthorizator
+	users map[string]string
+	calls int
+}
+
+func newFakeAuthorizator(controller *gomock.Controller) *fakeAuthorizator {
+	fake := &fakeAuthorizator{
+		mock: mocks.NewMockAuthorizator(controller),
+		users: map[string]string{
+			joe.Login: joe.Password, nick.Login: nick.Password, piter.Login: piter.Password,
+		},
+	}
+
+	fake.mock.EXPECT().Authorize(gomock.Any()).AnyTimes().DoAndReturn(
+		func(requisites *interfaces.Requisites) (int, error) {
+			fake.calls++
+			password, ok := fake.users[requisites.Login]
+			if !ok {
+				return 0, interfaces.ErrLogin
+			}
+			if password != requisites.Password {
+				return 0, interfaces.ErrPassword
+			}
+			return userIDs[requisites.Login], nil
+		})
+	fake.mock.EXPECT().Remove(gomock.Any()).AnyTimes().DoAndReturn(
+		func(requisites *interfaces.Requisites) error {
+			delete(fake.users, requisites.Login)
+			return nil
+		})
+	fake.mock.EXPECT().ChangeRequisites(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(requisitesOld, requisitesNew *interfaces.Requisites) error {
+			delete(fake.users, requisitesOld.Login)
+			fake.users[requisitesNew.Login] = requisitesNew.Password
+			return nil
+		})
+	return fake
+}
+
+func authorize(requisites interfaces.Requisites) func(*Authorizator) error {
+	return func(authorizator *Authorizator) error {
+		id, err := authorizator.Authorize(&requisites)
+		if err == nil && id != userIDs[requisites.Login] {
+			return errors.New("unexpected id")
+		}
+		return err
+	}
+}
+
+func remove(requisites interfaces.Requisites) func(*Authorizator) error {
+	return func(authorizator *Authorizator) error {
+		return authorizator.Remove(&requisites)
+	}
+}
+
+func change(requisitesOld, requisitesNew interfaces.Requisites) func(*Authorizator) error {
+	return func(authorizator *Authorizator) error {
+		return authorizator.ChangeRequisites(&requisitesOld, &requisitesNew)
+	}
+}
+
+func sleep(duration time.Duration) func(*Authorizator) error {
+	return func(*Authorizator) error {
+		time.Sleep(duration)
+		return nil
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package chain provides realization of interfaces.Authorizator interface
+// which federates an ordered list of other Authorizators
+package chain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/yagoggame/grpc_server/interfaces"
+)
+
+// Span is a number of user ids reserved for every namespace.
+// User with id of a backend in namespace N gets id N*Span+id.
+const Span = 1 << 24
+
+var (
+	// ErrNoBackends occurs when chain created without backends
+	ErrNoBackends = errors.New("no backends in chain")
+	// ErrNamespace occurs when backend's namespace is negative or duplicated
+	ErrNamespace = errors.New("wrong namespace of backend")
+	// ErrWritable occurs when more than one backend is writable
+	ErrWritable = errors.New("more than one writable backend")
+	// ErrReadOnly occurs when registering a user in chain without writable backend
+	ErrReadOnly = errors.New("no writable backend to register user")
+	// ErrIDRange occurs when backend returns id out of it's namespace
+	ErrIDRange = errors.New("user id out of namespace")
+)
+
+// Backend is an Authorizator with namespace of user ids
+type Backend struct {
+	Authorizator interfaces.Authorizator
+	Namespace    int
+	Writable     bool
+}
+
+// Authorizator implements interfaces.Authorizator interface.
+// Every user is served by the first backend which knows his login.
+type Authorizator struct {
+	backends []Backend
+	writable *Backend
+}
+
+// New constructs new Authorizator consulting backends in specified order
+func New(backends []Backend) (*Authorizator, error) {
+	if len(backends) < 1 {
+		return nil, ErrNoBackends
+	}
+
+	authorizator := &Authorizator{backends: make([]Backend, len(backends))}
+	copy(authorizator.backends, backends)
+
+	namespaces := make(map[int]bool, len(backends))
+	for i := range authorizator.backends {
+		backend := &authorizator.backends[i]
+		if backend.Namespace < 0 || namespaces[backend.Namespace] {
+			return nil, fmt.Errorf("%w: %d", ErrNamespace, backend.Namespace)
+		}
+		namespaces[backend.Namespace] = true
+
+		if backend.Writable {
+			if authorizator.writable != nil {
+				return nil, ErrWritable
+			}
+			authorizator.writable = backend
+		}
+	}
+	return authorizator, nil
+}
+
+// Authorize attempts to authorize a user and returns the id if success
+func (authorizator *Authorizator) Authorize(requisites *interfaces.Requisites) (id int, err error) {
+	backend, id, err := authorizator.find(requisites)
+	if err != nil {
+		return 0, err
+	}
+
+	if id < 0 || id >= Span {
+		return 0, fmt.Errorf("%w: %d of namespace %d", ErrIDRange, id, backend.Namespace)
+	}
+	return backend.Namespace*Span + id, nil
+}
+
+// Identify returns the id of user with login without password check.
+// Backends which are not interfaces.Identifier are skipped.
+func (authorizator *Authorizator) Identify(login string) (id int, err error) {
+	for _, backend := range authorizator.backends {
+		identifier, ok := backend.Authorizator.(interfaces.Identifier)
+		if !ok {
+			continue
+		}
+
+		id, err := identifier.Identify(login)
+		if errors.Is(err, interfaces.ErrLogin) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if id < 0 || id >= Span {
+			return 0, fmt.Errorf("%w: %d of namespace %d", ErrIDRange, id, backend.Namespace)
+		}
+		return backend.Namespace*Span + id, nil
+	}
+	return 0, interfaces.ErrLogin
+}
+
+// Register attempts to register a new user in writable backend
+func (authorizator *Authorizator) Register(requisites *interfaces.Requisites) error {
+	if authorizator.writable == nil {
+		return ErrReadOnly
+	}
+
+	if err := authorizator.checkVacant(requisites); err != nil {
+		return err
+	}
+	return authorizator.writable.Authorizator.Register(requisites)
+}
+
+// Remove attempts to remove a user from the backend which knows him
+func (authorizator *Authorizator) Remove(requisites *interfaces.Requisites) error {
+	backend, _, err := authorizator.find(requisites)
+	if err != nil {
+		return err
+	}
+	return backend.Authorizator.Remove(requisites)
+}
+
+// ChangeRequisites changes requisites of user from requisitesOld to requisitesNew
+// in the backend which knows him
+func (authorizator *Authorizator) ChangeRequisites(requisitesOld, requisitesNew *interfaces.Requisites) error {
+	backend, _, err := authorizator.find(requisitesOld)
+	if err != nil {
+		return err
+	}
+
+	if requisitesNew.Login != requisitesOld.Login {
+		if err := authorizator.checkVacant(requisitesNew); err != nil {
+			return err
+		}
+	}
+	return backend.Authorizator.ChangeRequisites(requisitesOld, requisitesNew)
+}
+
+// find returns the first backend which knows the login of requisites,
+// with local id of authorized user.
+func (authorizator *Authorizator) find(requisites *interfaces.Requisites) (*Backend, int, error) {
+	for i := range authorizator.backends {
+		backend := &authorizator.backends[i]
+
+		id, err := backend.Authorizator.Authorize(requisites)
+		if errors.Is(err, interfaces.ErrLogin) {
+			continue
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		return backend, id, nil
+	}
+	return nil, 0, interfaces.ErrLogin
+}
+
+// checkVacant checks that the login of requisites is unknown to all backends.
+// Backends which are interfaces.Identifier are asked by Identify,
+// so they aren't tried with a password of other user.
+func (authorizator *Authorizator) checkVacant(requisites *interfaces.Requisites) error {
+	for _, backend := range authorizator.backends {
+		var err error
+		if identifier, ok := backend.Authorizator.(interfaces.Identifier); ok {
+			_, err = identifier.Identify(requisites.Login)
+		} else {
+			_, err = backend.Authorizator.Authorize(requisites)
+		}
+		if errors.Is(err, interfaces.ErrLogin) {
+			continue
+		}
+		if err == nil || errors.Is(err, interfaces.ErrPassword) {
+			return interfaces.ErrLoginOccupied
+		}
+		return err
+	}
+	return nil
+}
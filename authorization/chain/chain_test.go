@@ -0,0 +1,305 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package chain_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"log"
+	"testing"
+
+	. "github.com/yagoggame/grpc_server/authorization/chain"
+	"github.com/yagoggame/grpc_server/authorization/dummy"
+	"github.com/yagoggame/grpc_server/interfaces"
+)
+
+const servicesNamespace = 1
+
+var (
+	joe = interfaces.Requisites{Login: "Joe", Password: "aaa"}
+	bot = interfaces.Requisites{Login: "Bot", Password: "ccc"}
+)
+
+var newTests = []struct {
+	caseName string
+	backends []Backend
+	want     error
+}{
+	{
+		caseName: "no backends",
+		want:     ErrNoBackends,
+	},
+	{
+		caseName: "negative namespace",
+		backends: []Backend{{Authorizator: dummy.New(), Namespace: -1}},
+		want:     ErrNamespace,
+	},
+	{
+		caseName: "duplicated namespace",
+		backends: []Backend{
+			{Authorizator: dummy.New(), Namespace: 1},
+			{Authorizator: dummy.New(), Namespace: 1},
+		},
+		want: ErrNamespace,
+	},
+	{
+		caseName: "two writable",
+		backends: []Backend{
+			{Authorizator: dummy.New(), Namespace: 0, Writable: true},
+			{Authorizator: dummy.New(), Namespace: 1, Writable: true},
+		},
+		want: ErrWritable,
+	},
+	{
+		caseName: "correct",
+		backends: []Backend{
+			{Authorizator: dummy.New(), Namespace: 0, Writable: true},
+			{Authorizator: dummy.New(), Namespace: 1},
+		},
+		want: nil,
+	},
+}
+
+var authorizeTests = []struct {
+	caseName   string
+	requisites interfaces.Requisites
+	wantID     int
+	wantErr    error
+}{
+	{
+		caseName:   "services backend",
+		requisites: bot,
+		wantID:     servicesNamespace*Span + 1,
+	},
+	{
+		caseName:   "players backend",
+		requisites: joe,
+		wantID:     2,
+	},
+	{
+		caseName:   "unknown login",
+		requisites: interfaces.Requisites{Login: "Piter", Password: "aaa"},
+		wantErr:    interfaces.ErrLogin,
+	},
+	{
+		caseName:   "wrong password",
+		requisites: interfaces.Requisites{Login: "Bot", Password: "aaa"},
+		wantErr:    interfaces.ErrPassword,
+	},
+}
+
+var registerTests = []struct {
+	caseName   string
+	requisites interfaces.Requisites
+	want       error
+}{
+	{
+		caseName:   "login of read only backend",
+		requisites: interfaces.Requisites{Login: "Bot", Password: "ddd"},
+		want:       interfaces.ErrLoginOccupied,
+	},
+	{
+		caseName:   "login of writable backend",
+		requisites: interfaces.Requisites{Login: "Joe", Password: "ddd"},
+		want:       interfaces.ErrLoginOccupied,
+	},
+	{
+		caseName:   "new login",
+		requisites: interfaces.Requisites{Login: "Piter", Password: "ppp"},
+		want:       nil,
+	},
+}
+
+var changeRequisitesTests = []struct {
+	caseName      string
+	requisitesOld interfaces.Requisites
+	requisitesNew interfaces.Requisites
+	want          error
+}{
+	{
+		caseName:      "to login of other backend",
+		requisitesOld: joe,
+		requisitesNew: interfaces.Requisites{Login: "Bot", Password: "ttt"},
+		want:          interfaces.ErrLoginOccupied,
+	},
+	{
+		caseName:      "unknown login",
+		requisitesOld: interfaces.Requisites{Login: "Piter", Password: "aaa"},
+		requisitesNew: interfaces.Requisites{Login: "Teodor", Password: "ttt"},
+		want:          interfaces.ErrLogin,
+	},
+	{
+		caseName:      "in read only backend",
+		requisitesOld: bot,
+		requisitesNew: interfaces.Requisites{Login: "Robot", Password: "rrr"},
+		want:          nil,
+	},
+}
+
+func TestNew(t *testing.T) {
+	for _, test := range newTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			_, err := New(test.backends)
+			if !errors.Is(err, test.want) {
+				t.Errorf("Unexpected err:\nwant: %v,\ngot: %v.", test.want, err)
+			}
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	authorizator := newChain(t, true)
+
+	for _, test := range authorizeTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			id, err := authorizator.Authorize(&test.requisites)
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("Unexpected err:\nwant: %v,\ngot: %v.", test.wantErr, err)
+			}
+			if id != test.wantID {
+				t.Errorf("Unexpected id:\nwant: %d,\ngot: %d.", test.wantID, id)
+			}
+		})
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	authorizator := newChain(t, true)
+
+	for _, test := range authorizeTests {
+		if errors.Is(test.wantErr, interfaces.ErrPassword) {
+			continue
+		}
+		t.Run(test.caseName, func(t *testing.T) {
+			id, err := authorizator.Identify(test.requisites.Login)
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("Unexpected err:\nwant: %v,\ngot: %v.", test.wantErr, err)
+			}
+			if id != test.wantID {
+				t.Errorf("Unexpected id:\nwant: %d,\ngot: %d.", test.wantID, id)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	authorizator := newChain(t, true)
+
+	for _, test := range registerTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			err := authorizator.Register(&test.requisites)
+			if !errors.Is(err, test.want) {
+				t.Errorf("Unexpected err:\nwant: %v,\ngot: %v.", test.want, err)
+			}
+			if _, err := authorizator.Authorize(&test.requisites); test.want == nil && err != nil {
+				t.Errorf("Unexpected Authorize err: %v.", err)
+			}
+		})
+	}
+
+	readOnly := newChain(t, false)
+	if err := readOnly.Register(&interfaces.Requisites{Login: "Piter", Password: "ppp"}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Unexpected err:\nwant: %v,\ngot: %v.", ErrReadOnly, err)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	authorizator := newChain(t, true)
+
+	if err := authorizator.Remove(&bot); err != nil {
+		t.Fatalf("Unexpected Remove err: %v.", err)
+	}
+	if _, err := authorizator.Authorize(&bot); !errors.Is(err, interfaces.ErrLogin) {
+		t.Errorf("Unexpected Authorize err:\nwant: %v,\ngot: %v.", interfaces.ErrLogin, err)
+	}
+	if err := authorizator.Remove(&bot); !errors.Is(err, interfaces.ErrLogin) {
+		t.Errorf("Unexpected Remove err:\nwant: %v,\ngot: %v.", interfaces.ErrLogin, err)
+	}
+}
+
+func TestChangeRequisites(t *testing.T) {
+	authorizator := newChain(t, true)
+
+	for _, test := range changeRequisitesTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			err := authorizator.ChangeRequisites(&test.requisitesOld, &test.requisitesNew)
+			if !errors.Is(err, test.want) {
+				t.Errorf("Unexpected err:\nwant: %v,\ngot: %v.", test.want, err)
+			}
+			id, authErr := authorizator.Authorize(&test.requisitesNew)
+			if err == nil && (authErr != nil || id/Span != servicesNamespace) {
+				t.Errorf("Unexpected Authorize result: %d, %v.", id, authErr)
+			}
+		})
+	}
+}
+
+// lockable counts attempts to authorize, as directories do to lock accounts
+type lockable struct {
+	*dummy.Authorizator
+	attempts int
+}
+
+func (authorizator *lockable) Authorize(requisites *interfaces.Requisites) (int, error) {
+	authorizator.attempts++
+	return authorizator.Authorizator.Authorize(requisites)
+}
+
+func TestRegisterWithoutAttempts(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	services := &lockable{Authorizator: dummy.New()}
+	authorizator, err := New([]Backend{
+		{Authorizator: services, Namespace: servicesNamespace},
+		{Authorizator: dummy.New(), Namespace: 0, Writable: true},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected New err: %v.", err)
+	}
+
+	if err := authorizator.Register(&interfaces.Requisites{Login: "Joe", Password: "ddd"}); !errors.Is(err, interfaces.ErrLoginOccupied) {
+		t.Errorf("Unexpected Register err:\nwant: %v,\ngot: %v.", interfaces.ErrLoginOccupied, err)
+	}
+	if err := authorizator.Register(&interfaces.Requisites{Login: "Piter", Password: "ppp"}); err != nil {
+		t.Errorf("Unexpected Register err: %v.", err)
+	}
+	if services.attempts != 0 {
+		t.Errorf("Unexpected attempts to authorize by other passwords: %d.", services.attempts)
+	}
+}
+
+// newChain creates chain of services backend with users "Bot" (id 1) and "Nick"
+// and players backend with default users of dummy Authorizator.
+func newChain(t *testing.T, writable bool) *Authorizator {
+	log.SetOutput(ioutil.Discard)
+
+	services := dummy.New()
+	if err := services.Remove(&joe); err != nil {
+		t.Fatalf("Unexpected Remove err: %v.", err)
+	}
+	if err := services.Register(&bot); err != nil {
+		t.Fatalf("Unexpected Register err: %v.", err)
+	}
+
+	authorizator, err := New([]Backend{
+		{Authorizator: services, Namespace: servicesNamespace},
+		{Authorizator: dummy.New(), Namespace: 0, Writable: writable},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected New err: %v.", err)
+	}
+	return authorizator
+}
@@ -59,6 +59,20 @@ func (authorizator *Authorizator) Authorize(requisites *interfaces.Requisites) (
 	return user.ID, nil
 }
 
+// Identify returns the id of user with login without password check
+func (authorizator *Authorizator) Identify(login string) (id int, err error) {
+	authorizator.mutex.RLock()
+	defer authorizator.mutex.RUnlock()
+
+	user, ok := authorizator.users[login]
+	if !ok {
+		return 0, interfaces.ErrLogin
+	}
+
+	log.Printf("identified client: %s, %d", login, user.ID)
+	return user.ID, nil
+}
+
 // Register attempts to register a new user and returns the id if success
 func (authorizator *Authorizator) Register(requisites *interfaces.Requisites) error {
 	authorizator.mutex.Lock()
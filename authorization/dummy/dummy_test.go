@@ -204,6 +204,15 @@ var testsChangeRequisites = []struct {
 	},
 }
 
+var testsIdentify = []struct {
+	caseName string
+	login    string
+	want     iderr
+}{
+	{caseName: "unregistred login", login: "Piter", want: iderr{id: 0, err: interfaces.ErrLogin}},
+	{caseName: "registred login", login: "Joe", want: iderr{id: 2, err: nil}},
+}
+
 func TestAuthorize(t *testing.T) {
 	log.SetOutput(ioutil.Discard)
 	authorizator := New()
@@ -219,6 +228,19 @@ func TestAuthorize(t *testing.T) {
 	}
 }
 
+func TestIdentify(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	authorizator := New()
+
+	for _, test := range testsIdentify {
+		t.Run(test.caseName, func(t *testing.T) {
+			id, err := authorizator.Identify(test.login)
+
+			testIDErr(t, test.want, iderr{id: id, err: err})
+		})
+	}
+}
+
 func TestRegister(t *testing.T) {
 	log.SetOutput(ioutil.Discard)
 	authorizator := New()
@@ -0,0 +1,120 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package filemap
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/yagoggame/grpc_server/atomicfile"
+)
+
+// rotateBackups shifts fileName.1 ... fileName.(backups-1) one step up,
+// dropping the oldest one, and copies fileName to fileName.1.
+// The live file stays in place all the time.
+func rotateBackups(fileName string, backups int) error {
+	if backups < 1 {
+		return nil
+	}
+	if _, err := os.Stat(fileName); os.IsNotExist(err) {
+		return nil
+	}
+
+	for i := backups - 1; i > 0; i-- {
+		err := os.Rename(backupName(fileName, i), backupName(fileName, i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return copyFile(fileName, backupName(fileName, 1))
+}
+
+func backupName(fileName string, i int) string {
+	return fmt.Sprintf("%s.%d", fileName, i)
+}
+
+// backupNames returns names of existing backups of fileName.
+func backupNames(fileName string) ([]string, error) {
+	names, err := filepath.Glob(fileName + ".*")
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []string
+	for _, name := range names {
+		i, err := strconv.Atoi(strings.TrimPrefix(name, fileName+"."))
+		if err == nil && i > 0 && name == backupName(fileName, i) {
+			backups = append(backups, name)
+		}
+	}
+	return backups, nil
+}
+
+// wipeFile overwrites fileName by zeros and removes it.
+// Copies made by file system, e.g. on journaling or copy on write, are out of reach.
+func wipeFile(fileName string) error {
+	file, err := os.OpenFile(fileName, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err == nil {
+		_, err = io.CopyN(file, zeros{}, info.Size())
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if errC := file.Close(); err == nil {
+		err = errC
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(fileName); err != nil {
+		return err
+	}
+	return atomicfile.SyncDir(filepath.Dir(fileName))
+}
+
+// zeros is an endless reader of zero bytes.
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	return atomicfile.WriteFile(dst, func(writer io.Writer) error {
+		_, err := io.Copy(writer, in)
+		return err
+	})
+}
@@ -0,0 +1,170 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package crypt provides FileMaper wrapper which encrypts
+// users file with AES-256-GCM.
+package crypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/yagoggame/grpc_server/authorization"
+	"github.com/yagoggame/grpc_server/authorization/filemap"
+)
+
+// KeySize is a size of encryption key in bytes
+const KeySize = 32
+
+// KeyEnv is the name of environment variable with base64 encoded key
+const KeyEnv = "YAGOGAME_FILEMAP_KEY"
+
+// magic starts every encrypted file and authenticates it's format version
+var magic = []byte("yagogame-filemap-aes256gcm-v1\n")
+
+var (
+	// ErrKeySize occurs when key has wrong length
+	ErrKeySize = errors.New("wrong key size")
+	// ErrKeyFormat occurs when key is not base64 encoded
+	ErrKeyFormat = errors.New("wrong key format")
+	// ErrNoKey occurs when neither key file nor environment variable provided
+	ErrNoKey = errors.New("no key provided")
+	// ErrNotEncrypted occurs when loaded content has no encryption header
+	ErrNotEncrypted = errors.New("content is not encrypted")
+	// ErrDecrypt occurs when content can't be decrypted by the key
+	ErrDecrypt = errors.New("can't decrypt content")
+)
+
+// Maper implements FileMaper interface encrypting
+// the content produced by another FileMaper
+type Maper struct {
+	maper filemap.FileMaper
+	aead  cipher.AEAD
+}
+
+// New creates Maper wrapping maper with key
+func New(maper filemap.FileMaper, key []byte) (*Maper, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("%w: want %d, got %d", ErrKeySize, KeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Maper{maper: maper, aead: aead}, nil
+}
+
+// Save encrypts users elements, encoded by wrapped maper, into writer
+func (m *Maper) Save(users map[string]*authorization.User, writer io.Writer) error {
+	plain := &bytes.Buffer{}
+	if err := m.maper.Save(users, plain); err != nil {
+		return err
+	}
+
+	nonce := make([]byte, m.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	content := make([]byte, 0, len(magic)+len(nonce)+plain.Len()+m.aead.Overhead())
+	content = append(content, magic...)
+	content = append(content, nonce...)
+	content = m.aead.Seal(content, nonce, plain.Bytes(), magic)
+
+	_, err := writer.Write(content)
+	return err
+}
+
+// Load decrypts users elements from reader and decodes them by wrapped maper
+func (m *Maper) Load(reader io.Reader) (map[string]*authorization.User, error) {
+	content, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.HasPrefix(content, magic) {
+		return nil, ErrNotEncrypted
+	}
+	content = content[len(magic):]
+
+	if len(content) < m.aead.NonceSize() {
+		return nil, fmt.Errorf("%w: content too short", ErrDecrypt)
+	}
+	nonce, sealed := content[:m.aead.NonceSize()], content[m.aead.NonceSize():]
+
+	plain, err := m.aead.Open(nil, nonce, sealed, magic)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDecrypt, err)
+	}
+
+	return m.maper.Load(bytes.NewReader(plain))
+}
+
+// GenerateKey creates a new random key
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// EncodeKey encodes key in the form accepted by LoadKey
+func EncodeKey(key []byte) string {
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// LoadKey reads base64 encoded key from keyFile,
+// or from KeyEnv environment variable if keyFile is empty
+func LoadKey(keyFile string) ([]byte, error) {
+	var encoded string
+	if keyFile != "" {
+		content, err := ioutil.ReadFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		encoded = string(content)
+	} else {
+		var ok bool
+		if encoded, ok = os.LookupEnv(KeyEnv); !ok {
+			return nil, fmt.Errorf("%w: set key file or %s environment variable", ErrNoKey, KeyEnv)
+		}
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeyFormat, err)
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("%w: want %d, got %d", ErrKeySize, KeySize, len(key))
+	}
+	return key, nil
+}
@@ -0,0 +1,265 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package crypt_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yagoggame/grpc_server/authorization"
+	"github.com/yagoggame/grpc_server/authorization/filemap"
+	. "github.com/yagoggame/grpc_server/authorization/filemap/crypt"
+	"github.com/yagoggame/grpc_server/authorization/filemap/json"
+	"github.com/yagoggame/grpc_server/interfaces"
+)
+
+const (
+	commonFileName = "tmp.json"
+	keyFileName    = "tmp.key"
+)
+
+var twoUsers = map[string]*authorization.User{
+	"Joe":  &authorization.User{Password: "aaa", ID: 2},
+	"Nick": &authorization.User{Password: "bbb", ID: 3},
+}
+
+var loadKeyTests = []struct {
+	name    string
+	content string
+	env     string
+	useFile bool
+	wantErr error
+}{
+	{
+		name:    "key file",
+		content: EncodeKey(bytes.Repeat([]byte{1}, KeySize)) + "\n",
+		useFile: true,
+		wantErr: nil,
+	},
+	{
+		name:    "environment variable",
+		env:     EncodeKey(bytes.Repeat([]byte{2}, KeySize)),
+		wantErr: nil,
+	},
+	{
+		name:    "short key",
+		content: EncodeKey([]byte("short")),
+		useFile: true,
+		wantErr: ErrKeySize,
+	},
+	{
+		name:    "not base64 key",
+		content: "not a base64 key",
+		useFile: true,
+		wantErr: ErrKeyFormat,
+	},
+	{
+		name:    "no key",
+		wantErr: ErrNoKey,
+	},
+}
+
+func TestReconstruct(t *testing.T) {
+	maper := newMaper(t)
+
+	content := &bytes.Buffer{}
+	if err := maper.Save(twoUsers, content); err != nil {
+		t.Fatalf("Unexpected Save err: %v.", err)
+	}
+	if strings.Contains(content.String(), "Joe") {
+		t.Errorf("Unexpected plaintext login in encrypted content: %q", content.String())
+	}
+
+	users, err := maper.Load(content)
+	if err != nil {
+		t.Fatalf("Unexpected Load err: %v.", err)
+	}
+	if !reflect.DeepEqual(twoUsers, users) {
+		t.Errorf("Unexpected users:\nwant: %v,\n got: %v", twoUsers, users)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	maper := newMaper(t)
+	content := &bytes.Buffer{}
+	if err := maper.Save(twoUsers, content); err != nil {
+		t.Fatalf("Unexpected Save err: %v.", err)
+	}
+	tampered := append([]byte{}, content.Bytes()...)
+	tampered[len(tampered)-1] ^= 1
+
+	tests := []struct {
+		name    string
+		maper   *Maper
+		content []byte
+		wantErr error
+	}{
+		{name: "plaintext", maper: maper, content: []byte("[]\n"), wantErr: ErrNotEncrypted},
+		{name: "tampered", maper: maper, content: tampered, wantErr: ErrDecrypt},
+		{name: "other key", maper: newMaper(t), content: content.Bytes(), wantErr: ErrDecrypt},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			users, err := test.maper.Load(bytes.NewReader(test.content))
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("Unexpected err:\nwant: %v,\ngot: %v.", test.wantErr, err)
+			}
+			if users != nil {
+				t.Errorf("Unexpected users:\nwant: %v,\n got: %v", nil, users)
+			}
+		})
+	}
+}
+
+func TestNewKeySize(t *testing.T) {
+	_, err := New(json.New(), []byte("short"))
+	if !errors.Is(err, ErrKeySize) {
+		t.Errorf("Unexpected err:\nwant: %v,\ngot: %v.", ErrKeySize, err)
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	for _, test := range loadKeyTests {
+		t.Run(test.name, func(t *testing.T) {
+			os.Unsetenv(KeyEnv)
+			if test.env != "" {
+				os.Setenv(KeyEnv, test.env)
+				defer os.Unsetenv(KeyEnv)
+			}
+
+			fileName := ""
+			if test.useFile {
+				fileName = keyFileName
+				if err := ioutil.WriteFile(fileName, []byte(test.content), 0600); err != nil {
+					t.Fatalf("Unexpected WriteFile err: %v", err)
+				}
+				defer os.Remove(fileName)
+			}
+
+			key, err := LoadKey(fileName)
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("Unexpected err:\nwant: %v,\ngot: %v.", test.wantErr, err)
+			}
+			if err == nil && len(key) != KeySize {
+				t.Errorf("Unexpected key size:\nwant: %d,\ngot: %d.", KeySize, len(key))
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	plain, err := filemap.NewWithBackups(commonFileName, 2)
+	if err != nil {
+		t.Fatalf("Unexpected NewWithBackups() err: %v", err)
+	}
+	defer os.Remove(commonFileName)
+	backup, stray := commonFileName+".1", commonFileName+".3"
+	defer os.Remove(backup)
+	if err := plain.Register(&interfaces.Requisites{Login: "Piter", Password: "ppp"}); err != nil {
+		t.Fatalf("Unexpected Register() err: %v", err)
+	}
+	// a backup, which can't be read as plain file, e.g. encrypted by other key
+	if err := ioutil.WriteFile(stray, []byte("garbage"), 0600); err != nil {
+		t.Fatalf("Unexpected WriteFile() err: %v", err)
+	}
+	defer os.Remove(stray)
+
+	oldKeyMaper, newKeyMaper := newMaper(t), newMaper(t)
+	unreadable, err := filemap.Convert(commonFileName, json.New(), oldKeyMaper, false)
+	if err != nil {
+		t.Fatalf("Unexpected encrypting Convert() err: %v", err)
+	}
+	testBackup(t, backup, oldKeyMaper)
+	testUnreadable(t, unreadable, []string{stray})
+	if content, err := ioutil.ReadFile(stray); err != nil || string(content) != "garbage" {
+		t.Errorf("Unexpected unreadable backup:\nwant: %q,\ngot: %q, err: %v.", "garbage", content, err)
+	}
+
+	// a wrong key must never destroy backups.
+	unreadable, err = filemap.Convert(commonFileName, oldKeyMaper, newKeyMaper, false)
+	if err != nil {
+		t.Fatalf("Unexpected rotating Convert() err: %v", err)
+	}
+	testBackup(t, backup, newKeyMaper)
+	testUnreadable(t, unreadable, []string{stray})
+
+	unreadable, err = filemap.Convert(commonFileName, newKeyMaper, newKeyMaper, true)
+	if err != nil {
+		t.Fatalf("Unexpected wiping Convert() err: %v", err)
+	}
+	testUnreadable(t, unreadable, nil)
+	if _, err := os.Stat(stray); !os.IsNotExist(err) {
+		t.Errorf("Unexpected Stat() of wiped backup err:\nwant: %v,\ngot: %v.", os.ErrNotExist, err)
+	}
+
+	if _, err := filemap.NewWithMaper(commonFileName, 0, oldKeyMaper); !errors.Is(err, filemap.ErrFillUsers) {
+		t.Errorf("Unexpected NewWithMaper() with old key err:\nwant: %v,\ngot: %v.", filemap.ErrFillUsers, err)
+	}
+
+	encrypted, err := filemap.NewWithMaper(commonFileName, 0, newKeyMaper)
+	if err != nil {
+		t.Fatalf("Unexpected NewWithMaper() err: %v", err)
+	}
+	if encrypted.Len() != plain.Len() {
+		t.Errorf("Unexpected users count:\nwant: %d,\ngot: %d.", plain.Len(), encrypted.Len())
+	}
+	if _, err := encrypted.Authorize(&interfaces.Requisites{Login: "Joe", Password: "aaa"}); err != nil {
+		t.Errorf("Unexpected Authorize() err: %v", err)
+	}
+}
+
+func testUnreadable(t *testing.T, got, want []string) {
+	t.Helper()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected unreadable backups:\nwant: %v,\ngot: %v.", want, got)
+	}
+}
+
+// testBackup checks that backup is readable only by maper.
+func testBackup(t *testing.T, backup string, maper *Maper) {
+	t.Helper()
+	content, err := ioutil.ReadFile(backup)
+	if err != nil {
+		t.Fatalf("Unexpected backup ReadFile() err: %v", err)
+	}
+	if _, err := maper.Load(bytes.NewReader(content)); err != nil {
+		t.Errorf("Unexpected backup Load() err: %v", err)
+	}
+	if _, err := json.New().Load(bytes.NewReader(content)); err == nil {
+		t.Errorf("Unexpected plain backup: %q", content)
+	}
+}
+
+func newMaper(t *testing.T) *Maper {
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("Unexpected GenerateKey err: %v", err)
+	}
+	maper, err := New(json.New(), key)
+	if err != nil {
+		t.Fatalf("Unexpected New err: %v", err)
+	}
+	return maper
+}
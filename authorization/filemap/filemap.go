@@ -17,15 +17,19 @@
 package filemap
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 	"os"
 	"path"
 	"sort"
 	"sync"
 
+	"github.com/yagoggame/grpc_server/atomicfile"
 	"github.com/yagoggame/grpc_server/authorization"
 	"github.com/yagoggame/grpc_server/authorization/filemap/json"
 	"github.com/yagoggame/grpc_server/interfaces"
@@ -38,6 +42,10 @@ var (
 	ErrFillUsers = errors.New("cant't fill users")
 	// ErrStoreUsers  error occurs when failed to store users on disk
 	ErrStoreUsers = errors.New("cant't store users")
+	// ErrBackups error occurs when negative number of backups requested
+	ErrBackups = errors.New("negative number of backups")
+	// ErrDuplicateID error occurs when loaded users share the same id
+	ErrDuplicateID = errors.New("duplicate user id")
 )
 
 // FileMaper wraps Load, Save methods
@@ -49,20 +57,41 @@ type FileMaper interface {
 // Authorizator implements interfaces.Authorizator interface
 type Authorizator struct {
 	fileName string
+	backups  int
 	maper    FileMaper
 	users    map[string]*authorization.User
+	sum      [sha256.Size]byte
 	mutex    sync.RWMutex
+	watcher  *watcher
 }
 
-// New constructs new Authorizator
+// New constructs new Authorizator which keeps no backups of users file
 func New(fileName string) (*Authorizator, error) {
-	maper, err := choseMaper(fileName)
+	return NewWithBackups(fileName, 0)
+}
+
+// NewWithBackups constructs new Authorizator which keeps up to backups
+// previous versions of users file as fileName.1 (the newest) ... fileName.N
+func NewWithBackups(fileName string, backups int) (*Authorizator, error) {
+	maper, err := ChoseMaper(fileName)
 	if err != nil {
 		return nil, err
 	}
 
+	return NewWithMaper(fileName, backups, maper)
+}
+
+// NewWithMaper constructs new Authorizator which uses maper
+// to load and save users file instead of the one chosen by file name extension.
+// It allows to wrap a FileMaper, e.g. to encrypt the file.
+func NewWithMaper(fileName string, backups int, maper FileMaper) (*Authorizator, error) {
+	if backups < 0 {
+		return nil, fmt.Errorf("%w: %d", ErrBackups, backups)
+	}
+
 	authorizator := &Authorizator{
 		fileName: fileName,
+		backups:  backups,
 		maper:    maper,
 		users:    nil,
 	}
@@ -90,6 +119,20 @@ func (authorizator *Authorizator) Authorize(requisites *interfaces.Requisites) (
 	return user.ID, nil
 }
 
+// Identify returns the id of user with login without password check
+func (authorizator *Authorizator) Identify(login string) (id int, err error) {
+	authorizator.mutex.RLock()
+	defer authorizator.mutex.RUnlock()
+
+	user, ok := authorizator.users[login]
+	if !ok {
+		return 0, interfaces.ErrLogin
+	}
+
+	log.Printf("identified client: %s, %d", login, user.ID)
+	return user.ID, nil
+}
+
 // Register attempts to register a new user and returns the id if success
 func (authorizator *Authorizator) Register(requisites *interfaces.Requisites) error {
 	authorizator.mutex.Lock()
@@ -179,11 +222,17 @@ func (authorizator *Authorizator) Len() int {
 	return len(authorizator.users)
 }
 
+// fillUsers loads users from the file, or seeds the default users
+// if there is no file yet. An existing but unreadable file is never
+// replaced, so that a corrupted database can be restored by hand.
 func (authorizator *Authorizator) fillUsers() error {
 	err := authorizator.loadUsers()
 	if err == nil {
 		return nil
 	}
+	if !os.IsNotExist(err) {
+		return fmt.Errorf("%w: load of existing file %q failed: %v", ErrFillUsers, authorizator.fileName, err)
+	}
 
 	authorizator.users = map[string]*authorization.User{
 		"Joe":  &authorization.User{Password: "aaa", ID: 2},
@@ -199,35 +248,114 @@ func (authorizator *Authorizator) fillUsers() error {
 }
 
 func (authorizator *Authorizator) loadUsers() error {
-	file, err := os.Open(authorizator.fileName)
+	content, err := ioutil.ReadFile(authorizator.fileName)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
 
-	users, err := authorizator.maper.Load(file)
+	users, err := authorizator.decodeUsers(content)
 	if err != nil {
 		return err
 	}
 	authorizator.users = users
+	authorizator.sum = sha256.Sum256(content)
 	return nil
 }
 
-func (authorizator *Authorizator) storeUsers() error {
-	file, err := os.Create(authorizator.fileName)
+func (authorizator *Authorizator) decodeUsers(content []byte) (map[string]*authorization.User, error) {
+	users, err := authorizator.maper.Load(bytes.NewReader(content))
 	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[int]string, len(users))
+	for login, user := range users {
+		if other, ok := ids[user.ID]; ok {
+			return nil, fmt.Errorf("%w: %d of %q and %q", ErrDuplicateID, user.ID, other, login)
+		}
+		ids[user.ID] = login
+	}
+	return users, nil
+}
+
+// storeUsers atomically replaces users file with the current users,
+// so that the file is never left truncated or half written.
+func (authorizator *Authorizator) storeUsers() error {
+	content := &bytes.Buffer{}
+	if err := authorizator.maper.Save(authorizator.users, content); err != nil {
 		return err
 	}
-	defer file.Close()
 
-	err = authorizator.maper.Save(authorizator.users, file)
+	tmpName, err := atomicfile.Temp(authorizator.fileName, func(writer io.Writer) error {
+		_, err := writer.Write(content.Bytes())
+		return err
+	})
 	if err != nil {
 		return err
 	}
+
+	if err := rotateBackups(authorizator.fileName, authorizator.backups); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+
+	if err := atomicfile.Replace(tmpName, authorizator.fileName); err != nil {
+		return err
+	}
+	// remember what has been written, so the watcher can ignore own changes.
+	authorizator.sum = sha256.Sum256(content.Bytes())
+	return nil
+}
+
+// Convert rewrites users file, loaded with from maper, by to maper.
+// It allows to encrypt an existing file or to change it's encryption key.
+// Backups of the file are rewritten too. Backups which can't be loaded
+// with from maper, e.g. left by a previous conversion or read with a wrong key,
+// are left in place and returned as unreadable. If wipe is set, they are wiped
+// instead, so no copy of users is left in the old form.
+func Convert(fileName string, from, to FileMaper, wipe bool) (unreadable []string, err error) {
+	if err := convertFile(fileName, from, to); err != nil {
+		return nil, err
+	}
+
+	backups, err := backupNames(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrStoreUsers, err)
+	}
+	for _, backup := range backups {
+		err := convertFile(backup, from, to)
+		if errors.Is(err, ErrFillUsers) {
+			if !wipe {
+				unreadable = append(unreadable, backup)
+				continue
+			}
+			err = wipeFile(backup)
+		}
+		if err != nil {
+			return unreadable, fmt.Errorf("%w: backup %q: %v", ErrStoreUsers, backup, err)
+		}
+	}
+	return unreadable, nil
+}
+
+func convertFile(fileName string, from, to FileMaper) error {
+	authorizator := &Authorizator{
+		fileName: fileName,
+		maper:    from,
+	}
+	if err := authorizator.loadUsers(); err != nil {
+		return fmt.Errorf("%w: %v", ErrFillUsers, err)
+	}
+
+	authorizator.maper = to
+	if err := authorizator.storeUsers(); err != nil {
+		return fmt.Errorf("%w: %v", ErrStoreUsers, err)
+	}
 	return nil
 }
 
-func choseMaper(fileName string) (FileMaper, error) {
+// ChoseMaper returns FileMaper suitable for file name extension
+func ChoseMaper(fileName string) (FileMaper, error) {
 	ext := path.Ext(fileName)
 	switch ext {
 	case ".json":
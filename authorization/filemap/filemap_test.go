@@ -19,10 +19,12 @@ package filemap_test
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
 	"testing"
+	"time"
 
 	. "github.com/yagoggame/grpc_server/authorization/filemap"
 	"github.com/yagoggame/grpc_server/interfaces"
@@ -47,6 +49,11 @@ var jsonPrestoredContent = `[
 ]
 `
 
+var jsonCorruptedContent = `[
+	{
+		"login": "Joe",
+		"passw`
+
 var newTests = []struct {
 	name        string
 	fileName    string
@@ -67,6 +74,13 @@ var newTests = []struct {
 		wantErr:     nil,
 		wantCount:   1,
 	},
+	{
+		name:        "correct extension corrupted file content",
+		fileName:    commonFileName,
+		fileContent: jsonCorruptedContent,
+		wantErr:     ErrFillUsers,
+		wantCount:   0,
+	},
 	{
 		name:      "wrong extension",
 		fileName:  wrongFileName,
@@ -269,6 +283,10 @@ func TestNew(t *testing.T) {
 			if err == nil && authorizator.Len() != test.wantCount {
 				t.Errorf("Unexpected users count:\nwant: %d,\ngot: %d.", test.wantCount, authorizator.Len())
 			}
+			if content, errF := ioutil.ReadFile(test.fileName); len(test.fileContent) > 0 && err != nil &&
+				(errF != nil || string(content) != test.fileContent) {
+				t.Errorf("Unexpected file change after failed New():\nwant: %q,\ngot: %q, %v.", test.fileContent, content, errF)
+			}
 
 			errR := os.Remove(test.fileName)
 			if err == nil && errR != nil {
@@ -278,6 +296,15 @@ func TestNew(t *testing.T) {
 	}
 }
 
+var testsIdentify = []struct {
+	caseName string
+	login    string
+	want     iderr
+}{
+	{caseName: "unregistred login", login: "Piter", want: iderr{id: 0, err: interfaces.ErrLogin}},
+	{caseName: "registred login", login: "Joe", want: iderr{id: 2, err: nil}},
+}
+
 func TestAuthorize(t *testing.T) {
 	log.SetOutput(ioutil.Discard)
 	authorizator, contentBefore := pretestActions(t, commonFileName)
@@ -298,6 +325,24 @@ func TestAuthorize(t *testing.T) {
 	}
 }
 
+func TestIdentify(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	authorizator, contentBefore := pretestActions(t, commonFileName)
+
+	for _, test := range testsIdentify {
+		t.Run(test.caseName, func(t *testing.T) {
+			id, err := authorizator.Identify(test.login)
+
+			testIDErr(t, test.want, iderr{id: id, err: err})
+		})
+	}
+
+	contentAfter := posttestActions(t, commonFileName)
+	if bytes.Compare(contentBefore, contentAfter) != 0 {
+		t.Errorf("unexpected file change")
+	}
+}
+
 func TestRegister(t *testing.T) {
 	log.SetOutput(ioutil.Discard)
 	authorizator, contentBefore := pretestActions(t, commonFileName)
@@ -374,6 +419,109 @@ func TestChangeRequisites(t *testing.T) {
 	}
 }
 
+func TestNewWithBackups(t *testing.T) {
+	_, err := NewWithBackups(commonFileName, -1)
+	if !errors.Is(err, ErrBackups) {
+		t.Errorf("Unexpected err:\nwant: %v,\ngot: %v.", ErrBackups, err)
+	}
+}
+
+func TestBackups(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	const backups = 2
+	authorizator, err := NewWithBackups(commonFileName, backups)
+	if err != nil {
+		t.Fatalf("Unexpected NewWithBackups() err: %v", err)
+	}
+	defer removeBackups(t, commonFileName, backups+1)
+
+	var previous []byte
+	for _, test := range testsRegister {
+		if previous, err = ioutil.ReadFile(commonFileName); err != nil {
+			t.Fatalf("Unexpected ReadFile err: %v", err)
+		}
+		if err := authorizator.Register(&test.requisites); err != nil {
+			continue
+		}
+
+		newest, err := ioutil.ReadFile(commonFileName + ".1")
+		if err != nil {
+			t.Fatalf("Unexpected backup ReadFile err: %v", err)
+		}
+		if bytes.Compare(previous, newest) != 0 {
+			t.Errorf("Unexpected backup content:\nwant: %q,\ngot: %q.", previous, newest)
+		}
+	}
+
+	if _, err := os.Stat(fmt.Sprintf("%s.%d", commonFileName, backups)); err != nil {
+		t.Errorf("Unexpected oldest backup Stat err: %v", err)
+	}
+	if _, err := os.Stat(fmt.Sprintf("%s.%d", commonFileName, backups+1)); !os.IsNotExist(err) {
+		t.Errorf("Unexpected extra backup Stat err:\nwant: %v,\ngot: %v.", os.ErrNotExist, err)
+	}
+
+	reloaded, err := New(commonFileName)
+	if err != nil {
+		t.Fatalf("Unexpected New() err: %v", err)
+	}
+	if reloaded.Len() != authorizator.Len() {
+		t.Errorf("Unexpected users count:\nwant: %d,\ngot: %d.", authorizator.Len(), reloaded.Len())
+	}
+}
+
+func TestWatch(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	authorizator, _ := pretestActions(t, commonFileName)
+	if err := authorizator.Watch(); err != nil {
+		t.Fatalf("Unexpected Watch() err: %v", err)
+	}
+	if err := authorizator.Watch(); !errors.Is(err, ErrWatching) {
+		t.Errorf("Unexpected second Watch() err:\nwant: %v,\ngot: %v.", ErrWatching, err)
+	}
+
+	if err := mkFileWithContent(commonFileName, jsonPrestoredContent); err != nil {
+		t.Fatalf("Unexpected mkFile() err: %v", err)
+	}
+	waitUsersCount(t, authorizator, 1)
+
+	if err := mkFileWithContent(commonFileName, jsonCorruptedContent); err != nil {
+		t.Fatalf("Unexpected mkFile() err: %v", err)
+	}
+	time.Sleep(3 * watchTimeStep)
+	waitUsersCount(t, authorizator, 1)
+
+	if err := authorizator.Register(&interfaces.Requisites{Login: "Piter", Password: "ppp"}); err != nil {
+		t.Fatalf("Unexpected Register() err: %v", err)
+	}
+	time.Sleep(3 * watchTimeStep)
+	waitUsersCount(t, authorizator, 2)
+
+	if err := authorizator.Close(); err != nil {
+		t.Errorf("Unexpected Close() err: %v", err)
+	}
+	posttestActions(t, commonFileName)
+}
+
+const watchTimeStep = 100 * time.Millisecond
+
+func waitUsersCount(t *testing.T, authorizator *Authorizator, count int) {
+	for i := 0; i < 20 && authorizator.Len() != count; i++ {
+		time.Sleep(watchTimeStep)
+	}
+	if authorizator.Len() != count {
+		t.Errorf("Unexpected users count:\nwant: %d,\ngot: %d.", count, authorizator.Len())
+	}
+}
+
+func removeBackups(t *testing.T, fileName string, backups int) {
+	if err := os.Remove(fileName); err != nil {
+		t.Errorf("Unexpected os.Remove err: %v.", err)
+	}
+	for i := 1; i <= backups; i++ {
+		os.Remove(fmt.Sprintf("%s.%d", fileName, i))
+	}
+}
+
 func mkFileWithContent(fileName, fileContent string) error {
 	if len(fileContent) < 1 {
 		return nil
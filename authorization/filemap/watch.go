@@ -0,0 +1,143 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package filemap
+
+import (
+	"crypto/sha256"
+	"errors"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDelay is a time to wait for an editor to finish
+// a series of writes before the file is reloaded.
+const reloadDelay = 100 * time.Millisecond
+
+// ErrWatching error occurs when Watch invoked for already watched Authorizator
+var ErrWatching = errors.New("users file already watched")
+
+type watcher struct {
+	notify *fsnotify.Watcher
+	done   chan struct{}
+}
+
+// Watch starts reloading of users on every external change of users file.
+// Content of the file is validated before use,
+// so a corrupted edit leaves current users untouched.
+// Close should be invoked to stop watching.
+func (authorizator *Authorizator) Watch() error {
+	authorizator.mutex.Lock()
+	defer authorizator.mutex.Unlock()
+
+	if authorizator.watcher != nil {
+		return ErrWatching
+	}
+
+	notify, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	// editors and storeUsers replace the file, so the directory is watched.
+	if err := notify.Add(filepath.Dir(authorizator.fileName)); err != nil {
+		notify.Close()
+		return err
+	}
+
+	authorizator.watcher = &watcher{
+		notify: notify,
+		done:   make(chan struct{}),
+	}
+	go authorizator.watch(authorizator.watcher)
+	return nil
+}
+
+// Close stops watching of users file, if it is.
+func (authorizator *Authorizator) Close() error {
+	authorizator.mutex.Lock()
+	w := authorizator.watcher
+	authorizator.watcher = nil
+	authorizator.mutex.Unlock()
+
+	if w == nil {
+		return nil
+	}
+	err := w.notify.Close()
+	<-w.done
+	return err
+}
+
+func (authorizator *Authorizator) watch(w *watcher) {
+	defer close(w.done)
+
+	fileName := filepath.Clean(authorizator.fileName)
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case event, ok := <-w.notify.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != fileName ||
+				event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			timer.Reset(reloadDelay)
+		case err, ok := <-w.notify.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("users file %q watching error: %v", fileName, err)
+		case <-timer.C:
+			authorizator.reloadUsers()
+		}
+	}
+}
+
+// reloadUsers replaces users by content of users file,
+// if it differs from the last loaded or stored one and is valid.
+func (authorizator *Authorizator) reloadUsers() {
+	authorizator.mutex.Lock()
+	defer authorizator.mutex.Unlock()
+
+	content, err := ioutil.ReadFile(authorizator.fileName)
+	if err != nil {
+		log.Printf("users file %q reload failed: %v", authorizator.fileName, err)
+		return
+	}
+
+	sum := sha256.Sum256(content)
+	if sum == authorizator.sum {
+		return
+	}
+
+	users, err := authorizator.decodeUsers(content)
+	if err != nil {
+		log.Printf("users file %q reload failed, previous users kept: %v", authorizator.fileName, err)
+		return
+	}
+
+	authorizator.users = users
+	authorizator.sum = sum
+	log.Printf("users file %q reloaded: %d users", authorizator.fileName, len(users))
+}
@@ -0,0 +1,244 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package ldap
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+)
+
+// LDAPv3 protocol operations (RFC 4511) used by Authorizator
+const (
+	appBindRequest      ber.Tag = 0
+	appBindResponse     ber.Tag = 1
+	appUnbindRequest    ber.Tag = 2
+	appSearchRequest    ber.Tag = 3
+	appSearchResultItem ber.Tag = 4
+	appSearchResultDone ber.Tag = 5
+	appExtendedRequest  ber.Tag = 23
+	appExtendedResponse ber.Tag = 24
+)
+
+// LDAP result codes
+const (
+	ResultSuccess            = 0
+	ResultNoSuchObject       = 32
+	ResultInvalidCredentials = 49
+	ResultInsufficientAccess = 50
+	ResultUnwillingToPerform = 53
+)
+
+const (
+	protocolVersion         = 3
+	scopeBaseObject         = 0
+	filterPresent   ber.Tag = 7
+)
+
+// passwordModifyOID is the name of Password Modify Extended Operation (RFC 3062)
+const passwordModifyOID = "1.3.6.1.4.1.4203.1.11.1"
+
+var (
+	// ErrProtocol occurs when LDAP server responds with unexpected message
+	ErrProtocol = errors.New("unexpected LDAP message")
+)
+
+// ResultError is a non successful result of LDAP operation
+type ResultError struct {
+	Code    int64
+	Message string
+}
+
+func (err *ResultError) Error() string {
+	return fmt.Sprintf("LDAP result code %d: %s", err.Code, err.Message)
+}
+
+// conn is a minimal synchronous LDAPv3 client connection
+type conn struct {
+	net.Conn
+	messageID int64
+}
+
+func (c *conn) bind(dn, password string) error {
+	request := ber.Encode(ber.ClassApplication, ber.TypeConstructed, appBindRequest, nil, "Bind Request")
+	request.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, protocolVersion, "Version"))
+	request.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, dn, "DN"))
+	request.AppendChild(ber.NewString(ber.ClassContext, ber.TypePrimitive, 0, password, "Simple Password"))
+
+	response, err := c.do(request, appBindResponse)
+	if err != nil {
+		return err
+	}
+	return checkResult(response)
+}
+
+// attribute returns values of attribute of the entry with dn
+func (c *conn) attribute(dn, attribute string) ([]string, error) {
+	request := ber.Encode(ber.ClassApplication, ber.TypeConstructed, appSearchRequest, nil, "Search Request")
+	request.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, dn, "Base DN"))
+	request.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, scopeBaseObject, "Scope"))
+	request.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, 0, "Deref Aliases"))
+	request.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, 1, "Size Limit"))
+	request.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, 0, "Time Limit"))
+	request.AppendChild(ber.NewLDAPBoolean(ber.ClassUniversal, ber.TypePrimitive, ber.TagBoolean, false, "Types Only"))
+	request.AppendChild(ber.NewString(ber.ClassContext, ber.TypePrimitive, filterPresent, "objectClass", "Filter"))
+	attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+	attributes.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, attribute, "Attribute"))
+	request.AppendChild(attributes)
+
+	if err := c.send(request); err != nil {
+		return nil, err
+	}
+
+	var values []string
+	for {
+		response, err := c.receive()
+		if err != nil {
+			return nil, err
+		}
+
+		switch response.Tag {
+		case appSearchResultItem:
+			values = append(values, entryValues(response, attribute)...)
+		case appSearchResultDone:
+			return values, checkResult(response)
+		default:
+			return nil, fmt.Errorf("%w: %d", ErrProtocol, response.Tag)
+		}
+	}
+}
+
+// passwordModify changes password of entry with dn by Password Modify Extended Operation
+func (c *conn) passwordModify(dn, oldPassword, newPassword string) error {
+	value := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Password Modify Request")
+	value.AppendChild(ber.NewString(ber.ClassContext, ber.TypePrimitive, 0, dn, "User Identity"))
+	value.AppendChild(ber.NewString(ber.ClassContext, ber.TypePrimitive, 1, oldPassword, "Old Password"))
+	value.AppendChild(ber.NewString(ber.ClassContext, ber.TypePrimitive, 2, newPassword, "New Password"))
+
+	request := ber.Encode(ber.ClassApplication, ber.TypeConstructed, appExtendedRequest, nil, "Extended Request")
+	request.AppendChild(ber.NewString(ber.ClassContext, ber.TypePrimitive, 0, passwordModifyOID, "Request Name"))
+	requestValue := ber.Encode(ber.ClassContext, ber.TypePrimitive, 1, nil, "Request Value")
+	requestValue.Data.Write(value.Bytes())
+	request.AppendChild(requestValue)
+
+	response, err := c.do(request, appExtendedResponse)
+	if err != nil {
+		return err
+	}
+	return checkResult(response)
+}
+
+// unbind notifies the server about the end of session and closes connection
+func (c *conn) unbind() error {
+	request := ber.Encode(ber.ClassApplication, ber.TypePrimitive, appUnbindRequest, nil, "Unbind Request")
+	err := c.send(request)
+	if errC := c.Close(); err == nil {
+		err = errC
+	}
+	return err
+}
+
+func (c *conn) do(request *ber.Packet, responseTag ber.Tag) (*ber.Packet, error) {
+	if err := c.send(request); err != nil {
+		return nil, err
+	}
+
+	response, err := c.receive()
+	if err != nil {
+		return nil, err
+	}
+	if response.Tag != responseTag {
+		return nil, fmt.Errorf("%w: %d instead of %d", ErrProtocol, response.Tag, responseTag)
+	}
+	return response, nil
+}
+
+func (c *conn) send(operation *ber.Packet) error {
+	c.messageID++
+	message := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Message")
+	message.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, c.messageID, "Message ID"))
+	message.AppendChild(operation)
+
+	_, err := c.Write(message.Bytes())
+	return err
+}
+
+// receive reads the response to the last sent message and returns it's protocol operation
+func (c *conn) receive() (*ber.Packet, error) {
+	message, err := ber.ReadPacket(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(message.Children) < 2 {
+		return nil, fmt.Errorf("%w: %d elements", ErrProtocol, len(message.Children))
+	}
+	if id, ok := message.Children[0].Value.(int64); !ok || id != c.messageID {
+		return nil, fmt.Errorf("%w: message id %v instead of %d", ErrProtocol, message.Children[0].Value, c.messageID)
+	}
+
+	operation := message.Children[1]
+	if operation.ClassType != ber.ClassApplication {
+		return nil, fmt.Errorf("%w: class %d", ErrProtocol, operation.ClassType)
+	}
+	return operation, nil
+}
+
+// checkResult converts LDAPResult of response into error
+func checkResult(response *ber.Packet) error {
+	if len(response.Children) < 3 {
+		return fmt.Errorf("%w: %d result elements", ErrProtocol, len(response.Children))
+	}
+
+	code, ok := response.Children[0].Value.(int64)
+	if !ok {
+		return fmt.Errorf("%w: result code %v", ErrProtocol, response.Children[0].Value)
+	}
+	if code == ResultSuccess {
+		return nil
+	}
+
+	message, _ := response.Children[2].Value.(string)
+	return &ResultError{Code: code, Message: message}
+}
+
+// entryValues returns values of attribute from SearchResultEntry
+func entryValues(entry *ber.Packet, attribute string) []string {
+	if len(entry.Children) < 2 {
+		return nil
+	}
+
+	var values []string
+	for _, partial := range entry.Children[1].Children {
+		if len(partial.Children) < 2 {
+			continue
+		}
+		// attribute descriptions are case insensitive
+		if name, _ := partial.Children[0].Value.(string); !strings.EqualFold(name, attribute) {
+			continue
+		}
+		for _, value := range partial.Children[1].Children {
+			if str, ok := value.Value.(string); ok {
+				values = append(values, str)
+			}
+		}
+	}
+	return values
+}
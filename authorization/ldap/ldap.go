@@ -0,0 +1,291 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package ldap provides LDAP realization of interfaces.Authorizator interface.
+// A user is authorized by simple bind with his requisites,
+// his id is taken from a numeric attribute of his entry.
+//
+// With a service account the entry of user is looked up before the bind,
+// so an unknown login is reported as interfaces.ErrLogin,
+// and users are identified without password by Identify.
+// Without it an unknown login can't be told from a wrong password,
+// both are reported as interfaces.ErrPassword, and Identify isn't supported.
+package ldap
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yagoggame/grpc_server/interfaces"
+)
+
+const defaultTimeout = 5 * time.Second
+
+var (
+	// ErrReadOnly occurs when modifying operation is not allowed by configuration
+	ErrReadOnly = errors.New("LDAP directory is read only")
+	// ErrUserDN occurs when UserDN template doesn't contain exactly one %s
+	ErrUserDN = errors.New("user DN template must contain exactly one %s")
+	// ErrNoID occurs when user's entry has no valid numeric id attribute
+	ErrNoID = errors.New("no valid id attribute")
+	// ErrNoService occurs when Identify is called without configured service account
+	ErrNoService = errors.New("no service account to look up users")
+	// ErrService occurs when service account fails to bind
+	ErrService = errors.New("failed to bind service account")
+)
+
+// ConnectionData struct stores all directory requisites
+type ConnectionData struct {
+	// Address is host:port of LDAP server
+	Address string
+	// TLS enables LDAPS connection with this configuration, if not nil
+	TLS *tls.Config
+	// UserDN is a template of user's entry DN with %s for escaped login,
+	// e.g. "uid=%s,ou=people,dc=example,dc=org"
+	UserDN string
+	// IDAttribute is a numeric attribute of user's entry used as id, e.g. "uidNumber"
+	IDAttribute string
+	// BindDN is DN of service account, which looks up entries of users, if not empty
+	BindDN string
+	// BindPassword is password of service account
+	BindPassword string
+	// PasswordModify allows ChangeRequisites to change password
+	// by Password Modify Extended Operation, otherwise directory is read only
+	PasswordModify bool
+	// Timeout of a whole LDAP session, defaultTimeout if zero
+	Timeout time.Duration
+}
+
+// Authorizator implements interfaces.Authorizator interface
+type Authorizator struct {
+	conData ConnectionData
+}
+
+// New constructs new Authorizator
+func New(conData *ConnectionData) (*Authorizator, error) {
+	if strings.Count(conData.UserDN, "%s") != 1 || strings.Count(conData.UserDN, "%") != 1 {
+		return nil, fmt.Errorf("%w: %q", ErrUserDN, conData.UserDN)
+	}
+
+	authorizator := &Authorizator{conData: *conData}
+	if authorizator.conData.Timeout <= 0 {
+		authorizator.conData.Timeout = defaultTimeout
+	}
+	return authorizator, nil
+}
+
+// Authorize attempts to authorize a user and returns the id if success
+func (authorizator *Authorizator) Authorize(requisites *interfaces.Requisites) (id int, err error) {
+	c, dn, err := authorizator.bind(requisites)
+	if err != nil {
+		return 0, err
+	}
+	defer c.unbind()
+
+	id, err = authorizator.id(c, dn)
+	if err != nil {
+		return 0, err
+	}
+
+	log.Printf("authenticated client: %s, %d", requisites.Login, id)
+	return id, nil
+}
+
+// Identify returns the id of user with login without password check.
+// It needs a service account, otherwise it returns ErrNoService.
+func (authorizator *Authorizator) Identify(login string) (id int, err error) {
+	if authorizator.conData.BindDN == "" {
+		return 0, ErrNoService
+	}
+	if len(login) < 1 {
+		return 0, interfaces.ErrLogin
+	}
+
+	c, err := authorizator.dial()
+	if err != nil {
+		return 0, err
+	}
+	defer c.unbind()
+
+	if err := authorizator.bindService(c); err != nil {
+		return 0, err
+	}
+	return authorizator.id(c, fmt.Sprintf(authorizator.conData.UserDN, escapeDN(login)))
+}
+
+// Register is not supported, accounts are created by directory administrators
+func (authorizator *Authorizator) Register(requisites *interfaces.Requisites) error {
+	return ErrReadOnly
+}
+
+// Remove is not supported, accounts are removed by directory administrators
+func (authorizator *Authorizator) Remove(requisites *interfaces.Requisites) error {
+	return ErrReadOnly
+}
+
+// ChangeRequisites changes password of user, if PasswordModify is configured.
+// Login can't be changed.
+func (authorizator *Authorizator) ChangeRequisites(requisitesOld, requisitesNew *interfaces.Requisites) error {
+	if !authorizator.conData.PasswordModify || requisitesNew.Login != requisitesOld.Login {
+		return ErrReadOnly
+	}
+
+	c, dn, err := authorizator.bind(requisitesOld)
+	if err != nil {
+		return err
+	}
+	defer c.unbind()
+
+	if err := c.passwordModify(dn, requisitesOld.Password, requisitesNew.Password); err != nil {
+		return mapError(err)
+	}
+
+	log.Printf("password changed for: %s", requisitesOld.Login)
+	return nil
+}
+
+// bind opens a connection authenticated with requisites
+func (authorizator *Authorizator) bind(requisites *interfaces.Requisites) (*conn, string, error) {
+	if len(requisites.Login) < 1 {
+		return nil, "", interfaces.ErrLogin
+	}
+	// simple bind with empty password is an anonymous bind, which always succeeds
+	if len(requisites.Password) < 1 {
+		return nil, "", interfaces.ErrPassword
+	}
+
+	c, err := authorizator.dial()
+	if err != nil {
+		return nil, "", err
+	}
+
+	dn := fmt.Sprintf(authorizator.conData.UserDN, escapeDN(requisites.Login))
+	if authorizator.conData.BindDN != "" {
+		if err := authorizator.lookup(c, dn); err != nil {
+			c.Close()
+			return nil, "", err
+		}
+	}
+	if err := c.bind(dn, requisites.Password); err != nil {
+		c.Close()
+		return nil, "", mapError(err)
+	}
+	return c, dn, nil
+}
+
+// lookup checks by service account, that the entry with dn exists,
+// so a failed bind of user means a wrong password.
+func (authorizator *Authorizator) lookup(c *conn, dn string) error {
+	if err := authorizator.bindService(c); err != nil {
+		return err
+	}
+	if _, err := c.attribute(dn, authorizator.conData.IDAttribute); err != nil {
+		return mapError(err)
+	}
+	return nil
+}
+
+// bindService authenticates connection as service account
+func (authorizator *Authorizator) bindService(c *conn) error {
+	if err := c.bind(authorizator.conData.BindDN, authorizator.conData.BindPassword); err != nil {
+		return fmt.Errorf("%w: %v", ErrService, err)
+	}
+	return nil
+}
+
+// id returns id of user from the entry with dn
+func (authorizator *Authorizator) id(c *conn, dn string) (int, error) {
+	values, err := c.attribute(dn, authorizator.conData.IDAttribute)
+	if err != nil {
+		return 0, mapError(err)
+	}
+	if len(values) != 1 {
+		return 0, fmt.Errorf("%w: %d values of %q", ErrNoID, len(values), authorizator.conData.IDAttribute)
+	}
+	id, err := strconv.Atoi(values[0])
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("%w: %q of %q", ErrNoID, values[0], authorizator.conData.IDAttribute)
+	}
+	return id, nil
+}
+
+func (authorizator *Authorizator) dial() (*conn, error) {
+	dialer := &net.Dialer{Timeout: authorizator.conData.Timeout}
+
+	var (
+		netConn net.Conn
+		err     error
+	)
+	if authorizator.conData.TLS != nil {
+		netConn, err = tls.DialWithDialer(dialer, "tcp", authorizator.conData.Address, authorizator.conData.TLS)
+	} else {
+		netConn, err = dialer.Dial("tcp", authorizator.conData.Address)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := netConn.SetDeadline(time.Now().Add(authorizator.conData.Timeout)); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	return &conn{Conn: netConn}, nil
+}
+
+// mapError converts LDAP results to errors of interfaces package
+func mapError(err error) error {
+	var result *ResultError
+	if !errors.As(err, &result) {
+		return err
+	}
+
+	switch result.Code {
+	case ResultNoSuchObject:
+		return interfaces.ErrLogin
+	case ResultInvalidCredentials:
+		// most servers don't distinguish unknown DN from wrong password,
+		// so without service account an unknown login is reported so too
+		return interfaces.ErrPassword
+	case ResultInsufficientAccess, ResultUnwillingToPerform:
+		return fmt.Errorf("%w: %v", ErrReadOnly, err)
+	}
+	return err
+}
+
+// escapeDN escapes login to be used as an attribute value of DN (RFC 4514)
+func escapeDN(value string) string {
+	var builder strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c == '\x00':
+			builder.WriteString(`\00`)
+			continue
+		case strings.IndexByte(`"+,;<>\=`, c) >= 0,
+			i == 0 && (c == ' ' || c == '#'),
+			i == len(value)-1 && c == ' ':
+			builder.WriteByte('\\')
+		}
+		builder.WriteByte(c)
+	}
+	return builder.String()
+}
@@ -0,0 +1,340 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package ldap_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"log"
+	"testing"
+	"time"
+
+	. "github.com/yagoggame/grpc_server/authorization/ldap"
+	"github.com/yagoggame/grpc_server/interfaces"
+)
+
+const (
+	userDN          = "uid=%s,ou=people,dc=example,dc=org"
+	idAttribute     = "uidNumber"
+	serviceDN       = "cn=service,dc=example,dc=org"
+	servicePassword = "sss"
+)
+
+func directoryEntries() map[string]*fakeEntry {
+	return map[string]*fakeEntry{
+		"uid=Joe,ou=people,dc=example,dc=org": {
+			password:   "aaa",
+			attributes: map[string][]string{idAttribute: {"2"}},
+		},
+		`uid=Nick\,Jr,ou=people,dc=example,dc=org`: {
+			password:   "bbb",
+			attributes: map[string][]string{idAttribute: {"3"}},
+		},
+		"uid=Printer,ou=people,dc=example,dc=org": {
+			password:   "ppp",
+			attributes: map[string][]string{"cn": {"Printer"}},
+		},
+		serviceDN: {
+			password: servicePassword,
+		},
+	}
+}
+
+var newTests = []struct {
+	caseName string
+	userDN   string
+	want     error
+}{
+	{caseName: "correct", userDN: userDN, want: nil},
+	{caseName: "no login placeholder", userDN: "ou=people,dc=example,dc=org", want: ErrUserDN},
+	{caseName: "extra verb", userDN: "uid=%s,cn=%d", want: ErrUserDN},
+}
+
+var authorizeTests = []struct {
+	caseName   string
+	requisites interfaces.Requisites
+	wantID     int
+	wantErr    error
+}{
+	{
+		caseName:   "registred login",
+		requisites: interfaces.Requisites{Login: "Joe", Password: "aaa"},
+		wantID:     2,
+	},
+	{
+		caseName:   "login to be escaped",
+		requisites: interfaces.Requisites{Login: "Nick,Jr", Password: "bbb"},
+		wantID:     3,
+	},
+	{
+		caseName:   "wrong password",
+		requisites: interfaces.Requisites{Login: "Joe", Password: "ababab"},
+		wantErr:    interfaces.ErrPassword,
+	},
+	{
+		caseName:   "unregistred login",
+		requisites: interfaces.Requisites{Login: "Piter", Password: "aaa"},
+		wantErr:    interfaces.ErrPassword,
+	},
+	{
+		caseName:   "empty password",
+		requisites: interfaces.Requisites{Login: "Joe", Password: ""},
+		wantErr:    interfaces.ErrPassword,
+	},
+	{
+		caseName:   "empty login",
+		requisites: interfaces.Requisites{Login: "", Password: "aaa"},
+		wantErr:    interfaces.ErrLogin,
+	},
+	{
+		caseName:   "no id attribute",
+		requisites: interfaces.Requisites{Login: "Printer", Password: "ppp"},
+		wantErr:    ErrNoID,
+	},
+}
+
+// serviceAuthorizeTests differ from authorizeTests, as unknown login is found out by service account
+var serviceAuthorizeTests = []struct {
+	caseName        string
+	servicePassword string
+	requisites      interfaces.Requisites
+	wantID          int
+	wantErr         error
+}{
+	{
+		caseName:        "registred login",
+		servicePassword: servicePassword,
+		requisites:      interfaces.Requisites{Login: "Joe", Password: "aaa"},
+		wantID:          2,
+	},
+	{
+		caseName:        "wrong password",
+		servicePassword: servicePassword,
+		requisites:      interfaces.Requisites{Login: "Joe", Password: "ababab"},
+		wantErr:         interfaces.ErrPassword,
+	},
+	{
+		caseName:        "unregistred login",
+		servicePassword: servicePassword,
+		requisites:      interfaces.Requisites{Login: "Piter", Password: "aaa"},
+		wantErr:         interfaces.ErrLogin,
+	},
+	{
+		caseName:        "wrong password of service",
+		servicePassword: "ababab",
+		requisites:      interfaces.Requisites{Login: "Joe", Password: "aaa"},
+		wantErr:         ErrService,
+	},
+}
+
+var identifyTests = []struct {
+	caseName        string
+	servicePassword string
+	login           string
+	wantID          int
+	wantErr         error
+}{
+	{caseName: "registred login", servicePassword: servicePassword, login: "Joe", wantID: 2},
+	{caseName: "login to be escaped", servicePassword: servicePassword, login: "Nick,Jr", wantID: 3},
+	{caseName: "unregistred login", servicePassword: servicePassword, login: "Piter", wantErr: interfaces.ErrLogin},
+	{caseName: "empty login", servicePassword: servicePassword, login: "", wantErr: interfaces.ErrLogin},
+	{caseName: "no id attribute", servicePassword: servicePassword, login: "Printer", wantErr: ErrNoID},
+	{caseName: "wrong password of service", servicePassword: "ababab", login: "Joe", wantErr: ErrService},
+}
+
+var changeRequisitesTests = []struct {
+	caseName       string
+	passwordModify bool
+	requisitesOld  interfaces.Requisites
+	requisitesNew  interfaces.Requisites
+	want           error
+}{
+	{
+		caseName:      "read only",
+		requisitesOld: interfaces.Requisites{Login: "Joe", Password: "aaa"},
+		requisitesNew: interfaces.Requisites{Login: "Joe", Password: "ccc"},
+		want:          ErrReadOnly,
+	},
+	{
+		caseName:       "change login",
+		passwordModify: true,
+		requisitesOld:  interfaces.Requisites{Login: "Joe", Password: "aaa"},
+		requisitesNew:  interfaces.Requisites{Login: "Teodor", Password: "aaa"},
+		want:           ErrReadOnly,
+	},
+	{
+		caseName:       "wrong password",
+		passwordModify: true,
+		requisitesOld:  interfaces.Requisites{Login: "Joe", Password: "ababab"},
+		requisitesNew:  interfaces.Requisites{Login: "Joe", Password: "ccc"},
+		want:           interfaces.ErrPassword,
+	},
+	{
+		caseName:       "change password",
+		passwordModify: true,
+		requisitesOld:  interfaces.Requisites{Login: "Joe", Password: "aaa"},
+		requisitesNew:  interfaces.Requisites{Login: "Joe", Password: "ccc"},
+		want:           nil,
+	},
+}
+
+func TestNew(t *testing.T) {
+	for _, test := range newTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			_, err := New(&ConnectionData{UserDN: test.userDN, IDAttribute: idAttribute})
+			if !errors.Is(err, test.want) {
+				t.Errorf("Unexpected err:\nwant: %v,\ngot: %v.", test.want, err)
+			}
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	directory := newFakeDirectory(t, directoryEntries())
+	defer directory.close()
+	authorizator := newAuthorizator(t, directory.address(), false)
+
+	for _, test := range authorizeTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			id, err := authorizator.Authorize(&test.requisites)
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("Unexpected err:\nwant: %v,\ngot: %v.", test.wantErr, err)
+			}
+			if id != test.wantID {
+				t.Errorf("Unexpected id:\nwant: %d,\ngot: %d.", test.wantID, id)
+			}
+		})
+	}
+}
+
+func TestAuthorizeWithService(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	directory := newFakeDirectory(t, directoryEntries())
+	defer directory.close()
+
+	for _, test := range serviceAuthorizeTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			authorizator := newServiceAuthorizator(t, directory.address(), test.servicePassword)
+			id, err := authorizator.Authorize(&test.requisites)
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("Unexpected err:\nwant: %v,\ngot: %v.", test.wantErr, err)
+			}
+			if id != test.wantID {
+				t.Errorf("Unexpected id:\nwant: %d,\ngot: %d.", test.wantID, id)
+			}
+		})
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	directory := newFakeDirectory(t, directoryEntries())
+	defer directory.close()
+
+	for _, test := range identifyTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			authorizator := newServiceAuthorizator(t, directory.address(), test.servicePassword)
+			id, err := authorizator.Identify(test.login)
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("Unexpected err:\nwant: %v,\ngot: %v.", test.wantErr, err)
+			}
+			if id != test.wantID {
+				t.Errorf("Unexpected id:\nwant: %d,\ngot: %d.", test.wantID, id)
+			}
+		})
+	}
+
+	authorizator := newAuthorizator(t, directory.address(), false)
+	if _, err := authorizator.Identify("Joe"); !errors.Is(err, ErrNoService) {
+		t.Errorf("Unexpected err without service account:\nwant: %v,\ngot: %v.", ErrNoService, err)
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	authorizator := newAuthorizator(t, "127.0.0.1:0", true)
+	requisites := &interfaces.Requisites{Login: "Piter", Password: "ppp"}
+
+	if err := authorizator.Register(requisites); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Unexpected Register err:\nwant: %v,\ngot: %v.", ErrReadOnly, err)
+	}
+	if err := authorizator.Remove(requisites); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Unexpected Remove err:\nwant: %v,\ngot: %v.", ErrReadOnly, err)
+	}
+}
+
+func TestChangeRequisites(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+
+	for _, test := range changeRequisitesTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			directory := newFakeDirectory(t, directoryEntries())
+			defer directory.close()
+			authorizator := newAuthorizator(t, directory.address(), test.passwordModify)
+
+			err := authorizator.ChangeRequisites(&test.requisitesOld, &test.requisitesNew)
+			if !errors.Is(err, test.want) {
+				t.Errorf("Unexpected err:\nwant: %v,\ngot: %v.", test.want, err)
+			}
+
+			_, authErr := authorizator.Authorize(&test.requisitesNew)
+			if (err == nil) != (authErr == nil) {
+				t.Errorf("Unexpected Authorize with new requisites err: %v.", authErr)
+			}
+		})
+	}
+}
+
+func TestUnreachable(t *testing.T) {
+	directory := newFakeDirectory(t, directoryEntries())
+	address := directory.address()
+	directory.close()
+
+	authorizator := newAuthorizator(t, address, false)
+	_, err := authorizator.Authorize(&interfaces.Requisites{Login: "Joe", Password: "aaa"})
+	if err == nil || errors.Is(err, interfaces.ErrLogin) || errors.Is(err, interfaces.ErrPassword) {
+		t.Errorf("Unexpected err:\nwant: connection error,\ngot: %v.", err)
+	}
+}
+
+func newAuthorizator(t *testing.T, address string, passwordModify bool) *Authorizator {
+	authorizator, err := New(&ConnectionData{
+		Address:        address,
+		UserDN:         userDN,
+		IDAttribute:    idAttribute,
+		PasswordModify: passwordModify,
+		Timeout:        time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected New err: %v", err)
+	}
+	return authorizator
+}
+
+func newServiceAuthorizator(t *testing.T, address, password string) *Authorizator {
+	authorizator, err := New(&ConnectionData{
+		Address:      address,
+		UserDN:       userDN,
+		IDAttribute:  idAttribute,
+		BindDN:       serviceDN,
+		BindPassword: password,
+		Timeout:      time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected New err: %v", err)
+	}
+	return authorizator
+}
@@ -0,0 +1,196 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package ldap_test
+
+import (
+	"net"
+	"sync"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	. "github.com/yagoggame/grpc_server/authorization/ldap"
+)
+
+const passwordModifyOID = "1.3.6.1.4.1.4203.1.11.1"
+
+type fakeEntry struct {
+	password   string
+	attributes map[string][]string
+}
+
+// fakeDirectory is an in-process LDAP server which supports
+// just enough of the protocol to serve Authorizator.
+type fakeDirectory struct {
+	listener net.Listener
+	entries  map[string]*fakeEntry
+	mutex    sync.Mutex
+	wg       sync.WaitGroup
+}
+
+func newFakeDirectory(t *testing.T, entries map[string]*fakeEntry) *fakeDirectory {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected Listen err: %v", err)
+	}
+
+	directory := &fakeDirectory{listener: listener, entries: entries}
+	directory.wg.Add(1)
+	go directory.accept()
+	return directory
+}
+
+func (directory *fakeDirectory) address() string {
+	return directory.listener.Addr().String()
+}
+
+func (directory *fakeDirectory) close() {
+	directory.listener.Close()
+	directory.wg.Wait()
+}
+
+func (directory *fakeDirectory) accept() {
+	defer directory.wg.Done()
+	for {
+		conn, err := directory.listener.Accept()
+		if err != nil {
+			return
+		}
+		directory.wg.Add(1)
+		go directory.serve(conn)
+	}
+}
+
+func (directory *fakeDirectory) serve(conn net.Conn) {
+	defer directory.wg.Done()
+	defer conn.Close()
+
+	var bound string
+	for {
+		message, err := ber.ReadPacket(conn)
+		if err != nil || len(message.Children) < 2 {
+			return
+		}
+		id := message.Children[0].Value.(int64)
+		operation := message.Children[1]
+
+		var responses []*ber.Packet
+		switch operation.Tag {
+		case 0:
+			var code int
+			bound, code = directory.bind(operation)
+			responses = append(responses, result(1, code))
+		case 2:
+			return
+		case 3:
+			responses = directory.search(operation, bound)
+		case 23:
+			responses = append(responses, result(24, directory.passwordModify(operation, bound)))
+		default:
+			return
+		}
+
+		for _, response := range responses {
+			envelope := ber.NewSequence("LDAP Message")
+			envelope.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, "Message ID"))
+			envelope.AppendChild(response)
+			if _, err := conn.Write(envelope.Bytes()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (directory *fakeDirectory) bind(request *ber.Packet) (string, int) {
+	directory.mutex.Lock()
+	defer directory.mutex.Unlock()
+
+	dn := request.Children[1].Value.(string)
+	entry, ok := directory.entries[dn]
+	if !ok || entry.password != request.Children[2].Data.String() {
+		return "", ResultInvalidCredentials
+	}
+	return dn, ResultSuccess
+}
+
+// search serves only authenticated clients as directories usually do.
+func (directory *fakeDirectory) search(request *ber.Packet, bound string) []*ber.Packet {
+	directory.mutex.Lock()
+	defer directory.mutex.Unlock()
+
+	if bound == "" {
+		return []*ber.Packet{result(5, ResultInsufficientAccess)}
+	}
+	dn := request.Children[0].Value.(string)
+	entry, ok := directory.entries[dn]
+	if !ok {
+		return []*ber.Packet{result(5, ResultNoSuchObject)}
+	}
+	attribute := request.Children[7].Children[0].Value.(string)
+
+	item := ber.Encode(ber.ClassApplication, ber.TypeConstructed, 4, nil, "Search Result Entry")
+	item.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, dn, "DN"))
+	attributes := ber.NewSequence("Attributes")
+	if values, ok := entry.attributes[attribute]; ok {
+		partial := ber.NewSequence("Attribute")
+		partial.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, attribute, "Type"))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, value := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+		}
+		partial.AppendChild(set)
+		attributes.AppendChild(partial)
+	}
+	item.AppendChild(attributes)
+
+	return []*ber.Packet{item, result(5, ResultSuccess)}
+}
+
+func (directory *fakeDirectory) passwordModify(request *ber.Packet, bound string) int {
+	directory.mutex.Lock()
+	defer directory.mutex.Unlock()
+
+	if request.Children[0].Data.String() != passwordModifyOID || len(request.Children) < 2 {
+		return ResultUnwillingToPerform
+	}
+	value, err := ber.DecodePacketErr(request.Children[1].Data.Bytes())
+	if err != nil {
+		return ResultUnwillingToPerform
+	}
+
+	fields := make(map[ber.Tag]string, 3)
+	for _, field := range value.Children {
+		fields[field.Tag] = field.Data.String()
+	}
+
+	entry, ok := directory.entries[fields[0]]
+	if !ok || fields[0] != bound {
+		return ResultInsufficientAccess
+	}
+	if entry.password != fields[1] {
+		return ResultInvalidCredentials
+	}
+	entry.password = fields[2]
+	return ResultSuccess
+}
+
+func result(tag ber.Tag, code int) *ber.Packet {
+	response := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Response")
+	response.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, "Result Code"))
+	response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Diagnostic Message"))
+	return response
+}
@@ -83,6 +83,19 @@ func (authorizator *Authorizator) Authorize(requisites *interfaces.Requisites) (
 	return id, nil
 }
 
+// Identify returns the id of user with login without password check
+func (authorizator *Authorizator) Identify(login string) (id int, err error) {
+	err = authorizator.db.QueryRow("SELECT id FROM users WHERE username = $1 LIMIT 1", login).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, interfaces.ErrLogin
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
 // Register attempts to register a new user and returns the id if success
 func (authorizator *Authorizator) Register(requisites *interfaces.Requisites) (err error) {
 	tx, err := authorizator.db.Begin()
@@ -94,6 +94,31 @@ var authorizeTests = []*commonTestCase{
 	},
 }
 
+var identifyTests = []*commonTestCase{
+	&commonTestCase{
+		name:           "identified user",
+		userRequisites: joe,
+		want:           iderr{id: 1, err: nil},
+		retRowsSel1: []*sqlmock.Rows{
+			sqlmock.NewRows([]string{"id"}).
+				AddRow(1)},
+	},
+	&commonTestCase{
+		name:           "login not found",
+		userRequisites: nick,
+		retErrSel1:     sql.ErrNoRows,
+		want:           iderr{id: 0, err: interfaces.ErrLogin},
+		retRowsSel1:    []*sqlmock.Rows{},
+	},
+	&commonTestCase{
+		name:           "some request error",
+		userRequisites: joe,
+		retErrSel1:     sql.ErrTxDone,
+		want:           iderr{id: 0, err: sql.ErrTxDone},
+		retRowsSel1:    []*sqlmock.Rows{},
+	},
+}
+
 var registerTests = []*commonTestCase{
 	&commonTestCase{
 		name:           "some query error",
@@ -357,6 +382,31 @@ func performAuthorizeTest(t *testing.T, test *commonTestCase) {
 	}
 }
 
+func TestIdentify(t *testing.T) {
+	for _, test := range identifyTests {
+		t.Run(test.name, func(t *testing.T) {
+			performIdentifyTest(t, test)
+		})
+	}
+}
+
+func performIdentifyTest(t *testing.T, test *commonTestCase) {
+	authorizator, mock := initMock(t)
+	defer authorizator.Close()
+
+	mock.ExpectQuery("SELECT id FROM users WHERE username = \\$1 LIMIT 1").
+		WithArgs(test.userRequisites.Login).
+		WillReturnRows(test.retRowsSel1...).
+		WillReturnError(test.retErrSel1)
+
+	id, err := authorizator.Identify(test.userRequisites.Login)
+
+	testIDErr(t, test.want, iderr{id: id, err: err})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
 func TestRegister(t *testing.T) {
 	for _, test := range registerTests {
 		t.Run(test.name, func(t *testing.T) {
@@ -0,0 +1,84 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package board renders api.State as ASCII text.
+package board
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yagoggame/api"
+)
+
+// Marks of points on the board
+const (
+	Empty          = '.'
+	Black          = 'X'
+	White          = 'O'
+	BlackTerritory = 'x'
+	WhiteTerritory = 'o'
+)
+
+// Render returns ASCII picture of the board with column numbers (x) on the top
+// and row numbers (y) on the left, followed by the score of both colours.
+// Coordinates start with 1 as in api.TurnMessage.
+func Render(state *api.State) string {
+	size := int(state.GetSize())
+	points := make([][]byte, size)
+	for y := range points {
+		points[y] = []byte(strings.Repeat(string(Empty), size))
+	}
+
+	put := func(turns []*api.TurnMessage, mark byte) {
+		for _, turn := range turns {
+			x, y := int(turn.GetX()), int(turn.GetY())
+			if x >= 1 && x <= size && y >= 1 && y <= size {
+				points[y-1][x-1] = mark
+			}
+		}
+	}
+	put(state.GetBlack().GetPointsUnderControl(), BlackTerritory)
+	put(state.GetWhite().GetPointsUnderControl(), WhiteTerritory)
+	put(state.GetBlack().GetChipsOnBoard(), Black)
+	put(state.GetWhite().GetChipsOnBoard(), White)
+
+	var builder strings.Builder
+	builder.WriteString("   ")
+	for x := 1; x <= size; x++ {
+		fmt.Fprintf(&builder, "%3d", x)
+	}
+	builder.WriteByte('\n')
+	for y := 1; y <= size; y++ {
+		fmt.Fprintf(&builder, "%3d", y)
+		for x := 1; x <= size; x++ {
+			fmt.Fprintf(&builder, "%3c", points[y-1][x-1])
+		}
+		builder.WriteByte('\n')
+	}
+
+	fmt.Fprintf(&builder, "black %c: %s\n", Black, colourSummary(state.GetBlack()))
+	fmt.Fprintf(&builder, "white %c: %s, komi %g\n", White, colourSummary(state.GetWhite()), state.GetKomi())
+	if state.GetGameOver() {
+		builder.WriteString("game over\n")
+	}
+	return builder.String()
+}
+
+func colourSummary(colour *api.State_ColourState) string {
+	return fmt.Sprintf("scores %g, captured %d, in cup %d",
+		colour.GetScores(), colour.GetChipsCaptured(), colour.GetChipsInCap())
+}
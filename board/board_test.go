@@ -0,0 +1,75 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package board_test
+
+import (
+	"testing"
+
+	"github.com/yagoggame/api"
+	. "github.com/yagoggame/grpc_server/board"
+)
+
+var renderTests = []struct {
+	caseName string
+	state    *api.State
+	want     string
+}{
+	{
+		caseName: "empty",
+		state:    &api.State{Size: 3, Komi: 0.5},
+		want: "     1  2  3\n" +
+			"  1  .  .  .\n" +
+			"  2  .  .  .\n" +
+			"  3  .  .  .\n" +
+			"black X: scores 0, captured 0, in cup 0\n" +
+			"white O: scores 0, captured 0, in cup 0, komi 0.5\n",
+	},
+	{
+		caseName: "chips and territory",
+		state: &api.State{
+			Size:     3,
+			GameOver: true,
+			Black: &api.State_ColourState{
+				ChipsOnBoard:       []*api.TurnMessage{{X: 1, Y: 2}, {X: 2, Y: 1}},
+				PointsUnderControl: []*api.TurnMessage{{X: 1, Y: 1}},
+				Scores:             1,
+				ChipsInCap:         3,
+			},
+			White: &api.State_ColourState{
+				ChipsOnBoard:  []*api.TurnMessage{{X: 3, Y: 3}, {X: 4, Y: 4}},
+				ChipsCaptured: 1,
+			},
+		},
+		want: "     1  2  3\n" +
+			"  1  x  X  .\n" +
+			"  2  X  .  .\n" +
+			"  3  .  .  O\n" +
+			"black X: scores 1, captured 0, in cup 3\n" +
+			"white O: scores 0, captured 1, in cup 0, komi 0\n" +
+			"game over\n",
+	},
+}
+
+func TestRender(t *testing.T) {
+	for _, test := range renderTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			if got := Render(test.state); got != test.want {
+				t.Errorf("Unexpected board:\nwant:\n%s\ngot:\n%s", test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package certreload provides TLS certificate of server,
+// which is reloaded on changes of certificate and key files.
+package certreload
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// expiryWarning is a time before expiry to warn about it on load
+const expiryWarning = 14 * 24 * time.Hour
+
+// reloadDelay is a time to wait for a tool to finish
+// replacing of both certificate and key files.
+const reloadDelay = 500 * time.Millisecond
+
+var (
+	// ErrExpired occurs when loaded certificate is expired
+	ErrExpired = errors.New("certificate expired")
+	// ErrWatching occurs when Watch invoked for already watched Reloader
+	ErrWatching = errors.New("certificate files already watched")
+)
+
+// Reloader keeps the last valid certificate loaded from certificate and key files
+type Reloader struct {
+	certFile string
+	keyFile  string
+	cert     *tls.Certificate
+	notAfter time.Time
+	sum      [sha256.Size]byte
+	mutex    sync.RWMutex
+	notify   *fsnotify.Watcher
+	done     chan struct{}
+}
+
+// New constructs new Reloader and loads the certificate
+func New(certFile, keyFile string) (*Reloader, error) {
+	reloader := &Reloader{certFile: certFile, keyFile: keyFile}
+	if err := reloader.Reload(); err != nil {
+		return nil, err
+	}
+	return reloader, nil
+}
+
+// GetCertificate returns the current certificate.
+// It is intended to be used as tls.Config.GetCertificate.
+func (reloader *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	reloader.mutex.RLock()
+	defer reloader.mutex.RUnlock()
+
+	return reloader.cert, nil
+}
+
+// NotAfter returns expiry time of the current certificate
+func (reloader *Reloader) NotAfter() time.Time {
+	reloader.mutex.RLock()
+	defer reloader.mutex.RUnlock()
+
+	return reloader.notAfter
+}
+
+// DaysUntilExpiry returns number of days until the current certificate expires,
+// negative if it is already expired
+func (reloader *Reloader) DaysUntilExpiry() float64 {
+	return time.Until(reloader.NotAfter()).Hours() / 24
+}
+
+// Reload loads certificate and key files, if they are changed since the last load.
+// The new pair is validated before use, so the current certificate
+// is kept if the files are invalid or the new certificate is expired.
+func (reloader *Reloader) Reload() error {
+	certPEM, err := ioutil.ReadFile(reloader.certFile)
+	if err != nil {
+		return err
+	}
+	keyPEM, err := ioutil.ReadFile(reloader.keyFile)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(append(append([]byte{}, certPEM...), keyPEM...))
+	reloader.mutex.RLock()
+	unchanged := reloader.cert != nil && sum == reloader.sum
+	reloader.mutex.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return err
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return err
+	}
+	if time.Now().After(leaf.NotAfter) {
+		return fmt.Errorf("%w: %q at %v", ErrExpired, reloader.certFile, leaf.NotAfter)
+	}
+	cert.Leaf = leaf
+
+	reloader.mutex.Lock()
+	reloader.cert = &cert
+	reloader.notAfter = leaf.NotAfter
+	reloader.sum = sum
+	reloader.mutex.Unlock()
+
+	log.Printf("TLS certificate %q loaded: valid from %v until %v",
+		reloader.certFile, leaf.NotBefore, leaf.NotAfter)
+	if time.Until(leaf.NotAfter) < expiryWarning {
+		log.Printf("WARNING: TLS certificate %q expires at %v", reloader.certFile, leaf.NotAfter)
+	}
+	return nil
+}
+
+// Watch starts reloading of certificate on every change of certificate or key file.
+// Close should be invoked to stop watching.
+func (reloader *Reloader) Watch() error {
+	reloader.mutex.Lock()
+	defer reloader.mutex.Unlock()
+
+	if reloader.notify != nil {
+		return ErrWatching
+	}
+
+	notify, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	// files are usually replaced or updated through symlinks,
+	// so the directories are watched.
+	for _, dir := range []string{filepath.Dir(reloader.certFile), filepath.Dir(reloader.keyFile)} {
+		if err := notify.Add(dir); err != nil {
+			notify.Close()
+			return err
+		}
+	}
+
+	reloader.notify = notify
+	reloader.done = make(chan struct{})
+	go reloader.watch(notify, reloader.done)
+	return nil
+}
+
+// Close stops watching of certificate files, if it is.
+func (reloader *Reloader) Close() error {
+	reloader.mutex.Lock()
+	notify, done := reloader.notify, reloader.done
+	reloader.notify = nil
+	reloader.mutex.Unlock()
+
+	if notify == nil {
+		return nil
+	}
+	err := notify.Close()
+	<-done
+	return err
+}
+
+func (reloader *Reloader) watch(notify *fsnotify.Watcher, done chan struct{}) {
+	defer close(done)
+
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case _, ok := <-notify.Events:
+			if !ok {
+				return
+			}
+			// unchanged files are skipped by Reload, so any event of directories will do
+			timer.Reset(reloadDelay)
+		case err, ok := <-notify.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("TLS certificate %q watching error: %v", reloader.certFile, err)
+		case <-timer.C:
+			if err := reloader.Reload(); err != nil {
+				log.Printf("TLS certificate %q reload failed, previous certificate kept: %v", reloader.certFile, err)
+			}
+		}
+	}
+}
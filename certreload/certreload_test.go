@@ -0,0 +1,183 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package certreload_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"log"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/yagoggame/grpc_server/certreload"
+)
+
+type pair struct {
+	cert []byte
+	key  []byte
+}
+
+var newTests = []struct {
+	caseName string
+	validFor time.Duration
+	want     error
+}{
+	{caseName: "valid", validFor: 48 * time.Hour, want: nil},
+	{caseName: "expired", validFor: -time.Hour, want: ErrExpired},
+}
+
+func TestNew(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+
+	for _, test := range newTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			certFile, keyFile := writePair(t, genPair(t, 1, test.validFor))
+			defer os.RemoveAll(filepath.Dir(certFile))
+
+			reloader, err := New(certFile, keyFile)
+			if !errors.Is(err, test.want) {
+				t.Fatalf("Unexpected err:\nwant: %v,\ngot: %v.", test.want, err)
+			}
+			if err != nil {
+				return
+			}
+			if days := reloader.DaysUntilExpiry(); math.Abs(days-2) > 0.01 {
+				t.Errorf("Unexpected days until expiry:\nwant: %v,\ngot: %v.", 2, days)
+			}
+		})
+	}
+}
+
+func TestReload(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	certFile, keyFile := writePair(t, genPair(t, 1, time.Hour))
+	defer os.RemoveAll(filepath.Dir(certFile))
+
+	reloader, err := New(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("Unexpected New err: %v.", err)
+	}
+	if err := reloader.Watch(); err != nil {
+		t.Fatalf("Unexpected Watch err: %v.", err)
+	}
+	defer reloader.Close()
+
+	if err := reloader.Watch(); !errors.Is(err, ErrWatching) {
+		t.Errorf("Unexpected second Watch err:\nwant: %v,\ngot: %v.", ErrWatching, err)
+	}
+
+	valid := genPair(t, 2, time.Hour)
+	overwritePair(t, certFile, keyFile, valid)
+	waitSerial(t, reloader, 2)
+
+	// key of other certificate
+	other := genPair(t, 3, time.Hour)
+	overwritePair(t, certFile, keyFile, pair{cert: other.cert, key: valid.key})
+	time.Sleep(time.Second)
+	if err := reloader.Reload(); err == nil {
+		t.Errorf("Unexpected Reload err:\nwant: key mismatch,\ngot: %v.", err)
+	}
+	checkSerial(t, reloader, 2)
+
+	overwritePair(t, certFile, keyFile, genPair(t, 4, -time.Hour))
+	time.Sleep(time.Second)
+	if err := reloader.Reload(); !errors.Is(err, ErrExpired) {
+		t.Errorf("Unexpected Reload err:\nwant: %v,\ngot: %v.", ErrExpired, err)
+	}
+	checkSerial(t, reloader, 2)
+
+	overwritePair(t, certFile, keyFile, other)
+	waitSerial(t, reloader, 3)
+}
+
+func waitSerial(t *testing.T, reloader *Reloader, serial int64) {
+	for i := 0; i < 50; i++ {
+		cert, _ := reloader.GetCertificate(nil)
+		if cert.Leaf.SerialNumber.Int64() == serial {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	checkSerial(t, reloader, serial)
+}
+
+func checkSerial(t *testing.T, reloader *Reloader, serial int64) {
+	cert, err := reloader.GetCertificate(nil)
+	if err != nil {
+		t.Fatalf("Unexpected GetCertificate err: %v.", err)
+	}
+	if got := cert.Leaf.SerialNumber.Int64(); got != serial {
+		t.Errorf("Unexpected certificate serial:\nwant: %d,\ngot: %d.", serial, got)
+	}
+}
+
+func genPair(t *testing.T, serial int64, validFor time.Duration) pair {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Unexpected GenerateKey err: %v.", err)
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    now.Add(-2 * time.Hour),
+		NotAfter:     now.Add(validFor),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Unexpected CreateCertificate err: %v.", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Unexpected MarshalECPrivateKey err: %v.", err)
+	}
+
+	return pair{
+		cert: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		key:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func writePair(t *testing.T, p pair) (string, string) {
+	dir, err := ioutil.TempDir("", "certreload")
+	if err != nil {
+		t.Fatalf("Unexpected TempDir err: %v.", err)
+	}
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	overwritePair(t, certFile, keyFile, p)
+	return certFile, keyFile
+}
+
+func overwritePair(t *testing.T, certFile, keyFile string, p pair) {
+	if err := ioutil.WriteFile(certFile, p.cert, 0644); err != nil {
+		t.Fatalf("Unexpected WriteFile err: %v.", err)
+	}
+	if err := ioutil.WriteFile(keyFile, p.key, 0600); err != nil {
+		t.Fatalf("Unexpected WriteFile err: %v.", err)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Blocklist is a Filter, which masks blocked words by asterisks.
+type Blocklist struct {
+	words map[string]struct{}
+}
+
+// NewBlocklist constructs Blocklist of words, they are compared case insensitive.
+func NewBlocklist(words []string) *Blocklist {
+	blocklist := &Blocklist{words: make(map[string]struct{}, len(words))}
+	for _, word := range words {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+			blocklist.words[word] = struct{}{}
+		}
+	}
+	return blocklist
+}
+
+// LoadBlocklist reads Blocklist from the file with one word per line.
+// Empty lines and lines starting with # are ignored.
+func LoadBlocklist(filename string) (*Blocklist, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewBlocklist(words), nil
+}
+
+// Filter masks every blocked word of text.
+func (blocklist *Blocklist) Filter(userID int, text string) (string, error) {
+	runes := []rune(text)
+	start := -1
+	for i := 0; i <= len(runes); i++ {
+		if i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			if _, ok := blocklist.words[strings.ToLower(string(runes[start:i]))]; ok {
+				for j := start; j < i; j++ {
+					runes[j] = '*'
+				}
+			}
+			start = -1
+		}
+	}
+	return string(runes), nil
+}
@@ -0,0 +1,78 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/yagoggame/grpc_server/chat"
+)
+
+var blocklistTests = []struct {
+	caseName string
+	text     string
+	want     string
+}{
+	{caseName: "clean", text: "good game", want: "good game"},
+	{caseName: "blocked", text: "you noob!", want: "you ****!"},
+	{caseName: "case insensitive", text: "NOOB", want: "****"},
+	{caseName: "part of word", text: "noobish", want: "noobish"},
+	{caseName: "several", text: "noob, lamer", want: "****, *****"},
+}
+
+func TestBlocklist(t *testing.T) {
+	blocklist := NewBlocklist([]string{"noob", " Lamer ", ""})
+
+	for _, test := range blocklistTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			got, err := blocklist.Filter(2, test.text)
+			if err != nil {
+				t.Fatalf("Unexpected err: %v", err)
+			}
+			if got != test.want {
+				t.Errorf("Unexpected text:\nwant: %q,\ngot: %q.", test.want, got)
+			}
+		})
+	}
+}
+
+func TestLoadBlocklist(t *testing.T) {
+	dir, err := ioutil.TempDir("", "blocklist")
+	if err != nil {
+		t.Fatalf("Unexpected TempDir err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "blocklist.txt")
+	if err := ioutil.WriteFile(filename, []byte("# words\nnoob\n\nlamer\n"), 0600); err != nil {
+		t.Fatalf("Unexpected WriteFile err: %v", err)
+	}
+
+	blocklist, err := LoadBlocklist(filename)
+	if err != nil {
+		t.Fatalf("Unexpected LoadBlocklist err: %v", err)
+	}
+	if got, _ := blocklist.Filter(2, "noob lamer words"); got != "**** ***** words" {
+		t.Errorf("Unexpected text:\nwant: %q,\ngot: %q.", "**** ***** words", got)
+	}
+
+	if _, err := LoadBlocklist(filepath.Join(dir, "absent.txt")); err == nil {
+		t.Errorf("Unexpected absence of err for absent file")
+	}
+}
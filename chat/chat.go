@@ -0,0 +1,250 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package chat provides channels of text messages with history,
+// length and rate limits and filtering of messages.
+package chat
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// subscriberBuffer is a size of buffer of subscription channels.
+// Messages are dropped for subscribers which don't read them.
+const subscriberBuffer = 32
+
+var (
+	// ErrEmpty occurs when message has no text
+	ErrEmpty = errors.New("empty message")
+	// ErrTooLong occurs when message is longer than allowed
+	ErrTooLong = errors.New("message is too long")
+	// ErrRateLimit occurs when user sends messages too often
+	ErrRateLimit = errors.New("messages are sent too often")
+	// ErrRejected occurs when Filter rejects a message
+	ErrRejected = errors.New("message rejected")
+)
+
+// Message is a message of user in a channel.
+type Message struct {
+	Channel string
+	UserID  int
+	Login   string
+	Text    string
+	Time    time.Time
+}
+
+// Filter checks and modifies text of messages before sending.
+// It returns ErrRejected wrapped, if message mustn't be sent.
+type Filter interface {
+	Filter(userID int, text string) (string, error)
+}
+
+// FilterFunc is an adapter to use ordinary functions as Filter.
+type FilterFunc func(userID int, text string) (string, error)
+
+// Filter calls f(userID, text).
+func (f FilterFunc) Filter(userID int, text string) (string, error) {
+	return f(userID, text)
+}
+
+// Options configures Hub.
+type Options struct {
+	// MaxLength is a maximal number of characters in a message, 0 means no limit
+	MaxLength int
+	// History is a number of last messages kept in each channel
+	History int
+	// Rate is a number of messages per second allowed for a user, 0 means no limit
+	Rate float64
+	// Burst is a number of messages a user can send at once
+	Burst int
+	// Filter is applied to the text of every message, if it is set
+	Filter Filter
+}
+
+type channel struct {
+	history     []*Message
+	subscribers map[chan *Message]struct{}
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Hub keeps channels of messages.
+type Hub struct {
+	options  Options
+	mutex    sync.Mutex
+	channels map[string]*channel
+	buckets  map[int]*bucket
+	now      func() time.Time
+}
+
+// New constructs new Hub.
+func New(options Options) *Hub {
+	if options.Burst < 1 {
+		options.Burst = 1
+	}
+	return &Hub{
+		options:  options,
+		channels: make(map[string]*channel),
+		buckets:  make(map[int]*bucket),
+		now:      time.Now,
+	}
+}
+
+// Send checks, filters and delivers message to subscribers of its channel.
+// Time of message is set by Hub.
+func (hub *Hub) Send(message *Message) error {
+	text := strings.TrimSpace(message.Text)
+	if text == "" {
+		return ErrEmpty
+	}
+	if hub.options.MaxLength > 0 && utf8.RuneCountInString(text) > hub.options.MaxLength {
+		return fmt.Errorf("%w: maximum is %d characters", ErrTooLong, hub.options.MaxLength)
+	}
+	if hub.options.Filter != nil {
+		filtered, err := hub.options.Filter.Filter(message.UserID, text)
+		if err != nil {
+			return err
+		}
+		text = filtered
+	}
+
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	if !hub.allow(message.UserID) {
+		return ErrRateLimit
+	}
+
+	sent := *message
+	sent.Text = text
+	sent.Time = hub.now()
+
+	ch := hub.channel(message.Channel)
+	ch.history = append(ch.history, &sent)
+	if extra := len(ch.history) - hub.options.History; extra > 0 {
+		ch.history = append(ch.history[:0:0], ch.history[extra:]...)
+	}
+	for subscriber := range ch.subscribers {
+		select {
+		case subscriber <- &sent:
+		default:
+		}
+	}
+	return nil
+}
+
+// Subscribe returns channel of new messages sent to the channel with name.
+// Returned function must be called to unsubscribe.
+// Go channel is closed, when chat channel is closed.
+func (hub *Hub) Subscribe(name string) (<-chan *Message, func()) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	ch := hub.channel(name)
+	messages := make(chan *Message, subscriberBuffer)
+	ch.subscribers[messages] = struct{}{}
+	cancel := func() {
+		hub.mutex.Lock()
+		defer hub.mutex.Unlock()
+		if _, ok := ch.subscribers[messages]; ok {
+			delete(ch.subscribers, messages)
+			close(messages)
+		}
+		if len(ch.subscribers) == 0 && len(ch.history) == 0 && hub.channels[name] == ch {
+			delete(hub.channels, name)
+		}
+	}
+	return messages, cancel
+}
+
+// History returns last n messages of the channel with name,
+// or all kept messages, if n isn't positive.
+func (hub *Hub) History(name string, n int) []*Message {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	ch, ok := hub.channels[name]
+	if !ok {
+		return nil
+	}
+	history := ch.history
+	if n > 0 && n < len(history) {
+		history = history[len(history)-n:]
+	}
+	return append([]*Message(nil), history...)
+}
+
+// Close removes the channel with name with its history
+// and closes channels of its subscribers.
+func (hub *Hub) Close(name string) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	ch, ok := hub.channels[name]
+	if !ok {
+		return
+	}
+	delete(hub.channels, name)
+	for subscriber := range ch.subscribers {
+		delete(ch.subscribers, subscriber)
+		close(subscriber)
+	}
+}
+
+// channel returns channel with name creating it if needed,
+// it must be called with locked mutex.
+func (hub *Hub) channel(name string) *channel {
+	ch, ok := hub.channels[name]
+	if !ok {
+		ch = &channel{subscribers: make(map[chan *Message]struct{})}
+		hub.channels[name] = ch
+	}
+	return ch
+}
+
+// allow takes a token from the bucket of user,
+// it must be called with locked mutex.
+func (hub *Hub) allow(userID int) bool {
+	if hub.options.Rate <= 0 {
+		return true
+	}
+
+	now := hub.now()
+	b, ok := hub.buckets[userID]
+	if !ok {
+		b = &bucket{tokens: float64(hub.options.Burst), last: now}
+		hub.buckets[userID] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * hub.options.Rate
+	if max := float64(hub.options.Burst); b.tokens > max {
+		b.tokens = max
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,133 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	. "github.com/yagoggame/grpc_server/chat"
+)
+
+var sendTests = []struct {
+	caseName string
+	text     string
+	want     string
+	wantErr  error
+}{
+	{caseName: "normal", text: "good game", want: "good game"},
+	{caseName: "trimmed", text: "  hi \n", want: "hi"},
+	{caseName: "empty", text: " \t ", wantErr: ErrEmpty},
+	{caseName: "too long", text: strings.Repeat("a", 11), wantErr: ErrTooLong},
+	{caseName: "multibyte at limit", text: strings.Repeat("я", 10), want: strings.Repeat("я", 10)},
+	{caseName: "rejected", text: "spam", wantErr: ErrRejected},
+}
+
+func TestSend(t *testing.T) {
+	filter := FilterFunc(func(userID int, text string) (string, error) {
+		if text == "spam" {
+			return "", fmt.Errorf("%w: user %d", ErrRejected, userID)
+		}
+		return text, nil
+	})
+
+	for _, test := range sendTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			hub := New(Options{MaxLength: 10, History: 5, Filter: filter})
+			messages, cancel := hub.Subscribe("lobby")
+			defer cancel()
+
+			err := hub.Send(&Message{Channel: "lobby", UserID: 2, Login: "Joe", Text: test.text})
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Unexpected err:\nwant: %v,\ngot: %v.", test.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+
+			message := <-messages
+			if message.Text != test.want || message.Login != "Joe" || message.UserID != 2 || message.Time.IsZero() {
+				t.Errorf("Unexpected message:\nwant: %q from Joe,\ngot: %+v.", test.want, message)
+			}
+		})
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	hub := New(Options{History: 5, Rate: 0.001, Burst: 2})
+
+	for i, want := range []error{nil, nil, ErrRateLimit} {
+		if err := hub.Send(&Message{Channel: "lobby", UserID: 2, Text: "hi"}); !errors.Is(err, want) {
+			t.Errorf("Unexpected err of message %d:\nwant: %v,\ngot: %v.", i, want, err)
+		}
+	}
+	if err := hub.Send(&Message{Channel: "lobby", UserID: 3, Text: "hi"}); err != nil {
+		t.Errorf("Unexpected err of other user:\nwant: %v,\ngot: %v.", nil, err)
+	}
+}
+
+var historyTests = []struct {
+	caseName string
+	n        int
+	want     []string
+}{
+	{caseName: "last", n: 2, want: []string{"3", "4"}},
+	{caseName: "more than kept", n: 10, want: []string{"2", "3", "4"}},
+	{caseName: "all", n: 0, want: []string{"2", "3", "4"}},
+}
+
+func TestHistory(t *testing.T) {
+	hub := New(Options{History: 3})
+	for i := 0; i < 5; i++ {
+		hub.Send(&Message{Channel: "game/1", UserID: 2, Text: fmt.Sprint(i)})
+	}
+
+	for _, test := range historyTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			history := hub.History("game/1", test.n)
+			got := make([]string, len(history))
+			for i, message := range history {
+				got[i] = message.Text
+			}
+			if strings.Join(got, ",") != strings.Join(test.want, ",") {
+				t.Errorf("Unexpected history:\nwant: %v,\ngot: %v.", test.want, got)
+			}
+		})
+	}
+
+	if history := hub.History("game/2", 0); len(history) != 0 {
+		t.Errorf("Unexpected history of unknown channel: %v", history)
+	}
+}
+
+func TestClose(t *testing.T) {
+	hub := New(Options{History: 3})
+	messages, cancel := hub.Subscribe("game/1")
+	defer cancel()
+	hub.Send(&Message{Channel: "game/1", UserID: 2, Text: "hi"})
+	<-messages
+
+	hub.Close("game/1")
+	if message, ok := <-messages; ok {
+		t.Errorf("Unexpected message after Close: %v", message)
+	}
+	if history := hub.History("game/1", 0); len(history) != 0 {
+		t.Errorf("Unexpected history after Close: %v", history)
+	}
+}
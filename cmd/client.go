@@ -0,0 +1,814 @@
+/*
+Copyright © 2020 Blinnikov AA <goofinator@mail.ru>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/yagoggame/api"
+	"github.com/yagoggame/grpc_server/board"
+	"github.com/yagoggame/grpc_server/listener"
+	"github.com/yagoggame/grpc_server/serverapi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+)
+
+// clientCmd is an interactive client of running grpc_server
+var clientCmd = &cobra.Command{
+	Use:   "client [command [args]]",
+	Short: "interactive client of running grpc_server",
+	Long: `interactive client of running grpc_server.
+Connects to --target, or to --address and --port if it is empty.
+Plaintext connection is used with --insecure,
+server's certificate is verified by --cacert file if it is set
+(e.g. the --self-signed-file of server), or by system CA certificates.
+If command is given, it is executed and client exits,
+otherwise commands are read from standard input:
+` + clientHelp,
+	Run: runClient,
+}
+
+const clientHelp = `  register                register user with --login and --password
+  remove                  remove the user
+  requisites LOGIN PASS   change requisites of the user
+  enter                   enter the lobby
+  leave                   leave the lobby
+  who                     list gamers in the lobby
+  online                  show gamers in the lobby after each change
+  join                    join a game, waits for the partner
+  seek [SIZE [MAIN [INC [COLOUR [HANDICAP [fixed|free]]]]]]
+                          seek a partner of close rating for a game of SIZE
+                          with time control of MAIN and INC seconds,
+                          preferring COLOUR random, black or white,
+                          with HANDICAP stones of Black, fixed by default,
+                          shows position in the queue until the game begins
+  wait                    wait for the turn
+  state                   show status of the user and his game without waiting
+  resume                  resume the game after interrupted connection
+  turn X Y                make a turn to column X and row Y
+  leavegame               leave the game
+  undo                    ask the partner to take back the last move
+                          of the user and wait for the answer
+  undo yes|no             accept or reject takeback of the last move of the partner
+  moves                   show moves and takebacks of the game
+  challenge LOGIN [SIZE [KOMI [COLOUR [MAIN [INC [HANDICAP [fixed|free]]]]]]]
+                          invite the gamer to a private game, COLOUR is random,
+                          black or white, MAIN and INC are time control in seconds,
+                          HANDICAP stones of black are placed on star points
+                          or by black himself, then wait for the answer by resume
+  challenges              list challenges of the user and to him
+  accept CODE             accept the challenge and begin the game
+  decline CODE            decline or cancel the challenge
+  rating [LOGIN]          show rating of the gamer or of the user
+  top [OFFSET [LIMIT]]    show LIMIT players of the highest rating after OFFSET ones
+  stats [LOGIN]           show statistics of games of the gamer or of the user
+  private on|off          hide the game from spectators or show it
+  games                   list games which can be watched
+  spectate ID             watch the game until it ends
+  say lobby|game TEXT     send the chat message to the lobby or the game
+  history lobby|game [N]  show last N chat messages, all kept by default
+  listen lobby|game       show new chat messages until the channel is closed
+  board                   show the board of the last game state
+  help                    show this help
+  quit                    exit the client
+`
+
+func init() {
+	rootCmd.AddCommand(clientCmd)
+
+	clientCmd.Flags().String("target", "", "\"host:port\" or \"unix:///path\" of server")
+	viper.BindPFlag("client.target", clientCmd.Flag("target"))
+	clientCmd.Flags().StringP("login", "l", "", "login of user")
+	viper.BindPFlag("client.login", clientCmd.Flag("login"))
+	clientCmd.Flags().String("password", "", "password of user")
+	viper.BindPFlag("client.password", clientCmd.Flag("password"))
+	clientCmd.Flags().String("cacert", "", "file with CA certificates to verify server's certificate")
+	viper.BindPFlag("client.cacert", clientCmd.Flag("cacert"))
+	clientCmd.Flags().String("servername", "", "name of server to verify it's certificate, host of target by default")
+	viper.BindPFlag("client.servername", clientCmd.Flag("servername"))
+	clientCmd.Flags().String("clientcert", "", "file with TLS certificate of client")
+	viper.BindPFlag("client.clientcert", clientCmd.Flag("clientcert"))
+	clientCmd.Flags().String("clientkey", "", "file with TLS key of client")
+	viper.BindPFlag("client.clientkey", clientCmd.Flag("clientkey"))
+}
+
+// gameClient keeps connection and requisites of the user
+type gameClient struct {
+	api       api.GoGameClient
+	session   serverapi.SessionClient
+	spectator serverapi.SpectatorClient
+	chat      serverapi.ChatClient
+	lobby     serverapi.LobbyClient
+	challenge serverapi.ChallengeClient
+	matching  serverapi.MatchmakingClient
+	rating    serverapi.RatingClient
+	takeback  serverapi.TakebackClient
+	login     string
+	password  string
+	state     *api.State
+	out       io.Writer
+}
+
+func runClient(cmd *cobra.Command, args []string) {
+	conn, err := dialServer()
+	if err != nil {
+		log.Fatalf("failed to connect: %s", err)
+	}
+	defer conn.Close()
+
+	client := &gameClient{
+		api:       api.NewGoGameClient(conn),
+		session:   serverapi.NewSessionClient(conn),
+		spectator: serverapi.NewSpectatorClient(conn),
+		chat:      serverapi.NewChatClient(conn),
+		lobby:     serverapi.NewLobbyClient(conn),
+		challenge: serverapi.NewChallengeClient(conn),
+		matching:  serverapi.NewMatchmakingClient(conn),
+		rating:    serverapi.NewRatingClient(conn),
+		takeback:  serverapi.NewTakebackClient(conn),
+		login:     viper.GetString("client.login"),
+		password:  viper.GetString("client.password"),
+		out:       cmd.OutOrStdout(),
+	}
+
+	if len(args) > 0 {
+		if err := client.execute(args); err != nil {
+			log.Fatalf("%s failed: %s", args[0], err)
+		}
+		return
+	}
+
+	scanner := bufio.NewScanner(cmd.InOrStdin())
+	fmt.Fprint(client.out, "> ")
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 0 {
+			if fields[0] == "quit" {
+				return
+			}
+			if err := client.execute(fields); err != nil {
+				fmt.Fprintf(client.out, "%s failed: %s\n", fields[0], err)
+			}
+		}
+		fmt.Fprint(client.out, "> ")
+	}
+}
+
+// dialServer connects to server with TLS settings of flags
+func dialServer() (*grpc.ClientConn, error) {
+	target := viper.GetString("client.target")
+	if target == "" {
+		target = net.JoinHostPort(viper.GetString("address"), strconv.Itoa(viper.GetInt("port")))
+	}
+	e, err := listener.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []grpc.DialOption{grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+		var dialer net.Dialer
+		return dialer.DialContext(ctx, e.Network, e.Address)
+	})}
+	if viper.GetBool("insecure") {
+		opts = append(opts, grpc.WithInsecure())
+	} else {
+		config, err := clientTLS(e)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(config)))
+	}
+	return grpc.Dial(e.Address, opts...)
+}
+
+func clientTLS(e listener.Endpoint) (*tls.Config, error) {
+	config := &tls.Config{ServerName: viper.GetString("client.servername")}
+	if config.ServerName == "" {
+		config.ServerName = "localhost"
+		if host, _, err := net.SplitHostPort(e.Address); err == nil && e.Network == "tcp" {
+			config.ServerName = host
+		}
+	}
+
+	if caFile := viper.GetString("client.cacert"); caFile != "" {
+		pem, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no CA certificates found in %q", caFile)
+		}
+	}
+
+	certFile, keyFile := viper.GetString("client.clientcert"), viper.GetString("client.clientkey")
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// context returns context with requisites metadata
+func (client *gameClient) context() context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(),
+		"login", client.login, "password", client.password)
+}
+
+// execute executes command with arguments
+func (client *gameClient) execute(fields []string) error {
+	ctx := client.context()
+	empty := &api.EmptyMessage{}
+
+	var (
+		state  *api.State
+		header metadata.MD
+		err    error
+	)
+	switch fields[0] {
+	case "register":
+		_, err = client.api.RegisterUser(ctx, empty)
+	case "remove":
+		_, err = client.api.RemoveUser(ctx, empty)
+	case "requisites":
+		if len(fields) != 3 {
+			return fmt.Errorf("usage: requisites LOGIN PASSWORD")
+		}
+		_, err = client.api.ChangeUserRequisits(ctx, &api.RequisitsMessage{Login: fields[1], Password: fields[2]})
+		if err == nil {
+			client.login, client.password = fields[1], fields[2]
+		}
+	case "enter":
+		_, err = client.api.EnterTheLobby(ctx, empty)
+	case "leave":
+		_, err = client.api.LeaveTheLobby(ctx, empty)
+	case "who":
+		lobby, errL := client.lobby.ListLobby(ctx, empty)
+		if errL != nil {
+			return errL
+		}
+		client.printLobby(lobby)
+		return nil
+	case "online":
+		return client.online(ctx)
+	case "join":
+		gameStatus, errJ := client.session.JoinGame(ctx, &serverapi.JoinRequest{})
+		if errJ != nil {
+			return errJ
+		}
+		state, header = gameStatus.GetState(), statusHeader(gameStatus)
+	case "seek":
+		request, errP := parseSeek(fields[1:])
+		if errP != nil {
+			return errP
+		}
+		state, err = client.seek(ctx, request)
+	case "wait":
+		gameStatus, errW := client.session.WaitTurn(ctx, empty)
+		if errW != nil {
+			return errW
+		}
+		state, header = gameStatus.GetState(), statusHeader(gameStatus)
+	case "state":
+		gameStatus, errS := client.session.GetGameState(ctx, empty)
+		if errS != nil {
+			return errS
+		}
+		state = gameStatus.GetState()
+		if state == nil {
+			fmt.Fprintln(client.out, gameStatus.GetStatus())
+			return nil
+		}
+		defer fmt.Fprintf(client.out, "%s, colour: %s, partner: %q, your turn: %v\n", gameStatus.GetStatus(),
+			gameStatus.GetColour(), gameStatus.GetPartner(), gameStatus.GetMyTurn())
+		if gameStatus.GetUndoRequested() {
+			defer fmt.Fprintln(client.out, "partner asks to take back his last move, answer by undo yes|no")
+		}
+		if gameStatus.GetTimeLeft() > 0 || gameStatus.GetPartnerTimeLeft() > 0 {
+			defer fmt.Fprintf(client.out, "time left: %s, partner's: %s\n",
+				time.Duration(gameStatus.GetTimeLeft())*time.Millisecond,
+				time.Duration(gameStatus.GetPartnerTimeLeft())*time.Millisecond)
+		}
+	case "resume":
+		resumed, errR := client.session.ResumeGame(ctx, empty)
+		if errR != nil {
+			return errR
+		}
+		state = resumed.GetState()
+		defer fmt.Fprintf(client.out, "colour: %s, your turn: %v, partner %q connected: %v\n",
+			resumed.GetColour(), resumed.GetMyTurn(), resumed.GetPartner(), resumed.GetPartnerConnected())
+	case "turn":
+		turn, errT := parseTurn(fields[1:])
+		if errT != nil {
+			return errT
+		}
+		state, err = client.api.MakeTurn(ctx, turn, grpc.Header(&header))
+	case "undo":
+		switch {
+		case len(fields) == 1:
+			state, err = client.takeback.RequestUndo(ctx, empty, grpc.Header(&header))
+		case len(fields) == 2 && (fields[1] == "yes" || fields[1] == "no"):
+			state, err = client.takeback.AnswerUndo(ctx, &serverapi.UndoAnswer{Accept: fields[1] == "yes"}, grpc.Header(&header))
+		default:
+			return fmt.Errorf("usage: undo [yes|no]")
+		}
+	case "moves":
+		moves, errM := client.takeback.ListMoves(ctx, empty)
+		if errM != nil {
+			return errM
+		}
+		for _, m := range moves.GetMoves() {
+			if m.GetUndo() {
+				fmt.Fprintf(client.out, "%d: %s took back %d %d\n", m.GetNumber(), m.GetColour(), m.GetX(), m.GetY())
+				continue
+			}
+			fmt.Fprintf(client.out, "%d: %s %d %d\n", m.GetNumber(), m.GetColour(), m.GetX(), m.GetY())
+		}
+		return nil
+	case "leavegame":
+		_, err = client.api.LeaveTheGame(ctx, empty)
+	case "challenge":
+		request, errP := parseChallenge(fields[1:])
+		if errP != nil {
+			return errP
+		}
+		invite, errC := client.challenge.Challenge(ctx, request)
+		if errC != nil {
+			return errC
+		}
+		fmt.Fprintf(client.out, "challenge code: %s\n", invite.GetCode())
+		return nil
+	case "challenges":
+		challenges, errC := client.challenge.ListChallenges(ctx, empty)
+		if errC != nil {
+			return errC
+		}
+		for _, invite := range challenges.GetChallenges() {
+			fmt.Fprintf(client.out, "%s: %s -> %s, size %d, komi %g, %s for %s, time %ds+%ds, handicap %d%s, expires %s\n",
+				invite.GetCode(), invite.GetFrom(), invite.GetTo(), invite.GetSize(), invite.GetKomi(),
+				invite.GetColour(), invite.GetFrom(), invite.GetMainTime(), invite.GetIncrement(),
+				invite.GetHandicap(), placement(invite), time.Unix(0, invite.GetExpires()).Format("15:04:05"))
+		}
+		return nil
+	case "accept":
+		if len(fields) != 2 {
+			return fmt.Errorf("usage: accept CODE")
+		}
+		state, err = client.challenge.AcceptChallenge(ctx, &serverapi.CodeMessage{Code: fields[1]}, grpc.Header(&header))
+	case "decline":
+		if len(fields) != 2 {
+			return fmt.Errorf("usage: decline CODE")
+		}
+		_, err = client.challenge.DeclineChallenge(ctx, &serverapi.CodeMessage{Code: fields[1]})
+	case "rating":
+		if len(fields) > 2 {
+			return fmt.Errorf("usage: rating [LOGIN]")
+		}
+		request := &serverapi.RatingRequest{}
+		if len(fields) == 2 {
+			request.Login = fields[1]
+		}
+		player, errR := client.rating.GetRating(ctx, request)
+		if errR != nil {
+			return errR
+		}
+		fmt.Fprintf(client.out, "%s: rating %.0f, deviation %.0f, volatility %.4f, games %d\n", player.GetLogin(),
+			player.GetRating(), player.GetDeviation(), player.GetVolatility(), player.GetGames())
+		return nil
+	case "top":
+		request, errP := parseLeaderboard(fields[1:])
+		if errP != nil {
+			return errP
+		}
+		leaderboard, errL := client.rating.Leaderboard(ctx, request)
+		if errL != nil {
+			return errL
+		}
+		for _, player := range leaderboard.GetPlayers() {
+			fmt.Fprintf(client.out, "%d. %s: rating %.0f, games %d\n",
+				player.GetRank(), player.GetLogin(), player.GetRating(), player.GetGames())
+		}
+		fmt.Fprintf(client.out, "rated players: %d\n", leaderboard.GetTotal())
+		return nil
+	case "stats":
+		if len(fields) > 2 {
+			return fmt.Errorf("usage: stats [LOGIN]")
+		}
+		request := &serverapi.RatingRequest{}
+		if len(fields) == 2 {
+			request.Login = fields[1]
+		}
+		stats, errS := client.rating.GetStats(ctx, request)
+		if errS != nil {
+			return errS
+		}
+		fmt.Fprintf(client.out, "%s: games %d, wins %d, losses %d, draws %d, longest win streak %d\n",
+			stats.GetLogin(), stats.GetGames(), stats.GetWins(), stats.GetLosses(), stats.GetDraws(),
+			stats.GetLongestWinStreak())
+		fmt.Fprintf(client.out, "average moves %.1f, average duration %s\n", stats.GetAverageMoves(),
+			time.Duration(stats.GetAverageDuration())*time.Millisecond)
+		for _, size := range stats.GetBySize() {
+			fmt.Fprintf(client.out, "%dx%d: %d games\n", size.GetSize(), size.GetSize(), size.GetGames())
+		}
+		return nil
+	case "private":
+		if len(fields) != 2 || (fields[1] != "on" && fields[1] != "off") {
+			return fmt.Errorf("usage: private on|off")
+		}
+		_, err = client.spectator.SetGamePrivate(ctx, &serverapi.PrivacyMessage{Private: fields[1] == "on"})
+	case "games":
+		games, errG := client.spectator.ListGames(ctx, empty)
+		if errG != nil {
+			return errG
+		}
+		for _, game := range games.GetGames() {
+			fmt.Fprintf(client.out, "%d: %s, size %d, spectators %d\n", game.GetId(),
+				strings.Join(game.GetPlayers(), " vs "), game.GetSize(), game.GetSpectators())
+		}
+		return nil
+	case "spectate":
+		if len(fields) != 2 {
+			return fmt.Errorf("usage: spectate ID")
+		}
+		gameID, errP := strconv.ParseInt(fields[1], 10, 64)
+		if errP != nil {
+			return fmt.Errorf("wrong ID: %v", errP)
+		}
+		return client.spectate(ctx, gameID)
+	case "say":
+		if len(fields) < 3 {
+			return fmt.Errorf("usage: say lobby|game TEXT")
+		}
+		channel, errC := parseChannel(fields[1])
+		if errC != nil {
+			return errC
+		}
+		_, err = client.chat.SendMessage(ctx, &serverapi.ChatMessage{Channel: channel, Text: strings.Join(fields[2:], " ")})
+	case "history":
+		if len(fields) != 2 && len(fields) != 3 {
+			return fmt.Errorf("usage: history lobby|game [N]")
+		}
+		channel, errC := parseChannel(fields[1])
+		if errC != nil {
+			return errC
+		}
+		var limit int64
+		if len(fields) == 3 {
+			if limit, err = strconv.ParseInt(fields[2], 10, 64); err != nil {
+				return fmt.Errorf("wrong N: %v", err)
+			}
+		}
+		history, errH := client.chat.History(ctx, &serverapi.HistoryRequest{Channel: channel, Limit: limit})
+		if errH != nil {
+			return errH
+		}
+		for _, message := range history.GetMessages() {
+			client.printMessage(message)
+		}
+		return nil
+	case "listen":
+		if len(fields) != 2 {
+			return fmt.Errorf("usage: listen lobby|game")
+		}
+		channel, errC := parseChannel(fields[1])
+		if errC != nil {
+			return errC
+		}
+		return client.listen(ctx, channel)
+	case "board":
+		if client.state == nil {
+			return fmt.Errorf("no game state yet")
+		}
+		state = client.state
+	case "help":
+		fmt.Fprint(client.out, clientHelp)
+		return nil
+	default:
+		return fmt.Errorf("unknown command, try help")
+	}
+	if err != nil {
+		return err
+	}
+
+	if state != nil {
+		client.state = state
+		fmt.Fprint(client.out, board.Render(state))
+		if colour := header.Get(serverapi.ColourHeader); len(colour) > 0 {
+			fmt.Fprintf(client.out, "you play %s", strings.ToLower(colour[0]))
+			if partner := header.Get(serverapi.PartnerHeader); len(partner) > 0 {
+				fmt.Fprintf(client.out, " against %s", partner[0])
+			}
+			fmt.Fprintln(client.out)
+		}
+		if turn := header.Get(serverapi.TurnHeader); len(turn) > 0 {
+			fmt.Fprintf(client.out, "%s moves\n", strings.ToLower(turn[0]))
+		}
+		return nil
+	}
+	fmt.Fprintln(client.out, "ok")
+	return nil
+}
+
+// statusHeader returns headers with the colour of gamer, his partner and
+// the colour, whose turn is now, as calls returning api.State report them.
+func statusHeader(gameStatus *serverapi.GameStatusMessage) metadata.MD {
+	header := metadata.MD{}
+	colour := gameStatus.GetColour()
+	if colour != serverapi.Colour_BLACK && colour != serverapi.Colour_WHITE {
+		return header
+	}
+	header.Set(serverapi.ColourHeader, colour.String())
+	if partner := gameStatus.GetPartner(); partner != "" {
+		header.Set(serverapi.PartnerHeader, partner)
+	}
+	if gameStatus.GetStatus() != serverapi.GameStatus_PLAYING {
+		return header
+	}
+	turn := colour
+	switch {
+	case gameStatus.GetMyTurn():
+	case colour == serverapi.Colour_BLACK:
+		turn = serverapi.Colour_WHITE
+	default:
+		turn = serverapi.Colour_BLACK
+	}
+	header.Set(serverapi.TurnHeader, turn.String())
+	return header
+}
+
+// spectate prints states of the game until it ends
+func (client *gameClient) spectate(ctx context.Context, gameID int64) error {
+	stream, err := client.spectator.WatchGame(ctx, &serverapi.GameIDMessage{Id: gameID})
+	if err != nil {
+		return err
+	}
+	for {
+		state, err := stream.Recv()
+		if err == io.EOF {
+			fmt.Fprintln(client.out, "spectating finished")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(client.out, board.Render(state))
+	}
+}
+
+// seek prints position in the matchmaking queue until the game begins
+// and returns the state of begun game
+func (client *gameClient) seek(ctx context.Context, request *serverapi.SeekRequest) (*api.State, error) {
+	stream, err := client.matching.Seek(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		place, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		if place.GetMatched() {
+			fmt.Fprintf(client.out, "partner: %s, your colour: %s\n", place.GetPartner(), place.GetColour())
+			return place.GetState(), nil
+		}
+		wait := "unknown"
+		if place.GetEstimatedWait() > 0 {
+			wait = (time.Duration(place.GetEstimatedWait()) * time.Millisecond).String()
+		}
+		fmt.Fprintf(client.out, "position %d, rating %g±%g, estimated wait %s\n",
+			place.GetPosition(), place.GetRating(), place.GetWindow(), wait)
+	}
+}
+
+// online prints gamers in the lobby after each change
+func (client *gameClient) online(ctx context.Context) error {
+	stream, err := client.lobby.WatchLobby(ctx, &api.EmptyMessage{})
+	if err != nil {
+		return err
+	}
+	for {
+		lobby, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(client.out, "---")
+		client.printLobby(lobby)
+	}
+}
+
+func (client *gameClient) printLobby(lobby *serverapi.LobbyMessage) {
+	for _, gamer := range lobby.GetGamers() {
+		fmt.Fprintf(client.out, "%s: %s\n", gamer.GetLogin(), gamer.GetStatus())
+	}
+}
+
+// listen prints new messages of the chat channel until it is closed
+func (client *gameClient) listen(ctx context.Context, channel serverapi.ChatChannel) error {
+	stream, err := client.chat.Subscribe(ctx, &serverapi.ChannelMessage{Channel: channel})
+	if err != nil {
+		return err
+	}
+	for {
+		message, err := stream.Recv()
+		if err == io.EOF {
+			fmt.Fprintln(client.out, "channel closed")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		client.printMessage(message)
+	}
+}
+
+func (client *gameClient) printMessage(message *serverapi.ChatMessage) {
+	fmt.Fprintf(client.out, "[%s] %s: %s\n",
+		time.Unix(0, message.GetTime()).Format("15:04:05"), message.GetLogin(), message.GetText())
+}
+
+func parseChannel(name string) (serverapi.ChatChannel, error) {
+	channel, ok := serverapi.ChatChannel_value[strings.ToUpper(name)]
+	if !ok {
+		return 0, fmt.Errorf("wrong channel %q, lobby or game expected", name)
+	}
+	return serverapi.ChatChannel(channel), nil
+}
+
+func parseColour(name string) (serverapi.Colour, error) {
+	colour, ok := serverapi.Colour_value[strings.ToUpper(name)]
+	if !ok || serverapi.Colour(colour) == serverapi.Colour_UNKNOWN {
+		return serverapi.Colour_RANDOM, fmt.Errorf("wrong COLOUR %q, random, black or white expected", name)
+	}
+	return serverapi.Colour(colour), nil
+}
+
+func parsePlacement(name string) (bool, error) {
+	switch name {
+	case "fixed":
+		return false, nil
+	case "free":
+		return true, nil
+	}
+	return false, fmt.Errorf("wrong placement %q, fixed or free expected", name)
+}
+
+func parseChallenge(args []string) (*serverapi.ChallengeRequest, error) {
+	if len(args) < 1 || len(args) > 8 {
+		return nil, fmt.Errorf("usage: challenge LOGIN [SIZE [KOMI [COLOUR [MAIN [INC [HANDICAP [fixed|free]]]]]]]")
+	}
+	request := &serverapi.ChallengeRequest{Login: args[0]}
+	var err error
+	if len(args) > 1 {
+		if request.Size, err = strconv.ParseInt(args[1], 10, 64); err != nil {
+			return nil, fmt.Errorf("wrong SIZE: %v", err)
+		}
+	}
+	if len(args) > 2 {
+		if request.Komi, err = strconv.ParseFloat(args[2], 64); err != nil {
+			return nil, fmt.Errorf("wrong KOMI: %v", err)
+		}
+	}
+	if len(args) > 3 {
+		colour, err := parseColour(args[3])
+		if err != nil {
+			return nil, err
+		}
+		request.Colour = colour
+	}
+	if len(args) > 4 {
+		if request.MainTime, err = strconv.ParseInt(args[4], 10, 64); err != nil {
+			return nil, fmt.Errorf("wrong MAIN: %v", err)
+		}
+	}
+	if len(args) > 5 {
+		if request.Increment, err = strconv.ParseInt(args[5], 10, 64); err != nil {
+			return nil, fmt.Errorf("wrong INC: %v", err)
+		}
+	}
+	if len(args) > 6 {
+		if request.Handicap, err = strconv.ParseInt(args[6], 10, 64); err != nil {
+			return nil, fmt.Errorf("wrong HANDICAP: %v", err)
+		}
+	}
+	if len(args) > 7 {
+		free, err := parsePlacement(args[7])
+		if err != nil {
+			return nil, err
+		}
+		request.FreePlacement = free
+	}
+	return request, nil
+}
+
+// placement returns description of handicap placement of the challenge
+func placement(invite *serverapi.ChallengeMessage) string {
+	switch {
+	case invite.GetHandicap() == 0:
+		return ""
+	case invite.GetFreePlacement():
+		return " free"
+	}
+	return " fixed"
+}
+
+func parseSeek(args []string) (*serverapi.SeekRequest, error) {
+	if len(args) > 6 {
+		return nil, fmt.Errorf("usage: seek [SIZE [MAIN [INC [COLOUR [HANDICAP [fixed|free]]]]]]")
+	}
+	values := make([]int64, 3)
+	for i, name := range []string{"SIZE", "MAIN", "INC"} {
+		if i >= len(args) {
+			break
+		}
+		value, err := strconv.ParseInt(args[i], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("wrong %s: %v", name, err)
+		}
+		values[i] = value
+	}
+	request := &serverapi.SeekRequest{Size: values[0], MainTime: values[1], Increment: values[2]}
+	if len(args) > 3 {
+		colour, err := parseColour(args[3])
+		if err != nil {
+			return nil, err
+		}
+		request.Colour = colour
+	}
+	if len(args) > 4 {
+		handicap, err := strconv.ParseInt(args[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("wrong HANDICAP: %v", err)
+		}
+		request.Handicap = handicap
+	}
+	if len(args) > 5 {
+		free, err := parsePlacement(args[5])
+		if err != nil {
+			return nil, err
+		}
+		request.FreePlacement = free
+	}
+	return request, nil
+}
+
+func parseLeaderboard(args []string) (*serverapi.LeaderboardRequest, error) {
+	if len(args) > 2 {
+		return nil, fmt.Errorf("usage: top [OFFSET [LIMIT]]")
+	}
+	values := make([]int64, 2)
+	for i, name := range []string{"OFFSET", "LIMIT"}[:len(args)] {
+		value, err := strconv.ParseInt(args[i], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("wrong %s: %v", name, err)
+		}
+		values[i] = value
+	}
+	return &serverapi.LeaderboardRequest{Offset: values[0], Limit: values[1]}, nil
+}
+
+func parseTurn(args []string) (*api.TurnMessage, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("usage: turn X Y")
+	}
+	x, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("wrong X: %v", err)
+	}
+	y, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("wrong Y: %v", err)
+	}
+	return &api.TurnMessage{X: x, Y: y}, nil
+}
@@ -0,0 +1,130 @@
+/*
+Copyright © 2020 Blinnikov AA <goofinator@mail.ru>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/yagoggame/grpc_server/authorization/filemap"
+	"github.com/yagoggame/grpc_server/authorization/filemap/crypt"
+)
+
+// filemapCmd groups maintenance commands of filemap authorizator's file
+var filemapCmd = &cobra.Command{
+	Use:   "filemap",
+	Short: "maintenance of the file used by filemap authorizator",
+	Long: `maintenance of the file used by filemap authorizator.
+Key is taken from the file set by --filekey flag,
+or from ` + crypt.KeyEnv + ` environment variable.
+Backups of the file are encrypted with it. Backups which can't be read
+in the old form, e.g. left by a previous encryption, are left in place and reported,
+unless --wipe-unreadable flag is set: then they are overwritten by zeros and removed.`,
+}
+
+var filemapGenKeyCmd = &cobra.Command{
+	Use:   "genkey",
+	Short: "print a new random key to encrypt the file",
+	Args:  cobra.NoArgs,
+	Run:   runFilemapGenKey,
+}
+
+var filemapEncryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "encrypt the plaintext file by the key",
+	Args:  cobra.NoArgs,
+	Run:   runFilemapEncrypt,
+}
+
+var filemapRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "reencrypt the encrypted file by the key from --newfilekey file",
+	Args:  cobra.NoArgs,
+	Run:   runFilemapRotate,
+}
+
+func init() {
+	filemapCmd.PersistentFlags().Bool("wipe-unreadable", false, "overwrite by zeros and remove backups which can't be read in the old form")
+
+	filemapRotateCmd.Flags().String("newfilekey", "", "file with a new key to encrypt the file")
+	filemapRotateCmd.MarkFlagRequired("newfilekey")
+
+	filemapCmd.AddCommand(filemapGenKeyCmd, filemapEncryptCmd, filemapRotateCmd)
+	rootCmd.AddCommand(filemapCmd)
+}
+
+func runFilemapGenKey(cmd *cobra.Command, args []string) {
+	key, err := crypt.GenerateKey()
+	if err != nil {
+		log.Fatalf("failed to generate key: %s", err)
+	}
+	fmt.Println(crypt.EncodeKey(key))
+}
+
+func runFilemapEncrypt(cmd *cobra.Command, args []string) {
+	fileName := viper.GetString("filename")
+	plain, err := filemap.ChoseMaper(fileName)
+	if err != nil {
+		log.Fatalf("failed to chose maper for %q: %s", fileName, err)
+	}
+	encrypted := cryptMaper(plain, viper.GetString("filekey"))
+
+	convertFilemap(cmd, fileName, plain, encrypted, "encrypt")
+	log.Printf("file %q encrypted", fileName)
+}
+
+func runFilemapRotate(cmd *cobra.Command, args []string) {
+	fileName := viper.GetString("filename")
+	plain, err := filemap.ChoseMaper(fileName)
+	if err != nil {
+		log.Fatalf("failed to chose maper for %q: %s", fileName, err)
+	}
+	newKeyFile, _ := cmd.Flags().GetString("newfilekey")
+
+	convertFilemap(cmd, fileName,
+		cryptMaper(plain, viper.GetString("filekey")),
+		cryptMaper(plain, newKeyFile), "rotate key of")
+	log.Printf("key of file %q rotated", fileName)
+}
+
+// convertFilemap converts fileName from maper to maper and reports backups left unreadable.
+func convertFilemap(cmd *cobra.Command, fileName string, from, to filemap.FileMaper, action string) {
+	wipe, _ := cmd.Flags().GetBool("wipe-unreadable")
+	unreadable, err := filemap.Convert(fileName, from, to, wipe)
+	for _, backup := range unreadable {
+		log.Printf("backup %q can't be read in the old form and is left as is", backup)
+	}
+	if err != nil {
+		log.Fatalf("failed to %s %q: %s", action, fileName, err)
+	}
+}
+
+// cryptMaper wraps maper by encryption with key from keyFile.
+func cryptMaper(maper filemap.FileMaper, keyFile string) filemap.FileMaper {
+	key, err := crypt.LoadKey(keyFile)
+	if err != nil {
+		log.Fatalf("failed to load filemap key: %s", err)
+	}
+	encrypted, err := crypt.New(maper, key)
+	if err != nil {
+		log.Fatalf("failed to create filemap encryption: %s", err)
+	}
+	return encrypted
+}
@@ -0,0 +1,92 @@
+/*
+Copyright © 2020 Blinnikov AA <goofinator@mail.ru>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"expvar"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/yagoggame/grpc_server/authorization/cache"
+	"github.com/yagoggame/grpc_server/certreload"
+)
+
+const (
+	// certificateExpiryVar is a name of metric of days until expiry of served TLS certificate
+	certificateExpiryVar = "tls_certificate_days_until_expiry"
+	// authorizationCacheVar is a name of metric of hits, misses and size of authorizations cache
+	authorizationCacheVar = "authorization_cache"
+)
+
+// metricsVars are names of served metrics
+var metricsVars = []string{certificateExpiryVar, authorizationCacheVar}
+
+var (
+	// certificateReloader holds *certreload.Reloader of served TLS certificate
+	certificateReloader atomic.Value
+	// authorizationCache holds *cache.Authorizator of the server
+	authorizationCache atomic.Value
+)
+
+func init() {
+	expvar.Publish(certificateExpiryVar, expvar.Func(func() interface{} {
+		reloader, ok := certificateReloader.Load().(*certreload.Reloader)
+		if !ok {
+			return nil
+		}
+		return reloader.DaysUntilExpiry()
+	}))
+	expvar.Publish(authorizationCacheVar, expvar.Func(func() interface{} {
+		cached, ok := authorizationCache.Load().(*cache.Authorizator)
+		if !ok {
+			return nil
+		}
+		stats := cached.Stats()
+		return map[string]interface{}{"hits": stats.Hits, "misses": stats.Misses, "size": cached.Len()}
+	}))
+}
+
+// serveMetrics serves metrics at address, if it is not empty.
+// Only metrics of the server are served, other expvar variables,
+// like cmdline with passwords in arguments, are not exposed.
+func serveMetrics(address string) {
+	if address == "" {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/vars", metricsHandler)
+	go func() {
+		if err := http.ListenAndServe(address, mux); err != nil {
+			log.Fatalf("failed to serve metrics: %s", err)
+		}
+	}()
+}
+
+// metricsHandler writes metrics of the server as JSON object in expvar format.
+func metricsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	vars := make([]string, 0, len(metricsVars))
+	for _, name := range metricsVars {
+		vars = append(vars, fmt.Sprintf("%q: %s", name, expvar.Get(name)))
+	}
+	fmt.Fprintf(w, "{\n%s\n}\n", strings.Join(vars, ",\n"))
+}
@@ -0,0 +1,131 @@
+/*
+Copyright © 2020 Blinnikov AA <goofinator@mail.ru>
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/yagoggame/grpc_server/rating"
+)
+
+// ratingsCmd groups maintenance commands of ratings file
+var ratingsCmd = &cobra.Command{
+	Use:   "ratings",
+	Short: "maintenance of the file of game records set by --ratings flag",
+	Long: `maintenance of the file of game records set by --ratings flag.
+Ratings are kept in the file with .ratings suffix.
+Server using the file must be stopped.`,
+}
+
+var ratingsRecomputeCmd = &cobra.Command{
+	Use:   "recompute",
+	Short: "recompute ratings of all players from the records of games",
+	Args:  cobra.NoArgs,
+	Run:   runRatingsRecompute,
+}
+
+var ratingsTopCmd = &cobra.Command{
+	Use:   "top [LIMIT]",
+	Short: "print the leaderboard of players, 10 players by default",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   runRatingsTop,
+}
+
+var ratingsStatsCmd = &cobra.Command{
+	Use:   "stats LOGIN",
+	Short: "print statistics of games of the player",
+	Args:  cobra.ExactArgs(1),
+	Run:   runRatingsStats,
+}
+
+func init() {
+	ratingsCmd.AddCommand(ratingsRecomputeCmd)
+	ratingsCmd.AddCommand(ratingsTopCmd)
+	ratingsCmd.AddCommand(ratingsStatsCmd)
+	rootCmd.AddCommand(ratingsCmd)
+}
+
+func runRatingsRecompute(cmd *cobra.Command, args []string) {
+	fileName, ratings := loadRatings()
+	if err := ratings.Recompute(); err != nil {
+		log.Fatalf("failed to recompute ratings of %q: %s", fileName, err)
+	}
+	log.Printf("ratings of %q recomputed", fileName)
+}
+
+func runRatingsTop(cmd *cobra.Command, args []string) {
+	limit := 10
+	if len(args) == 1 {
+		var err error
+		if limit, err = strconv.Atoi(args[0]); err != nil || limit < 1 {
+			log.Fatalf("wrong limit %q: must be a positive number", args[0])
+		}
+	}
+
+	_, ratings := loadRatings()
+	players, total := ratings.Leaderboard(0, limit)
+	for i, player := range players {
+		fmt.Printf("%d. %s: rating %.0f, games %d\n", i+1, player.Login, player.Rating, player.Games)
+	}
+	fmt.Printf("rated players: %d\n", total)
+}
+
+func runRatingsStats(cmd *cobra.Command, args []string) {
+	_, ratings := loadRatings()
+	player, ok := ratings.PlayerByLogin(args[0])
+	if !ok {
+		log.Fatalf("player %q has no rated games", args[0])
+	}
+	stats, err := ratings.Stats(player.ID)
+	if err != nil {
+		log.Fatalf("failed to get statistics of %q: %s", args[0], err)
+	}
+
+	fmt.Printf("%s: games %d, wins %d, losses %d, draws %d, longest win streak %d\n",
+		player.Login, stats.Games(), stats.Wins, stats.Losses, stats.Draws, stats.LongestWinStreak)
+	fmt.Printf("average moves %.1f, average duration %s\n",
+		stats.AverageMoves, stats.AverageDuration.Round(time.Second))
+	sizes := make([]int, 0, len(stats.BySize))
+	for size := range stats.BySize {
+		sizes = append(sizes, size)
+	}
+	sort.Ints(sizes)
+	for _, size := range sizes {
+		fmt.Printf("%dx%d: %d games\n", size, size, stats.BySize[size])
+	}
+}
+
+// loadRatings loads ratings from the file set by --ratings flag.
+func loadRatings() (string, *rating.Ratings) {
+	fileName := viper.GetString("ratings")
+	if fileName == "" {
+		log.Fatalf("--ratings file is not set")
+	}
+
+	ratings, err := rating.New(rating.NewFileStore(fileName), viper.GetFloat64("ratingtau"))
+	if err != nil {
+		log.Fatalf("failed to load ratings from %q: %s", fileName, err)
+	}
+	return fileName, ratings
+}
@@ -18,21 +18,37 @@ along with this program. If not, see <http://www.gnu.org/licenses/>.
 package cmd
 
 import (
+	"crypto/tls"
 	"fmt"
 	"log"
 	"net"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/yagoggame/api"
 	"github.com/yagoggame/gomaster"
+	"github.com/yagoggame/grpc_server/authorization/cache"
+	"github.com/yagoggame/grpc_server/authorization/chain"
 	"github.com/yagoggame/grpc_server/authorization/dummy"
 	"github.com/yagoggame/grpc_server/authorization/filemap"
+	"github.com/yagoggame/grpc_server/authorization/ldap"
 	"github.com/yagoggame/grpc_server/authorization/postgres"
+	"github.com/yagoggame/grpc_server/chat"
 	"github.com/yagoggame/grpc_server/cmd/server"
+	"github.com/yagoggame/grpc_server/gateway"
 	"github.com/yagoggame/grpc_server/interfaces"
+	"github.com/yagoggame/grpc_server/listener"
+	"github.com/yagoggame/grpc_server/matchmaking"
+	"github.com/yagoggame/grpc_server/rating"
+	"github.com/yagoggame/grpc_server/serverapi"
+	"github.com/yagoggame/grpc_server/tlsconfig"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/reflection"
 
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/viper"
@@ -40,7 +56,7 @@ import (
 
 var (
 	cfgFile                  string
-	acceptedAuthorizator     = []string{"dummy", "filemap", "postgresql"}
+	acceptedAuthorizator     = []string{"dummy", "filemap", "postgresql", "ldap", "chain"}
 	acceptedAuthorizatorFlag = newOfist(acceptedAuthorizator)
 )
 
@@ -97,15 +113,95 @@ func init() {
 	viper.BindPFlag("address", rootCmd.Flag("address"))
 	rootCmd.PersistentFlags().IntP("port", "p", 7777, "port of grpc_server")
 	viper.BindPFlag("port", rootCmd.Flag("port"))
+	rootCmd.PersistentFlags().StringSlice("listen", nil, "list of endpoints \"host:port\", \"[ipv6]:port\" or \"unix:///path\" to listen instead of --address and --port")
+	viper.BindPFlag("listen", rootCmd.Flag("listen"))
+	rootCmd.PersistentFlags().String("socketmode", "0660", "octal permissions of unix sockets")
+	viper.BindPFlag("socketmode", rootCmd.Flag("socketmode"))
+	rootCmd.PersistentFlags().String("socketowner", "", "\"user:group\" owner of unix sockets, empty parts are left unchanged")
+	viper.BindPFlag("socketowner", rootCmd.Flag("socketowner"))
+	rootCmd.PersistentFlags().Bool("socketinsecure", false, "serve unix sockets without TLS")
+	viper.BindPFlag("socketinsecure", rootCmd.Flag("socketinsecure"))
 	rootCmd.PersistentFlags().StringP("cert", "C", "", "file with TLS certificate")
 	viper.BindPFlag("cert", rootCmd.Flag("cert"))
 	rootCmd.PersistentFlags().StringP("key", "K", "", "file with TLS key")
 	viper.BindPFlag("key", rootCmd.Flag("key"))
+	rootCmd.PersistentFlags().Bool("certreload", true, "reload TLS certificate on changes of --cert and --key files")
+	viper.BindPFlag("certreload", rootCmd.Flag("certreload"))
+	rootCmd.PersistentFlags().String("gateway", "", "address to serve HTTP/JSON gateway, empty disables")
+	viper.BindPFlag("gateway", rootCmd.Flag("gateway"))
+	rootCmd.PersistentFlags().Bool("grpcweb", false, "serve gRPC-Web calls on --gateway address")
+	viper.BindPFlag("grpcweb", rootCmd.Flag("grpcweb"))
+	rootCmd.PersistentFlags().Bool("websocket", false, "serve WebSocket bridge on --gateway address")
+	viper.BindPFlag("websocket", rootCmd.Flag("websocket"))
+	rootCmd.PersistentFlags().StringSlice("cors", nil, "list of origins of pages allowed to call --gateway with credentials of visitor, \"*\" allows any page without them")
+	viper.BindPFlag("cors", rootCmd.Flag("cors"))
+	rootCmd.PersistentFlags().Bool("reflection", false, "register gRPC server reflection service")
+	viper.BindPFlag("reflection", rootCmd.Flag("reflection"))
+	rootCmd.PersistentFlags().String("metrics", "", "address to serve metrics at /debug/vars, empty disables")
+	viper.BindPFlag("metrics", rootCmd.Flag("metrics"))
+	rootCmd.PersistentFlags().Bool("insecure", false, "serve without TLS, for development on loopback address only")
+	viper.BindPFlag("insecure", rootCmd.Flag("insecure"))
+	rootCmd.PersistentFlags().Bool("force-insecure", false, "allow --insecure on non loopback address")
+	viper.BindPFlag("force-insecure", rootCmd.Flag("force-insecure"))
+	rootCmd.PersistentFlags().Bool("self-signed", false, "serve with TLS certificate generated at startup instead of --cert and --key")
+	viper.BindPFlag("self-signed", rootCmd.Flag("self-signed"))
+	rootCmd.PersistentFlags().String("self-signed-file", "grpc_server.pem", "file to write PEM of the generated certificate for clients")
+	viper.BindPFlag("self-signed-file", rootCmd.Flag("self-signed-file"))
+	rootCmd.PersistentFlags().String("clientca", "", "file with CA certificates to verify TLS certificates of clients")
+	viper.BindPFlag("clientca", rootCmd.Flag("clientca"))
+	rootCmd.PersistentFlags().Bool("clientcertrequired", false, "reject clients without TLS certificate verified by --clientca")
+	viper.BindPFlag("clientcertrequired", rootCmd.Flag("clientcertrequired"))
+	rootCmd.PersistentFlags().Bool("certauth", false, "authenticate clients with verified TLS certificate without password")
+	viper.BindPFlag("certauth", rootCmd.Flag("certauth"))
+	rootCmd.PersistentFlags().Duration("grace", 30*time.Second, "time to hold the seat in a game for a disconnected gamer, 0 disables holding")
+	viper.BindPFlag("grace", rootCmd.Flag("grace"))
+	rootCmd.PersistentFlags().Int("spectators", server.DefaultSpectatorLimit, "maximal number of spectators of one game, 0 disables spectating")
+	viper.BindPFlag("spectators", rootCmd.Flag("spectators"))
+	rootCmd.PersistentFlags().Duration("challengettl", server.DefaultChallengeTTL, "time, during which a challenge awaits an answer")
+	viper.BindPFlag("challengettl", rootCmd.Flag("challengettl"))
+	rootCmd.PersistentFlags().Float64("matchwindow", server.DefaultMatchWindow, "initial maximal difference of ratings of gamers paired by matchmaking")
+	viper.BindPFlag("matchwindow", rootCmd.Flag("matchwindow"))
+	rootCmd.PersistentFlags().Float64("matchwidening", server.DefaultMatchWidening, "rating points per second the matchmaking window widens by")
+	viper.BindPFlag("matchwidening", rootCmd.Flag("matchwidening"))
+	rootCmd.PersistentFlags().Float64("matchmaxwindow", server.DefaultMatchMaxWindow, "maximal width of the matchmaking window, 0 means no limit")
+	viper.BindPFlag("matchmaxwindow", rootCmd.Flag("matchmaxwindow"))
+	rootCmd.PersistentFlags().String("ratings", "", "file with records of games, ratings are kept in the file with .ratings suffix, empty to keep them in memory")
+	viper.BindPFlag("ratings", rootCmd.Flag("ratings"))
+	rootCmd.PersistentFlags().Float64("ratingtau", rating.DefaultTau, "Glicko-2 system constant, which constrains the change of volatility")
+	viper.BindPFlag("ratingtau", rootCmd.Flag("ratingtau"))
+	rootCmd.PersistentFlags().Int("chatlength", server.DefaultChatLength, "maximal number of characters in a chat message, 0 means no limit")
+	viper.BindPFlag("chatlength", rootCmd.Flag("chatlength"))
+	rootCmd.PersistentFlags().Int("chathistory", server.DefaultChatHistory, "number of last messages kept in each chat channel")
+	viper.BindPFlag("chathistory", rootCmd.Flag("chathistory"))
+	rootCmd.PersistentFlags().Float64("chatrate", 1, "chat messages per second allowed for a gamer, 0 means no limit")
+	viper.BindPFlag("chatrate", rootCmd.Flag("chatrate"))
+	rootCmd.PersistentFlags().Int("chatburst", 5, "number of chat messages a gamer can send at once")
+	viper.BindPFlag("chatburst", rootCmd.Flag("chatburst"))
+	rootCmd.PersistentFlags().String("chatblocklist", "", "file with words to be masked in chat messages, one per line")
+	viper.BindPFlag("chatblocklist", rootCmd.Flag("chatblocklist"))
 
 	rootCmd.PersistentFlags().VarP(acceptedAuthorizatorFlag, "authorizator", "A", fmt.Sprintf("one of %v values to chose authorizator", acceptedAuthorizator))
 	viper.BindPFlag("authorizator", rootCmd.Flag("authorizator"))
 	rootCmd.PersistentFlags().StringP("filename", "F", "", "filename to be used by filemap authorizator")
 	viper.BindPFlag("filename", rootCmd.Flag("filename"))
+	rootCmd.PersistentFlags().Int("backups", 3, "number of backups of file to be kept by filemap authorizator")
+	viper.BindPFlag("backups", rootCmd.Flag("backups"))
+	rootCmd.PersistentFlags().Bool("watch", true, "reload users on external changes of file used by filemap authorizator")
+	viper.BindPFlag("watch", rootCmd.Flag("watch"))
+	rootCmd.PersistentFlags().Bool("encrypted", false, "file used by filemap authorizator is encrypted")
+	viper.BindPFlag("encrypted", rootCmd.Flag("encrypted"))
+	rootCmd.PersistentFlags().String("filekey", "", "file with key of file used by filemap authorizator")
+	viper.BindPFlag("filekey", rootCmd.Flag("filekey"))
+
+	rootCmd.PersistentFlags().Duration("cachettl", 0, "time to cache successful authorizations, 0 disables caching")
+	viper.BindPFlag("cachettl", rootCmd.Flag("cachettl"))
+	rootCmd.PersistentFlags().Int("cachesize", 1024, "max number of users in authorizations cache")
+	viper.BindPFlag("cachesize", rootCmd.Flag("cachesize"))
+
+	rootCmd.PersistentFlags().StringSlice("chain", nil, "list of \"type:namespace\" items of authorizators used by chain authorizator in order of consulting, settings of backend in namespace can be overridden by \"chainconfig.<namespace>\" section of config file")
+	viper.BindPFlag("chain", rootCmd.Flag("chain"))
+	rootCmd.PersistentFlags().String("chainwritable", "", "\"type:namespace\" item of chain to register new users")
+	viper.BindPFlag("chainwritable", rootCmd.Flag("chainwritable"))
 
 	rootCmd.PersistentFlags().StringP("dbhost", "H", "localhost", "host of database used by postgresql authorizator")
 	viper.BindPFlag("dbhost", rootCmd.Flag("dbhost"))
@@ -118,6 +214,21 @@ func init() {
 	rootCmd.PersistentFlags().StringP("dbpassword", "S", "", "password of user with access to database used by postgresql authorizator")
 	viper.BindPFlag("dbpassword", rootCmd.Flag("dbpassword"))
 
+	rootCmd.PersistentFlags().String("ldapaddress", "localhost:389", "host:port of server used by ldap authorizator")
+	viper.BindPFlag("ldapaddress", rootCmd.Flag("ldapaddress"))
+	rootCmd.PersistentFlags().Bool("ldaptls", false, "use LDAPS connection by ldap authorizator")
+	viper.BindPFlag("ldaptls", rootCmd.Flag("ldaptls"))
+	rootCmd.PersistentFlags().String("ldapuserdn", "", "template of user's DN with %s for login used by ldap authorizator")
+	viper.BindPFlag("ldapuserdn", rootCmd.Flag("ldapuserdn"))
+	rootCmd.PersistentFlags().String("ldapidattr", "uidNumber", "numeric attribute of user's entry used as id by ldap authorizator")
+	viper.BindPFlag("ldapidattr", rootCmd.Flag("ldapidattr"))
+	rootCmd.PersistentFlags().Bool("ldappasswordmodify", false, "allow ldap authorizator to change passwords by Password Modify Extended Operation")
+	viper.BindPFlag("ldappasswordmodify", rootCmd.Flag("ldappasswordmodify"))
+	rootCmd.PersistentFlags().String("ldapbinddn", "", "DN of service account used by ldap authorizator to look up users, it's required in chain and with certauth")
+	viper.BindPFlag("ldapbinddn", rootCmd.Flag("ldapbinddn"))
+	rootCmd.PersistentFlags().String("ldapbindpassword", "", "password of service account used by ldap authorizator")
+	viper.BindPFlag("ldapbindpassword", rootCmd.Flag("ldapbindpassword"))
+
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -149,68 +260,350 @@ func initConfig() {
 func iniFromViper(initData *server.IniDataContainer, command *cobra.Command) {
 	initData.Port = viper.GetInt("port")
 	initData.IP = viper.GetString("address")
+	initData.Listen = viper.GetStringSlice("listen")
+	initData.SocketMode = viper.GetString("socketmode")
+	initData.SocketOwner = viper.GetString("socketowner")
+	initData.SocketInsecure = viper.GetBool("socketinsecure")
 	initData.CertFile = viper.GetString("cert")
 	initData.KeyFile = viper.GetString("key")
+	initData.CertReload = viper.GetBool("certreload")
+	initData.Gateway = viper.GetString("gateway")
+	initData.GRPCWeb = viper.GetBool("grpcweb")
+	initData.WebSocket = viper.GetBool("websocket")
+	initData.CORSOrigins = viper.GetStringSlice("cors")
+	initData.Reflection = viper.GetBool("reflection")
+	initData.Metrics = viper.GetString("metrics")
+	initData.Insecure = viper.GetBool("insecure")
+	initData.ForceInsecure = viper.GetBool("force-insecure")
+	initData.SelfSigned = viper.GetBool("self-signed")
+	initData.SelfSignedFile = viper.GetString("self-signed-file")
+	initData.ClientCAFile = viper.GetString("clientca")
+	initData.ClientCertRequired = viper.GetBool("clientcertrequired")
+	initData.CertAuth = viper.GetBool("certauth")
+	initData.GracePeriod = viper.GetDuration("grace")
+	initData.SpectatorLimit = viper.GetInt("spectators")
+	initData.ChallengeTTL = viper.GetDuration("challengettl")
+	initData.MatchWindow = viper.GetFloat64("matchwindow")
+	initData.MatchWidening = viper.GetFloat64("matchwidening")
+	initData.MatchMaxWindow = viper.GetFloat64("matchmaxwindow")
+	initData.RatingsFile = viper.GetString("ratings")
+	initData.RatingTau = viper.GetFloat64("ratingtau")
+	initData.ChatLength = viper.GetInt("chatlength")
+	initData.ChatHistory = viper.GetInt("chathistory")
+	initData.ChatRate = viper.GetFloat64("chatrate")
+	initData.ChatBurst = viper.GetInt("chatburst")
+	initData.ChatBlocklist = viper.GetString("chatblocklist")
 
 	initData.Authorizer = viper.GetString("authorizator")
 	if err := acceptedAuthorizatorFlag.Set(initData.Authorizer); err != nil {
 		log.Fatalf("Error: invalid argument %v for \"-A, --authorizator\" flag:%v\n%s", initData.Authorizer, err, command.UsageString())
 	}
 
-	initData.Filename = viper.GetString("filename")
+	initData.Chain = viper.GetStringSlice("chain")
+	initData.ChainWritable = viper.GetString("chainwritable")
+	initData.CacheTTL = viper.GetDuration("cachettl")
+	initData.CacheSize = viper.GetInt("cachesize")
 
-	initData.DBHost = viper.GetString("dbhost")
-	initData.DBPort = viper.GetInt("dbport")
-	initData.DBName = viper.GetString("dbname")
-	initData.DBUser = viper.GetString("dbuser")
-	initData.DBPassword = viper.GetString("dbpassword")
+	backendFromViper(initData, viper.GetViper())
 }
 
-func createServer(initData *server.IniDataContainer) (net.Listener, *grpc.Server) {
-	creds, err := credentials.NewServerTLSFromFile(initData.CertFile, initData.KeyFile)
-	if err != nil {
-		log.Fatalf("could not load TLS keys: %s", err)
+// backendKeys are keys of settings of authorizator backends,
+// which can be set for every chain item separately.
+var backendKeys = []string{"filename", "backups", "watch", "encrypted", "filekey",
+	"dbhost", "dbport", "dbname", "dbuser", "dbpassword",
+	"ldapaddress", "ldaptls", "ldapuserdn", "ldapidattr", "ldappasswordmodify", "ldapbinddn", "ldapbindpassword"}
+
+// backendFromViper fills settings of authorizator backends from v.
+func backendFromViper(initData *server.IniDataContainer, v *viper.Viper) {
+	initData.Filename = v.GetString("filename")
+	initData.Backups = v.GetInt("backups")
+	initData.Watch = v.GetBool("watch")
+	initData.Encrypted = v.GetBool("encrypted")
+	initData.FileKey = v.GetString("filekey")
+
+	initData.DBHost = v.GetString("dbhost")
+	initData.DBPort = v.GetInt("dbport")
+	initData.DBName = v.GetString("dbname")
+	initData.DBUser = v.GetString("dbuser")
+	initData.DBPassword = v.GetString("dbpassword")
+
+	initData.LDAPAddress = v.GetString("ldapaddress")
+	initData.LDAPTLS = v.GetBool("ldaptls")
+	initData.LDAPUserDN = v.GetString("ldapuserdn")
+	initData.LDAPIDAttribute = v.GetString("ldapidattr")
+	initData.LDAPPasswordModify = v.GetBool("ldappasswordmodify")
+	initData.LDAPBindDN = v.GetString("ldapbinddn")
+	initData.LDAPBindPassword = v.GetString("ldapbindpassword")
+}
+
+// chainItemData returns initData of chain item in namespace, where settings of backend
+// are overridden by "chainconfig.<namespace>" section of config file, if any.
+// It allows several items of the same type, e.g. two filemap files.
+func chainItemData(initData *server.IniDataContainer, namespace int) *server.IniDataContainer {
+	itemData := *initData
+	section := viper.Sub(fmt.Sprintf("chainconfig.%d", namespace))
+	if section == nil {
+		return &itemData
+	}
+
+	v := viper.New()
+	for _, key := range backendKeys {
+		v.Set(key, viper.Get(key))
 	}
+	for _, key := range section.AllKeys() {
+		if !isInList(key, backendKeys) {
+			log.Fatalf("failed to create chain authorizator: unknown key %q in settings of namespace %d", key, namespace)
+		}
+		v.Set(key, section.Get(key))
+	}
+	backendFromViper(&itemData, v)
+	return &itemData
+}
+
+// serving is a server with a listener to serve on
+type serving struct {
+	lis    net.Listener
+	server interface{ Serve(net.Listener) error }
+}
 
-	lis, err := net.Listen("tcp", fmt.Sprintf("%s:%d", initData.IP, initData.Port))
+// createServers creates a gRPC server for every listen endpoint and
+// HTTP/JSON gateway to s, if initData.Gateway is set.
+// Endpoints share TLS configuration, but unix sockets are served
+// without TLS if initData.SocketInsecure is set.
+func createServers(initData *server.IniDataContainer, s *server.Server) []serving {
+	list, err := endpoints(initData)
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
+	hosts := listener.TCPHosts(list)
+	if initData.Gateway != "" {
+		host, _, err := net.SplitHostPort(initData.Gateway)
+		if err != nil {
+			log.Fatalf("wrong gateway address %q: %v", initData.Gateway, err)
+		}
+		hosts = append(hosts, host)
+	}
 
-	opts := []grpc.ServerOption{grpc.Creds(creds),
-		grpc.UnaryInterceptor(server.UnaryInterceptor)}
+	config, reloader, err := tlsconfig.Server(tlsOptions(initData), hosts)
+	if err != nil {
+		log.Fatalf("could not set up TLS: %s", err)
+	}
+	if reloader != nil {
+		certificateReloader.Store(reloader)
+	}
 
-	return lis, grpc.NewServer(opts...)
+	servings := make([]serving, 0, len(list)+1)
+	for _, e := range list {
+		lis, err := listener.Listen(e, listener.Options{Mode: initData.SocketMode, Owner: initData.SocketOwner})
+		if err != nil {
+			log.Fatalf("failed to listen on %s: %v", e, err)
+		}
+
+		opts := []grpc.ServerOption{grpc.UnaryInterceptor(server.UnaryInterceptor),
+			grpc.StreamInterceptor(server.StreamInterceptor)}
+		if config != nil && !(e.Network == "unix" && initData.SocketInsecure) {
+			opts = append(opts, grpc.Creds(credentials.NewTLS(config)))
+		} else {
+			log.Printf("WARNING: serving on %s without TLS, requisites are sent in plaintext", e)
+		}
+		grpcServer := grpc.NewServer(opts...)
+		api.RegisterGoGameServer(grpcServer, s)
+		serverapi.RegisterSessionServer(grpcServer, s)
+		serverapi.RegisterSpectatorServer(grpcServer, s)
+		serverapi.RegisterChatServer(grpcServer, s)
+		serverapi.RegisterLobbyServer(grpcServer, s)
+		serverapi.RegisterChallengeServer(grpcServer, s)
+		serverapi.RegisterMatchmakingServer(grpcServer, s)
+		serverapi.RegisterRatingServer(grpcServer, s)
+		serverapi.RegisterTakebackServer(grpcServer, s)
+		if initData.Reflection {
+			reflection.Register(grpcServer)
+		}
+		servings = append(servings, serving{lis: lis, server: grpcServer})
+	}
+
+	if initData.Gateway != "" {
+		servings = append(servings, createGateway(initData, config, s))
+	}
+	return servings
+}
+
+// endpoints returns endpoints of initData.Listen items,
+// or the one of --address and --port if the list is empty.
+func endpoints(initData *server.IniDataContainer) ([]listener.Endpoint, error) {
+	if len(initData.Listen) < 1 {
+		return []listener.Endpoint{{Network: "tcp", Address: net.JoinHostPort(initData.IP, strconv.Itoa(initData.Port))}}, nil
+	}
+	return listener.ParseList(initData.Listen)
+}
+
+// tlsOptions returns TLS options of initData
+func tlsOptions(initData *server.IniDataContainer) tlsconfig.Options {
+	return tlsconfig.Options{
+		Insecure:           initData.Insecure,
+		ForceInsecure:      initData.ForceInsecure,
+		SelfSigned:         initData.SelfSigned,
+		SelfSignedFile:     initData.SelfSignedFile,
+		CertFile:           initData.CertFile,
+		KeyFile:            initData.KeyFile,
+		CertReload:         initData.CertReload,
+		ClientCAFile:       initData.ClientCAFile,
+		ClientCertRequired: initData.ClientCertRequired,
+		CertAuth:           initData.CertAuth,
+	}
+}
+
+// createGateway creates HTTP/JSON gateway to s listening on initData.Gateway
+// with gRPC-Web and WebSocket bridge, if they are enabled
+func createGateway(initData *server.IniDataContainer, config *tls.Config, s *server.Server) serving {
+	lis, err := net.Listen("tcp", initData.Gateway)
+	if err != nil {
+		log.Fatalf("failed to listen on %s: %v", initData.Gateway, err)
+	}
+
+	if config != nil {
+		lis = tls.NewListener(lis, config)
+	} else {
+		log.Printf("WARNING: serving gateway on %s without TLS, requisites are sent in plaintext", initData.Gateway)
+	}
+	options := gateway.Options{
+		GRPCWeb:           initData.GRPCWeb,
+		WebSocket:         initData.WebSocket,
+		StreamInterceptor: server.StreamInterceptor,
+		Origins:           initData.CORSOrigins,
+	}
+	return serving{lis: lis, server: &http.Server{Handler: gateway.Handler(s, server.UnaryInterceptor, options)}}
 }
 
 func runService(cmd *cobra.Command, args []string) {
 	initData := new(server.IniDataContainer)
 	iniFromViper(initData, cmd)
 
-	lis, grpcServer := createServer(initData)
-
 	gamePool := gomaster.NewGamersPool()
 	// gameGeter is separated from the object for testing purposes
 	authorizator := getAuthorizator(initData)
+	if initData.CertAuth && initData.Authorizer == "ldap" && initData.LDAPBindDN == "" {
+		log.Fatalf("failed to enable certauth: ldap authorizator identifies users only with --ldapbinddn")
+	}
 	gameGeter := server.NewGameGeter(gamePool)
 	s := server.NewServer(authorizator, gamePool, gameGeter)
+	s.SetCertificateAuth(initData.CertAuth)
+	s.SetGracePeriod(initData.GracePeriod)
+	s.SetSpectatorLimit(initData.SpectatorLimit)
+	s.SetChallengeTTL(initData.ChallengeTTL)
+	s.SetMatchmaking(matchmaking.Options{
+		Window:    initData.MatchWindow,
+		Widening:  initData.MatchWidening,
+		MaxWindow: initData.MatchMaxWindow,
+	})
+	s.SetRatings(getRatings(initData))
+	s.SetChat(getChat(initData))
 	defer s.Release()
 
-	api.RegisterGoGameServer(grpcServer, s)
-	if err := grpcServer.Serve(lis); err != nil {
+	servings := createServers(initData, s)
+	serveMetrics(initData.Metrics)
+
+	errs := make(chan error, len(servings))
+	for _, sv := range servings {
+		go func(sv serving) {
+			log.Printf("serving on %s", sv.lis.Addr())
+			errs <- sv.server.Serve(sv.lis)
+		}(sv)
+	}
+	if err := <-errs; err != nil {
 		log.Fatalf("failed to serve: %s", err)
 	}
 }
 
+// getRatings loads ratings from the file of initData or creates them in memory.
+func getRatings(initData *server.IniDataContainer) *rating.Ratings {
+	var store rating.Store = rating.NewMemoryStore()
+	if initData.RatingsFile != "" {
+		store = rating.NewFileStore(initData.RatingsFile)
+	}
+	ratings, err := rating.New(store, initData.RatingTau)
+	if err != nil {
+		log.Fatalf("failed to load ratings: %s", err)
+	}
+	return ratings
+}
+
+// getChat creates chat hub with limits and blocklist of initData.
+func getChat(initData *server.IniDataContainer) *chat.Hub {
+	options := chat.Options{
+		MaxLength: initData.ChatLength,
+		History:   initData.ChatHistory,
+		Rate:      initData.ChatRate,
+		Burst:     initData.ChatBurst,
+	}
+	if initData.ChatBlocklist != "" {
+		blocklist, err := chat.LoadBlocklist(initData.ChatBlocklist)
+		if err != nil {
+			log.Fatalf("failed to load chat blocklist: %s", err)
+		}
+		options.Filter = blocklist
+	}
+	return chat.New(options)
+}
+
 func getAuthorizator(initData *server.IniDataContainer) interfaces.Authorizator {
-	switch initData.Authorizer {
+	authorizator := getBackendAuthorizator(initData.Authorizer, initData)
+	if initData.CacheTTL <= 0 {
+		return authorizator
+	}
+
+	cached, err := cache.New(authorizator, initData.CacheTTL, initData.CacheSize)
+	if err != nil {
+		log.Fatalf("failed to create authorizations cache: %s", err)
+	}
+	authorizationCache.Store(cached)
+	return cached
+}
+
+func getBackendAuthorizator(kind string, initData *server.IniDataContainer) interfaces.Authorizator {
+	switch kind {
+	case "chain":
+		return getChainAuthorizator(initData)
 	case "dummy":
 		return dummy.New()
 	case "filemap":
-		authorizator, err := filemap.New(initData.Filename)
+		maper, err := filemap.ChoseMaper(initData.Filename)
+		if err != nil {
+			log.Fatalf("failed to create filemap authorizator: %s", err)
+		}
+		if initData.Encrypted {
+			maper = cryptMaper(maper, initData.FileKey)
+		}
+		authorizator, err := filemap.NewWithMaper(initData.Filename, initData.Backups, maper)
 		if err != nil {
 			log.Fatalf("failed to create filemap authorizator: %s", err)
 		}
+		if initData.Watch {
+			if err := authorizator.Watch(); err != nil {
+				log.Fatalf("failed to watch file of filemap authorizator: %s", err)
+			}
+		}
+		return authorizator
+	case "ldap":
+		conData := &ldap.ConnectionData{
+			Address:        initData.LDAPAddress,
+			UserDN:         initData.LDAPUserDN,
+			IDAttribute:    initData.LDAPIDAttribute,
+			PasswordModify: initData.LDAPPasswordModify,
+			BindDN:         initData.LDAPBindDN,
+			BindPassword:   initData.LDAPBindPassword,
+		}
+		if initData.LDAPTLS {
+			host, _, err := net.SplitHostPort(initData.LDAPAddress)
+			if err != nil {
+				log.Fatalf("failed to create ldap authorizator: %s", err)
+			}
+			conData.TLS = &tls.Config{ServerName: host}
+		}
+		authorizator, err := ldap.New(conData)
+		if err != nil {
+			log.Fatalf("failed to create ldap authorizator: %s", err)
+		}
 		return authorizator
 	case "postgresql":
 		conData := &postgres.ConnectionData{
@@ -226,10 +619,49 @@ func getAuthorizator(initData *server.IniDataContainer) interfaces.Authorizator
 		}
 		return authorizator
 	}
-	log.Fatalf("failed to create %q authorizator of unknown type", initData.Authorizer)
+	log.Fatalf("failed to create %q authorizator of unknown type", kind)
 	return nil
 }
 
+// getChainAuthorizator creates chain of authorizators described by
+// "type:namespace" items of initData.Chain.
+// New users are registered by the item equal to initData.ChainWritable.
+func getChainAuthorizator(initData *server.IniDataContainer) interfaces.Authorizator {
+	if initData.ChainWritable != "" && !isInList(initData.ChainWritable, initData.Chain) {
+		log.Fatalf("failed to create chain authorizator: writable item %q is not in chain", initData.ChainWritable)
+	}
+
+	backends := make([]chain.Backend, len(initData.Chain))
+	for i, item := range initData.Chain {
+		parts := strings.Split(item, ":")
+		if len(parts) != 2 || parts[0] == "chain" || !isInList(parts[0], acceptedAuthorizator) {
+			log.Fatalf("failed to create chain authorizator: wrong item %q, want \"type:namespace\"", item)
+		}
+		namespace, err := strconv.Atoi(parts[1])
+		if err != nil {
+			log.Fatalf("failed to create chain authorizator: wrong namespace of item %q: %s", item, err)
+		}
+		itemData := chainItemData(initData, namespace)
+		// without service account unknown login of ldap looks like a wrong password,
+		// so the chain would stop on it instead of asking the next backend
+		if parts[0] == "ldap" && itemData.LDAPBindDN == "" {
+			log.Fatalf("failed to create chain authorizator: ldap item %q requires --ldapbinddn", item)
+		}
+
+		backends[i] = chain.Backend{
+			Authorizator: getBackendAuthorizator(parts[0], itemData),
+			Namespace:    namespace,
+			Writable:     item == initData.ChainWritable,
+		}
+	}
+
+	authorizator, err := chain.New(backends)
+	if err != nil {
+		log.Fatalf("failed to create chain authorizator: %s", err)
+	}
+	return authorizator
+}
+
 func isInList(str string, list []string) bool {
 	for _, variant := range list {
 		if str == variant {
@@ -18,14 +18,25 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/yagoggame/api"
 	"github.com/yagoggame/grpc_server/interfaces"
 	"github.com/yagoggame/grpc_server/interfaces/mocks"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
 	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
 )
 
 var (
@@ -164,3 +175,186 @@ func gomockDependsOnSkiper(isSkiper bool,
 
 	return status.Error(codes.Unauthenticated, interfaces.ErrLogin.Error())
 }
+
+// identifier is a MockAuthorizator extended by interfaces.Identifier
+type identifier struct {
+	*mocks.MockAuthorizator
+	ids map[string]int
+}
+
+func (i *identifier) Identify(login string) (int, error) {
+	id, ok := i.ids[login]
+	if !ok {
+		return 0, interfaces.ErrLogin
+	}
+	return id, nil
+}
+
+var certificateAuthTests = []struct {
+	caseName   string
+	certAuth   bool
+	identifier bool
+	cert       *x509.Certificate
+	timesAuth  int
+	want       *iderr
+}{
+	{
+		caseName: "common name", certAuth: true, identifier: true,
+		cert: &x509.Certificate{Subject: pkix.Name{CommonName: "Bot"}},
+		want: &iderr{id: correctID + 1, err: nil}},
+	{
+		caseName: "DNS name", certAuth: true, identifier: true,
+		cert: &x509.Certificate{DNSNames: []string{"bot.example.org"}},
+		want: &iderr{id: correctID + 2, err: nil}},
+	{
+		caseName: "unknown login", certAuth: true, identifier: true,
+		cert: &x509.Certificate{Subject: pkix.Name{CommonName: "Piter"}},
+		want: &iderr{id: 0, err: status.Error(codes.Unauthenticated, interfaces.ErrLogin.Error())}},
+	{
+		caseName: "not identifier", certAuth: true, identifier: false,
+		cert: &x509.Certificate{Subject: pkix.Name{CommonName: "Bot"}},
+		want: &iderr{id: 0, err: status.Error(codes.Unauthenticated, interfaces.ErrNotIdentifier.Error())}},
+	{
+		caseName: "disabled", certAuth: false, identifier: true,
+		cert: &x509.Certificate{Subject: pkix.Name{CommonName: "Bot"}},
+		want: &iderr{id: 0, err: ErrMissCred}},
+	{
+		caseName: "no identity", certAuth: true, identifier: true,
+		cert: &x509.Certificate{},
+		want: &iderr{id: 0, err: ErrMissCred}},
+	{
+		caseName: "no certificate", certAuth: true, identifier: true,
+		want: &iderr{id: 0, err: ErrMissCred}},
+}
+
+func TestCertificateAuth(t *testing.T) {
+	for _, test := range certificateAuthTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			var authorizator interfaces.Authorizator = mocks.NewMockAuthorizator(controller)
+			if test.identifier {
+				authorizator = &identifier{
+					MockAuthorizator: authorizator.(*mocks.MockAuthorizator),
+					ids:              map[string]int{"Bot": correctID + 1, "bot.example.org": correctID + 2},
+				}
+			}
+			pooler := mocks.NewMockPooler(controller)
+			pooler.EXPECT().Release().Times(1)
+			s := NewServer(authorizator, pooler, nil)
+			s.SetCertificateAuth(test.certAuth)
+			defer s.Release()
+
+			val, err := UnaryInterceptor(certificateContext(context.Background(), test.cert), nil,
+				&grpc.UnaryServerInfo{Server: s}, handler)
+			ival := transform(t, val, err)
+			testIDErr(t, &iderr{id: ival, err: err}, test.want)
+		})
+	}
+}
+
+func TestCertificateAuthWithRequisites(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	authorizator := mocks.NewMockAuthorizator(controller)
+	pooler := mocks.NewMockPooler(controller)
+	gomock.InOrder(
+		authorizator.EXPECT().
+			Authorize(&usualRequisites).
+			Return(correctID, nil).
+			Times(1),
+		pooler.EXPECT().
+			Release().
+			Times(1),
+	)
+	s := NewServer(&identifier{MockAuthorizator: authorizator}, pooler, nil)
+	s.SetCertificateAuth(true)
+	defer s.Release()
+
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "Bot"}}
+	val, err := UnaryInterceptor(certificateContext(userContext(someLogin, somePassword), cert), nil,
+		&grpc.UnaryServerInfo{Server: s}, handler)
+	ival := transform(t, val, err)
+	testIDErr(t, &iderr{id: ival, err: err}, &iderr{id: correctID, err: nil})
+}
+
+func TestCertificateLogin(t *testing.T) {
+	ctx := certificateContext(context.Background(), &x509.Certificate{Subject: pkix.Name{CommonName: "Bot"}})
+	ctx = context.WithValue(ctx, clientLoginKey, "Bot")
+	ctx = context.WithValue(ctx, clientIDKey, correctID)
+	ctx = metadata.NewIncomingContext(ctx, metadata.MD{})
+
+	gamer, err := userFromContext(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected err: %v.", err)
+	}
+	if gamer.Name != "Bot" || gamer.ID != correctID {
+		t.Errorf("Unexpected gamer:\nwant: %s %d,\ngot: %s %d.", "Bot", correctID, gamer.Name, gamer.ID)
+	}
+}
+
+func TestReflectionInterceptors(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	// reflection must not touch authorizator.
+	authorizator := mocks.NewMockAuthorizator(controller)
+	pooler := mocks.NewMockPooler(controller)
+	pooler.EXPECT().Release().Times(1)
+	s := NewServer(authorizator, pooler, nil)
+	defer s.Release()
+
+	lis := bufconn.Listen(1 << 16)
+	grpcServer := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryInterceptor),
+		grpc.StreamInterceptor(StreamInterceptor))
+	api.RegisterGoGameServer(grpcServer, s)
+	reflection.Register(grpcServer)
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(),
+		grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+			return lis.Dial()
+		}))
+	if err != nil {
+		t.Fatalf("Unexpected Dial err: %v", err)
+	}
+	defer conn.Close()
+
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected ServerReflectionInfo err: %v", err)
+	}
+	err = stream.Send(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_ListServices{}})
+	if err != nil {
+		t.Fatalf("Unexpected Send err: %v", err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Unexpected Recv err: %v", err)
+	}
+
+	services := make(map[string]bool)
+	for _, service := range resp.GetListServicesResponse().GetService() {
+		services[service.GetName()] = true
+	}
+	if !services["api.GoGame"] {
+		t.Errorf("Unexpected services: api.GoGame not found in %v.", services)
+	}
+}
+
+// certificateContext returns ctx of peer with verified TLS certificate cert, if not nil.
+func certificateContext(ctx context.Context, cert *x509.Certificate) context.Context {
+	state := tls.ConnectionState{}
+	if cert != nil {
+		state.VerifiedChains = [][]*x509.Certificate{{cert}}
+	}
+	return peer.NewContext(ctx, &peer.Peer{
+		Addr:     &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)},
+		AuthInfo: credentials.TLSInfo{State: state},
+	})
+}
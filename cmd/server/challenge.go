@@ -0,0 +1,237 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/yagoggame/api"
+	"github.com/yagoggame/gomaster/game/igame"
+	"github.com/yagoggame/grpc_server/handicap"
+	"github.com/yagoggame/grpc_server/serverapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultChallengeTTL is a default time, during which a challenge awaits an answer.
+const DefaultChallengeTTL = 2 * time.Minute
+
+var (
+	// ErrNoSuchGamer occurs when challenged gamer isn't in the lobby
+	ErrNoSuchGamer = status.Errorf(codes.NotFound, "no such gamer in the lobby")
+	// ErrNoSuchChallenge occurs when challenge doesn't exist or is addressed to other gamer
+	ErrNoSuchChallenge = status.Errorf(codes.NotFound, "no such challenge")
+	// ErrWrongChallenge occurs when terms of challenge are wrong
+	ErrWrongChallenge = status.Errorf(codes.InvalidArgument, "wrong challenge")
+	// ErrBusy occurs when gamer already has a game
+	ErrBusy = status.Errorf(codes.FailedPrecondition, "gamer already has a game")
+	// ErrColourChoice occurs when challenger doesn't get the chosen colour in a new game
+	ErrColourChoice = status.Errorf(codes.Internal, "failed to give the chosen colour")
+)
+
+// Challenge creates private game of gamer with chosen terms and invites the gamer
+// with in.Login to it. The challenger awaits the answer by ResumeGame.
+// Nobody but the invited gamer can join the game with returned code.
+// With handicap Black gets stones on star points or places them by his first moves.
+func (s *Server) Challenge(ctx context.Context, in *serverapi.ChallengeRequest) (*serverapi.ChallengeMessage, error) {
+	gamer, err := userFromContext(ctx)
+	if err != nil {
+		log.Printf("Challenge error: %s", err)
+		return &serverapi.ChallengeMessage{}, err
+	}
+
+	t, err := challengeTerms(in)
+	if err == nil && in.GetLogin() == gamer.Name {
+		err = extGrpcError(ErrWrongChallenge, "gamer can't challenge himself")
+	}
+	if err == nil {
+		err = s.checkFree(gamer.ID)
+	}
+	if err != nil {
+		log.Printf("Challenge error: %s", err)
+		return &serverapi.ChallengeMessage{}, err
+	}
+
+	to, ok := s.lobbyGamerID(in.GetLogin())
+	if !ok {
+		err = extGrpcError(ErrNoSuchGamer, fmt.Sprintf("login %q", in.GetLogin()))
+		log.Printf("Challenge error: %s", err)
+		return &serverapi.ChallengeMessage{}, err
+	}
+
+	invite, err := s.invitations.create(gamer.ID, gamer.Name, to, in.GetLogin(), t, s.challengeTTL)
+	if err != nil {
+		log.Printf("Challenge error: %s", err)
+		return &serverapi.ChallengeMessage{}, err
+	}
+	s.presence.changed()
+
+	log.Printf("gamer with id %d challenged gamer with id %d", gamer.ID, to)
+	return toChallengeMessage(invite), nil
+}
+
+// ListChallenges returns challenges of gamer and to him.
+func (s *Server) ListChallenges(ctx context.Context, in *api.EmptyMessage) (*serverapi.ChallengesMessage, error) {
+	id, err := idFromCtx(ctx)
+	if err != nil {
+		log.Printf("ListChallenges error: %s", err)
+		return &serverapi.ChallengesMessage{}, err
+	}
+
+	invites := s.invitations.list(id)
+	challenges := make([]*serverapi.ChallengeMessage, len(invites))
+	for i := range invites {
+		challenges[i] = toChallengeMessage(&invites[i])
+	}
+	return &serverapi.ChallengesMessage{Challenges: challenges}, nil
+}
+
+// AcceptChallenge joins the invited gamer to the private game of challenge
+// and returns the state of begun game.
+func (s *Server) AcceptChallenge(ctx context.Context, in *serverapi.CodeMessage) (*api.State, error) {
+	gamer, err := userFromContext(ctx)
+	if err != nil {
+		log.Printf("AcceptChallenge error: %s", err)
+		return &api.State{}, err
+	}
+	if err := s.checkFree(gamer.ID); err != nil {
+		log.Printf("AcceptChallenge error: %s", err)
+		return &api.State{}, err
+	}
+
+	if _, err := s.invitations.accept(in.GetCode(), gamer.ID, gamer.Name); err != nil {
+		err = extGrpcError(err, fmt.Sprintf("gamer with id %d", gamer.ID))
+		log.Printf("AcceptChallenge error: %s", err)
+		return &api.State{}, err
+	}
+	defer s.presence.changed()
+
+	state, err := s.waitGame(ctx, gamer.ID)
+	if err != nil {
+		log.Printf("AcceptChallenge error: %s", err)
+		return &api.State{}, err
+	}
+
+	log.Printf("gamer with id %d accepted challenge, game has been begun", gamer.ID)
+	return state, nil
+}
+
+// DeclineChallenge declines the challenge by invited gamer or cancels it by challenger.
+func (s *Server) DeclineChallenge(ctx context.Context, in *serverapi.CodeMessage) (*api.EmptyMessage, error) {
+	id, err := idFromCtx(ctx)
+	if err != nil {
+		log.Printf("DeclineChallenge error: %s", err)
+		return &api.EmptyMessage{}, err
+	}
+
+	if err := s.invitations.decline(in.GetCode(), id); err != nil {
+		err = extGrpcError(err, fmt.Sprintf("gamer with id %d", id))
+		log.Printf("DeclineChallenge error: %s", err)
+		return &api.EmptyMessage{}, err
+	}
+
+	log.Printf("gamer with id %d declined challenge", id)
+	return &api.EmptyMessage{}, nil
+}
+
+// checkFree checks, that gamer is in the lobby, has no game and doesn't seek it.
+func (s *Server) checkFree(id int) error {
+	if _, err := s.pool.GetGamer(id); err != nil {
+		return extGrpcError(ErrNotInLobby, fmt.Sprintf("gamer with id %d", id))
+	}
+	if s.matchmaker.queue.Contains(id) {
+		return extGrpcError(ErrSeeking, fmt.Sprintf("gamer with id %d", id))
+	}
+	if gameManager, err := s.gameGeter.GetGame(id); err != nil || gameManager != nil {
+		return extGrpcError(ErrBusy, fmt.Sprintf("gamer with id %d", id))
+	}
+	return nil
+}
+
+// lobbyGamerID returns id of gamer in the lobby with login.
+func (s *Server) lobbyGamerID(login string) (int, bool) {
+	for _, gamer := range s.pool.ListGamers() {
+		if gamer.Name == login {
+			return gamer.ID, true
+		}
+	}
+	return 0, false
+}
+
+func challengeTerms(in *serverapi.ChallengeRequest) (terms, error) {
+	t := terms{
+		size:      int(in.GetSize()),
+		komi:      in.GetKomi(),
+		mainTime:  time.Duration(in.GetMainTime()) * time.Second,
+		increment: time.Duration(in.GetIncrement()) * time.Second,
+	}
+	if t.size == 0 {
+		t.size = standartSize
+	}
+	switch in.GetColour() {
+	case serverapi.Colour_BLACK:
+		t.colour = igame.Black
+	case serverapi.Colour_WHITE:
+		t.colour = igame.White
+	case serverapi.Colour_RANDOM:
+		t.colour = igame.NoColour
+	default:
+		return terms{}, extGrpcError(ErrWrongChallenge, fmt.Sprintf("unknown colour %v", in.GetColour()))
+	}
+	if t.mainTime < 0 || t.increment < 0 {
+		return terms{}, extGrpcError(ErrWrongChallenge, "time control must not be negative")
+	}
+	if in.GetHandicap() != 0 {
+		if err := handicapTerms(&t, int(in.GetHandicap()), in.GetFreePlacement()); err != nil {
+			return terms{}, extGrpcError(ErrWrongChallenge, err.Error())
+		}
+	}
+	return t, nil
+}
+
+// handicapTerms sets handicap of terms and its komi.
+func handicapTerms(t *terms, stones int, free bool) error {
+	if free {
+		if err := handicap.Check(stones); err != nil {
+			return err
+		}
+	} else if _, err := handicap.Fixed(t.size, stones); err != nil {
+		return err
+	}
+	t.handicap, t.free = stones, free
+	t.komi = handicap.Komi
+	return nil
+}
+
+func toChallengeMessage(invite *invitation) *serverapi.ChallengeMessage {
+	return &serverapi.ChallengeMessage{
+		Code:          invite.code,
+		From:          invite.fromLogin,
+		To:            invite.toLogin,
+		Size:          int64(invite.terms.size),
+		Komi:          invite.terms.komi,
+		Colour:        serverapi.Colour(invite.terms.colour),
+		MainTime:      int64(invite.terms.mainTime / time.Second),
+		Increment:     int64(invite.terms.increment / time.Second),
+		Expires:       invite.expires.UnixNano(),
+		Handicap:      int64(invite.terms.handicap),
+		FreePlacement: invite.terms.free,
+	}
+}
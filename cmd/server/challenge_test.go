@@ -0,0 +1,231 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/yagoggame/api"
+	"github.com/yagoggame/gomaster/game"
+	"github.com/yagoggame/gomaster/game/igame"
+	"github.com/yagoggame/grpc_server/serverapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var challengeErrTests = []struct {
+	caseName string
+	request  *serverapi.ChallengeRequest
+	want     codes.Code
+}{
+	{caseName: "himself", request: &serverapi.ChallengeRequest{Login: "Joe"}, want: codes.InvalidArgument},
+	{caseName: "not in lobby", request: &serverapi.ChallengeRequest{Login: "Piter"}, want: codes.NotFound},
+	{caseName: "wrong size", request: &serverapi.ChallengeRequest{Login: "Nick", Size: 100}, want: codes.InvalidArgument},
+	{caseName: "wrong colour", request: &serverapi.ChallengeRequest{Login: "Nick", Colour: 5}, want: codes.InvalidArgument},
+	{caseName: "negative time", request: &serverapi.ChallengeRequest{Login: "Nick", MainTime: -1}, want: codes.InvalidArgument},
+}
+
+func TestChallenge(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	conn, release := startTestServer(t, DefaultSpectatorLimit)
+	defer release()
+	gameClient := api.NewGoGameClient(conn)
+	sessionClient := serverapi.NewSessionClient(conn)
+	challengeClient := serverapi.NewChallengeClient(conn)
+
+	joe := clientContext("Joe", "aaa")
+	nick := clientContext("Nick", "bbb")
+	piter := clientContext("Piter", "ppp")
+	if _, err := gameClient.RegisterUser(piter, &api.EmptyMessage{}); err != nil {
+		t.Fatalf("Unexpected RegisterUser err: %v", err)
+	}
+	for _, ctx := range []context.Context{joe, nick} {
+		if _, err := gameClient.EnterTheLobby(ctx, &api.EmptyMessage{}); err != nil {
+			t.Fatalf("Unexpected EnterTheLobby err: %v", err)
+		}
+	}
+
+	for _, test := range challengeErrTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			if _, err := challengeClient.Challenge(joe, test.request); status.Code(err) != test.want {
+				t.Errorf("Unexpected Challenge err:\nwant: %v,\ngot: %v.", test.want, err)
+			}
+		})
+	}
+
+	invite, err := challengeClient.Challenge(joe, &serverapi.ChallengeRequest{Login: "Nick", Size: 7, Colour: serverapi.Colour_WHITE, MainTime: 60})
+	if err != nil {
+		t.Fatalf("Unexpected Challenge err: %v", err)
+	}
+	if _, err := challengeClient.Challenge(joe, &serverapi.ChallengeRequest{Login: "Nick"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Unexpected second Challenge err:\nwant: %v,\ngot: %v.", ErrBusy, err)
+	}
+	challenges, err := challengeClient.ListChallenges(nick, &api.EmptyMessage{})
+	if err != nil || len(challenges.GetChallenges()) != 1 {
+		t.Fatalf("Unexpected ListChallenges result: %v, %v", challenges, err)
+	}
+	if got := challenges.GetChallenges()[0]; got.GetCode() != invite.GetCode() || got.GetFrom() != "Joe" ||
+		got.GetTo() != "Nick" || got.GetColour() != serverapi.Colour_WHITE || got.GetSize() != 7 {
+		t.Errorf("Unexpected challenge: %v", got)
+	}
+
+	// nobody else can join the private game
+	if _, err := gameClient.EnterTheLobby(piter, &api.EmptyMessage{}); err != nil {
+		t.Fatalf("Unexpected EnterTheLobby err: %v", err)
+	}
+	if _, err := challengeClient.AcceptChallenge(piter, &serverapi.CodeMessage{Code: invite.GetCode()}); status.Code(err) != codes.NotFound {
+		t.Errorf("Unexpected AcceptChallenge by other gamer err:\nwant: %v,\ngot: %v.", ErrNoSuchChallenge, err)
+	}
+	joinCtx, cancelJoin := context.WithTimeout(piter, testGrace/3)
+	defer cancelJoin()
+	if _, err := gameClient.JoinTheGame(joinCtx, &api.EmptyMessage{}); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("Unexpected JoinTheGame of other gamer err:\nwant: %v,\ngot: %v.", codes.DeadlineExceeded, err)
+	}
+	if _, err := gameClient.LeaveTheGame(piter, &api.EmptyMessage{}); err != nil {
+		t.Fatalf("Unexpected LeaveTheGame err: %v", err)
+	}
+
+	resumed := make(chan error, 1)
+	go func() {
+		_, err := sessionClient.ResumeGame(joe, &api.EmptyMessage{})
+		resumed <- err
+	}()
+	state, err := challengeClient.AcceptChallenge(nick, &serverapi.CodeMessage{Code: invite.GetCode()})
+	if err != nil {
+		t.Fatalf("Unexpected AcceptChallenge err: %v", err)
+	}
+	if state.GetSize() != 7 {
+		t.Errorf("Unexpected size of game:\nwant: %d,\ngot: %d.", 7, state.GetSize())
+	}
+	if err := <-resumed; err != nil {
+		t.Fatalf("Unexpected ResumeGame err: %v", err)
+	}
+
+	gameStatus, err := sessionClient.GetGameState(nick, &api.EmptyMessage{})
+	if err != nil {
+		t.Fatalf("Unexpected GetGameState err: %v", err)
+	}
+	if !gameStatus.GetMyTurn() || gameStatus.GetTimeLeft() <= 0 || gameStatus.GetTimeLeft() > 60000 ||
+		gameStatus.GetPartnerTimeLeft() != 60000 {
+		t.Errorf("Unexpected game status of black gamer: %v", gameStatus)
+	}
+	if _, err := gameClient.MakeTurn(nick, &api.TurnMessage{X: 3, Y: 3}); err != nil {
+		t.Fatalf("Unexpected MakeTurn err: %v", err)
+	}
+	if gameStatus, err := sessionClient.GetGameState(joe, &api.EmptyMessage{}); err != nil || !gameStatus.GetMyTurn() {
+		t.Errorf("Unexpected game status of white gamer: %v, %v", gameStatus, err)
+	}
+
+	for _, ctx := range []context.Context{joe, nick} {
+		if _, err := gameClient.LeaveTheGame(ctx, &api.EmptyMessage{}); err != nil {
+			t.Fatalf("Unexpected LeaveTheGame err: %v", err)
+		}
+	}
+
+	invite, err = challengeClient.Challenge(nick, &serverapi.ChallengeRequest{Login: "Joe"})
+	if err != nil {
+		t.Fatalf("Unexpected Challenge err: %v", err)
+	}
+	if _, err := challengeClient.DeclineChallenge(joe, &serverapi.CodeMessage{Code: invite.GetCode()}); err != nil {
+		t.Fatalf("Unexpected DeclineChallenge err: %v", err)
+	}
+	if gameStatus, err := sessionClient.GetGameState(nick, &api.EmptyMessage{}); err != nil || gameStatus.GetStatus() != serverapi.GameStatus_IN_LOBBY {
+		t.Errorf("Unexpected game status after decline: %v, %v", gameStatus, err)
+	}
+	if challenges, err := challengeClient.ListChallenges(joe, &api.EmptyMessage{}); err != nil || len(challenges.GetChallenges()) != 0 {
+		t.Errorf("Unexpected ListChallenges after decline: %v, %v", challenges, err)
+	}
+}
+
+func TestChallengeTimeout(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	conn, release := startTestServer(t, DefaultSpectatorLimit)
+	defer release()
+	gameClient := api.NewGoGameClient(conn)
+	challengeClient := serverapi.NewChallengeClient(conn)
+
+	joe := clientContext("Joe", "aaa")
+	nick := clientContext("Nick", "bbb")
+	for _, ctx := range []context.Context{joe, nick} {
+		if _, err := gameClient.EnterTheLobby(ctx, &api.EmptyMessage{}); err != nil {
+			t.Fatalf("Unexpected EnterTheLobby err: %v", err)
+		}
+	}
+
+	invite, err := challengeClient.Challenge(joe, &serverapi.ChallengeRequest{Login: "Nick", Colour: serverapi.Colour_BLACK, MainTime: 1})
+	if err != nil {
+		t.Fatalf("Unexpected Challenge err: %v", err)
+	}
+	if _, err := challengeClient.AcceptChallenge(nick, &serverapi.CodeMessage{Code: invite.GetCode()}); err != nil {
+		t.Fatalf("Unexpected AcceptChallenge err: %v", err)
+	}
+
+	// black gamer doesn't move, so white one's waiting ends with his loss on time
+	ctx, cancel := context.WithTimeout(nick, 3*time.Second)
+	defer cancel()
+	start := time.Now()
+	if _, err := gameClient.WaitTheTurn(ctx, &api.EmptyMessage{}); err == nil || status.Code(err) == codes.DeadlineExceeded {
+		t.Errorf("Unexpected WaitTheTurn err after timeout: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Unexpected duration of waiting: %s", elapsed)
+	}
+	if _, err := gameClient.MakeTurn(joe, &api.TurnMessage{X: 1, Y: 1}); err == nil {
+		t.Errorf("Unexpected absence of MakeTurn err after timeout")
+	}
+}
+
+var colouredGameTests = []struct {
+	caseName string
+	colour   igame.ChipColour
+}{
+	{caseName: "black", colour: igame.Black},
+	{caseName: "white", colour: igame.White},
+}
+
+func TestColouredGame(t *testing.T) {
+	inv := newInvitations(func(int) {})
+	for _, test := range colouredGameTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			// gomaster gives a random colour to the first gamer
+			for i := 0; i < 8; i++ {
+				g, err := inv.newColouredGame(1, "Joe", terms{size: 9, komi: standartKomi, colour: test.colour})
+				if err != nil {
+					t.Fatalf("Unexpected newColouredGame err: %v", err)
+				}
+				if err := g.Join(&game.Gamer{Name: "Nick", ID: 2}); err != nil {
+					t.Fatalf("Unexpected Join err: %v", err)
+				}
+				testGamerColour(t, g, 1, test.colour)
+				testGamerColour(t, g, 2, opposite(test.colour))
+				_ = g.End()
+			}
+		})
+	}
+}
+
+func testGamerColour(t *testing.T, g managedGame, id int, want igame.ChipColour) {
+	t.Helper()
+	state, err := g.GamerState(id)
+	if err != nil || state.Colour != want {
+		t.Fatalf("Unexpected colour of gamer with id %d:\nwant: %v,\ngot: %v, %v.", id, want, state.Colour, err)
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/yagoggame/api"
+	"github.com/yagoggame/grpc_server/chat"
+	"github.com/yagoggame/grpc_server/serverapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultChatLength is a default maximal number of characters in a chat message.
+	DefaultChatLength = 500
+	// DefaultChatHistory is a default number of last messages kept in a chat channel.
+	DefaultChatHistory = 50
+)
+
+// lobbyChannelName is a name of lobby channel in chat.Hub.
+const lobbyChannelName = "lobby"
+
+var (
+	// ErrNotInLobby occurs when gamer uses lobby channel without entering the lobby
+	ErrNotInLobby = status.Errorf(codes.FailedPrecondition, "gamer is not in the lobby")
+	// ErrUnknownChannel occurs when requested chat channel is unknown
+	ErrUnknownChannel = status.Errorf(codes.InvalidArgument, "unknown chat channel")
+)
+
+// SendMessage sends text of the message to the lobby channel,
+// if gamer is in the lobby, or to the channel of his game.
+func (s *Server) SendMessage(ctx context.Context, in *serverapi.ChatMessage) (*api.EmptyMessage, error) {
+	id, err := idFromCtx(ctx)
+	if err != nil {
+		log.Printf("SendMessage error: %s", err)
+		return &api.EmptyMessage{}, err
+	}
+	login, err := loginFromContext(ctx)
+	if err != nil {
+		log.Printf("SendMessage error: %s", err)
+		return &api.EmptyMessage{}, err
+	}
+
+	name, err := s.chatChannel(id, in.GetChannel())
+	if err != nil {
+		log.Printf("SendMessage error: %s", err)
+		return &api.EmptyMessage{}, err
+	}
+
+	message := &chat.Message{Channel: name, UserID: id, Login: login, Text: in.GetText()}
+	if err := s.chat.Send(message); err != nil {
+		err = chatError(err, fmt.Sprintf("gamer with id %d", id))
+		log.Printf("SendMessage error: %s", err)
+		return &api.EmptyMessage{}, err
+	}
+	return &api.EmptyMessage{}, nil
+}
+
+// Subscribe sends new messages of the channel, until the gamer leaves it
+// or the game of the channel ends.
+func (s *Server) Subscribe(in *serverapi.ChannelMessage, stream serverapi.Chat_SubscribeServer) error {
+	ctx := stream.Context()
+	id, err := idFromCtx(ctx)
+	if err != nil {
+		log.Printf("Subscribe error: %s", err)
+		return err
+	}
+
+	name, err := s.chatChannel(id, in.GetChannel())
+	if err != nil {
+		log.Printf("Subscribe error: %s", err)
+		return err
+	}
+	messages, cancel := s.chat.Subscribe(name)
+	defer cancel()
+	log.Printf("gamer with id %d subscribed to chat channel %q", id, name)
+
+	for {
+		select {
+		case message, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			// gamer could have left the channel meanwhile
+			if current, err := s.chatChannel(id, in.GetChannel()); err != nil || current != name {
+				return nil
+			}
+			if err := stream.Send(toChatMessage(in.GetChannel(), message)); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+}
+
+// History returns last in.Limit messages of the channel
+// or all kept messages, if in.Limit is 0.
+func (s *Server) History(ctx context.Context, in *serverapi.HistoryRequest) (*serverapi.HistoryMessage, error) {
+	id, err := idFromCtx(ctx)
+	if err != nil {
+		log.Printf("History error: %s", err)
+		return &serverapi.HistoryMessage{}, err
+	}
+
+	name, err := s.chatChannel(id, in.GetChannel())
+	if err != nil {
+		log.Printf("History error: %s", err)
+		return &serverapi.HistoryMessage{}, err
+	}
+
+	history := s.chat.History(name, int(in.GetLimit()))
+	messages := make([]*serverapi.ChatMessage, len(history))
+	for i, message := range history {
+		messages[i] = toChatMessage(in.GetChannel(), message)
+	}
+	return &serverapi.HistoryMessage{Messages: messages}, nil
+}
+
+// chatChannel returns name of the channel of kind, available to gamer with id.
+func (s *Server) chatChannel(id int, kind serverapi.ChatChannel) (string, error) {
+	switch kind {
+	case serverapi.ChatChannel_LOBBY:
+		if _, err := s.pool.GetGamer(id); err != nil {
+			return "", extGrpcError(ErrNotInLobby, fmt.Sprintf("gamer with id %d", id))
+		}
+		return lobbyChannelName, nil
+	case serverapi.ChatChannel_GAME:
+		tableID, ok := s.seats.tableOf(id)
+		if !ok {
+			return "", extGrpcError(ErrNoGame, fmt.Sprintf("gamer with id %d", id))
+		}
+		return gameChannelName(tableID), nil
+	}
+	return "", extGrpcError(ErrUnknownChannel, kind.String())
+}
+
+// gameChannelName returns a name of the channel of game with tableID in chat.Hub.
+func gameChannelName(tableID int) string {
+	return "game/" + strconv.Itoa(tableID)
+}
+
+// chatError converts error of chat.Hub to grpc error.
+func chatError(err error, ext string) error {
+	code := codes.InvalidArgument
+	if errors.Is(err, chat.ErrRateLimit) {
+		code = codes.ResourceExhausted
+	}
+	return status.Errorf(code, "%s: %s", err, ext)
+}
+
+func toChatMessage(kind serverapi.ChatChannel, message *chat.Message) *serverapi.ChatMessage {
+	return &serverapi.ChatMessage{
+		Channel: kind,
+		UserId:  int64(message.UserID),
+		Login:   message.Login,
+		Text:    message.Text,
+		Time:    message.Time.UnixNano(),
+	}
+}
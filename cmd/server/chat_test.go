@@ -0,0 +1,160 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yagoggame/api"
+	"github.com/yagoggame/grpc_server/serverapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var chatErrTests = []struct {
+	caseName string
+	message  *serverapi.ChatMessage
+	want     codes.Code
+}{
+	{caseName: "empty", message: &serverapi.ChatMessage{Channel: serverapi.ChatChannel_LOBBY, Text: "  "}, want: codes.InvalidArgument},
+	{caseName: "too long", message: &serverapi.ChatMessage{Channel: serverapi.ChatChannel_LOBBY, Text: strings.Repeat("a", DefaultChatLength+1)}, want: codes.InvalidArgument},
+	{caseName: "unknown channel", message: &serverapi.ChatMessage{Channel: 7, Text: "hi"}, want: codes.InvalidArgument},
+	{caseName: "not in game", message: &serverapi.ChatMessage{Channel: serverapi.ChatChannel_GAME, Text: "hi"}, want: codes.FailedPrecondition},
+}
+
+func TestChat(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	conn, release := startTestServer(t, DefaultSpectatorLimit)
+	defer release()
+	gameClient := api.NewGoGameClient(conn)
+	chatClient := serverapi.NewChatClient(conn)
+
+	joe := clientContext("Joe", "aaa")
+	nick := clientContext("Nick", "bbb")
+	if _, err := chatClient.SendMessage(joe, &serverapi.ChatMessage{Text: "hi"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Unexpected SendMessage out of lobby err:\nwant: %v,\ngot: %v.", ErrNotInLobby, err)
+	}
+	for _, ctx := range []context.Context{joe, nick} {
+		if _, err := gameClient.EnterTheLobby(ctx, &api.EmptyMessage{}); err != nil {
+			t.Fatalf("Unexpected EnterTheLobby err: %v", err)
+		}
+	}
+
+	for _, test := range chatErrTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			if _, err := chatClient.SendMessage(joe, test.message); status.Code(err) != test.want {
+				t.Errorf("Unexpected SendMessage err:\nwant: %v,\ngot: %v.", test.want, err)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(nick)
+	lobby := subscribeChat(t, chatClient, ctx, serverapi.ChatChannel_LOBBY)
+	message := sendUntilReceived(t, chatClient, joe, &serverapi.ChatMessage{Channel: serverapi.ChatChannel_LOBBY, Text: "hi"}, lobby)
+	if message.GetLogin() != "Joe" || message.GetUserId() != 2 || message.GetText() != "hi" || message.GetTime() == 0 {
+		t.Errorf("Unexpected lobby message: %v", message)
+	}
+	history, err := chatClient.History(nick, &serverapi.HistoryRequest{Channel: serverapi.ChatChannel_LOBBY, Limit: 1})
+	if err != nil || len(history.GetMessages()) != 1 || history.GetMessages()[0].GetText() != "hi" {
+		t.Errorf("Unexpected lobby History: %v, %v", history, err)
+	}
+
+	for _, ctx := range []context.Context{joe, nick} {
+		if _, err := gameClient.LeaveTheLobby(ctx, &api.EmptyMessage{}); err != nil {
+			t.Fatalf("Unexpected LeaveTheLobby err: %v", err)
+		}
+	}
+	joinBoth(t, gameClient, joe, nick)
+
+	game := subscribeChat(t, chatClient, nick, serverapi.ChatChannel_GAME)
+	message = sendUntilReceived(t, chatClient, joe, &serverapi.ChatMessage{Channel: serverapi.ChatChannel_GAME, Text: "gg"}, game)
+	if message.GetChannel() != serverapi.ChatChannel_GAME || message.GetText() != "gg" {
+		t.Errorf("Unexpected game message: %v", message)
+	}
+	history, err = chatClient.History(joe, &serverapi.HistoryRequest{Channel: serverapi.ChatChannel_LOBBY})
+	if err != nil {
+		t.Fatalf("Unexpected lobby History err: %v", err)
+	}
+	for _, message := range history.GetMessages() {
+		if message.GetText() == "gg" {
+			t.Errorf("Unexpected game message in lobby History: %v", message)
+		}
+	}
+
+	for _, ctx := range []context.Context{joe, nick} {
+		if _, err := gameClient.LeaveTheGame(ctx, &api.EmptyMessage{}); err != nil {
+			t.Fatalf("Unexpected LeaveTheGame err: %v", err)
+		}
+	}
+	cancel()
+	for range lobby {
+	}
+
+	select {
+	case message, ok := <-game:
+		if ok {
+			t.Errorf("Unexpected game message after game end: %v", message)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Unexpected game channel after game end")
+	}
+}
+
+func subscribeChat(t *testing.T, client serverapi.ChatClient, ctx context.Context, channel serverapi.ChatChannel) <-chan *serverapi.ChatMessage {
+	stream, err := client.Subscribe(ctx, &serverapi.ChannelMessage{Channel: channel})
+	if err != nil {
+		t.Fatalf("Unexpected Subscribe err: %v", err)
+	}
+	messages := make(chan *serverapi.ChatMessage, eventsBuffer)
+	go func() {
+		defer close(messages)
+		for {
+			message, err := stream.Recv()
+			if err != nil {
+				if err != io.EOF && status.Code(err) != codes.Canceled {
+					t.Errorf("Unexpected Subscribe stream err: %v", err)
+				}
+				return
+			}
+			messages <- message
+		}
+	}()
+	return messages
+}
+
+// sendUntilReceived repeats message, because subscription is made asynchronously.
+func sendUntilReceived(t *testing.T, client serverapi.ChatClient, ctx context.Context, message *serverapi.ChatMessage, messages <-chan *serverapi.ChatMessage) *serverapi.ChatMessage {
+	timeout := time.After(time.Second)
+	for {
+		if _, err := client.SendMessage(ctx, message); err != nil {
+			t.Fatalf("Unexpected SendMessage err: %v", err)
+		}
+		select {
+		case received := <-messages:
+			return received
+		case <-time.After(20 * time.Millisecond):
+		case <-timeout:
+			t.Fatalf("Unexpected absence of chat message")
+		}
+	}
+}
@@ -50,5 +50,10 @@ func (gg *GameGeter) GetGame(id int) (interfaces.GameManager, error) {
 		err = extGrpcError(ErrNoGamerID, fmt.Sprintf(" with id %d: %v", id, err))
 		return nil, err
 	}
-	return gamer.GetGame(), nil
+	// nil Game must be returned as nil interface, not as interface holding nil.
+	game := gamer.GetGame()
+	if game == nil {
+		return nil, nil
+	}
+	return game, nil
 }
@@ -0,0 +1,285 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"testing"
+
+	"github.com/yagoggame/api"
+	"github.com/yagoggame/gomaster/game"
+	"github.com/yagoggame/gomaster/game/igame"
+	"github.com/yagoggame/grpc_server/handicap"
+	"github.com/yagoggame/grpc_server/serverapi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var handicapTermsTests = []struct {
+	caseName string
+	request  *serverapi.ChallengeRequest
+	want     terms
+	wantErr  bool
+}{
+	{caseName: "fixed", request: &serverapi.ChallengeRequest{Size: 19, Komi: 6.5, Handicap: 4},
+		want: terms{size: 19, komi: handicap.Komi, handicap: 4}},
+	{caseName: "free", request: &serverapi.ChallengeRequest{Size: 11, Handicap: 3, FreePlacement: true},
+		want: terms{size: 11, komi: handicap.Komi, handicap: 3, free: true}},
+	{caseName: "no star points", request: &serverapi.ChallengeRequest{Size: 11, Handicap: 3}, wantErr: true},
+	{caseName: "too many stones", request: &serverapi.ChallengeRequest{Handicap: 10, FreePlacement: true}, wantErr: true},
+	{caseName: "one stone", request: &serverapi.ChallengeRequest{Handicap: 1}, wantErr: true},
+}
+
+func TestHandicapTerms(t *testing.T) {
+	for _, test := range handicapTermsTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			got, err := challengeTerms(test.request)
+			if test.wantErr {
+				if status.Code(err) != codes.InvalidArgument {
+					t.Errorf("Unexpected challengeTerms err:\nwant: %v,\ngot: %v.", ErrWrongChallenge, err)
+				}
+				return
+			}
+			if err != nil || got != test.want {
+				t.Errorf("Unexpected challengeTerms:\nwant: %+v,\ngot: %+v, %v.", test.want, got, err)
+			}
+		})
+	}
+}
+
+var handicapGameTests = []struct {
+	caseName string
+	colour   igame.ChipColour
+}{
+	{caseName: "black", colour: igame.Black},
+	{caseName: "white", colour: igame.White},
+}
+
+func TestHandicapGame(t *testing.T) {
+	want, err := handicap.Fixed(9, 2)
+	if err != nil {
+		t.Fatalf("Unexpected Fixed err: %v", err)
+	}
+	inv := newInvitations(func(int) {})
+	for _, test := range handicapGameTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			g := newJoinedGame(t, inv, terms{size: 9, komi: handicap.Komi, colour: test.colour, handicap: 2})
+			defer g.End()
+			testGamerColour(t, g, 1, test.colour)
+			testGamerColour(t, g, 2, opposite(test.colour))
+			black, white := 1, 2
+			if test.colour == igame.White {
+				black, white = 2, 1
+			}
+
+			state, err := g.GameState(black)
+			if err != nil {
+				t.Fatalf("Unexpected GameState err: %v", err)
+			}
+			if len(state.ChipsOnBoard[igame.Black]) != len(want) || len(state.ChipsOnBoard[igame.White]) != 0 || state.Komi != handicap.Komi {
+				t.Fatalf("Unexpected state of handicap game: %+v", state)
+			}
+			for _, stone := range want {
+				if !containsStone(state.ChipsOnBoard[igame.Black], stone) {
+					t.Errorf("Unexpected absence of handicap stone %v in %v", stone, state.ChipsOnBoard[igame.Black])
+				}
+			}
+
+			if myTurn, err := g.IsMyTurn(white); err != nil || !myTurn {
+				t.Errorf("Unexpected IsMyTurn of White: %v, %v", myTurn, err)
+			}
+			if err := g.MakeTurn(black, &igame.TurnData{X: 5, Y: 5}); !errors.Is(err, game.ErrNotYourTurn) {
+				t.Errorf("Unexpected MakeTurn of Black before White err:\nwant: %v,\ngot: %v.", game.ErrNotYourTurn, err)
+			}
+			if err := g.MakeTurn(white, &want[0]); !errors.Is(err, game.ErrWrongTurn) {
+				t.Errorf("Unexpected MakeTurn to handicap stone err:\nwant: %v,\ngot: %v.", game.ErrWrongTurn, err)
+			}
+			if err := g.MakeTurn(white, &igame.TurnData{X: 5, Y: 5}); err != nil {
+				t.Fatalf("Unexpected MakeTurn of White err: %v", err)
+			}
+			if err := g.MakeTurn(black, &igame.TurnData{X: 4, Y: 4}); err != nil {
+				t.Fatalf("Unexpected MakeTurn of Black err: %v", err)
+			}
+			if state, err = g.GameState(white); err != nil || len(state.ChipsOnBoard[igame.Black]) != 3 || len(state.ChipsOnBoard[igame.White]) != 1 {
+				t.Errorf("Unexpected state after moves: %+v, %v", state, err)
+			}
+		})
+	}
+}
+
+func TestFreeHandicap(t *testing.T) {
+	inv := newInvitations(func(int) {})
+	// Joe with id 1 is Black, Nick with id 2 is White
+	g := newJoinedGame(t, inv, terms{size: 9, komi: handicap.Komi, colour: igame.Black, handicap: 2, free: true})
+	defer g.End()
+
+	waited := make(chan error, 1)
+	go func() { waited <- g.WaitTurn(context.Background(), 2) }()
+
+	if myTurn, err := g.IsMyTurn(2); err != nil || myTurn {
+		t.Errorf("Unexpected IsMyTurn of White during placement: %v, %v", myTurn, err)
+	}
+	if err := g.MakeTurn(2, &igame.TurnData{X: 5, Y: 5}); !errors.Is(err, game.ErrNotYourTurn) {
+		t.Errorf("Unexpected MakeTurn of White during placement err:\nwant: %v,\ngot: %v.", game.ErrNotYourTurn, err)
+	}
+	stones := []igame.TurnData{{X: 2, Y: 8}, {X: 1, Y: 1}}
+	if err := g.MakeTurn(1, &stones[0]); err != nil {
+		t.Fatalf("Unexpected placement err: %v", err)
+	}
+	if err := g.MakeTurn(1, &stones[0]); !errors.Is(err, game.ErrWrongTurn) {
+		t.Errorf("Unexpected placement to occupied point err:\nwant: %v,\ngot: %v.", game.ErrWrongTurn, err)
+	}
+	if myTurn, err := g.IsMyTurn(1); err != nil || !myTurn {
+		t.Errorf("Unexpected IsMyTurn of Black during placement: %v, %v", myTurn, err)
+	}
+	if err := g.MakeTurn(1, &stones[1]); err != nil {
+		t.Fatalf("Unexpected placement err: %v", err)
+	}
+	if err := <-waited; err != nil {
+		t.Errorf("Unexpected WaitTurn of White err: %v", err)
+	}
+
+	if myTurn, err := g.IsMyTurn(1); err != nil || myTurn {
+		t.Errorf("Unexpected IsMyTurn of Black after placement: %v, %v", myTurn, err)
+	}
+	state, err := g.GameState(2)
+	if err != nil {
+		t.Fatalf("Unexpected GameState err: %v", err)
+	}
+	// stones are ordered by x, then y
+	if len(state.ChipsOnBoard[igame.Black]) != 2 || *state.ChipsOnBoard[igame.Black][0] != stones[1] || *state.ChipsOnBoard[igame.Black][1] != stones[0] {
+		t.Errorf("Unexpected stones of Black:\nwant: %v,\ngot: %v.", stones, state.ChipsOnBoard[igame.Black])
+	}
+	if err := g.MakeTurn(2, &stones[1]); !errors.Is(err, game.ErrWrongTurn) {
+		t.Errorf("Unexpected MakeTurn to handicap stone err:\nwant: %v,\ngot: %v.", game.ErrWrongTurn, err)
+	}
+	if err := g.MakeTurn(2, &igame.TurnData{X: 5, Y: 5}); err != nil {
+		t.Errorf("Unexpected MakeTurn of White err: %v", err)
+	}
+}
+
+// newJoinedGame creates a game of terms on gomaster, where Joe with id 1
+// and Nick with id 2 are joined.
+func newJoinedGame(t *testing.T, inv *invitations, tr terms) managedGame {
+	t.Helper()
+	g, err := inv.newColouredGame(1, "Joe", tr)
+	if err != nil {
+		t.Fatalf("Unexpected newColouredGame err: %v", err)
+	}
+	if err := g.Join(&game.Gamer{Name: "Nick", ID: 2}); err != nil {
+		t.Fatalf("Unexpected Join err: %v", err)
+	}
+	return g
+}
+
+func TestHandicapChallenge(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	conn, release := startTestServer(t, DefaultSpectatorLimit)
+	defer release()
+	gameClient := api.NewGoGameClient(conn)
+	challengeClient := serverapi.NewChallengeClient(conn)
+
+	joe := clientContext("Joe", "aaa")
+	nick := clientContext("Nick", "bbb")
+	for _, ctx := range []context.Context{joe, nick} {
+		if _, err := gameClient.EnterTheLobby(ctx, &api.EmptyMessage{}); err != nil {
+			t.Fatalf("Unexpected EnterTheLobby err: %v", err)
+		}
+	}
+
+	invite, err := challengeClient.Challenge(joe, &serverapi.ChallengeRequest{Login: "Nick", Size: 9, Colour: serverapi.Colour_BLACK, Handicap: 3})
+	if err != nil {
+		t.Fatalf("Unexpected Challenge err: %v", err)
+	}
+	if invite.GetHandicap() != 3 || invite.GetFreePlacement() || invite.GetKomi() != handicap.Komi {
+		t.Errorf("Unexpected challenge: %v", invite)
+	}
+
+	var header metadata.MD
+	state, err := challengeClient.AcceptChallenge(nick, &serverapi.CodeMessage{Code: invite.GetCode()}, grpc.Header(&header))
+	if err != nil {
+		t.Fatalf("Unexpected AcceptChallenge err: %v", err)
+	}
+	if turn := header.Get(serverapi.TurnHeader); len(turn) != 1 || turn[0] != serverapi.Colour_WHITE.String() {
+		t.Errorf("Unexpected turn of begun handicap game:\nwant: %v,\ngot: %v.", serverapi.Colour_WHITE, turn)
+	}
+	if len(state.GetBlack().GetChipsOnBoard()) != 3 || state.GetKomi() != handicap.Komi {
+		t.Errorf("Unexpected state of begun handicap game: %v", state)
+	}
+
+	if _, err := gameClient.MakeTurn(joe, &api.TurnMessage{X: 4, Y: 4}); err == nil {
+		t.Errorf("Unexpected MakeTurn of black before white.")
+	}
+	if _, err := gameClient.MakeTurn(nick, &api.TurnMessage{X: 7, Y: 7}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Unexpected MakeTurn to handicap stone err:\nwant: %v,\ngot: %v.", ErrWrongTurn, err)
+	}
+	if _, err := gameClient.MakeTurn(nick, &api.TurnMessage{X: 4, Y: 4}); err != nil {
+		t.Fatalf("Unexpected MakeTurn err: %v", err)
+	}
+	if _, err := gameClient.MakeTurn(joe, &api.TurnMessage{X: 6, Y: 6}); err != nil {
+		t.Fatalf("Unexpected MakeTurn err: %v", err)
+	}
+}
+
+func TestHandicapSeek(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	conn, release := startTestServer(t, DefaultSpectatorLimit)
+	defer release()
+	gameClient := api.NewGoGameClient(conn)
+	matchmakingClient := serverapi.NewMatchmakingClient(conn)
+
+	joe := clientContext("Joe", "aaa")
+	nick := clientContext("Nick", "bbb")
+	var streams []serverapi.Matchmaking_SeekClient
+	for _, ctx := range []context.Context{joe, nick} {
+		if _, err := gameClient.EnterTheLobby(ctx, &api.EmptyMessage{}); err != nil {
+			t.Fatalf("Unexpected EnterTheLobby err: %v", err)
+		}
+		stream, err := matchmakingClient.Seek(ctx, &serverapi.SeekRequest{Size: 9, Handicap: 2})
+		if err != nil {
+			t.Fatalf("Unexpected Seek err: %v", err)
+		}
+		streams = append(streams, stream)
+	}
+
+	for _, stream := range streams {
+		var found *serverapi.SeekMessage
+		for !found.GetMatched() {
+			var err error
+			if found, err = stream.Recv(); err != nil {
+				t.Fatalf("Unexpected Recv err: %v", err)
+			}
+		}
+		if len(found.GetState().GetBlack().GetChipsOnBoard()) != 2 || found.GetState().GetKomi() != handicap.Komi {
+			t.Errorf("Unexpected state of handicap game: %v", found.GetState())
+		}
+	}
+}
+
+func containsStone(stones []*igame.TurnData, stone igame.TurnData) bool {
+	for _, s := range stones {
+		if *s == stone {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,220 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yagoggame/gomaster/game"
+	"github.com/yagoggame/gomaster/game/igame"
+	"github.com/yagoggame/grpc_server/interfaces"
+	"github.com/yagoggame/grpc_server/serverapi"
+)
+
+// move is an entry of the move history of a game.
+type move struct {
+	id     int
+	number int
+	colour serverapi.Colour
+	turn   igame.TurnData
+	undo   bool // the move with number is taken back
+	time   time.Time
+}
+
+// undoRequest is a request of gamer to take back his last move.
+type undoRequest struct {
+	from int
+	// answer receives the only answer of partner
+	answer chan bool
+}
+
+// gameHistory is the move history of a game with its request of takeback.
+type gameHistory struct {
+	game interfaces.GameManager
+	ids  []int
+	// moves can be taken back, history keeps taken back moves and takebacks too
+	moves   []move
+	history []move
+	pending *undoRequest
+	// done is closed, when a gamer leaves the game
+	done chan struct{}
+}
+
+// histories keeps move histories of played games and requests of takeback.
+// The game manager takes moves back, histories only record them
+// and wait for the consent of partner.
+type histories struct {
+	mutex sync.Mutex
+	games map[interfaces.GameManager]*gameHistory
+	byID  map[int]*gameHistory
+}
+
+func newHistories() *histories {
+	return &histories{
+		games: make(map[interfaces.GameManager]*gameHistory),
+		byID:  make(map[int]*gameHistory),
+	}
+}
+
+// moved records the move of gamer, it rejects pending request of takeback.
+func (hs *histories) moved(game interfaces.GameManager, id int, colour serverapi.Colour, turn *igame.TurnData) {
+	hs.mutex.Lock()
+	defer hs.mutex.Unlock()
+
+	gh := hs.historyOf(game, id)
+	m := move{id: id, number: len(gh.moves) + 1, colour: colour, turn: *turn, time: time.Now()}
+	gh.moves = append(gh.moves, m)
+	gh.history = append(gh.history, m)
+	if gh.pending != nil {
+		gh.pending.answer <- false
+		gh.pending = nil
+	}
+}
+
+// request registers request of gamer to take back his last move.
+// Partner is informed, when he leaves the game before the answer.
+func (hs *histories) request(game interfaces.GameManager, id, partner int) (*undoRequest, <-chan struct{}, error) {
+	hs.mutex.Lock()
+	defer hs.mutex.Unlock()
+
+	gh, ok := hs.games[game]
+	if !ok || len(gh.moves) == 0 || gh.moves[len(gh.moves)-1].id != id {
+		return nil, nil, extGrpcError(ErrNothingToUndo, fmt.Sprintf("gamer with id %d", id))
+	}
+	if gh.pending != nil {
+		return nil, nil, extGrpcError(ErrUndoPending, fmt.Sprintf("gamer with id %d", id))
+	}
+	hs.historyOf(game, partner)
+	gh.pending = &undoRequest{from: id, answer: make(chan bool, 1)}
+	return gh.pending, gh.done, nil
+}
+
+// await waits for the answer of partner to request.
+// Leaving of the game rejects the request, cancellation withdraws it.
+func (hs *histories) await(ctx context.Context, request *undoRequest, done <-chan struct{}) (bool, error) {
+	select {
+	case accepted := <-request.answer:
+		return accepted, nil
+	case <-done:
+		return false, nil
+	case <-ctx.Done():
+	}
+
+	hs.mutex.Lock()
+	for _, gh := range hs.games {
+		if gh.pending == request {
+			gh.pending = nil
+		}
+	}
+	hs.mutex.Unlock()
+	// partner could answer before the request is withdrawn
+	select {
+	case accepted := <-request.answer:
+		return accepted, nil
+	default:
+		return false, game.ErrCancellation
+	}
+}
+
+// requested reports whether partner of gamer asks to take back his last move.
+func (hs *histories) requested(game interfaces.GameManager, id int) bool {
+	hs.mutex.Lock()
+	defer hs.mutex.Unlock()
+
+	gh, ok := hs.games[game]
+	return ok && gh.pending != nil && gh.pending.from != id
+}
+
+// answer accepts or rejects request of partner of gamer and returns id of partner.
+// On acceptance undo takes back the last move of partner in the game.
+func (hs *histories) answer(game interfaces.GameManager, id int, accept bool, undo func(from int) error) (int, error) {
+	hs.mutex.Lock()
+	defer hs.mutex.Unlock()
+
+	gh, ok := hs.games[game]
+	if !ok || gh.pending == nil || gh.pending.from == id {
+		return 0, extGrpcError(ErrNoUndoRequest, fmt.Sprintf("gamer with id %d", id))
+	}
+	request := gh.pending
+	gh.pending = nil
+	if !accept {
+		request.answer <- false
+		return request.from, nil
+	}
+	if err := undo(request.from); err != nil {
+		request.answer <- false
+		return 0, err
+	}
+
+	last := gh.moves[len(gh.moves)-1]
+	gh.moves = gh.moves[:len(gh.moves)-1]
+	last.undo = true
+	last.time = time.Now()
+	gh.history = append(gh.history, last)
+	request.answer <- true
+	return request.from, nil
+}
+
+// moves returns moves of the game with taken back moves and takebacks.
+func (hs *histories) moves(game interfaces.GameManager) []move {
+	hs.mutex.Lock()
+	defer hs.mutex.Unlock()
+
+	gh, ok := hs.games[game]
+	if !ok {
+		return nil
+	}
+	history := make([]move, len(gh.history))
+	copy(history, gh.history)
+	return history
+}
+
+// left forgets the history of game of gamer, who leaves it,
+// pending request of takeback is rejected.
+func (hs *histories) left(id int) {
+	hs.mutex.Lock()
+	defer hs.mutex.Unlock()
+
+	gh, ok := hs.byID[id]
+	if !ok {
+		return
+	}
+	close(gh.done)
+	gh.pending = nil
+	delete(hs.games, gh.game)
+	for _, id := range gh.ids {
+		delete(hs.byID, id)
+	}
+}
+
+// historyOf returns the history of game, where gamer plays,
+// it must be called with locked mutex.
+func (hs *histories) historyOf(game interfaces.GameManager, id int) *gameHistory {
+	gh, ok := hs.games[game]
+	if !ok {
+		gh = &gameHistory{game: game, done: make(chan struct{})}
+		hs.games[game] = gh
+	}
+	if hs.byID[id] != gh {
+		gh.ids = append(gh.ids, id)
+		hs.byID[id] = gh
+	}
+	return gh
+}
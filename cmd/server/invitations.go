@@ -0,0 +1,471 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yagoggame/gomaster/game"
+	"github.com/yagoggame/gomaster/game/igame"
+	"github.com/yagoggame/grpc_server/interfaces"
+)
+
+// terms are conditions of a challenge.
+type terms struct {
+	size      int
+	komi      float64
+	colour    igame.ChipColour // colour of challenger, igame.NoColour means a random one
+	mainTime  time.Duration    // 0 means no time control
+	increment time.Duration
+	handicap  int  // number of handicap stones of Black, 0 means even game
+	free      bool // handicap stones are placed by Black, not on star points
+}
+
+// managedGame is a game, which is managed by invitations.
+type managedGame interface {
+	interfaces.GameManager
+	Join(gamer *game.Gamer) error
+	Leave(id int) error
+	End() error
+	GamerState(id int) (*game.GamerState, error)
+}
+
+// invitation is a challenge awaiting an answer.
+type invitation struct {
+	code      string
+	from      int
+	fromLogin string
+	to        int
+	toLogin   string
+	terms     terms
+	expires   time.Time
+	timer     *time.Timer
+	game      *privateGame
+}
+
+// privateGame is a game of challenge or matchmaking, which isn't managed by the pool,
+// so nobody else can join it, or a begun game of the pool adopted by invitations.
+type privateGame struct {
+	game managedGame
+	// pooled is the adopted game of the pool, nil for games of challenges and matchmaking
+	pooled    interfaces.GameManager
+	terms     terms
+	players   []int
+	remaining map[int]time.Duration
+	turnOf    int
+	turnStart time.Time
+	turns     int
+	timer     *time.Timer
+}
+
+// invitations keeps challenges, private games of accepted ones, of matchmaking
+// and games adopted from the pool.
+type invitations struct {
+	mutex  sync.Mutex
+	byCode map[string]*invitation
+	games  map[int]*privateGame
+	// closed is called without locked mutex, when a private game
+	// of gamer is ended not by him: on expiry or decline of challenge or on timeout.
+	closed func(id int)
+}
+
+func newInvitations(closed func(id int)) *invitations {
+	return &invitations{
+		byCode: make(map[string]*invitation),
+		games:  make(map[int]*privateGame),
+		closed: closed,
+	}
+}
+
+// create starts a private game of challenger and invites the other gamer to it.
+func (inv *invitations) create(from int, fromLogin string, to int, toLogin string, t terms, ttl time.Duration) (*invitation, error) {
+	code, err := newCode()
+	if err != nil {
+		return nil, err
+	}
+
+	inv.mutex.Lock()
+	defer inv.mutex.Unlock()
+
+	if _, ok := inv.games[from]; ok {
+		return nil, ErrBusy
+	}
+
+	g, err := inv.newColouredGame(from, fromLogin, t)
+	if err != nil {
+		return nil, err
+	}
+	if t.colour == igame.NoColour {
+		if state, err := g.GamerState(from); err == nil {
+			t.colour = state.Colour
+		}
+	}
+
+	pg := &privateGame{game: g, terms: t, players: []int{from}}
+	invite := &invitation{
+		code:      code,
+		from:      from,
+		fromLogin: fromLogin,
+		to:        to,
+		toLogin:   toLogin,
+		terms:     t,
+		expires:   time.Now().Add(ttl),
+		game:      pg,
+	}
+	invite.timer = time.AfterFunc(ttl, func() { inv.expire(invite) })
+	inv.byCode[code] = invite
+	inv.games[from] = pg
+
+	copied := *invite
+	return &copied, nil
+}
+
+// accept joins invited gamer to the private game of challenge with code.
+func (inv *invitations) accept(code string, id int, login string) (interfaces.GameManager, error) {
+	inv.mutex.Lock()
+	defer inv.mutex.Unlock()
+
+	invite, ok := inv.byCode[code]
+	if !ok || invite.to != id {
+		return nil, ErrNoSuchChallenge
+	}
+	if _, ok := inv.games[id]; ok {
+		return nil, ErrBusy
+	}
+
+	pg := invite.game
+	if err := pg.game.Join(&game.Gamer{Name: login, ID: id}); err != nil {
+		return nil, extGrpcError(ErrNoSuchChallenge, err.Error())
+	}
+	invite.timer.Stop()
+	delete(inv.byCode, code)
+	pg.players = append(pg.players, id)
+	inv.games[id] = pg
+	inv.startClock(pg)
+	return pg.game, nil
+}
+
+// pair starts a private game of two gamers, which are paired by matchmaking.
+func (inv *invitations) pair(first, second *game.Gamer, t terms) error {
+	inv.mutex.Lock()
+	defer inv.mutex.Unlock()
+
+	for _, id := range []int{first.ID, second.ID} {
+		if _, ok := inv.games[id]; ok {
+			return extGrpcError(ErrBusy, fmt.Sprintf("gamer with id %d", id))
+		}
+	}
+
+	g, err := inv.newColouredGame(first.ID, first.Name, t)
+	if err != nil {
+		return err
+	}
+	if err := g.Join(&game.Gamer{Name: second.Name, ID: second.ID}); err != nil {
+		_ = g.End()
+		return err
+	}
+
+	pg := &privateGame{game: g, terms: t, players: []int{first.ID, second.ID}}
+	inv.games[first.ID] = pg
+	inv.games[second.ID] = pg
+	inv.startClock(pg)
+	return nil
+}
+
+// adopt replaces begun game of the pool by a private game, where gamers keep
+// their colours, so its moves can be taken back. The first gamer gets colour of terms.
+// Both gamers get the same game.
+func (inv *invitations) adopt(pooled interfaces.GameManager, first, second *game.Gamer, t terms) (interfaces.GameManager, error) {
+	inv.mutex.Lock()
+	defer inv.mutex.Unlock()
+
+	for _, id := range []int{first.ID, second.ID} {
+		if pg, ok := inv.games[id]; ok {
+			if pg.pooled == pooled {
+				return pg.game, nil
+			}
+			return nil, extGrpcError(ErrBusy, fmt.Sprintf("gamer with id %d", id))
+		}
+	}
+
+	g, err := inv.newColouredGame(first.ID, first.Name, t)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.Join(&game.Gamer{Name: second.Name, ID: second.ID}); err != nil {
+		_ = g.End()
+		return nil, err
+	}
+
+	pg := &privateGame{game: g, pooled: pooled, terms: t, players: []int{first.ID, second.ID}}
+	inv.games[first.ID] = pg
+	inv.games[second.ID] = pg
+	return g, nil
+}
+
+// decline removes the challenge with code, if gamer with id is its
+// challenger or invited gamer, and ends its game.
+func (inv *invitations) decline(code string, id int) error {
+	inv.mutex.Lock()
+	invite, ok := inv.byCode[code]
+	if !ok || (invite.to != id && invite.from != id) {
+		inv.mutex.Unlock()
+		return ErrNoSuchChallenge
+	}
+	inv.cancel(invite)
+	inv.mutex.Unlock()
+
+	inv.closed(invite.from)
+	return nil
+}
+
+// list returns challenges of gamer and challenges to him ordered by expiration.
+func (inv *invitations) list(id int) []invitation {
+	inv.mutex.Lock()
+	defer inv.mutex.Unlock()
+
+	var invites []invitation
+	for _, invite := range inv.byCode {
+		if invite.from == id || invite.to == id {
+			invites = append(invites, *invite)
+		}
+	}
+	sort.Slice(invites, func(i, j int) bool { return invites[i].expires.Before(invites[j].expires) })
+	return invites
+}
+
+// gameOf returns private or adopted game of gamer.
+func (inv *invitations) gameOf(id int) (interfaces.GameManager, bool) {
+	inv.mutex.Lock()
+	defer inv.mutex.Unlock()
+
+	pg, ok := inv.games[id]
+	if !ok {
+		return nil, false
+	}
+	return pg.game, true
+}
+
+// leave releases private game of gamer, the challenge is cancelled,
+// if it isn't accepted yet. It returns false, if gamer has no private game
+// or his game is adopted from the pool, which must release it too.
+func (inv *invitations) leave(id int) bool {
+	inv.mutex.Lock()
+	defer inv.mutex.Unlock()
+
+	pg, ok := inv.games[id]
+	if !ok {
+		return false
+	}
+	for _, invite := range inv.byCode {
+		if invite.game == pg {
+			inv.cancel(invite)
+			return true
+		}
+	}
+
+	_ = pg.game.Leave(id)
+	delete(inv.games, id)
+	inv.stopClock(pg)
+	return pg.pooled == nil
+}
+
+// moved switches the clock of private game of gamer to his partner.
+func (inv *invitations) moved(id int) {
+	inv.mutex.Lock()
+	defer inv.mutex.Unlock()
+
+	pg, ok := inv.games[id]
+	if !ok || pg.timer == nil || pg.turnOf != id {
+		return
+	}
+	pg.timer.Stop()
+	pg.remaining[id] += pg.terms.increment - time.Since(pg.turnStart)
+	inv.startTurn(pg, partnerOf(pg, id))
+}
+
+// undone switches the clock of private game back to gamer, whose move is taken back.
+func (inv *invitations) undone(id int) {
+	inv.mutex.Lock()
+	defer inv.mutex.Unlock()
+
+	pg, ok := inv.games[id]
+	if !ok || pg.timer == nil || pg.turnOf == id {
+		return
+	}
+	pg.timer.Stop()
+	pg.remaining[pg.turnOf] -= time.Since(pg.turnStart)
+	inv.startTurn(pg, id)
+}
+
+// clocks returns remaining time of gamer and of his partner.
+// It returns false, if gamer's game has no time control.
+func (inv *invitations) clocks(id int) (mine, partner time.Duration, ok bool) {
+	inv.mutex.Lock()
+	defer inv.mutex.Unlock()
+
+	pg, found := inv.games[id]
+	if !found || pg.remaining == nil {
+		return 0, 0, false
+	}
+	return pg.remainingOf(id), pg.remainingOf(partnerOf(pg, id)), true
+}
+
+// clear cancels all challenges and stops clocks of private games.
+func (inv *invitations) clear() {
+	inv.mutex.Lock()
+	defer inv.mutex.Unlock()
+
+	for _, invite := range inv.byCode {
+		inv.cancel(invite)
+	}
+	for _, pg := range inv.games {
+		inv.stopClock(pg)
+	}
+}
+
+// expire cancels challenge, which isn't answered in time.
+func (inv *invitations) expire(invite *invitation) {
+	inv.mutex.Lock()
+	if inv.byCode[invite.code] != invite {
+		inv.mutex.Unlock()
+		return
+	}
+	inv.cancel(invite)
+	inv.mutex.Unlock()
+
+	inv.closed(invite.from)
+}
+
+// timeout ends the game of gamer, whose time is over, by his leaving.
+func (inv *invitations) timeout(pg *privateGame, turns int) {
+	inv.mutex.Lock()
+	if pg.timer == nil || pg.turns != turns {
+		inv.mutex.Unlock()
+		return
+	}
+	loser := pg.turnOf
+	pg.remaining[loser] = 0
+	_ = pg.game.Leave(loser)
+	delete(inv.games, loser)
+	inv.stopClock(pg)
+	inv.mutex.Unlock()
+
+	inv.closed(loser)
+}
+
+// cancel must be called with locked mutex.
+func (inv *invitations) cancel(invite *invitation) {
+	invite.timer.Stop()
+	delete(inv.byCode, invite.code)
+	delete(inv.games, invite.from)
+	_ = invite.game.game.End()
+}
+
+// startClock must be called with locked mutex.
+func (inv *invitations) startClock(pg *privateGame) {
+	if pg.terms.mainTime <= 0 {
+		return
+	}
+	pg.remaining = make(map[int]time.Duration)
+	for _, id := range pg.players {
+		pg.remaining[id] = pg.terms.mainTime
+	}
+	first := pg.players[0]
+	if myTurn, err := pg.game.IsMyTurn(first); err == nil && !myTurn {
+		first = pg.players[1]
+	}
+	inv.startTurn(pg, first)
+}
+
+// startTurn must be called with locked mutex.
+func (inv *invitations) startTurn(pg *privateGame, id int) {
+	pg.turns++
+	turns := pg.turns
+	pg.turnOf = id
+	pg.turnStart = time.Now()
+	pg.timer = time.AfterFunc(pg.remaining[id], func() { inv.timeout(pg, turns) })
+}
+
+// stopClock must be called with locked mutex.
+func (inv *invitations) stopClock(pg *privateGame) {
+	if pg.timer == nil {
+		return
+	}
+	pg.timer.Stop()
+	pg.timer = nil
+	pg.remaining[pg.turnOf] -= time.Since(pg.turnStart)
+}
+
+// remainingOf must be called with locked mutex.
+func (pg *privateGame) remainingOf(id int) time.Duration {
+	remaining := pg.remaining[id]
+	if pg.timer != nil && pg.turnOf == id {
+		remaining -= time.Since(pg.turnStart)
+	}
+	return remaining
+}
+
+func partnerOf(pg *privateGame, id int) int {
+	for _, player := range pg.players {
+		if player != id {
+			return player
+		}
+	}
+	return id
+}
+
+// newColouredGame creates a game of terms with joined challenger, who gets colour of terms.
+// The colour isn't chosen, if terms have no colour.
+func (inv *invitations) newColouredGame(id int, login string, t terms) (managedGame, error) {
+	newEngine := func() (managedGame, error) {
+		g, err := game.NewGame(t.size, t.komi)
+		if err != nil {
+			return nil, extGrpcError(ErrWrongChallenge, err.Error())
+		}
+		return g, nil
+	}
+	return newServerGame(newEngine, &game.Gamer{Name: login, ID: id}, t)
+}
+
+// newCode returns random code of invitation.
+func newCode() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// invitationsGeter returns private games of gamers
+// and games of the pool for other gamers.
+type invitationsGeter struct {
+	invitations *invitations
+	next        interfaces.GameGeter
+}
+
+func (geter *invitationsGeter) GetGame(id int) (interfaces.GameManager, error) {
+	if g, ok := geter.invitations.gameOf(id); ok {
+		return g, nil
+	}
+	return geter.next.GetGame(id)
+}
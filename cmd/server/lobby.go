@@ -0,0 +1,103 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"log"
+	"sort"
+
+	"github.com/yagoggame/api"
+	"github.com/yagoggame/gomaster/game"
+	"github.com/yagoggame/grpc_server/serverapi"
+	"google.golang.org/grpc/status"
+)
+
+// ListLobby returns gamers in the lobby with their activities.
+func (s *Server) ListLobby(ctx context.Context, in *api.EmptyMessage) (*serverapi.LobbyMessage, error) {
+	if _, err := idFromCtx(ctx); err != nil {
+		log.Printf("ListLobby error: %s", err)
+		return &serverapi.LobbyMessage{}, err
+	}
+	return &serverapi.LobbyMessage{Gamers: s.lobbyGamers()}, nil
+}
+
+// WatchLobby sends gamers in the lobby and then sends them again after each change.
+func (s *Server) WatchLobby(in *api.EmptyMessage, stream serverapi.Lobby_WatchLobbyServer) error {
+	ctx := stream.Context()
+	id, err := idFromCtx(ctx)
+	if err != nil {
+		log.Printf("WatchLobby error: %s", err)
+		return err
+	}
+
+	changes, cancel := s.presence.watch()
+	defer cancel()
+	log.Printf("user with id %d watches the lobby", id)
+
+	for {
+		if err := stream.Send(&serverapi.LobbyMessage{Gamers: s.lobbyGamers()}); err != nil {
+			return err
+		}
+
+		select {
+		case <-changes:
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+}
+
+// lobbyGamers returns gamers of the pool ordered by id.
+func (s *Server) lobbyGamers() []*serverapi.LobbyGamer {
+	gamers := s.pool.ListGamers()
+	sort.Slice(gamers, func(i, j int) bool { return gamers[i].ID < gamers[j].ID })
+
+	lobby := make([]*serverapi.LobbyGamer, len(gamers))
+	for i, gamer := range gamers {
+		lobby[i] = &serverapi.LobbyGamer{
+			Id:     int64(gamer.ID),
+			Login:  gamer.Name,
+			Status: s.lobbyStatus(gamer),
+		}
+	}
+	return lobby
+}
+
+// lobbyStatus returns activity of the gamer.
+// Gamer, whose game is over, is idle.
+func (s *Server) lobbyStatus(gamer *game.Gamer) serverapi.LobbyStatus {
+	if s.matchmaker.queue.Contains(gamer.ID) {
+		return serverapi.LobbyStatus_SEEKING
+	}
+	gameManager, err := s.gameGeter.GetGame(gamer.ID)
+	if err != nil || gameManager == nil {
+		if s.presence.isSpectating(gamer.ID) {
+			return serverapi.LobbyStatus_SPECTATING
+		}
+		return serverapi.LobbyStatus_IDLE
+	}
+
+	begun, err := gameManager.IsGameBegun(gamer.ID)
+	switch {
+	case err != nil:
+		return serverapi.LobbyStatus_IDLE
+	case !begun:
+		return serverapi.LobbyStatus_SEEKING
+	}
+	return serverapi.LobbyStatus_IN_GAME
+}
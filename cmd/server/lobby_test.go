@@ -0,0 +1,147 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/yagoggame/api"
+	"github.com/yagoggame/grpc_server/serverapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLobbyPresence(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	conn, release := startTestServer(t, DefaultSpectatorLimit)
+	defer release()
+	gameClient := api.NewGoGameClient(conn)
+	lobbyClient := serverapi.NewLobbyClient(conn)
+	spectatorClient := serverapi.NewSpectatorClient(conn)
+
+	joe := clientContext("Joe", "aaa")
+	nick := clientContext("Nick", "bbb")
+	piter := clientContext("Piter", "ppp")
+	if _, err := gameClient.RegisterUser(piter, &api.EmptyMessage{}); err != nil {
+		t.Fatalf("Unexpected RegisterUser err: %v", err)
+	}
+	for _, ctx := range []context.Context{joe, nick} {
+		if _, err := gameClient.EnterTheLobby(ctx, &api.EmptyMessage{}); err != nil {
+			t.Fatalf("Unexpected EnterTheLobby err: %v", err)
+		}
+	}
+
+	lobby, err := lobbyClient.ListLobby(piter, &api.EmptyMessage{})
+	if err != nil {
+		t.Fatalf("Unexpected ListLobby err: %v", err)
+	}
+	want := map[string]serverapi.LobbyStatus{"Joe": serverapi.LobbyStatus_IDLE, "Nick": serverapi.LobbyStatus_IDLE}
+	if got := lobbyStatuses(lobby); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected ListLobby result:\nwant: %v,\ngot: %v.", want, got)
+	}
+
+	ctx, cancel := context.WithCancel(piter)
+	defer cancel()
+	stream, err := lobbyClient.WatchLobby(ctx, &api.EmptyMessage{})
+	if err != nil {
+		t.Fatalf("Unexpected WatchLobby err: %v", err)
+	}
+	lobbies := make(chan *serverapi.LobbyMessage, eventsBuffer)
+	go func() {
+		defer close(lobbies)
+		for {
+			lobby, err := stream.Recv()
+			if err != nil {
+				if status.Code(err) != codes.Canceled {
+					t.Errorf("Unexpected WatchLobby stream err: %v", err)
+				}
+				return
+			}
+			lobbies <- lobby
+		}
+	}()
+	awaitLobby(t, lobbies, want)
+
+	joined := make(chan error, 1)
+	go func() {
+		_, err := gameClient.JoinTheGame(joe, &api.EmptyMessage{})
+		joined <- err
+	}()
+	awaitLobby(t, lobbies, map[string]serverapi.LobbyStatus{"Joe": serverapi.LobbyStatus_SEEKING, "Nick": serverapi.LobbyStatus_IDLE})
+
+	if _, err := gameClient.JoinTheGame(nick, &api.EmptyMessage{}); err != nil {
+		t.Fatalf("Unexpected JoinTheGame err: %v", err)
+	}
+	if err := <-joined; err != nil {
+		t.Fatalf("Unexpected JoinTheGame err: %v", err)
+	}
+	awaitLobby(t, lobbies, map[string]serverapi.LobbyStatus{"Joe": serverapi.LobbyStatus_IN_GAME, "Nick": serverapi.LobbyStatus_IN_GAME})
+
+	if _, err := gameClient.EnterTheLobby(piter, &api.EmptyMessage{}); err != nil {
+		t.Fatalf("Unexpected EnterTheLobby err: %v", err)
+	}
+	games, err := spectatorClient.ListGames(piter, &api.EmptyMessage{})
+	if err != nil || len(games.GetGames()) != 1 {
+		t.Fatalf("Unexpected ListGames result: %v, %v", games, err)
+	}
+	watchCtx, stopWatching := context.WithCancel(piter)
+	defer stopWatching()
+	if _, err := spectatorClient.WatchGame(watchCtx, &serverapi.GameIDMessage{Id: games.GetGames()[0].GetId()}); err != nil {
+		t.Fatalf("Unexpected WatchGame err: %v", err)
+	}
+	awaitLobby(t, lobbies, map[string]serverapi.LobbyStatus{
+		"Joe": serverapi.LobbyStatus_IN_GAME, "Nick": serverapi.LobbyStatus_IN_GAME, "Piter": serverapi.LobbyStatus_SPECTATING})
+
+	if _, err := gameClient.LeaveTheGame(joe, &api.EmptyMessage{}); err != nil {
+		t.Fatalf("Unexpected LeaveTheGame err: %v", err)
+	}
+	awaitLobby(t, lobbies, map[string]serverapi.LobbyStatus{
+		"Joe": serverapi.LobbyStatus_IDLE, "Nick": serverapi.LobbyStatus_IDLE, "Piter": serverapi.LobbyStatus_SPECTATING})
+
+	cancel()
+	for range lobbies {
+	}
+}
+
+func lobbyStatuses(lobby *serverapi.LobbyMessage) map[string]serverapi.LobbyStatus {
+	statuses := make(map[string]serverapi.LobbyStatus)
+	for _, gamer := range lobby.GetGamers() {
+		statuses[gamer.GetLogin()] = gamer.GetStatus()
+	}
+	return statuses
+}
+
+// awaitLobby skips lobby messages until statuses of gamers are equal to want.
+func awaitLobby(t *testing.T, lobbies <-chan *serverapi.LobbyMessage, want map[string]serverapi.LobbyStatus) {
+	timeout := time.After(time.Second)
+	var got map[string]serverapi.LobbyStatus
+	for {
+		select {
+		case lobby := <-lobbies:
+			if got = lobbyStatuses(lobby); reflect.DeepEqual(got, want) {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("Unexpected lobby:\nwant: %v,\ngot: %v.", want, got)
+		}
+	}
+}
@@ -0,0 +1,437 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/yagoggame/gomaster/game"
+	"github.com/yagoggame/gomaster/game/field"
+	"github.com/yagoggame/gomaster/game/igame"
+	"github.com/yagoggame/grpc_server/matchmaking"
+	"github.com/yagoggame/grpc_server/rating"
+	"github.com/yagoggame/grpc_server/serverapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Defaults of matchmaking.
+const (
+	// DefaultRating is a rating of gamers, whose rating is unknown
+	DefaultRating = rating.DefaultRating
+	// DefaultMatchWindow is an initial maximal difference of ratings of partners
+	DefaultMatchWindow = 100.0
+	// DefaultMatchWidening is a number of points per second the window widens by
+	DefaultMatchWidening = 5.0
+	// DefaultMatchMaxWindow limits widening of the window
+	DefaultMatchMaxWindow = 500.0
+)
+
+// matchInterval is an interval of pairing and of reports to seekers.
+const matchInterval = time.Second
+
+var (
+	// ErrWrongSeek occurs when kind of sought game is wrong
+	ErrWrongSeek = status.Errorf(codes.InvalidArgument, "wrong seek request")
+	// ErrSeeking occurs when gamer already seeks a game
+	ErrSeeking = status.Errorf(codes.FailedPrecondition, "gamer already seeks a game")
+)
+
+// match is a result of pairing of a seeker.
+type match struct {
+	partner string
+	err     error
+}
+
+// matchmaker keeps the queue of seekers, pairs them in a single loop
+// and delivers results of pairing to them.
+type matchmaker struct {
+	mutex   sync.Mutex
+	queue   *matchmaking.Queue
+	found   map[int]chan match
+	start   func(pair matchmaking.Pair) error
+	running bool
+	wake    chan struct{}
+}
+
+// newMatchmaker creates matchmaker, which starts games of pairs by start.
+func newMatchmaker(options matchmaking.Options, start func(pair matchmaking.Pair) error) *matchmaker {
+	return &matchmaker{
+		queue: matchmaking.New(options),
+		found: make(map[int]chan match),
+		start: start,
+		wake:  make(chan struct{}, 1),
+	}
+}
+
+// add places seeker to the queue and returns channel of his match.
+// The loop of pairing is started, if it doesn't run, and woken up.
+func (mm *matchmaker) add(seeker matchmaking.Seeker) (<-chan match, error) {
+	mm.mutex.Lock()
+	defer mm.mutex.Unlock()
+
+	if err := mm.queue.Add(seeker); err != nil {
+		return nil, err
+	}
+	found := make(chan match, 1)
+	mm.found[seeker.ID] = found
+
+	if !mm.running {
+		mm.running = true
+		go mm.run()
+	}
+	select {
+	case mm.wake <- struct{}{}:
+	default:
+	}
+	return found, nil
+}
+
+// remove removes seeker from the queue.
+// It returns false, if seeker is already paired.
+func (mm *matchmaker) remove(id int) bool {
+	mm.mutex.Lock()
+	defer mm.mutex.Unlock()
+
+	delete(mm.found, id)
+	return mm.queue.Remove(id)
+}
+
+// reject removes seeker from the queue and fails his seeking with err.
+func (mm *matchmaker) reject(id int, err error) {
+	mm.mutex.Lock()
+	defer mm.mutex.Unlock()
+
+	if !mm.queue.Remove(id) {
+		return
+	}
+	if found, ok := mm.found[id]; ok {
+		found <- match{err: err}
+		delete(mm.found, id)
+	}
+}
+
+// run pairs seekers on arrival of a new one and every matchInterval,
+// while the queue isn't empty.
+func (mm *matchmaker) run() {
+	ticker := time.NewTicker(matchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-mm.wake:
+		case <-ticker.C:
+		}
+		if !mm.pair() {
+			return
+		}
+	}
+}
+
+// pair pairs seekers of the queue and starts their games.
+// It returns false, when the queue is empty and the loop of pairing stops.
+func (mm *matchmaker) pair() bool {
+	mm.mutex.Lock()
+	defer mm.mutex.Unlock()
+
+	for _, pair := range mm.queue.Match() {
+		err := mm.start(pair)
+		for i, seeker := range pair {
+			found, ok := mm.found[seeker.ID]
+			if !ok {
+				continue
+			}
+			found <- match{partner: pair[1-i].Login, err: err}
+			delete(mm.found, seeker.ID)
+		}
+	}
+	if len(mm.found) > 0 {
+		return true
+	}
+	mm.running = false
+	return false
+}
+
+// SetMatchmaking replaces the queue of matchmaking by the queue with options.
+// It must be called before serving.
+func (s *Server) SetMatchmaking(options matchmaking.Options) {
+	s.matchmaker = newMatchmaker(options, s.startMatch)
+}
+
+// Seek queues the gamer for a game of requested size and time control.
+// Until pairing by the loop of matchmaker, his position in the queue of such games,
+// the estimated wait and the current window of ratings are sent every matchInterval.
+// Paired gamers play a private game, which is begun when it is sent.
+// Seeking is cancelled by cancellation of the call.
+// Conflicting preferences of colour are resolved by nigiri.
+func (s *Server) Seek(in *serverapi.SeekRequest, stream serverapi.Matchmaking_SeekServer) error {
+	ctx := stream.Context()
+	gamer, err := userFromContext(ctx)
+	if err != nil {
+		log.Printf("Seek error: %s", err)
+		return err
+	}
+
+	request, colour, err := seekRequest(in)
+	if err == nil {
+		err = s.checkFree(gamer.ID)
+	}
+	if err != nil {
+		log.Printf("Seek error: %s", err)
+		return err
+	}
+
+	rating := s.rating(gamer.ID)
+	found, err := s.addSeeker(matchmaking.Seeker{
+		ID:      gamer.ID,
+		Login:   gamer.Name,
+		Rating:  rating,
+		Request: request,
+		Colour:  colour,
+	})
+	if err != nil {
+		log.Printf("Seek error: %s", err)
+		return err
+	}
+	s.presence.changed()
+	defer s.presence.changed()
+	log.Printf("gamer with id %d and rating %g seeks a game", gamer.ID, rating)
+
+	ticker := time.NewTicker(matchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case m := <-found:
+			return s.seekFound(ctx, stream, gamer.ID, m)
+		default:
+		}
+
+		if place, ok := s.matchmaker.queue.Status(gamer.ID); ok {
+			err := stream.Send(&serverapi.SeekMessage{
+				Position:      int64(place.Position),
+				EstimatedWait: int64(place.EstimatedWait / time.Millisecond),
+				Rating:        rating,
+				Window:        place.Window,
+			})
+			if err != nil {
+				s.stopSeeking(ctx, gamer.ID)
+				return err
+			}
+		}
+
+		select {
+		case m := <-found:
+			return s.seekFound(ctx, stream, gamer.ID, m)
+		case <-ticker.C:
+		case <-ctx.Done():
+			s.stopSeeking(ctx, gamer.ID)
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+}
+
+// seekGame queues free seeker and waits for his pairing and the begin of his game.
+// Waiting is cancelled by cancellation of ctx.
+func (s *Server) seekGame(ctx context.Context, seeker matchmaking.Seeker) error {
+	if err := s.checkFree(seeker.ID); err != nil {
+		return err
+	}
+	found, err := s.addSeeker(seeker)
+	if err != nil {
+		return err
+	}
+	s.presence.changed()
+	defer s.presence.changed()
+	log.Printf("gamer with id %d and rating %g seeks a game", seeker.ID, seeker.Rating)
+
+	select {
+	case m := <-found:
+		if m.err != nil {
+			return m.err
+		}
+	case <-ctx.Done():
+		s.stopSeeking(ctx, seeker.ID)
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	_, err = s.waitGame(ctx, seeker.ID)
+	return err
+}
+
+// addSeeker places seeker to the queue of matchmaker.
+func (s *Server) addSeeker(seeker matchmaking.Seeker) (<-chan match, error) {
+	found, err := s.matchmaker.add(seeker)
+	if err != nil {
+		if errors.Is(err, matchmaking.ErrQueued) {
+			err = ErrSeeking
+		}
+		return nil, extGrpcError(err, fmt.Sprintf("gamer with id %d", seeker.ID))
+	}
+	return found, nil
+}
+
+// seekFound sends the partner and the state of the game to paired gamer.
+func (s *Server) seekFound(ctx context.Context, stream serverapi.Matchmaking_SeekServer, id int, m match) error {
+	if m.err != nil {
+		log.Printf("Seek error: %s", m.err)
+		return m.err
+	}
+
+	state, err := s.waitGame(ctx, id)
+	if err != nil {
+		log.Printf("Seek error: %s", err)
+		return err
+	}
+
+	gameManager, err := s.gameGeter.GetGame(id)
+	if err != nil {
+		log.Printf("Seek error: %s", err)
+		return err
+	}
+
+	log.Printf("gamer with id %d paired with %q, game has been begun", id, m.partner)
+	return stream.Send(&serverapi.SeekMessage{Matched: true, Partner: m.partner, State: state, Colour: colourOf(gameManager, id)})
+}
+
+// stopSeeking removes gamer from the queue. If gamer is already paired,
+// his seat is held for grace period or his game is released.
+func (s *Server) stopSeeking(ctx context.Context, id int) {
+	if s.matchmaker.remove(id) {
+		log.Printf("gamer with id %d stopped seeking", id)
+		return
+	}
+
+	gameManager, err := s.gameGeter.GetGame(id)
+	if err != nil || gameManager == nil {
+		return
+	}
+	if login, err := loginFromContext(ctx); err == nil {
+		s.seats.take(id, login, gameManager)
+	}
+	if s.disconnect(ctx, id) {
+		return
+	}
+	s.seats.leave(id)
+	if err := s.releaseGame(id); err != nil {
+		log.Printf("failed to release game of gamer with id %d after seeking: %s", id, err)
+	}
+}
+
+// startMatch starts private game of paired seekers.
+func (s *Server) startMatch(pair matchmaking.Pair) error {
+	t := terms{
+		size:      pair[0].Request.Size,
+		komi:      standartKomi,
+		mainTime:  pair[0].Request.MainTime,
+		increment: pair[0].Request.Increment,
+		colour:    chipColour(pair.Colours(nigiri)[0]),
+	}
+	if request := pair[0].Request; request.Handicap != 0 {
+		if err := handicapTerms(&t, request.Handicap, request.Free); err != nil {
+			return extGrpcError(ErrJoinGame, err.Error())
+		}
+	}
+	err := s.invitations.pair(
+		&game.Gamer{ID: pair[0].ID, Name: pair[0].Login},
+		&game.Gamer{ID: pair[1].ID, Name: pair[1].Login}, t)
+	if err != nil {
+		return extGrpcError(ErrJoinGame, err.Error())
+	}
+	// both gamers take their seats at once, so each of them knows his partner
+	if gameManager, ok := s.invitations.gameOf(pair[0].ID); ok {
+		for _, seeker := range pair {
+			s.seats.take(seeker.ID, seeker.Login, gameManager)
+		}
+	}
+	log.Printf("gamers with id %d and %d paired by matchmaking", pair[0].ID, pair[1].ID)
+	return nil
+}
+
+// rating returns rating of gamer by Rater of server or DefaultRating.
+func (s *Server) rating(id int) float64 {
+	if s.rater == nil {
+		return DefaultRating
+	}
+	rating, err := s.rater.Rating(id)
+	if err != nil {
+		log.Printf("failed to get rating of gamer with id %d: %s", id, err)
+		return DefaultRating
+	}
+	return rating
+}
+
+// nigiri chooses colour randomly.
+func nigiri() matchmaking.Colour {
+	if rand.Intn(2) == 0 {
+		return matchmaking.Black
+	}
+	return matchmaking.White
+}
+
+// chipColour converts colour of matchmaking to the colour of stones.
+func chipColour(colour matchmaking.Colour) igame.ChipColour {
+	switch colour {
+	case matchmaking.Black:
+		return igame.Black
+	case matchmaking.White:
+		return igame.White
+	}
+	return igame.NoColour
+}
+
+func seekRequest(in *serverapi.SeekRequest) (matchmaking.Request, matchmaking.Colour, error) {
+	request := matchmaking.Request{
+		Size:      int(in.GetSize()),
+		MainTime:  time.Duration(in.GetMainTime()) * time.Second,
+		Increment: time.Duration(in.GetIncrement()) * time.Second,
+	}
+	if request.Size == 0 {
+		request.Size = standartSize
+	}
+	if request.MainTime < 0 || request.Increment < 0 {
+		return matchmaking.Request{}, matchmaking.Random, extGrpcError(ErrWrongSeek, "time control must not be negative")
+	}
+
+	var colour matchmaking.Colour
+	switch in.GetColour() {
+	case serverapi.Colour_RANDOM:
+		colour = matchmaking.Random
+	case serverapi.Colour_BLACK:
+		colour = matchmaking.Black
+	case serverapi.Colour_WHITE:
+		colour = matchmaking.White
+	default:
+		return matchmaking.Request{}, matchmaking.Random, extGrpcError(ErrWrongSeek, fmt.Sprintf("unknown colour %v", in.GetColour()))
+	}
+
+	// size is checked by gomaster on creation of a field.
+	if _, err := field.New(request.Size, standartKomi); err != nil {
+		return matchmaking.Request{}, matchmaking.Random, extGrpcError(ErrWrongSeek, err.Error())
+	}
+	if in.GetHandicap() != 0 {
+		t := terms{size: request.Size}
+		if err := handicapTerms(&t, int(in.GetHandicap()), in.GetFreePlacement()); err != nil {
+			return matchmaking.Request{}, matchmaking.Random, extGrpcError(ErrWrongSeek, err.Error())
+		}
+		request.Handicap, request.Free = t.handicap, t.free
+	}
+	return request, colour, nil
+}
@@ -0,0 +1,251 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yagoggame/api"
+	"github.com/yagoggame/grpc_server/matchmaking"
+	"github.com/yagoggame/grpc_server/serverapi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var seekErrTests = []struct {
+	caseName string
+	request  *serverapi.SeekRequest
+	want     codes.Code
+}{
+	{caseName: "wrong size", request: &serverapi.SeekRequest{Size: 100}, want: codes.InvalidArgument},
+	{caseName: "negative time", request: &serverapi.SeekRequest{MainTime: -1}, want: codes.InvalidArgument},
+	{caseName: "negative increment", request: &serverapi.SeekRequest{Increment: -1}, want: codes.InvalidArgument},
+	{caseName: "wrong colour", request: &serverapi.SeekRequest{Colour: 3}, want: codes.InvalidArgument},
+	{caseName: "no star points", request: &serverapi.SeekRequest{Size: 11, Handicap: 3}, want: codes.InvalidArgument},
+	{caseName: "too many stones", request: &serverapi.SeekRequest{Handicap: 10, FreePlacement: true}, want: codes.InvalidArgument},
+}
+
+func TestMatchmaking(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	conn, release := startTestServer(t, DefaultSpectatorLimit)
+	defer release()
+	gameClient := api.NewGoGameClient(conn)
+	sessionClient := serverapi.NewSessionClient(conn)
+	challengeClient := serverapi.NewChallengeClient(conn)
+	matchmakingClient := serverapi.NewMatchmakingClient(conn)
+
+	joe := clientContext("Joe", "aaa")
+	nick := clientContext("Nick", "bbb")
+	if _, err := seek(matchmakingClient, joe, &serverapi.SeekRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Unexpected Seek out of lobby err:\nwant: %v,\ngot: %v.", ErrNotInLobby, err)
+	}
+	for _, ctx := range []context.Context{joe, nick} {
+		if _, err := gameClient.EnterTheLobby(ctx, &api.EmptyMessage{}); err != nil {
+			t.Fatalf("Unexpected EnterTheLobby err: %v", err)
+		}
+	}
+
+	for _, test := range seekErrTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			if _, err := seek(matchmakingClient, joe, test.request); status.Code(err) != test.want {
+				t.Errorf("Unexpected Seek err:\nwant: %v,\ngot: %v.", test.want, err)
+			}
+		})
+	}
+
+	request := &serverapi.SeekRequest{Size: 7, MainTime: 60}
+	joeSeek, err := matchmakingClient.Seek(joe, &serverapi.SeekRequest{Size: 7, MainTime: 60, Colour: serverapi.Colour_WHITE})
+	if err != nil {
+		t.Fatalf("Unexpected Seek err: %v", err)
+	}
+	place, err := joeSeek.Recv()
+	if err != nil {
+		t.Fatalf("Unexpected Recv err: %v", err)
+	}
+	if place.GetMatched() || place.GetPosition() != 1 || place.GetRating() != DefaultRating ||
+		place.GetWindow() < DefaultMatchWindow {
+		t.Errorf("Unexpected place in the queue: %v", place)
+	}
+	if _, err := seek(matchmakingClient, joe, request); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Unexpected repeated Seek err:\nwant: %v,\ngot: %v.", ErrSeeking, err)
+	}
+	if _, err := challengeClient.Challenge(joe, &serverapi.ChallengeRequest{Login: "Nick"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Unexpected Challenge of seeking gamer err:\nwant: %v,\ngot: %v.", ErrSeeking, err)
+	}
+	if lobby, err := serverapi.NewLobbyClient(conn).ListLobby(nick, &api.EmptyMessage{}); err != nil ||
+		lobbyStatuses(lobby)["Joe"] != serverapi.LobbyStatus_SEEKING {
+		t.Errorf("Unexpected lobby of seeking gamer: %v, %v", lobby, err)
+	}
+
+	nickSeek, err := matchmakingClient.Seek(nick, request)
+	if err != nil {
+		t.Fatalf("Unexpected Seek err: %v", err)
+	}
+	for _, test := range []struct {
+		stream  serverapi.Matchmaking_SeekClient
+		partner string
+		colour  serverapi.Colour
+	}{{joeSeek, "Nick", serverapi.Colour_WHITE}, {nickSeek, "Joe", serverapi.Colour_BLACK}} {
+		var found *serverapi.SeekMessage
+		for !found.GetMatched() {
+			var err error
+			if found, err = test.stream.Recv(); err != nil {
+				t.Fatalf("Unexpected Recv err: %v", err)
+			}
+		}
+		if found.GetPartner() != test.partner || found.GetPartner() != test.partner || found.GetState().GetSize() != 7 ||
+			found.GetColour() != test.colour {
+			t.Errorf("Unexpected match:\nwant: partner %q, size %d and colour %v,\ngot: %v.",
+				test.partner, 7, test.colour, found)
+		}
+	}
+
+	gameStatus, err := sessionClient.GetGameState(nick, &api.EmptyMessage{})
+	if err != nil {
+		t.Fatalf("Unexpected GetGameState err: %v", err)
+	}
+	if gameStatus.GetStatus() != serverapi.GameStatus_PLAYING || gameStatus.GetTimeLeft() <= 0 || gameStatus.GetTimeLeft() > 60000 ||
+		gameStatus.GetColour() != serverapi.Colour_BLACK || gameStatus.GetPartner() != "Joe" {
+		t.Errorf("Unexpected game status of paired gamer: %v", gameStatus)
+	}
+
+	var header metadata.MD
+	if _, err := gameClient.WaitTheTurn(nick, &api.EmptyMessage{}, grpc.Header(&header)); err != nil {
+		t.Fatalf("Unexpected WaitTheTurn err: %v", err)
+	}
+	for key, want := range map[string]string{serverapi.ColourHeader: "BLACK", serverapi.TurnHeader: "BLACK", serverapi.PartnerHeader: "Joe"} {
+		if got := header.Get(key); len(got) != 1 || got[0] != want {
+			t.Errorf("Unexpected %s header:\nwant: %q,\ngot: %q.", key, want, got)
+		}
+	}
+	for _, ctx := range []context.Context{joe, nick} {
+		if _, err := gameClient.LeaveTheGame(ctx, &api.EmptyMessage{}); err != nil {
+			t.Fatalf("Unexpected LeaveTheGame err: %v", err)
+		}
+	}
+
+	// leaving of the lobby ends the seeking
+	nickSeek, err = matchmakingClient.Seek(nick, &serverapi.SeekRequest{})
+	if err != nil {
+		t.Fatalf("Unexpected Seek err: %v", err)
+	}
+	if _, err := nickSeek.Recv(); err != nil {
+		t.Fatalf("Unexpected Recv err: %v", err)
+	}
+	if _, err := gameClient.LeaveTheLobby(nick, &api.EmptyMessage{}); err != nil {
+		t.Fatalf("Unexpected LeaveTheLobby err: %v", err)
+	}
+	if _, err := nickSeek.Recv(); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Unexpected Recv err after leaving the lobby:\nwant: %v,\ngot: %v.", ErrNotInLobby, err)
+	}
+
+	// seek cancels the call, so gamer must be removed from the queue
+	if _, err := seek(matchmakingClient, joe, &serverapi.SeekRequest{}); err != nil {
+		t.Fatalf("Unexpected Seek err: %v", err)
+	}
+	deadline := time.Now().Add(testGrace)
+	for {
+		_, err := seek(matchmakingClient, joe, &serverapi.SeekRequest{})
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Unexpected Seek err after cancellation: %v", err)
+		}
+		time.Sleep(testGrace / 10)
+	}
+}
+
+// seek starts seeking and receives the first place in the queue.
+func TestMatchmakerLoop(t *testing.T) {
+	var (
+		mutex  sync.Mutex
+		starts int
+	)
+	mm := newMatchmaker(matchmaking.Options{Window: DefaultMatchWindow}, func(pair matchmaking.Pair) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		starts++
+		return nil
+	})
+
+	// the loop stops, when the queue is empty, and starts again with a new seeker.
+	for round := 1; round <= 2; round++ {
+		var founds []<-chan match
+		for _, seeker := range []matchmaking.Seeker{
+			{ID: 2, Login: "Joe", Rating: DefaultRating, Request: matchmaking.Request{Size: 9}},
+			{ID: 3, Login: "Nick", Rating: DefaultRating, Request: matchmaking.Request{Size: 9}},
+		} {
+			found, err := mm.add(seeker)
+			if err != nil {
+				t.Fatalf("Unexpected add err: %v", err)
+			}
+			founds = append(founds, found)
+		}
+		for i, want := range []string{"Nick", "Joe"} {
+			select {
+			case m := <-founds[i]:
+				if m.err != nil || m.partner != want {
+					t.Errorf("Unexpected match:\nwant: %v,\ngot: %v, %v.", want, m.partner, m.err)
+				}
+			case <-time.After(2 * matchInterval):
+				t.Fatalf("Unexpected timeout of pairing.")
+			}
+		}
+
+		mutex.Lock()
+		got := starts
+		mutex.Unlock()
+		if got != round {
+			t.Errorf("Unexpected number of started games:\nwant: %d,\ngot: %d.", round, got)
+		}
+		waitStopped(t, mm)
+	}
+}
+
+// waitStopped waits until the loop of mm stops.
+func waitStopped(t *testing.T, mm *matchmaker) {
+	t.Helper()
+	deadline := time.Now().Add(2 * matchInterval)
+	for time.Now().Before(deadline) {
+		mm.mutex.Lock()
+		running := mm.running
+		mm.mutex.Unlock()
+		if !running {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Unexpected running loop of pairing with empty queue.")
+}
+
+func seek(client serverapi.MatchmakingClient, ctx context.Context, request *serverapi.SeekRequest) (*serverapi.SeekMessage, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := client.Seek(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return stream.Recv()
+}
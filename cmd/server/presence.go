@@ -0,0 +1,97 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package server
+
+import "sync"
+
+// presence keeps gamers, who spectate games,
+// and informs watchers of the lobby about changes of gamers' activities.
+type presence struct {
+	mutex      sync.Mutex
+	spectating map[int]int
+	watchers   map[chan struct{}]struct{}
+}
+
+func newPresence() *presence {
+	return &presence{
+		spectating: make(map[int]int),
+		watchers:   make(map[chan struct{}]struct{}),
+	}
+}
+
+// spectate marks gamer with id as a spectator.
+// Returned function must be called when spectating ends.
+func (p *presence) spectate(id int) func() {
+	p.mutex.Lock()
+	p.spectating[id]++
+	p.notify()
+	p.mutex.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			p.mutex.Lock()
+			defer p.mutex.Unlock()
+			if p.spectating[id]--; p.spectating[id] < 1 {
+				delete(p.spectating, id)
+			}
+			p.notify()
+		})
+	}
+}
+
+// isSpectating reports whether gamer with id spectates a game.
+func (p *presence) isSpectating(id int) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.spectating[id] > 0
+}
+
+// changed informs watchers about changes in the lobby.
+func (p *presence) changed() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.notify()
+}
+
+// watch subscribes to changes in the lobby.
+// Returned function must be called to unsubscribe.
+func (p *presence) watch() (<-chan struct{}, func()) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	changes := make(chan struct{}, 1)
+	p.watchers[changes] = struct{}{}
+	cancel := func() {
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+		delete(p.watchers, changes)
+	}
+	return changes, cancel
+}
+
+// notify must be called with locked mutex.
+func (p *presence) notify() {
+	for changes := range p.watchers {
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+	}
+}
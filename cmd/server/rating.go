@@ -0,0 +1,185 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/yagoggame/grpc_server/rating"
+	"github.com/yagoggame/grpc_server/serverapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultLeaderboardLimit is a number of players in page of leaderboard, if limit isn't requested.
+	DefaultLeaderboardLimit = 20
+	// MaxLeaderboardLimit is the maximum number of players in page of leaderboard.
+	MaxLeaderboardLimit = 100
+)
+
+var (
+	// ErrNoSuchPlayer occurs when requested player has no rated games
+	ErrNoSuchPlayer = status.Errorf(codes.NotFound, "no rated games of player")
+	// ErrWrongPage occurs when requested page of leaderboard has negative offset or limit
+	ErrWrongPage = status.Errorf(codes.InvalidArgument, "wrong page of leaderboard")
+	// ErrStats occurs when statistics can't be computed from the history of games
+	ErrStats = status.Errorf(codes.Internal, "failed to compute statistics")
+)
+
+// SetRatings replaces ratings of gamers, which are updated by results of games
+// and are used by matchmaking. It must be called before serving.
+func (s *Server) SetRatings(ratings *rating.Ratings) {
+	s.ratings = ratings
+	s.rater = ratings
+}
+
+// GetRating returns rating of gamer with in.Login.
+// Gamer without rated games is found only by himself, with empty login.
+func (s *Server) GetRating(ctx context.Context, in *serverapi.RatingRequest) (*serverapi.RatingMessage, error) {
+	gamer, err := userFromContext(ctx)
+	if err != nil {
+		log.Printf("GetRating error: %s", err)
+		return &serverapi.RatingMessage{}, err
+	}
+
+	if in.GetLogin() == "" || in.GetLogin() == gamer.Name {
+		player := s.ratings.Player(gamer.ID)
+		player.Login = gamer.Name
+		return toRatingMessage(player), nil
+	}
+
+	player, ok := s.ratings.PlayerByLogin(in.GetLogin())
+	if !ok {
+		err := extGrpcError(ErrNoSuchPlayer, fmt.Sprintf("login %q", in.GetLogin()))
+		log.Printf("GetRating error: %s", err)
+		return &serverapi.RatingMessage{}, err
+	}
+	return toRatingMessage(player), nil
+}
+
+// Leaderboard returns a page of rated players ordered by rating.
+// DefaultLeaderboardLimit players are returned, if in.Limit is 0,
+// and at most MaxLeaderboardLimit ones.
+func (s *Server) Leaderboard(ctx context.Context, in *serverapi.LeaderboardRequest) (*serverapi.LeaderboardMessage, error) {
+	if _, err := userFromContext(ctx); err != nil {
+		log.Printf("Leaderboard error: %s", err)
+		return &serverapi.LeaderboardMessage{}, err
+	}
+
+	offset, limit := in.GetOffset(), in.GetLimit()
+	if offset < 0 || limit < 0 {
+		err := extGrpcError(ErrWrongPage, fmt.Sprintf("offset %d, limit %d", offset, limit))
+		log.Printf("Leaderboard error: %s", err)
+		return &serverapi.LeaderboardMessage{}, err
+	}
+	if limit == 0 {
+		limit = DefaultLeaderboardLimit
+	}
+	if limit > MaxLeaderboardLimit {
+		limit = MaxLeaderboardLimit
+	}
+
+	players, total := s.ratings.Leaderboard(int(offset), int(limit))
+	msg := &serverapi.LeaderboardMessage{Total: int64(total)}
+	for i, player := range players {
+		ratingMsg := toRatingMessage(player)
+		ratingMsg.Rank = offset + int64(i) + 1
+		msg.Players = append(msg.Players, ratingMsg)
+	}
+	return msg, nil
+}
+
+// GetStats returns statistics of games of gamer with in.Login.
+// Gamer without rated games is found only by himself, with empty login.
+func (s *Server) GetStats(ctx context.Context, in *serverapi.RatingRequest) (*serverapi.StatsMessage, error) {
+	gamer, err := userFromContext(ctx)
+	if err != nil {
+		log.Printf("GetStats error: %s", err)
+		return &serverapi.StatsMessage{}, err
+	}
+
+	id, login := gamer.ID, gamer.Name
+	if in.GetLogin() != "" && in.GetLogin() != gamer.Name {
+		player, ok := s.ratings.PlayerByLogin(in.GetLogin())
+		if !ok {
+			err := extGrpcError(ErrNoSuchPlayer, fmt.Sprintf("login %q", in.GetLogin()))
+			log.Printf("GetStats error: %s", err)
+			return &serverapi.StatsMessage{}, err
+		}
+		id, login = player.ID, player.Login
+	}
+
+	stats, err := s.ratings.Stats(id)
+	if err != nil {
+		err := extGrpcError(ErrStats, err.Error())
+		log.Printf("GetStats error: %s", err)
+		return &serverapi.StatsMessage{}, err
+	}
+	return toStatsMessage(login, stats), nil
+}
+
+// recordResult updates ratings by the result of finished game.
+func (s *Server) recordResult(record rating.Record) {
+	black, white, err := s.ratings.Record(record)
+	if err != nil {
+		log.Printf("failed to record result of game of gamers with id %d and %d: %s", record.Black, record.White, err)
+		return
+	}
+	log.Printf("game of gamers with id %d and %d is over by %s, result: %s, ratings: %.0f and %.0f",
+		record.Black, record.White, record.Reason, record.Result, black.Rating, white.Rating)
+}
+
+// removeRating drops rating and statistics of removed user,
+// so a new user, who gets the same id, doesn't inherit them.
+func (s *Server) removeRating(id int) {
+	if err := s.ratings.Remove(id); err != nil {
+		log.Printf("failed to remove rating of gamer with id %d: %s", id, err)
+	}
+}
+
+func toRatingMessage(player rating.Player) *serverapi.RatingMessage {
+	return &serverapi.RatingMessage{
+		Login:      player.Login,
+		Rating:     player.Rating,
+		Deviation:  player.Deviation,
+		Volatility: player.Volatility,
+		Games:      int64(player.Games),
+	}
+}
+
+func toStatsMessage(login string, stats rating.Stats) *serverapi.StatsMessage {
+	msg := &serverapi.StatsMessage{
+		Login:            login,
+		Wins:             int64(stats.Wins),
+		Losses:           int64(stats.Losses),
+		Draws:            int64(stats.Draws),
+		Games:            int64(stats.Games()),
+		AverageMoves:     stats.AverageMoves,
+		AverageDuration:  int64(stats.AverageDuration / time.Millisecond),
+		LongestWinStreak: int64(stats.LongestWinStreak),
+	}
+	for size, games := range stats.BySize {
+		msg.BySize = append(msg.BySize, &serverapi.SizeGames{Size: int64(size), Games: int64(games)})
+	}
+	sort.Slice(msg.BySize, func(i, j int) bool { return msg.BySize[i].Size < msg.BySize[j].Size })
+	return msg
+}
@@ -0,0 +1,271 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/yagoggame/api"
+	"github.com/yagoggame/grpc_server/interfaces"
+	"github.com/yagoggame/grpc_server/rating"
+	"github.com/yagoggame/grpc_server/serverapi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// fakeGame is a game which is used only as a key of played games
+type fakeGame struct {
+	interfaces.GameManager
+}
+
+var (
+	joeSeat  = &seatInfo{id: 2, login: "Joe"}
+	nickSeat = &seatInfo{id: 3, login: "Nick"}
+)
+
+func overState(black, white float64) *api.State {
+	return &api.State{
+		Size:     9,
+		GameOver: true,
+		Black:    &api.State_ColourState{Scores: black},
+		White:    &api.State_ColourState{Scores: white},
+	}
+}
+
+var resultsTests = []struct {
+	caseName   string
+	moves      int
+	partner    *seatInfo
+	end        *api.State
+	leaver     int
+	want       bool
+	wantResult rating.Result
+	wantReason rating.Reason
+}{
+	{caseName: "black wins by score", moves: 3, partner: nickSeat, end: overState(10, 5.5),
+		want: true, wantResult: rating.BlackWins, wantReason: rating.ByScore},
+	{caseName: "white wins by score", moves: 3, partner: nickSeat, end: overState(5, 5.5),
+		want: true, wantResult: rating.WhiteWins, wantReason: rating.ByScore},
+	{caseName: "draw", moves: 3, partner: nickSeat, end: overState(5, 5),
+		want: true, wantResult: rating.Draw, wantReason: rating.ByScore},
+	{caseName: "black left", moves: 2, partner: nickSeat, leaver: 2,
+		want: true, wantResult: rating.WhiteWins, wantReason: rating.ByLeaving},
+	{caseName: "white left", moves: 2, partner: nickSeat, leaver: 3,
+		want: true, wantResult: rating.BlackWins, wantReason: rating.ByLeaving},
+	{caseName: "aborted", moves: 1, partner: nickSeat, leaver: 3},
+	{caseName: "unknown partner", moves: 3, end: overState(10, 5)},
+}
+
+func TestResults(t *testing.T) {
+	for _, test := range resultsTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			var records []rating.Record
+			rs := newResults(func(record rating.Record) { records = append(records, record) })
+			game := &fakeGame{}
+
+			for i := 0; i < test.moves; i++ {
+				gamer, partner := joeSeat, test.partner
+				if i%2 == 1 && test.partner != nil {
+					gamer, partner = test.partner, joeSeat
+				}
+				state := &api.State{Size: 9}
+				if i == test.moves-1 && test.end != nil {
+					state = test.end
+				}
+				rs.moved(game, gamer, partner, state)
+			}
+			if test.leaver != 0 {
+				rs.left(test.leaver, rating.ByLeaving)
+			}
+			// nothing is recorded twice
+			rs.left(2, rating.ByLeaving)
+			rs.left(3, rating.ByLeaving)
+
+			if !test.want {
+				if len(records) != 0 {
+					t.Fatalf("Unexpected records: %+v", records)
+				}
+				return
+			}
+			if len(records) != 1 {
+				t.Fatalf("Unexpected number of records:\nwant: %d,\ngot: %d.", 1, len(records))
+			}
+			record := records[0]
+			if record.Result != test.wantResult || record.Reason != test.wantReason ||
+				record.Black != 2 || record.White != 3 || record.WhiteLogin != "Nick" ||
+				record.Moves != test.moves || record.Size != 9 || record.Ended.Before(record.Begun) {
+				t.Errorf("Unexpected record:\nwant: %s by %s,\ngot: %+v.", test.wantResult, test.wantReason, record)
+			}
+		})
+	}
+}
+
+func TestRating(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	conn, release := startTestServer(t, DefaultSpectatorLimit)
+	defer release()
+	gameClient := api.NewGoGameClient(conn)
+	ratingClient := serverapi.NewRatingClient(conn)
+
+	joe := clientContext("Joe", "aaa")
+	nick := clientContext("Nick", "bbb")
+	joinBoth(t, gameClient, joe, nick)
+	black, white := playMoves(t, conn, 2, joe, nick)
+	if _, err := gameClient.LeaveTheGame(black, &api.EmptyMessage{}); err != nil {
+		t.Fatalf("Unexpected LeaveTheGame err: %v", err)
+	}
+	if _, err := gameClient.LeaveTheGame(white, &api.EmptyMessage{}); err != nil {
+		t.Fatalf("Unexpected LeaveTheGame err: %v", err)
+	}
+
+	winner, err := ratingClient.GetRating(white, &serverapi.RatingRequest{})
+	if err != nil {
+		t.Fatalf("Unexpected GetRating err: %v", err)
+	}
+	loser, err := ratingClient.GetRating(white, &serverapi.RatingRequest{Login: loginOf(black)})
+	if err != nil {
+		t.Fatalf("Unexpected GetRating err: %v", err)
+	}
+	if winner.GetRating() <= DefaultRating || winner.GetGames() != 1 || winner.GetLogin() != loginOf(white) ||
+		winner.GetDeviation() >= rating.DefaultDeviation {
+		t.Errorf("Unexpected rating of winner: %v", winner)
+	}
+	if loser.GetRating() >= DefaultRating || loser.GetGames() != 1 || loser.GetLogin() != loginOf(black) {
+		t.Errorf("Unexpected rating of loser: %v", loser)
+	}
+
+	if _, err := ratingClient.GetRating(joe, &serverapi.RatingRequest{Login: "Piter"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Unexpected GetRating of unrated gamer err:\nwant: %v,\ngot: %v.", ErrNoSuchPlayer, err)
+	}
+	piter := clientContext("Piter", "ppp")
+	if _, err := gameClient.RegisterUser(piter, &api.EmptyMessage{}); err != nil {
+		t.Fatalf("Unexpected RegisterUser err: %v", err)
+	}
+	if own, err := ratingClient.GetRating(piter, &serverapi.RatingRequest{}); err != nil ||
+		own.GetRating() != DefaultRating || own.GetGames() != 0 || own.GetLogin() != "Piter" {
+		t.Errorf("Unexpected own rating of new gamer: %v, %v", own, err)
+	}
+
+	// game left after the first move is aborted
+	errs := make(chan error, 2)
+	for _, ctx := range []context.Context{joe, nick} {
+		go func(ctx context.Context) {
+			_, err := gameClient.JoinTheGame(ctx, &api.EmptyMessage{})
+			errs <- err
+		}(ctx)
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("Unexpected JoinTheGame err: %v", err)
+		}
+	}
+	black, _ = playMoves(t, conn, 1, joe, nick)
+	if _, err := gameClient.LeaveTheGame(black, &api.EmptyMessage{}); err != nil {
+		t.Fatalf("Unexpected LeaveTheGame err: %v", err)
+	}
+	if again, err := ratingClient.GetRating(white, &serverapi.RatingRequest{}); err != nil || again.GetGames() != 1 {
+		t.Errorf("Unexpected rating after aborted game: %v, %v", again, err)
+	}
+}
+
+func TestLeaderboardAndStats(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	conn, release := startTestServer(t, DefaultSpectatorLimit)
+	defer release()
+	gameClient := api.NewGoGameClient(conn)
+	ratingClient := serverapi.NewRatingClient(conn)
+
+	joe := clientContext("Joe", "aaa")
+	nick := clientContext("Nick", "bbb")
+	joinBoth(t, gameClient, joe, nick)
+	black, white := playMoves(t, conn, 3, joe, nick)
+	if _, err := gameClient.LeaveTheGame(black, &api.EmptyMessage{}); err != nil {
+		t.Fatalf("Unexpected LeaveTheGame err: %v", err)
+	}
+
+	leaderboard, err := ratingClient.Leaderboard(joe, &serverapi.LeaderboardRequest{})
+	if err != nil {
+		t.Fatalf("Unexpected Leaderboard err: %v", err)
+	}
+	players := leaderboard.GetPlayers()
+	if leaderboard.GetTotal() != 2 || len(players) != 2 ||
+		players[0].GetLogin() != loginOf(white) || players[0].GetRank() != 1 ||
+		players[1].GetLogin() != loginOf(black) || players[1].GetRank() != 2 {
+		t.Errorf("Unexpected Leaderboard: %v", leaderboard)
+	}
+	page, err := ratingClient.Leaderboard(joe, &serverapi.LeaderboardRequest{Offset: 1, Limit: 1})
+	if err != nil || len(page.GetPlayers()) != 1 || page.GetPlayers()[0].GetRank() != 2 || page.GetTotal() != 2 {
+		t.Errorf("Unexpected second page of Leaderboard: %v, %v", page, err)
+	}
+	if _, err := ratingClient.Leaderboard(joe, &serverapi.LeaderboardRequest{Offset: -1}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Unexpected Leaderboard of wrong page err:\nwant: %v,\ngot: %v.", ErrWrongPage, err)
+	}
+
+	stats, err := ratingClient.GetStats(black, &serverapi.RatingRequest{Login: loginOf(white)})
+	if err != nil {
+		t.Fatalf("Unexpected GetStats err: %v", err)
+	}
+	want := &serverapi.StatsMessage{Login: loginOf(white), Wins: 1, Games: 1, BySize: []*serverapi.SizeGames{{Size: 9, Games: 1}},
+		AverageMoves: 3, AverageDuration: stats.GetAverageDuration(), LongestWinStreak: 1}
+	if !proto.Equal(stats, want) {
+		t.Errorf("Unexpected GetStats:\nwant: %v,\ngot: %v.", want, stats)
+	}
+	if own, err := ratingClient.GetStats(black, &serverapi.RatingRequest{}); err != nil ||
+		own.GetLosses() != 1 || own.GetGames() != 1 || own.GetLongestWinStreak() != 0 {
+		t.Errorf("Unexpected own GetStats: %v, %v", own, err)
+	}
+	if _, err := ratingClient.GetStats(joe, &serverapi.RatingRequest{Login: "Piter"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Unexpected GetStats of unrated gamer err:\nwant: %v,\ngot: %v.", ErrNoSuchPlayer, err)
+	}
+}
+
+// playMoves makes moves by turns and returns contexts of black and white gamers.
+func playMoves(t *testing.T, conn *grpc.ClientConn, moves int, ctxs ...context.Context) (black, white context.Context) {
+	gameClient := api.NewGoGameClient(conn)
+	gameStatus, err := serverapi.NewSessionClient(conn).GetGameState(ctxs[0], &api.EmptyMessage{})
+	if err != nil {
+		t.Fatalf("Unexpected GetGameState err: %v", err)
+	}
+	black, white = ctxs[0], ctxs[1]
+	if !gameStatus.GetMyTurn() {
+		black, white = white, black
+	}
+
+	for i := 0; i < moves; i++ {
+		gamer := black
+		if i%2 == 1 {
+			gamer = white
+		}
+		if _, err := gameClient.MakeTurn(gamer, &api.TurnMessage{X: int64(i + 1), Y: 1}); err != nil {
+			t.Fatalf("Unexpected MakeTurn err: %v", err)
+		}
+	}
+	return black, white
+}
+
+// loginOf returns login of client context.
+func loginOf(ctx context.Context) string {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	return strings.Join(md["login"], "")
+}
@@ -0,0 +1,189 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/yagoggame/api"
+	"github.com/yagoggame/gomaster/game"
+	"github.com/yagoggame/gomaster/game/igame"
+	"github.com/yagoggame/grpc_server/interfaces"
+	"github.com/yagoggame/grpc_server/rating"
+)
+
+// minRatedMoves is a number of moves, after which a game is rated.
+// Games left earlier are aborted.
+const minRatedMoves = 2
+
+// playedGame is a begun game, whose result isn't recorded yet.
+type playedGame struct {
+	game   interfaces.GameManager
+	record rating.Record
+}
+
+// colourer is implemented by games, which report colours of gamers.
+type colourer interface {
+	GamerState(id int) (*game.GamerState, error)
+}
+
+// results tracks played games and records their results.
+// Black is the gamer, who makes the first move, unless game reports colours by colourer:
+// White moves first after handicap stones.
+type results struct {
+	mutex sync.Mutex
+	games map[interfaces.GameManager]*playedGame
+	byID  map[int]*playedGame
+	// finished is called without locked mutex for each ended game
+	finished func(record rating.Record)
+}
+
+func newResults(finished func(record rating.Record)) *results {
+	return &results{
+		games:    make(map[interfaces.GameManager]*playedGame),
+		byID:     make(map[int]*playedGame),
+		finished: finished,
+	}
+}
+
+// moved counts the move of gamer and finishes the game, if state is game over.
+func (rs *results) moved(game interfaces.GameManager, gamer, partner *seatInfo, state *api.State) {
+	if record, ok := rs.countMove(game, gamer, partner, state); ok {
+		rs.finished(record)
+	}
+}
+
+// undone uncounts the taken back move of game.
+func (rs *results) undone(game interfaces.GameManager) {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	if played, ok := rs.games[game]; ok && played.record.Moves > 0 {
+		played.record.Moves--
+	}
+}
+
+// left finishes the game of gamer by his loss.
+// Game with less than minRatedMoves moves is aborted.
+func (rs *results) left(id int, reason rating.Reason) {
+	if record, ok := rs.leave(id, reason); ok {
+		rs.finished(record)
+	}
+}
+
+// countMove returns the record of game, if it is over.
+func (rs *results) countMove(game interfaces.GameManager, gamer, partner *seatInfo, state *api.State) (rating.Record, bool) {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	played, ok := rs.games[game]
+	if !ok {
+		if partner == nil {
+			return rating.Record{}, false
+		}
+		if isWhite(game, gamer.id) {
+			gamer, partner = partner, gamer
+		}
+		played = &playedGame{
+			game: game,
+			record: rating.Record{
+				Black:      gamer.id,
+				White:      partner.id,
+				BlackLogin: gamer.login,
+				WhiteLogin: partner.login,
+				Size:       int(state.GetSize()),
+				Komi:       state.GetKomi(),
+				Begun:      time.Now(),
+			},
+		}
+		rs.games[game] = played
+		rs.byID[gamer.id] = played
+		rs.byID[partner.id] = played
+	}
+	played.record.Moves++
+	if !state.GetGameOver() {
+		return rating.Record{}, false
+	}
+
+	record := &played.record
+	record.BlackScore = state.GetBlack().GetScores()
+	record.WhiteScore = state.GetWhite().GetScores()
+	switch {
+	case record.BlackScore > record.WhiteScore:
+		record.Result = rating.BlackWins
+	case record.BlackScore < record.WhiteScore:
+		record.Result = rating.WhiteWins
+	default:
+		record.Result = rating.Draw
+	}
+	record.Reason = rating.ByScore
+	return rs.finish(played), true
+}
+
+// leave returns the record of game of gamer, if it is rated.
+func (rs *results) leave(id int, reason rating.Reason) (rating.Record, bool) {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	played, ok := rs.byID[id]
+	if !ok {
+		return rating.Record{}, false
+	}
+	if played.record.Moves < minRatedMoves {
+		rs.forget(played)
+		return rating.Record{}, false
+	}
+
+	record := &played.record
+	record.Result = rating.BlackWins
+	if id == record.Black {
+		record.Result = rating.WhiteWins
+	}
+	record.Reason = reason
+	return rs.finish(played), true
+}
+
+// finish must be called with locked mutex.
+func (rs *results) finish(played *playedGame) rating.Record {
+	rs.forget(played)
+	played.record.Ended = time.Now()
+	return played.record
+}
+
+// forget must be called with locked mutex.
+func (rs *results) forget(played *playedGame) {
+	delete(rs.games, played.game)
+	delete(rs.byID, played.record.Black)
+	delete(rs.byID, played.record.White)
+}
+
+// isWhite reports whether the gamer of game is known to play white.
+func isWhite(g interfaces.GameManager, id int) bool {
+	c, ok := g.(colourer)
+	if !ok {
+		return false
+	}
+	state, err := c.GamerState(id)
+	return err == nil && state.Colour == igame.White
+}
+
+// seatInfo identifies a gamer in a game.
+type seatInfo struct {
+	id    int
+	login string
+}
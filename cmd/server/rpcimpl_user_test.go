@@ -28,6 +28,7 @@ import (
 	"github.com/yagoggame/api"
 	"github.com/yagoggame/grpc_server/interfaces"
 	"github.com/yagoggame/grpc_server/interfaces/mocks"
+	"github.com/yagoggame/grpc_server/rating"
 )
 
 var RegisterUserTests = []commonTestCase{
@@ -153,8 +154,22 @@ func TestRemoveUser(t *testing.T) {
 					Release().
 					Times(test.times[1]))
 
-			_, err := s.RemoveUser(test.ctx, &api.EmptyMessage{})
+			_, _, err := s.ratings.Record(rating.Record{Black: correctID, White: correctID + 1, Result: rating.Draw})
+			if err != nil {
+				t.Fatalf("Unexpected Record err: %v", err)
+			}
+
+			_, err = s.RemoveUser(test.ctx, &api.EmptyMessage{})
 			testErr(t, err, test.want)
+
+			// rating of removed user must not be inherited by a new one with the same id.
+			wantGames := 1
+			if test.want == nil {
+				wantGames = 0
+			}
+			if games := s.ratings.Player(correctID).Games; games != wantGames {
+				t.Errorf("Unexpected games of player:\nwant: %d,\ngot: %d.", wantGames, games)
+			}
 		})
 	}
 }
@@ -22,11 +22,17 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/yagoggame/api"
 	"github.com/yagoggame/gomaster/game"
 	"github.com/yagoggame/gomaster/game/igame"
+	"github.com/yagoggame/grpc_server/chat"
 	"github.com/yagoggame/grpc_server/interfaces"
+	"github.com/yagoggame/grpc_server/matchmaking"
+	"github.com/yagoggame/grpc_server/rating"
+	"github.com/yagoggame/grpc_server/serverapi"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
@@ -82,19 +88,100 @@ func extGrpcError(err error, ext string) error {
 
 // Server represents the gRPC server.
 type Server struct {
-	pool         interfaces.Pooler
-	authorizator interfaces.Authorizator
-	gameGeter    interfaces.GameGeter
+	pool           interfaces.Pooler
+	authorizator   interfaces.Authorizator
+	gameGeter      interfaces.GameGeter
+	rater          interfaces.Rater
+	certAuth       bool
+	grace          time.Duration
+	spectatorLimit int
+	challengeTTL   time.Duration
+	seats          *seats
+	invitations    *invitations
+	matchmaker     *matchmaker
+	ratings        *rating.Ratings
+	results        *results
+	histories      *histories
+	presence       *presence
+	chat           *chat.Hub
 }
 
 // NewServer Creates a new Server instance.
 // After using, it mast be destroyed by Release call.
 func NewServer(authorizator interfaces.Authorizator, pool interfaces.Pooler, gameGeter interfaces.GameGeter) *Server {
-	return &Server{
-		pool:         pool,
-		authorizator: authorizator,
-		gameGeter:    gameGeter,
-	}
+	s := &Server{
+		pool:           pool,
+		authorizator:   authorizator,
+		spectatorLimit: DefaultSpectatorLimit,
+		challengeTTL:   DefaultChallengeTTL,
+		histories:      newHistories(),
+		presence:       newPresence(),
+		chat:           chat.New(chat.Options{MaxLength: DefaultChatLength, History: DefaultChatHistory}),
+	}
+	s.seats = newSeats(func(id int) {
+		defer s.presence.changed()
+		if err := s.releaseGame(id); err != nil {
+			log.Printf("failed to release game of gamer with id %d after grace period: %s", id, err)
+			return
+		}
+		log.Printf("grace period of gamer with id %d expired, his game released", id)
+	})
+	s.invitations = newInvitations(func(id int) {
+		s.results.left(id, rating.ByTimeout)
+		s.histories.left(id)
+		s.seats.leave(id)
+		s.presence.changed()
+	})
+	s.gameGeter = &invitationsGeter{invitations: s.invitations, next: gameGeter}
+	s.SetMatchmaking(matchmaking.Options{
+		Window:    DefaultMatchWindow,
+		Widening:  DefaultMatchWidening,
+		MaxWindow: DefaultMatchMaxWindow,
+	})
+	// ratings in memory can't fail to load.
+	ratings, _ := rating.New(rating.NewMemoryStore(), rating.DefaultTau)
+	s.SetRatings(ratings)
+	s.results = newResults(s.recordResult)
+	s.seats.closed = func(tableID int) {
+		s.chat.Close(gameChannelName(tableID))
+	}
+	return s
+}
+
+// SetCertificateAuth enables or disables authentication of clients
+// by verified TLS certificates without password.
+func (s *Server) SetCertificateAuth(enabled bool) {
+	s.certAuth = enabled
+}
+
+// SetSpectatorLimit sets maximal number of spectators of one game.
+// Zero limit disables spectating.
+func (s *Server) SetSpectatorLimit(limit int) {
+	s.spectatorLimit = limit
+}
+
+// SetChat replaces the hub of chat channels.
+// It must be called before serving.
+func (s *Server) SetChat(hub *chat.Hub) {
+	s.chat = hub
+}
+
+// SetChallengeTTL sets the time, during which a challenge awaits an answer.
+func (s *Server) SetChallengeTTL(ttl time.Duration) {
+	s.challengeTTL = ttl
+}
+
+// SetRater replaces the source of ratings of gamers for matchmaking,
+// by default the ratings of SetRatings are used.
+func (s *Server) SetRater(rater interfaces.Rater) {
+	s.rater = rater
+}
+
+// SetGracePeriod sets the time, during which the seat in a game is held
+// for a gamer, whose connection was interrupted, so he can resume the game.
+// Zero period disables holding: game awaiting is released immediately.
+func (s *Server) SetGracePeriod(grace time.Duration) {
+	s.grace = grace
 }
 
 // RegisterUser provides registration of user by authorizator.
@@ -125,6 +212,7 @@ func (s *Server) RemoveUser(ctx context.Context, in *api.EmptyMessage) (*api.Emp
 		return &api.EmptyMessage{}, err
 	}
 
+	s.removeRating(id)
 	log.Printf("user with login %q, id %d removed", requisites.Login, id)
 
 	return &api.EmptyMessage{}, nil
@@ -173,6 +261,7 @@ func (s *Server) EnterTheLobby(ctx context.Context, in *api.EmptyMessage) (*api.
 		return &api.EmptyMessage{}, err
 	}
 
+	s.presence.changed()
 	log.Printf("gamer %s added to the Lobby", gamer)
 	return &api.EmptyMessage{}, nil
 }
@@ -186,6 +275,12 @@ func (s *Server) LeaveTheLobby(ctx context.Context, in *api.EmptyMessage) (*api.
 	}
 
 	gamer, err := s.pool.RmGamer(id)
+	s.results.left(id, rating.ByLeaving)
+	s.histories.left(id)
+	s.seats.leave(id)
+	s.invitations.leave(id)
+	s.matchmaker.reject(id, extGrpcError(ErrNotInLobby, fmt.Sprintf("gamer with id %d", id)))
+	s.presence.changed()
 	if err != nil {
 		err := extGrpcError(ErrLeaveLobby, err.Error())
 		log.Printf("LeaveTheLobby error: %s", err)
@@ -204,13 +299,7 @@ func (s *Server) JoinTheGame(ctx context.Context, in *api.EmptyMessage) (*api.St
 		return &api.State{}, err
 	}
 
-	if err := s.pool.JoinGame(id, standartSize, standartKomi); err != nil {
-		err := extGrpcError(ErrJoinGame, err.Error())
-		log.Printf("JoinTheGame error: %s", err)
-		return &api.State{}, err
-	}
-
-	state, err := s.waitGame(ctx, id)
+	state, err := s.joinGame(ctx, id, terms{size: standartSize, komi: standartKomi})
 	if err != nil {
 		log.Printf("JoinTheGame error: %s", err)
 		return &api.State{}, err
@@ -228,24 +317,13 @@ func (s *Server) WaitTheTurn(ctx context.Context, in *api.EmptyMessage) (*api.St
 		return &api.State{}, err
 	}
 
-	gameManager, err := s.gameGeter.GetGame(id)
-	if err != nil {
-		log.Printf("WaitTheTurn error: %s", err)
-		return &api.State{}, err
-	}
-	if gameManager == nil {
-		err = extGrpcError(ErrNilGame, fmt.Sprintf(" with id %d: %v", id, err))
-		log.Printf("WaitTheTurn error: %s", err)
-		return &api.State{}, err
-	}
-
-	log.Printf("Gamer with id %d waiting for his turn...", id)
-	state, err := s.waitTurn(ctx, gameManager, id)
+	gameManager, state, err := s.awaitTurn(ctx, id)
 	if err != nil {
 		log.Printf("WaitTheTurn error: %s", err)
 		return &api.State{}, err
 	}
 
+	s.setGameHeader(ctx, gameManager, id)
 	log.Printf("turn of gamer with id %d has been begun", id)
 
 	return state, nil
@@ -261,7 +339,9 @@ func (s *Server) LeaveTheGame(ctx context.Context, in *api.EmptyMessage) (*api.E
 	}
 
 	//leave the gamer's game, if it is.
-	if err := s.pool.ReleaseGame(id); err != nil {
+	s.seats.leave(id)
+	defer s.presence.changed()
+	if err := s.releaseGame(id); err != nil {
 		err = extGrpcError(ErrReleaseGame, fmt.Sprintf("failed to ReleaseGame for gamer with id %d: %v", id, err))
 		log.Printf("LeaveTheGame error: %s", err)
 		return &api.EmptyMessage{}, err
@@ -289,14 +369,20 @@ func (s *Server) MakeTurn(ctx context.Context, in *api.TurnMessage) (*api.State,
 		log.Printf("MakeTurn error: %s", err)
 		return &api.State{}, err
 	}
+	s.seats.reconnect(id)
 
-	state, err := s.makeTurn(gameManager, id,
-		&igame.TurnData{X: int(in.X), Y: int(in.Y)})
+	turn := &igame.TurnData{X: int(in.X), Y: int(in.Y)}
+	state, err := s.makeTurn(gameManager, id, turn)
 	if err != nil {
 		log.Printf("MakeTurn error: %s", err)
 		return &api.State{}, err
 	}
 
+	s.seats.moved(id)
+	s.invitations.moved(id)
+	s.histories.moved(gameManager, id, colourOf(gameManager, id), turn)
+	s.countMove(ctx, gameManager, id, state)
+	s.setGameHeader(ctx, gameManager, id)
 	log.Printf("gamer with id %d made a turn: %v %v", id, in.X, in.Y)
 
 	return state, nil
@@ -304,9 +390,49 @@ func (s *Server) MakeTurn(ctx context.Context, in *api.TurnMessage) (*api.State,
 
 // Release sops the server intity.
 func (s *Server) Release() {
+	s.seats.clear()
+	s.invitations.clear()
 	s.pool.Release()
 }
 
+// releaseGame releases private game of gamer or his game in the pool.
+// Leaving of begun game is his loss.
+func (s *Server) releaseGame(id int) error {
+	s.results.left(id, rating.ByLeaving)
+	s.histories.left(id)
+	if s.invitations.leave(id) {
+		return nil
+	}
+	return s.pool.ReleaseGame(id)
+}
+
+// countMove counts the move of gamer for the record of his game.
+func (s *Server) countMove(ctx context.Context, gameManager interfaces.GameManager, id int, state *api.State) {
+	login, err := loginFromContext(ctx)
+	if err != nil {
+		return
+	}
+	var partner *seatInfo
+	if partnerID, partnerLogin, ok := s.seats.partnerID(id); ok {
+		partner = &seatInfo{id: partnerID, login: partnerLogin}
+	}
+	s.results.moved(gameManager, &seatInfo{id: id, login: login}, partner, state)
+}
+
+// disconnect holds the seat of gamer for grace period,
+// if his call is interrupted by cancellation of ctx.
+// It returns true if the seat is held.
+func (s *Server) disconnect(ctx context.Context, id int) bool {
+	if s.grace <= 0 || ctx.Err() == nil {
+		return false
+	}
+	if !s.seats.disconnect(id, s.grace) {
+		return false
+	}
+	log.Printf("gamer with id %d disconnected, his seat is held for %s", id, s.grace)
+	return true
+}
+
 // idFromCtx gets id as integer from context.
 func idFromCtx(ctx context.Context) (id int, err error) {
 	iid := ctx.Value(clientIDKey)
@@ -323,11 +449,10 @@ func idFromCtx(ctx context.Context) (id int, err error) {
 }
 
 func userFromContext(ctx context.Context) (gamer *game.Gamer, err error) {
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return nil, ErrMissCred
+	login, err := loginFromContext(ctx)
+	if err != nil {
+		return nil, err
 	}
-	login := strings.Join(md["login"], "")
 	if len(login) < 1 {
 		return nil, ErrLoginEmpty
 	}
@@ -340,6 +465,20 @@ func userFromContext(ctx context.Context) (gamer *game.Gamer, err error) {
 	return &game.Gamer{Name: login, ID: id}, nil
 }
 
+// loginFromContext returns login of client authenticated
+// by TLS certificate or provided in metadata.
+func loginFromContext(ctx context.Context) (string, error) {
+	if login, ok := ctx.Value(clientLoginKey).(string); ok {
+		return login, nil
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", ErrMissCred
+	}
+	return strings.Join(md["login"], ""), nil
+}
+
 func requisitesFromContext(ctx context.Context) (requisites *interfaces.Requisites, id int, err error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
@@ -364,6 +503,45 @@ func requisitesFromContext(ctx context.Context) (requisites *interfaces.Requisit
 	return requisites, id, nil
 }
 
+// joinGame joins gamer to a game of the pool of terms t.
+func (s *Server) joinGame(ctx context.Context, id int, t terms) (*api.State, error) {
+	if _, ok := s.invitations.gameOf(id); ok {
+		return &api.State{}, extGrpcError(ErrJoinGame, ErrBusy.Error())
+	}
+	if s.matchmaker.queue.Contains(id) {
+		return &api.State{}, extGrpcError(ErrJoinGame, ErrSeeking.Error())
+	}
+
+	if err := s.pool.JoinGame(id, t.size, t.komi); err != nil {
+		return &api.State{}, extGrpcError(ErrJoinGame, err.Error())
+	}
+	s.presence.changed()
+	defer s.presence.changed()
+
+	return s.waitGame(ctx, id)
+}
+
+// awaitTurn waits for the turn of gamer and returns his game.
+func (s *Server) awaitTurn(ctx context.Context, id int) (interfaces.GameManager, *api.State, error) {
+	gameManager, err := s.gameGeter.GetGame(id)
+	if err != nil {
+		return nil, &api.State{}, err
+	}
+	if gameManager == nil {
+		err = extGrpcError(ErrNilGame, fmt.Sprintf(" with id %d: %v", id, err))
+		return nil, &api.State{}, err
+	}
+	s.seats.reconnect(id)
+
+	log.Printf("Gamer with id %d waiting for his turn...", id)
+	state, err := s.waitTurn(ctx, gameManager, id)
+	if err != nil {
+		s.disconnect(ctx, id)
+		return nil, &api.State{}, err
+	}
+	return gameManager, state, nil
+}
+
 func (s *Server) waitGame(ctx context.Context, id int) (*api.State, error) {
 	gameManager, err := s.gameGeter.GetGame(id)
 	if err != nil {
@@ -374,23 +552,129 @@ func (s *Server) waitGame(ctx context.Context, id int) (*api.State, error) {
 		return &api.State{}, err
 	}
 
+	if login, err := loginFromContext(ctx); err == nil {
+		s.seats.take(id, login, gameManager)
+	}
+
 	if err := gameManager.WaitBegin(ctx, id); err != nil {
+		// connection of gamer is interrupted, hold his seat for a while.
+		if s.disconnect(ctx, id) {
+			return &api.State{}, err
+		}
 		//gamer joined a game, so it's must be released.
-		if errl := s.pool.ReleaseGame(id); errl != nil {
+		s.seats.leave(id)
+		if errl := s.releaseGame(id); errl != nil {
 			err = extGrpcError(err, fmt.Sprintf(", gamer with id %d: failed to Release game: %q, after failed game awaiting", id, errl))
 			return &api.State{}, err
 		}
 		return &api.State{}, err
 	}
 
+	gameManager = s.adoptGame(id, gameManager)
 	state, err := s.getGameState(gameManager, id)
 	if err != nil {
 		return &api.State{}, err
 	}
+	s.setGameHeader(ctx, gameManager, id)
 
 	return state, nil
 }
 
+// adoptGame replaces begun game of the pool by a game of invitations,
+// so its moves can be taken back. Games, which don't report colours
+// of gamers, games of gamers without seats and games with moves are kept in the pool.
+func (s *Server) adoptGame(id int, gameManager interfaces.GameManager) interfaces.GameManager {
+	if g, ok := s.invitations.gameOf(id); ok {
+		return g
+	}
+	c, ok := gameManager.(colourer)
+	if !ok {
+		return gameManager
+	}
+	partnerID, partnerLogin, ok := s.seats.partnerID(id)
+	if !ok {
+		return gameManager
+	}
+	mine, err := c.GamerState(id)
+	if err != nil {
+		return gameManager
+	}
+	size, err := gameManager.FieldSize(id)
+	if err != nil {
+		return gameManager
+	}
+	state, err := gameManager.GameState(id)
+	if err != nil || len(state.ChipsOnBoard[igame.Black])+len(state.ChipsOnBoard[igame.White]) > 0 {
+		return gameManager
+	}
+
+	t := terms{size: size, komi: standartKomi, colour: mine.Colour}
+	g, err := s.invitations.adopt(gameManager,
+		&game.Gamer{ID: id, Name: mine.Name}, &game.Gamer{ID: partnerID, Name: partnerLogin}, t)
+	if err != nil {
+		log.Printf("failed to adopt game of gamer with id %d: %s", id, err)
+		return gameManager
+	}
+	s.seats.replace(gameManager, g)
+	return g
+}
+
+// setGameHeader reports by headers of the call what api.State has no fields for:
+// the colour of gamer, the colour, whose turn is now, and the login of partner.
+// Black moves first, but White does it after handicap stones.
+func (s *Server) setGameHeader(ctx context.Context, gameManager interfaces.GameManager, id int) {
+	header := metadata.MD{}
+	if partner, _ := s.seats.partner(id); partner != "" {
+		header.Set(serverapi.PartnerHeader, partner)
+	}
+	if colour := colourOf(gameManager, id); colour != serverapi.Colour_UNKNOWN {
+		header.Set(serverapi.ColourHeader, colour.String())
+		if myTurn, err := gameManager.IsMyTurn(id); err == nil {
+			turn := colour
+			if !myTurn {
+				turn = serverapi.Colour(opposite(igame.ChipColour(colour)))
+			}
+			header.Set(serverapi.TurnHeader, turn.String())
+		}
+	}
+	if header.Len() == 0 {
+		return
+	}
+	// the call may have no transport stream, e.g. if it is made by gateway
+	_ = grpc.SetHeader(ctx, header)
+}
+
+// colourOf returns the colour of gamer, if his game reports colours,
+// otherwise it returns Colour_UNKNOWN.
+func colourOf(gameManager interfaces.GameManager, id int) serverapi.Colour {
+	c, ok := gameManager.(colourer)
+	if !ok {
+		return serverapi.Colour_UNKNOWN
+	}
+	state, err := c.GamerState(id)
+	if err != nil {
+		return serverapi.Colour_UNKNOWN
+	}
+	switch state.Colour {
+	case igame.Black:
+		return serverapi.Colour_BLACK
+	case igame.White:
+		return serverapi.Colour_WHITE
+	}
+	return serverapi.Colour_UNKNOWN
+}
+
+// opposite returns colour of the partner.
+func opposite(colour igame.ChipColour) igame.ChipColour {
+	switch colour {
+	case igame.Black:
+		return igame.White
+	case igame.White:
+		return igame.Black
+	}
+	return colour
+}
+
 func (s *Server) waitTurn(ctx context.Context, gameManager interfaces.GameManager, id int) (*api.State, error) {
 	if err := gameManager.WaitTurn(ctx, id); err != nil {
 		return &api.State{}, err
@@ -0,0 +1,452 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yagoggame/grpc_server/interfaces"
+	"github.com/yagoggame/grpc_server/serverapi"
+)
+
+// eventsBuffer is a size of buffer of PartnerEvent channels.
+// Events are dropped for watchers which don't read them.
+const eventsBuffer = 8
+
+// table is a game with seats of its gamers and spectators.
+type table struct {
+	id         int
+	game       interfaces.GameManager
+	seats      []*seat
+	private    bool
+	spectators map[chan struct{}]struct{}
+}
+
+// seat is a place of a gamer in a game.
+type seat struct {
+	id        int
+	login     string
+	table     *table
+	connected bool
+	expiry    *time.Timer
+	watchers  map[chan *serverapi.PartnerEvent]struct{}
+}
+
+// seats keeps places of gamers in games, so disconnected gamer
+// can resume his game during a grace period.
+type seats struct {
+	mutex       sync.Mutex
+	byID        map[int]*seat
+	tables      map[interfaces.GameManager]*table
+	lastTableID int
+	release     func(id int)
+	// closed is called with locked mutex, when the last gamer leaves a table
+	closed func(tableID int)
+}
+
+// newSeats creates seats, release is called for gamers,
+// which haven't reconnected in time.
+func newSeats(release func(id int)) *seats {
+	return &seats{
+		byID:    make(map[int]*seat),
+		tables:  make(map[interfaces.GameManager]*table),
+		release: release,
+	}
+}
+
+// take places connected gamer to the game.
+func (st *seats) take(id int, login string, game interfaces.GameManager) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	if old, ok := st.byID[id]; ok {
+		if old.table.game == game {
+			return
+		}
+		st.free(old)
+	}
+	gameTable, ok := st.tables[game]
+	if !ok {
+		st.lastTableID++
+		gameTable = &table{
+			id:         st.lastTableID,
+			game:       game,
+			spectators: make(map[chan struct{}]struct{}),
+		}
+		st.tables[game] = gameTable
+	}
+	gamerSeat := &seat{
+		id:        id,
+		login:     login,
+		table:     gameTable,
+		connected: true,
+		watchers:  make(map[chan *serverapi.PartnerEvent]struct{}),
+	}
+	gameTable.seats = append(gameTable.seats, gamerSeat)
+	st.byID[id] = gamerSeat
+}
+
+// replace moves the table of game to its replacement.
+func (st *seats) replace(game, replacement interfaces.GameManager) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	gameTable, ok := st.tables[game]
+	if !ok {
+		return
+	}
+	delete(st.tables, game)
+	gameTable.game = replacement
+	st.tables[replacement] = gameTable
+}
+
+// leave frees the seat of gamer and informs his partner.
+func (st *seats) leave(id int) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	if gamerSeat, ok := st.byID[id]; ok {
+		st.free(gamerSeat)
+	}
+}
+
+// disconnect holds the seat of gamer for grace period.
+// It returns false, if gamer has no seat.
+func (st *seats) disconnect(id int, grace time.Duration) bool {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	gamerSeat, ok := st.byID[id]
+	if !ok {
+		return false
+	}
+	if !gamerSeat.connected {
+		return true
+	}
+
+	gamerSeat.connected = false
+	gamerSeat.expiry = time.AfterFunc(grace, func() { st.expire(gamerSeat) })
+	st.notifyPartner(gamerSeat, &serverapi.PartnerEvent{Partner: gamerSeat.login})
+	return true
+}
+
+// reconnect cancels grace period of gamer.
+func (st *seats) reconnect(id int) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	gamerSeat, ok := st.byID[id]
+	if !ok || gamerSeat.connected {
+		return
+	}
+
+	gamerSeat.expiry.Stop()
+	gamerSeat.expiry = nil
+	gamerSeat.connected = true
+	st.notifyPartner(gamerSeat, &serverapi.PartnerEvent{Partner: gamerSeat.login, Connected: true})
+}
+
+// undo informs partner of gamer about the step of takeback.
+func (st *seats) undo(id int, event serverapi.UndoEvent) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	if gamerSeat, ok := st.byID[id]; ok {
+		st.notifyPartner(gamerSeat, &serverapi.PartnerEvent{Partner: gamerSeat.login, Connected: gamerSeat.connected, Undo: event})
+	}
+}
+
+// partner returns login and connection state of gamer's partner.
+func (st *seats) partner(id int) (login string, connected bool) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	gamerSeat, ok := st.byID[id]
+	if !ok {
+		return "", false
+	}
+	if partnerSeat := st.partnerSeat(gamerSeat); partnerSeat != nil {
+		return partnerSeat.login, partnerSeat.connected
+	}
+	return "", false
+}
+
+// watch subscribes to events of gamer's partner.
+// Channel is closed when gamer leaves his seat.
+// Returned function must be called to unsubscribe.
+func (st *seats) watch(id int) (<-chan *serverapi.PartnerEvent, func(), bool) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	gamerSeat, ok := st.byID[id]
+	if !ok {
+		return nil, nil, false
+	}
+
+	events := make(chan *serverapi.PartnerEvent, eventsBuffer)
+	gamerSeat.watchers[events] = struct{}{}
+	cancel := func() {
+		st.mutex.Lock()
+		defer st.mutex.Unlock()
+		if _, ok := gamerSeat.watchers[events]; ok {
+			delete(gamerSeat.watchers, events)
+			close(events)
+		}
+	}
+	return events, cancel, true
+}
+
+// clear frees all seats without releasing their games.
+func (st *seats) clear() {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	for _, gamerSeat := range st.byID {
+		st.free(gamerSeat)
+	}
+}
+
+// expire releases the game of gamer, who hasn't reconnected in time.
+func (st *seats) expire(gamerSeat *seat) {
+	st.mutex.Lock()
+	if st.byID[gamerSeat.id] != gamerSeat || gamerSeat.connected {
+		st.mutex.Unlock()
+		return
+	}
+	st.free(gamerSeat)
+	st.mutex.Unlock()
+
+	st.release(gamerSeat.id)
+}
+
+// free removes the seat, it must be called with locked mutex.
+func (st *seats) free(gamerSeat *seat) {
+	if gamerSeat.expiry != nil {
+		gamerSeat.expiry.Stop()
+	}
+	delete(st.byID, gamerSeat.id)
+	st.notifyPartner(gamerSeat, &serverapi.PartnerEvent{Partner: gamerSeat.login, Left: true})
+	for events := range gamerSeat.watchers {
+		delete(gamerSeat.watchers, events)
+		close(events)
+	}
+
+	gameTable := gamerSeat.table
+	for i, other := range gameTable.seats {
+		if other == gamerSeat {
+			gameTable.seats = append(gameTable.seats[:i], gameTable.seats[i+1:]...)
+			break
+		}
+	}
+	if len(gameTable.seats) > 0 {
+		st.notifySpectators(gameTable)
+		return
+	}
+	delete(st.tables, gameTable.game)
+	st.closeSpectators(gameTable)
+	if st.closed != nil {
+		st.closed(gameTable.id)
+	}
+}
+
+// notifyPartner sends event to watchers of gamer's partner,
+// it must be called with locked mutex.
+func (st *seats) notifyPartner(gamerSeat *seat, event *serverapi.PartnerEvent) {
+	partnerSeat := st.partnerSeat(gamerSeat)
+	if partnerSeat == nil {
+		return
+	}
+	for events := range partnerSeat.watchers {
+		select {
+		case events <- event:
+		default:
+		}
+	}
+}
+
+// partnerSeat returns the seat in the same game,
+// it must be called with locked mutex.
+func (st *seats) partnerSeat(gamerSeat *seat) *seat {
+	for _, other := range gamerSeat.table.seats {
+		if other != gamerSeat {
+			return other
+		}
+	}
+	return nil
+}
+
+// moved informs spectators of the game of gamer about changes.
+func (st *seats) moved(id int) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	if gamerSeat, ok := st.byID[id]; ok {
+		st.notifySpectators(gamerSeat.table)
+	}
+}
+
+// setPrivate hides the game of gamer from spectators
+// and stops current spectating, or makes it public.
+// It returns false, if gamer has no seat.
+func (st *seats) setPrivate(id int, private bool) bool {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	gamerSeat, ok := st.byID[id]
+	if !ok {
+		return false
+	}
+	gamerSeat.table.private = private
+	if private {
+		st.closeSpectators(gamerSeat.table)
+	}
+	return true
+}
+
+// tableInfo describes a public game.
+type tableInfo struct {
+	id         int
+	game       interfaces.GameManager
+	players    []string
+	playerID   int
+	spectators int
+}
+
+// publicTables returns public games, which have begun.
+func (st *seats) publicTables() []tableInfo {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	infos := make([]tableInfo, 0, len(st.tables))
+	for _, gameTable := range st.tables {
+		if gameTable.private || len(gameTable.seats) < 2 {
+			continue
+		}
+		infos = append(infos, describeTable(gameTable))
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].id < infos[j].id })
+	return infos
+}
+
+// spectate subscribes to changes of public game with tableID,
+// if it has less than limit spectators.
+// Channel is closed when the game ends or becomes private.
+// Returned function must be called to unsubscribe.
+func (st *seats) spectate(tableID, limit int) (tableInfo, <-chan struct{}, func(), error) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	var gameTable *table
+	for _, t := range st.tables {
+		if t.id == tableID {
+			gameTable = t
+			break
+		}
+	}
+	switch {
+	case gameTable == nil || gameTable.private:
+		return tableInfo{}, nil, nil, ErrNoSuchGame
+	case len(gameTable.spectators) >= limit:
+		return tableInfo{}, nil, nil, ErrSpectatorLimit
+	}
+
+	changes := make(chan struct{}, 1)
+	gameTable.spectators[changes] = struct{}{}
+	cancel := func() {
+		st.mutex.Lock()
+		defer st.mutex.Unlock()
+		if _, ok := gameTable.spectators[changes]; ok {
+			delete(gameTable.spectators, changes)
+			close(changes)
+		}
+	}
+	return describeTable(gameTable), changes, cancel, nil
+}
+
+// tableOf returns id of the table, where gamer is seated.
+func (st *seats) tableOf(id int) (int, bool) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	gamerSeat, ok := st.byID[id]
+	if !ok {
+		return 0, false
+	}
+	return gamerSeat.table.id, true
+}
+
+// playerOf returns id of any gamer seated in the game with tableID.
+func (st *seats) playerOf(tableID int) (int, bool) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	for _, gameTable := range st.tables {
+		if gameTable.id == tableID && len(gameTable.seats) > 0 {
+			return gameTable.seats[0].id, true
+		}
+	}
+	return 0, false
+}
+
+// describeTable must be called with locked mutex.
+func describeTable(gameTable *table) tableInfo {
+	info := tableInfo{
+		id:         gameTable.id,
+		game:       gameTable.game,
+		playerID:   gameTable.seats[0].id,
+		spectators: len(gameTable.spectators),
+	}
+	for _, gamerSeat := range gameTable.seats {
+		info.players = append(info.players, gamerSeat.login)
+	}
+	return info
+}
+
+// notifySpectators must be called with locked mutex.
+func (st *seats) notifySpectators(gameTable *table) {
+	for changes := range gameTable.spectators {
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// closeSpectators must be called with locked mutex.
+func (st *seats) closeSpectators(gameTable *table) {
+	for changes := range gameTable.spectators {
+		delete(gameTable.spectators, changes)
+		close(changes)
+	}
+}
+
+// partnerID returns id and login of gamer's partner.
+func (st *seats) partnerID(id int) (int, string, bool) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	gamerSeat, ok := st.byID[id]
+	if !ok {
+		return 0, "", false
+	}
+	if partnerSeat := st.partnerSeat(gamerSeat); partnerSeat != nil {
+		return partnerSeat.id, partnerSeat.login, true
+	}
+	return 0, "", false
+}
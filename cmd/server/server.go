@@ -18,12 +18,16 @@ package server
 
 import (
 	"context"
+	"crypto/x509"
 	"strings"
+	"time"
 
 	"github.com/yagoggame/grpc_server/interfaces"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 )
 
@@ -36,25 +40,77 @@ var (
 
 // IniDataContainer is a container of initial data to run server.
 type IniDataContainer struct {
-	Port       int
-	IP         string
-	CertFile   string
-	KeyFile    string
-	Authorizer string
-	Filename   string
-	DBHost     string
-	DBPort     int
-	DBName     string
-	DBUser     string
-	DBPassword string
+	Port               int
+	IP                 string
+	Listen             []string
+	SocketMode         string
+	SocketOwner        string
+	SocketInsecure     bool
+	CertFile           string
+	KeyFile            string
+	CertReload         bool
+	Gateway            string
+	GRPCWeb            bool
+	WebSocket          bool
+	CORSOrigins        []string
+	Reflection         bool
+	Metrics            string
+	Insecure           bool
+	ForceInsecure      bool
+	SelfSigned         bool
+	SelfSignedFile     string
+	ClientCAFile       string
+	ClientCertRequired bool
+	CertAuth           bool
+	GracePeriod        time.Duration
+	SpectatorLimit     int
+	ChallengeTTL       time.Duration
+	MatchWindow        float64
+	MatchWidening      float64
+	MatchMaxWindow     float64
+	RatingsFile        string
+	RatingTau          float64
+	ChatLength         int
+	ChatHistory        int
+	ChatRate           float64
+	ChatBurst          int
+	ChatBlocklist      string
+	Authorizer         string
+	Chain              []string
+	ChainWritable      string
+	CacheTTL           time.Duration
+	CacheSize          int
+	Filename           string
+	Backups            int
+	Watch              bool
+	Encrypted          bool
+	FileKey            string
+	DBHost             string
+	DBPort             int
+	DBName             string
+	DBUser             string
+	DBPassword         string
+	LDAPAddress        string
+	LDAPTLS            bool
+	LDAPUserDN         string
+	LDAPIDAttribute    string
+	LDAPPasswordModify bool
+	LDAPBindDN         string
+	LDAPBindPassword   string
 }
 
+// reflectionPrefix is a prefix of methods of server reflection service.
+// Reflection describes services to tools, like grpcurl,
+// so it is served without authentication.
+const reflectionPrefix = "/grpc.reflection."
+
 // private type for Context keys.
 type contextKey int
 
 // set of context keys
 const (
 	clientIDKey contextKey = iota
+	clientLoginKey
 )
 
 // authenticateAgent checks the client credentials.
@@ -75,6 +131,57 @@ func authenticateClient(ctx context.Context, s *Server) (int, error) {
 	return id, nil
 }
 
+// identifyClient gets id of client, authenticated by TLS certificate, with login.
+func identifyClient(login string, s *Server) (int, error) {
+	identifier, ok := s.authorizator.(interfaces.Identifier)
+	if !ok {
+		return 0, status.Error(codes.Unauthenticated, interfaces.ErrNotIdentifier.Error())
+	}
+
+	id, err := identifier.Identify(login)
+	if err != nil {
+		return 0, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return id, nil
+}
+
+// certificateLogin returns login of client from his verified TLS certificate.
+// Login is taken from subject's common name or, if it is empty,
+// from the first DNS name, email address or URI of subject alternative names.
+func certificateLogin(ctx context.Context) (string, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) < 1 || len(tlsInfo.State.VerifiedChains[0]) < 1 {
+		return "", false
+	}
+
+	login := certificateIdentity(tlsInfo.State.VerifiedChains[0][0])
+	return login, len(login) > 0
+}
+
+func certificateIdentity(cert *x509.Certificate) string {
+	switch {
+	case len(cert.Subject.CommonName) > 0:
+		return cert.Subject.CommonName
+	case len(cert.DNSNames) > 0:
+		return cert.DNSNames[0]
+	case len(cert.EmailAddresses) > 0:
+		return cert.EmailAddresses[0]
+	case len(cert.URIs) > 0:
+		return cert.URIs[0].String()
+	}
+	return ""
+}
+
+// hasLogin reports whether client provided login in metadata.
+func hasLogin(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	return ok && len(strings.Join(md["login"], "")) > 0
+}
+
 // authenticateAgent checks the client credentials.
 func registerClient(ctx context.Context, s *Server) error {
 	md, ok := metadata.FromIncomingContext(ctx)
@@ -95,18 +202,73 @@ func registerClient(ctx context.Context, s *Server) error {
 }
 
 // UnaryInterceptor calls authenticateClient with current context.
+// Calls of server reflection are passed through.
 func UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if strings.HasPrefix(info.FullMethod, reflectionPrefix) {
+		return handler(ctx, req)
+	}
+
 	s, ok := info.Server.(*Server)
 	if !ok {
 		return nil, ErrServerCast
 	}
 
-	if strings.HasSuffix(info.FullMethod, "RegisterUser") {
+	ctx, err := authenticate(ctx, s, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// StreamInterceptor authenticates client of streaming call as UnaryInterceptor does.
+// Calls of server reflection are passed through.
+func StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if strings.HasPrefix(info.FullMethod, reflectionPrefix) {
+		return handler(srv, ss)
+	}
+
+	s, ok := srv.(*Server)
+	if !ok {
+		return ErrServerCast
+	}
+
+	ctx, err := authenticate(ss.Context(), s, info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+}
+
+// authenticatedStream replaces context of stream with authenticated one.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *authenticatedStream) Context() context.Context {
+	return stream.ctx
+}
+
+// authenticate returns ctx with id of client calling fullMethod.
+func authenticate(ctx context.Context, s *Server, fullMethod string) (context.Context, error) {
+	if strings.HasSuffix(fullMethod, "RegisterUser") {
 		err := registerClient(ctx, s)
 		if err != nil {
 			return nil, err
 		}
-		return handler(ctx, req)
+		return ctx, nil
+	}
+
+	// clients with verified certificate need no password,
+	// unless they explicitly provide requisites
+	if login, ok := certificateLogin(ctx); ok && s.certAuth && !hasLogin(ctx) {
+		clientID, err := identifyClient(login, s)
+		if err != nil {
+			return nil, err
+		}
+
+		ctx = context.WithValue(ctx, clientLoginKey, login)
+		return context.WithValue(ctx, clientIDKey, clientID), nil
 	}
 
 	clientID, err := authenticateClient(ctx, s)
@@ -114,6 +276,5 @@ func UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServ
 		return nil, err
 	}
 
-	ctx = context.WithValue(ctx, clientIDKey, clientID)
-	return handler(ctx, req)
+	return context.WithValue(ctx, clientIDKey, clientID), nil
 }
@@ -0,0 +1,430 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/yagoggame/gomaster/game"
+	"github.com/yagoggame/gomaster/game/igame"
+	"github.com/yagoggame/grpc_server/handicap"
+)
+
+// maxColourAttempts limits creations of a game, where the first gamer
+// doesn't get the chosen colour.
+const maxColourAttempts = 64
+
+// errNoMove occurs when the last move of game isn't a move of gamer.
+var errNoMove = errors.New("no move of gamer to take back")
+
+// serverMove is a move recorded by serverGame.
+type serverMove struct {
+	id   int
+	turn igame.TurnData
+}
+
+// serverGame is a private game, whose moves are recorded by the server.
+// The engine has no takeback, so a move is taken back by replay
+// of the others on a new engine, which replaces the old one.
+//
+// The engine has no handicap either, so handicap stones of Black are kept
+// by serverGame and colours of the engine are swapped in handicap games:
+// White moves first as Black of the engine. The board of engine has no captures,
+// so stones of engine never take liberties of handicap ones.
+type serverGame struct {
+	mutex sync.Mutex
+	// newEngine creates an empty engine for replay
+	newEngine func() (managedGame, error)
+	engine    managedGame
+	// gamers are in the order of joining, the first one keeps his colour on replay
+	gamers []*game.Gamer
+	moves  []serverMove
+	// komi is reported by GameState, the engine doesn't report it
+	komi float64
+	// Black gets fixed stones on star points or places free ones by his first moves,
+	// stones are handicap stones on the board
+	fixed  []igame.TurnData
+	free   int
+	stones []igame.TurnData
+	// changed is closed and replaced on changes, which the engine doesn't report:
+	// the end of placement of free stones, leaving, end and takeback
+	changed chan struct{}
+}
+
+// newServerGame creates a game of terms with joined gamer on engines created by newEngine.
+// The gamer gets colour of terms, if it is chosen.
+func newServerGame(newEngine func() (managedGame, error), gamer *game.Gamer, t terms) (*serverGame, error) {
+	g := &serverGame{
+		newEngine: newEngine,
+		gamers:    []*game.Gamer{gamer},
+		komi:      t.komi,
+		changed:   make(chan struct{}),
+	}
+	if err := g.setHandicap(t); err != nil {
+		return nil, extGrpcError(ErrWrongChallenge, err.Error())
+	}
+	engine, err := joinedGame(newEngine, g.colour(t.colour), gamer)
+	if err != nil {
+		return nil, err
+	}
+	g.engine = engine
+	return g, nil
+}
+
+// setHandicap gives handicap stones of terms to Black on star points
+// or lets him place them by his first moves.
+func (g *serverGame) setHandicap(t terms) error {
+	if t.handicap == 0 {
+		return nil
+	}
+	if t.free {
+		if err := handicap.Check(t.handicap); err != nil {
+			return err
+		}
+		g.free = t.handicap
+		return nil
+	}
+	stones, err := handicap.Fixed(t.size, t.handicap)
+	if err != nil {
+		return err
+	}
+	g.fixed = stones
+	g.stones = append([]igame.TurnData(nil), stones...)
+	return nil
+}
+
+// joinedGame creates a game by newGame, where the first of gamers gets colour
+// and the second one gets the opposite colour. The game gives a random colour
+// to the first gamer, so it is created anew, until he gets the chosen one.
+// The colour isn't chosen, if it is igame.NoColour.
+func joinedGame(newGame func() (managedGame, error), colour igame.ChipColour, gamers ...*game.Gamer) (managedGame, error) {
+	for attempt := 0; attempt < maxColourAttempts; attempt++ {
+		g, err := newGame()
+		if err != nil {
+			return nil, err
+		}
+		if err := g.Join(gamers[0]); err != nil {
+			_ = g.End()
+			return nil, extGrpcError(ErrWrongChallenge, err.Error())
+		}
+		if state, err := g.GamerState(gamers[0].ID); colour != igame.NoColour && (err != nil || state.Colour != colour) {
+			_ = g.End()
+			continue
+		}
+		for _, gamer := range gamers[1:] {
+			if err := g.Join(gamer); err != nil {
+				_ = g.End()
+				return nil, err
+			}
+		}
+		return g, nil
+	}
+	return nil, extGrpcError(ErrColourChoice, fmt.Sprintf("gamer with id %d", gamers[0].ID))
+}
+
+// Undo takes back the last move of gamer: the other moves are replayed
+// on a new engine, where gamers keep their colours.
+func (g *serverGame) Undo(id int) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if _, err := g.engine.IsMyTurn(id); err != nil {
+		return err
+	}
+	last := len(g.moves) - 1
+	if last < 0 || g.moves[last].id != id {
+		return errNoMove
+	}
+	first, err := g.engine.GamerState(g.gamers[0].ID)
+	if err != nil {
+		return err
+	}
+
+	engine, err := joinedGame(g.newEngine, first.Colour, g.gamers...)
+	if err != nil {
+		return err
+	}
+	old, stones := g.engine, g.stones
+	g.engine, g.stones = engine, append([]igame.TurnData(nil), g.fixed...)
+	for _, m := range g.moves[:last] {
+		turn := m.turn
+		if err := g.makeTurn(m.id, &turn); err != nil {
+			_ = engine.End()
+			g.engine, g.stones = old, stones
+			return err
+		}
+	}
+	g.moves = g.moves[:last]
+	// waiters of the old engine are woken up by its end and wait on the new one
+	g.change()
+	_ = old.End()
+	return nil
+}
+
+func (g *serverGame) Join(gamer *game.Gamer) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if err := g.engine.Join(gamer); err != nil {
+		return err
+	}
+	copied := *gamer
+	g.gamers = append(g.gamers, &copied)
+	return nil
+}
+
+func (g *serverGame) MakeTurn(id int, turn *igame.TurnData) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if err := g.makeTurn(id, turn); err != nil {
+		return err
+	}
+	g.moves = append(g.moves, serverMove{id: id, turn: *turn})
+	return nil
+}
+
+func (g *serverGame) WaitBegin(ctx context.Context, id int) error {
+	return g.wait(func(engine managedGame) error { return engine.WaitBegin(ctx, id) })
+}
+
+// WaitTurn waits on the engine, but White waits on the game,
+// while Black places free handicap stones.
+func (g *serverGame) WaitTurn(ctx context.Context, id int) error {
+	for waited := false; ; waited = true {
+		g.mutex.Lock()
+		placing, changed := g.placing(), g.changed
+		var myTurn bool
+		var err error
+		if placing {
+			myTurn, err = g.placingTurn(id)
+		}
+		g.mutex.Unlock()
+
+		if !placing {
+			return g.wait(func(engine managedGame) error { return engine.WaitTurn(ctx, id) })
+		}
+		if waited && errors.Is(err, game.ErrGameOver) {
+			return game.ErrOtherGamerLeft
+		}
+		if err != nil || myTurn {
+			return err
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return game.ErrCancellation
+		}
+	}
+}
+
+func (g *serverGame) IsGameBegun(id int) (bool, error) {
+	return g.current().IsGameBegun(id)
+}
+
+func (g *serverGame) IsMyTurn(id int) (bool, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.placing() {
+		return g.placingTurn(id)
+	}
+	return g.engine.IsMyTurn(id)
+}
+
+func (g *serverGame) FieldSize(id int) (int, error) {
+	return g.current().FieldSize(id)
+}
+
+// GameState adds komi and handicap stones to the state of engine.
+func (g *serverGame) GameState(id int) (*igame.FieldState, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	state, err := g.engine.GameState(id)
+	if err != nil {
+		return nil, err
+	}
+	if g.handicapped() {
+		swapColours(state)
+		state.ChipsInCup[igame.Black] -= len(g.stones)
+		stones := state.ChipsOnBoard[igame.Black]
+		for i := range g.stones {
+			stone := g.stones[i]
+			stones = append(stones, &stone)
+		}
+		sort.Slice(stones, func(i, j int) bool {
+			if stones[i].X != stones[j].X {
+				return stones[i].X < stones[j].X
+			}
+			return stones[i].Y < stones[j].Y
+		})
+		state.ChipsOnBoard[igame.Black] = stones
+	}
+	state.Komi = g.komi
+	state.Scores[igame.White] += g.komi
+	return state, nil
+}
+
+func (g *serverGame) GamerState(id int) (*game.GamerState, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	state, err := g.engine.GamerState(id)
+	if err != nil {
+		return state, err
+	}
+	state.Colour = g.colour(state.Colour)
+	return state, nil
+}
+
+func (g *serverGame) Leave(id int) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if err := g.engine.Leave(id); err != nil {
+		return err
+	}
+	g.change()
+	return nil
+}
+
+func (g *serverGame) End() error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if err := g.engine.End(); err != nil {
+		return err
+	}
+	g.change()
+	return nil
+}
+
+// current returns the engine of game.
+func (g *serverGame) current() managedGame {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.engine
+}
+
+// wait waits on the engine again, if it is replaced during waiting.
+func (g *serverGame) wait(wait func(engine managedGame) error) error {
+	for {
+		engine := g.current()
+		err := wait(engine)
+		if err == nil || g.current() == engine {
+			return err
+		}
+	}
+}
+
+// makeTurn places a free handicap stone of Black or makes the move on the engine,
+// it must be called with locked mutex.
+func (g *serverGame) makeTurn(id int, turn *igame.TurnData) error {
+	if g.placing() {
+		return g.place(id, turn)
+	}
+	if myTurn, err := g.engine.IsMyTurn(id); err == nil && myTurn && g.occupied(*turn) {
+		return fmt.Errorf("gamer with id %d: %w: %v is occupied by handicap stone", id, game.ErrWrongTurn, *turn)
+	}
+	return g.engine.MakeTurn(id, turn)
+}
+
+// place places a free handicap stone of Black, it must be called with locked mutex.
+func (g *serverGame) place(id int, turn *igame.TurnData) error {
+	myTurn, err := g.placingTurn(id)
+	if err != nil {
+		return err
+	}
+	if !myTurn {
+		return fmt.Errorf("gamer with id %d: %w", id, game.ErrNotYourTurn)
+	}
+	size, err := g.engine.FieldSize(id)
+	if err != nil {
+		return err
+	}
+	if turn.X < 1 || turn.Y < 1 || turn.X > size || turn.Y > size || g.occupied(*turn) {
+		return fmt.Errorf("gamer with id %d: %w: handicap stone %v", id, game.ErrWrongTurn, *turn)
+	}
+	g.stones = append(g.stones, *turn)
+	if !g.placing() {
+		g.change()
+	}
+	return nil
+}
+
+// placingTurn returns true for Black, who places free handicap stones,
+// it must be called with locked mutex.
+func (g *serverGame) placingTurn(id int) (bool, error) {
+	if _, err := g.engine.IsMyTurn(id); err != nil {
+		return false, err
+	}
+	state, err := g.engine.GamerState(id)
+	if err != nil {
+		return false, err
+	}
+	return g.colour(state.Colour) == igame.Black, nil
+}
+
+// handicapped reports, whether Black has handicap, it must be called with locked mutex.
+func (g *serverGame) handicapped() bool {
+	return len(g.fixed)+g.free > 0
+}
+
+// placing reports, whether Black still places free handicap stones,
+// it must be called with locked mutex.
+func (g *serverGame) placing() bool {
+	return len(g.stones) < len(g.fixed)+g.free
+}
+
+// occupied must be called with locked mutex.
+func (g *serverGame) occupied(point igame.TurnData) bool {
+	for _, stone := range g.stones {
+		if stone == point {
+			return true
+		}
+	}
+	return false
+}
+
+// colour converts colour of the engine to the colour of game and vice versa.
+// It is set on creation, so it may be called without locked mutex.
+func (g *serverGame) colour(colour igame.ChipColour) igame.ChipColour {
+	if g.handicapped() {
+		return opposite(colour)
+	}
+	return colour
+}
+
+// change must be called with locked mutex.
+func (g *serverGame) change() {
+	close(g.changed)
+	g.changed = make(chan struct{})
+}
+
+// swapColours swaps colours of engine in state.
+func swapColours(state *igame.FieldState) {
+	state.ChipsInCup[igame.Black], state.ChipsInCup[igame.White] = state.ChipsInCup[igame.White], state.ChipsInCup[igame.Black]
+	state.ChipsCuptured[igame.Black], state.ChipsCuptured[igame.White] = state.ChipsCuptured[igame.White], state.ChipsCuptured[igame.Black]
+	state.PointsUnderControl[igame.Black], state.PointsUnderControl[igame.White] = state.PointsUnderControl[igame.White], state.PointsUnderControl[igame.Black]
+	state.Scores[igame.Black], state.Scores[igame.White] = state.Scores[igame.White], state.Scores[igame.Black]
+	state.ChipsOnBoard[igame.Black], state.ChipsOnBoard[igame.White] = state.ChipsOnBoard[igame.White], state.ChipsOnBoard[igame.Black]
+}
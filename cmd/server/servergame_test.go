@@ -0,0 +1,123 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yagoggame/gomaster/game"
+	"github.com/yagoggame/gomaster/game/igame"
+	"github.com/yagoggame/grpc_server/handicap"
+)
+
+// undoMoves are moves of White Joe with id 1 and Black Nick with id 2.
+var undoMoves = []struct {
+	id   int
+	turn igame.TurnData
+}{
+	{id: 2, turn: igame.TurnData{X: 1, Y: 1}},
+	{id: 1, turn: igame.TurnData{X: 2, Y: 2}},
+	{id: 2, turn: igame.TurnData{X: 3, Y: 3}},
+}
+
+func TestServerGameUndo(t *testing.T) {
+	inv := newInvitations(func(int) {})
+	managed, err := inv.newColouredGame(1, "Joe", terms{size: 9, komi: standartKomi, colour: igame.White})
+	if err != nil {
+		t.Fatalf("Unexpected newColouredGame err: %v", err)
+	}
+	g := managed.(*serverGame)
+	defer g.End()
+	if err := g.Join(&game.Gamer{Name: "Nick", ID: 2}); err != nil {
+		t.Fatalf("Unexpected Join err: %v", err)
+	}
+	for _, move := range undoMoves {
+		if err := g.MakeTurn(move.id, &move.turn); err != nil {
+			t.Fatalf("Unexpected MakeTurn err: %v", err)
+		}
+	}
+
+	// Nick waits on the engine, which is replaced by takeback of his move
+	waited := make(chan error, 1)
+	go func() { waited <- g.WaitTurn(context.Background(), 2) }()
+
+	if err := g.Undo(1); !errors.Is(err, errNoMove) {
+		t.Errorf("Unexpected Undo of partner's move err:\nwant: %v,\ngot: %v.", errNoMove, err)
+	}
+	if err := g.Undo(2); err != nil {
+		t.Fatalf("Unexpected Undo err: %v", err)
+	}
+	if err := <-waited; err != nil {
+		t.Errorf("Unexpected WaitTurn err: %v", err)
+	}
+
+	testGamerColour(t, g, 1, igame.White)
+	testGamerColour(t, g, 2, igame.Black)
+	if myTurn, err := g.IsMyTurn(2); err != nil || !myTurn {
+		t.Errorf("Unexpected IsMyTurn after takeback: %v, %v", myTurn, err)
+	}
+	state, err := g.GameState(2)
+	if err != nil {
+		t.Fatalf("Unexpected GameState err: %v", err)
+	}
+	if len(state.ChipsOnBoard[igame.Black]) != 1 || *state.ChipsOnBoard[igame.Black][0] != undoMoves[0].turn ||
+		len(state.ChipsOnBoard[igame.White]) != 1 || *state.ChipsOnBoard[igame.White][0] != undoMoves[1].turn {
+		t.Errorf("Unexpected stones after takeback:\nblack: %v,\nwhite: %v.", state.ChipsOnBoard[igame.Black], state.ChipsOnBoard[igame.White])
+	}
+	if err := g.MakeTurn(2, &undoMoves[2].turn); err != nil {
+		t.Errorf("Unexpected MakeTurn to the point of taken back move err: %v", err)
+	}
+
+	if err := g.Leave(1); err != nil {
+		t.Fatalf("Unexpected Leave err: %v", err)
+	}
+	if err := g.Undo(2); !errors.Is(err, game.ErrGameOver) {
+		t.Errorf("Unexpected Undo after leaving err:\nwant: %v,\ngot: %v.", game.ErrGameOver, err)
+	}
+}
+
+func TestServerGameUndoPlacement(t *testing.T) {
+	inv := newInvitations(func(int) {})
+	// Joe with id 1 is Black, Nick with id 2 is White
+	g := newJoinedGame(t, inv, terms{size: 9, komi: handicap.Komi, colour: igame.Black, handicap: 2, free: true})
+	defer g.End()
+	for _, stone := range []igame.TurnData{{X: 1, Y: 1}, {X: 2, Y: 2}} {
+		if err := g.MakeTurn(1, &stone); err != nil {
+			t.Fatalf("Unexpected placement err: %v", err)
+		}
+	}
+
+	if err := g.(*serverGame).Undo(1); err != nil {
+		t.Fatalf("Unexpected Undo err: %v", err)
+	}
+	testGamerColour(t, g, 1, igame.Black)
+	if myTurn, err := g.IsMyTurn(1); err != nil || !myTurn {
+		t.Errorf("Unexpected IsMyTurn of Black after takeback of placement: %v, %v", myTurn, err)
+	}
+	state, err := g.GameState(1)
+	if err != nil || len(state.ChipsOnBoard[igame.Black]) != 1 {
+		t.Errorf("Unexpected state after takeback of placement: %+v, %v", state, err)
+	}
+	if err := g.MakeTurn(1, &igame.TurnData{X: 3, Y: 3}); err != nil {
+		t.Errorf("Unexpected placement after takeback err: %v", err)
+	}
+	if myTurn, err := g.IsMyTurn(2); err != nil || !myTurn {
+		t.Errorf("Unexpected IsMyTurn of White after placement: %v, %v", myTurn, err)
+	}
+}
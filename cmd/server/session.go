@@ -0,0 +1,294 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/yagoggame/api"
+	"github.com/yagoggame/grpc_server/interfaces"
+	"github.com/yagoggame/grpc_server/matchmaking"
+	"github.com/yagoggame/grpc_server/serverapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrNoGame occurs when gamer has no game to resume or to manage
+	ErrNoGame = status.Errorf(codes.FailedPrecondition, "gamer is not in a game")
+	// ErrWrongJoin occurs when JoinGame request is invalid
+	ErrWrongJoin = status.Errorf(codes.InvalidArgument, "wrong request to join a game")
+)
+
+// GetGameState returns current status of gamer and state of his game, if any.
+// Unlike JoinTheGame and WaitTheTurn it never waits.
+func (s *Server) GetGameState(ctx context.Context, in *api.EmptyMessage) (*serverapi.GameStatusMessage, error) {
+	id, err := idFromCtx(ctx)
+	if err != nil {
+		log.Printf("GetGameState error: %s", err)
+		return &serverapi.GameStatusMessage{}, err
+	}
+
+	if _, err := s.pool.GetGamer(id); err != nil {
+		return &serverapi.GameStatusMessage{Status: serverapi.GameStatus_NOT_IN_LOBBY}, nil
+	}
+
+	gameManager, err := s.gameGeter.GetGame(id)
+	if err != nil {
+		log.Printf("GetGameState error: %s", err)
+		return &serverapi.GameStatusMessage{}, err
+	}
+	if gameManager == nil {
+		return &serverapi.GameStatusMessage{Status: serverapi.GameStatus_IN_LOBBY}, nil
+	}
+
+	gameStatus, err := s.gameStatus(gameManager, id)
+	if err != nil {
+		log.Printf("GetGameState error: %s", err)
+		return &serverapi.GameStatusMessage{}, err
+	}
+	return gameStatus, nil
+}
+
+// JoinGame joins a gamer to another gamer or starts a game and waits of another gamer
+// as JoinTheGame does. The pool gives random colours and even games, so gamers,
+// who prefer a colour or a handicap, are paired by the queue of matchmaking
+// with gamers of the same handicap and play a private game.
+// Conflicting preferences of colour are resolved by nigiri.
+func (s *Server) JoinGame(ctx context.Context, in *serverapi.JoinRequest) (*serverapi.GameStatusMessage, error) {
+	gamer, err := userFromContext(ctx)
+	if err != nil {
+		log.Printf("JoinGame error: %s", err)
+		return &serverapi.GameStatusMessage{}, err
+	}
+	id := gamer.ID
+
+	request, colour, err := joinRequest(in)
+	if err != nil {
+		log.Printf("JoinGame error: %s", err)
+		return &serverapi.GameStatusMessage{}, err
+	}
+
+	if colour == matchmaking.Random && request.Handicap == 0 {
+		_, err = s.joinGame(ctx, id, terms{size: request.Size, komi: standartKomi})
+	} else {
+		err = s.seekGame(ctx, matchmaking.Seeker{
+			ID:      id,
+			Login:   gamer.Name,
+			Rating:  s.rating(id),
+			Request: request,
+			Colour:  colour,
+		})
+	}
+	if err != nil {
+		log.Printf("JoinGame error: %s", err)
+		return &serverapi.GameStatusMessage{}, err
+	}
+
+	gameManager, err := s.gameGeter.GetGame(id)
+	if err == nil && gameManager == nil {
+		err = extGrpcError(ErrNilGame, fmt.Sprintf(" with id %d", id))
+	}
+	if err != nil {
+		log.Printf("JoinGame error: %s", err)
+		return &serverapi.GameStatusMessage{}, err
+	}
+
+	gameStatus, err := s.gameStatus(gameManager, id)
+	if err != nil {
+		log.Printf("JoinGame error: %s", err)
+		return &serverapi.GameStatusMessage{}, err
+	}
+
+	log.Printf("game for gamer with id %d has been begun", id)
+	return gameStatus, nil
+}
+
+// WaitTurn waits for gamers turn as WaitTheTurn does.
+func (s *Server) WaitTurn(ctx context.Context, in *api.EmptyMessage) (*serverapi.GameStatusMessage, error) {
+	id, err := idFromCtx(ctx)
+	if err != nil {
+		log.Printf("WaitTurn error: %s", err)
+		return &serverapi.GameStatusMessage{}, err
+	}
+
+	gameManager, _, err := s.awaitTurn(ctx, id)
+	if err != nil {
+		log.Printf("WaitTurn error: %s", err)
+		return &serverapi.GameStatusMessage{}, err
+	}
+
+	gameStatus, err := s.gameStatus(gameManager, id)
+	if err != nil {
+		log.Printf("WaitTurn error: %s", err)
+		return &serverapi.GameStatusMessage{}, err
+	}
+
+	log.Printf("turn of gamer with id %d has been begun", id)
+	return gameStatus, nil
+}
+
+// gameStatus returns status of gamer in his game and the state of it.
+func (s *Server) gameStatus(gameManager interfaces.GameManager, id int) (*serverapi.GameStatusMessage, error) {
+	state, err := s.getGameState(gameManager, id)
+	if err != nil {
+		return nil, err
+	}
+	colour := colourOf(gameManager, id)
+	partner, _ := s.seats.partner(id)
+	if state.GameOver {
+		return &serverapi.GameStatusMessage{Status: serverapi.GameStatus_GAME_OVER, State: state, Colour: colour, Partner: partner}, nil
+	}
+
+	begun, err := gameManager.IsGameBegun(id)
+	if err != nil {
+		return nil, extGrpcError(ErrGameState, fmt.Sprintf("user with id %d on IsGameBegun: %v", id, err))
+	}
+	if !begun {
+		return &serverapi.GameStatusMessage{Status: serverapi.GameStatus_AWAITING_PARTNER, State: state, Colour: colour}, nil
+	}
+
+	myTurn, err := gameManager.IsMyTurn(id)
+	if err != nil {
+		return nil, extGrpcError(ErrGameState, fmt.Sprintf("user with id %d on IsMyTurn: %v", id, err))
+	}
+
+	gameStatus := &serverapi.GameStatusMessage{
+		Status:  serverapi.GameStatus_PLAYING,
+		State:   state,
+		MyTurn:  myTurn,
+		Colour:  colour,
+		Partner: partner,
+	}
+	gameStatus.UndoRequested = s.histories.requested(gameManager, id)
+	if mine, partner, ok := s.invitations.clocks(id); ok {
+		gameStatus.TimeLeft = mine.Milliseconds()
+		gameStatus.PartnerTimeLeft = partner.Milliseconds()
+	}
+	return gameStatus, nil
+}
+
+// joinRequest converts JoinRequest to the request of a game of standard size.
+func joinRequest(in *serverapi.JoinRequest) (matchmaking.Request, matchmaking.Colour, error) {
+	request, colour, err := seekRequest(&serverapi.SeekRequest{
+		Colour:        in.GetColour(),
+		Handicap:      in.GetHandicap(),
+		FreePlacement: in.GetFreePlacement(),
+	})
+	if err != nil {
+		return matchmaking.Request{}, matchmaking.Random, extGrpcError(ErrWrongJoin, status.Convert(err).Message())
+	}
+	return request, colour, nil
+}
+
+// ResumeGame returns the game of gamer, whose connection was interrupted.
+// If the game hasn't begun yet, it awaits the begin as JoinTheGame does.
+func (s *Server) ResumeGame(ctx context.Context, in *api.EmptyMessage) (*serverapi.ResumeMessage, error) {
+	id, err := idFromCtx(ctx)
+	if err != nil {
+		log.Printf("ResumeGame error: %s", err)
+		return &serverapi.ResumeMessage{}, err
+	}
+
+	gameManager, err := s.gameGeter.GetGame(id)
+	if err != nil {
+		log.Printf("ResumeGame error: %s", err)
+		return &serverapi.ResumeMessage{}, err
+	}
+	if gameManager == nil {
+		err = extGrpcError(ErrNoGame, fmt.Sprintf("gamer with id %d", id))
+		log.Printf("ResumeGame error: %s", err)
+		return &serverapi.ResumeMessage{}, err
+	}
+
+	if login, err := loginFromContext(ctx); err == nil {
+		s.seats.take(id, login, gameManager)
+	}
+	s.seats.reconnect(id)
+
+	if err := gameManager.WaitBegin(ctx, id); err != nil {
+		s.disconnect(ctx, id)
+		log.Printf("ResumeGame error: %s", err)
+		return &serverapi.ResumeMessage{}, err
+	}
+
+	state, err := s.getGameState(gameManager, id)
+	if err != nil {
+		log.Printf("ResumeGame error: %s", err)
+		return &serverapi.ResumeMessage{}, err
+	}
+
+	myTurn, err := gameManager.IsMyTurn(id)
+	if err != nil {
+		err = extGrpcError(ErrGameState, fmt.Sprintf("user with id %d on IsMyTurn: %v", id, err))
+		log.Printf("ResumeGame error: %s", err)
+		return &serverapi.ResumeMessage{}, err
+	}
+
+	partner, connected := s.seats.partner(id)
+	log.Printf("gamer with id %d resumed his game", id)
+
+	return &serverapi.ResumeMessage{
+		State:            state,
+		MyTurn:           myTurn,
+		Partner:          partner,
+		PartnerConnected: connected,
+		Colour:           colourOf(gameManager, id),
+	}, nil
+}
+
+// WatchPartner sends current state of gamer's partner
+// and then his disconnections, reconnections and steps of takeback, until gamer leaves the game.
+func (s *Server) WatchPartner(in *api.EmptyMessage, stream serverapi.Session_WatchPartnerServer) error {
+	ctx := stream.Context()
+	id, err := idFromCtx(ctx)
+	if err != nil {
+		log.Printf("WatchPartner error: %s", err)
+		return err
+	}
+
+	events, cancel, ok := s.seats.watch(id)
+	if !ok {
+		err = extGrpcError(ErrNoGame, fmt.Sprintf("gamer with id %d", id))
+		log.Printf("WatchPartner error: %s", err)
+		return err
+	}
+	defer cancel()
+
+	partner, connected := s.seats.partner(id)
+	if partner != "" {
+		if err := stream.Send(&serverapi.PartnerEvent{Partner: partner, Connected: connected}); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+}
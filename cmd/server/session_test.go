@@ -0,0 +1,534 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
+	"github.com/yagoggame/api"
+	"github.com/yagoggame/gomaster"
+	"github.com/yagoggame/gomaster/game"
+	"github.com/yagoggame/gomaster/game/igame"
+	"github.com/yagoggame/grpc_server/authorization/dummy"
+	"github.com/yagoggame/grpc_server/interfaces"
+	"github.com/yagoggame/grpc_server/interfaces/mocks"
+	"github.com/yagoggame/grpc_server/serverapi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const testGrace = 300 * time.Millisecond
+
+var seatsTests = []struct {
+	caseName    string
+	reconnect   bool
+	wantRelease bool
+	wantEvents  []serverapi.PartnerEvent
+}{
+	{
+		caseName:    "reconnected",
+		reconnect:   true,
+		wantRelease: false,
+		wantEvents: []serverapi.PartnerEvent{
+			{Partner: "Nick"},
+			{Partner: "Nick", Connected: true},
+		},
+	},
+	{
+		caseName:    "grace period expired",
+		wantRelease: true,
+		wantEvents: []serverapi.PartnerEvent{
+			{Partner: "Nick"},
+			{Partner: "Nick", Left: true},
+		},
+	},
+}
+
+func TestSeats(t *testing.T) {
+	for _, test := range seatsTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			released := make(chan int, 1)
+			st := newSeats(func(id int) { released <- id })
+			game := mocks.NewMockGameManager(controller)
+			st.take(2, "Joe", game)
+			st.take(3, "Nick", game)
+
+			events, cancel, ok := st.watch(2)
+			if !ok {
+				t.Fatalf("Unexpected watch result: no seat")
+			}
+			defer cancel()
+
+			if !st.disconnect(3, testGrace) {
+				t.Fatalf("Unexpected disconnect result: no seat")
+			}
+			if login, connected := st.partner(2); login != "Nick" || connected {
+				t.Errorf("Unexpected partner:\nwant: Nick, false,\ngot: %s, %v.", login, connected)
+			}
+			if test.reconnect {
+				st.reconnect(3)
+			}
+
+			for _, want := range test.wantEvents {
+				testPartnerEvent(t, recvEvent(t, events), &want)
+			}
+
+			select {
+			case id := <-released:
+				if !test.wantRelease || id != 3 {
+					t.Errorf("Unexpected release of gamer with id %d", id)
+				}
+			case <-time.After(2 * testGrace):
+				if test.wantRelease {
+					t.Errorf("Unexpected absence of release")
+				}
+			}
+		})
+	}
+}
+
+var getGameStateTests = []struct {
+	caseName   string
+	ctx        context.Context
+	gamerErr   error
+	nilManager bool
+	gameOver   bool
+	begun      bool
+	begunErr   error
+	myTurn     bool
+	want       *serverapi.GameStatusMessage
+	wantErr    error
+}{
+	{
+		caseName: "No ID",
+		ctx:      userContext(someLogin, somePassword),
+		want:     &serverapi.GameStatusMessage{},
+		wantErr:  ErrGetIDFailed},
+	{
+		caseName: "not in lobby",
+		ctx:      context.WithValue(userContext(someLogin, somePassword), clientIDKey, correctID),
+		gamerErr: errors.New("no such gamer"),
+		want:     &serverapi.GameStatusMessage{Status: serverapi.GameStatus_NOT_IN_LOBBY}},
+	{
+		caseName:   "in lobby",
+		ctx:        context.WithValue(userContext(someLogin, somePassword), clientIDKey, correctID),
+		nilManager: true,
+		want:       &serverapi.GameStatusMessage{Status: serverapi.GameStatus_IN_LOBBY}},
+	{
+		caseName: "awaiting partner",
+		ctx:      context.WithValue(userContext(someLogin, somePassword), clientIDKey, correctID),
+		want:     &serverapi.GameStatusMessage{Status: serverapi.GameStatus_AWAITING_PARTNER}},
+	{
+		caseName: "playing",
+		ctx:      context.WithValue(userContext(someLogin, somePassword), clientIDKey, correctID),
+		begun:    true,
+		myTurn:   true,
+		want:     &serverapi.GameStatusMessage{Status: serverapi.GameStatus_PLAYING, MyTurn: true}},
+	{
+		caseName: "game over",
+		ctx:      context.WithValue(userContext(someLogin, somePassword), clientIDKey, correctID),
+		gameOver: true,
+		want:     &serverapi.GameStatusMessage{Status: serverapi.GameStatus_GAME_OVER}},
+	{
+		caseName: "IsGameBegun error",
+		ctx:      context.WithValue(userContext(someLogin, somePassword), clientIDKey, correctID),
+		begunErr: errors.New("some internal error"),
+		want:     &serverapi.GameStatusMessage{},
+		wantErr:  ErrGameState},
+}
+
+func TestGetGameState(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+
+	for _, test := range getGameStateTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			pooler := mocks.NewMockPooler(controller)
+			gameGeter := mocks.NewMockGameGeter(controller)
+			gameManager := mocks.NewMockGameManager(controller)
+			s := NewServer(mocks.NewMockAuthorizator(controller), pooler, gameGeter)
+
+			var gm interfaces.GameManager
+			if !test.nilManager {
+				gm = gameManager
+			}
+			fieldState := genFieldState()
+			fieldState.GameOver = test.gameOver
+
+			pooler.EXPECT().GetGamer(correctID).Return(&game.Gamer{ID: correctID}, test.gamerErr).MaxTimes(1)
+			gameGeter.EXPECT().GetGame(correctID).Return(gm, nil).MaxTimes(1)
+			gameManager.EXPECT().FieldSize(correctID).Return(usualSize, nil).MaxTimes(1)
+			gameManager.EXPECT().GameState(correctID).Return(fieldState, nil).MaxTimes(1)
+			gameManager.EXPECT().IsGameBegun(correctID).Return(test.begun, test.begunErr).MaxTimes(1)
+			gameManager.EXPECT().IsMyTurn(correctID).Return(test.myTurn, nil).MaxTimes(1)
+
+			got, err := s.GetGameState(test.ctx, &api.EmptyMessage{})
+			testErr(t, err, test.wantErr)
+			if got.GetStatus() != test.want.GetStatus() || got.GetMyTurn() != test.want.GetMyTurn() {
+				t.Errorf("Unexpected status:\nwant: %v,\ngot: %v.", test.want, got)
+			}
+			if got.GetStatus() >= serverapi.GameStatus_AWAITING_PARTNER {
+				testGameState(t, got.GetState(), fieldState)
+				if got.GetColour() != serverapi.Colour_UNKNOWN {
+					t.Errorf("Unexpected colour of game without colours:\nwant: %v,\ngot: %v.", serverapi.Colour_UNKNOWN, got.GetColour())
+				}
+			}
+		})
+	}
+}
+
+func TestJoinGame(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	conn, release := startTestServer(t, 0)
+	defer release()
+	gameClient := api.NewGoGameClient(conn)
+	sessionClient := serverapi.NewSessionClient(conn)
+
+	joe := clientContext("Joe", "aaa")
+	nick := clientContext("Nick", "bbb")
+	replies := joinGameBoth(t, conn, map[context.Context]*serverapi.JoinRequest{joe: {}, nick: {}})
+
+	black, white, blackPartner, whitePartner := joe, nick, "Nick", "Joe"
+	if replies[joe].GetColour() == serverapi.Colour_WHITE {
+		black, white, blackPartner, whitePartner = nick, joe, "Joe", "Nick"
+	}
+	testJoined(t, replies[black], serverapi.Colour_BLACK, blackPartner)
+	testJoined(t, replies[white], serverapi.Colour_WHITE, whitePartner)
+
+	if _, err := gameClient.MakeTurn(black, &api.TurnMessage{X: 3, Y: 3}); err != nil {
+		t.Fatalf("Unexpected MakeTurn err: %v", err)
+	}
+	reply, err := sessionClient.WaitTurn(white, &api.EmptyMessage{})
+	if err != nil {
+		t.Fatalf("Unexpected WaitTurn err: %v", err)
+	}
+	if !reply.GetMyTurn() || reply.GetColour() != serverapi.Colour_WHITE || len(reply.GetState().GetBlack().GetChipsOnBoard()) != 1 {
+		t.Errorf("Unexpected WaitTurn reply: %v", reply)
+	}
+}
+
+var joinGamePreferenceTests = []struct {
+	caseName   string
+	joe, nick  serverapi.Colour
+	joeColours []serverapi.Colour
+}{
+	{caseName: "no conflict", joe: serverapi.Colour_WHITE, nick: serverapi.Colour_BLACK,
+		joeColours: []serverapi.Colour{serverapi.Colour_WHITE}},
+	{caseName: "conflict", joe: serverapi.Colour_BLACK, nick: serverapi.Colour_BLACK,
+		joeColours: []serverapi.Colour{serverapi.Colour_BLACK, serverapi.Colour_WHITE}},
+}
+
+func TestJoinGamePreference(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	for _, test := range joinGamePreferenceTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			conn, release := startTestServer(t, 0)
+			defer release()
+
+			joe := clientContext("Joe", "aaa")
+			nick := clientContext("Nick", "bbb")
+			replies := joinGameBoth(t, conn, map[context.Context]*serverapi.JoinRequest{
+				joe:  {Colour: test.joe},
+				nick: {Colour: test.nick},
+			})
+
+			colour := replies[joe].GetColour()
+			if !containsColour(test.joeColours, colour) {
+				t.Fatalf("Unexpected colour of Joe:\nwant: one of %v,\ngot: %v.", test.joeColours, colour)
+			}
+			testJoined(t, replies[joe], colour, "Nick")
+			testJoined(t, replies[nick], serverapi.Colour(opposite(igame.ChipColour(colour))), "Joe")
+		})
+	}
+}
+
+func containsColour(colours []serverapi.Colour, colour serverapi.Colour) bool {
+	for _, c := range colours {
+		if c == colour {
+			return true
+		}
+	}
+	return false
+}
+
+var joinGameWrongTests = []struct {
+	caseName string
+	request  *serverapi.JoinRequest
+	want     codes.Code
+}{
+	{caseName: "unknown colour", request: &serverapi.JoinRequest{Colour: serverapi.Colour_UNKNOWN}, want: codes.InvalidArgument},
+	{caseName: "one stone", request: &serverapi.JoinRequest{Handicap: 1}, want: codes.InvalidArgument},
+	{caseName: "too many stones", request: &serverapi.JoinRequest{Handicap: 10}, want: codes.InvalidArgument},
+}
+
+func TestJoinGameWrong(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	conn, release := startTestServer(t, 0)
+	defer release()
+
+	joe := clientContext("Joe", "aaa")
+	if _, err := api.NewGoGameClient(conn).EnterTheLobby(joe, &api.EmptyMessage{}); err != nil {
+		t.Fatalf("Unexpected EnterTheLobby err: %v", err)
+	}
+	for _, test := range joinGameWrongTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			_, err := serverapi.NewSessionClient(conn).JoinGame(joe, test.request)
+			if status.Code(err) != test.want {
+				t.Errorf("Unexpected JoinGame err:\nwant: %v,\ngot: %v.", test.want, err)
+			}
+		})
+	}
+}
+
+// joinGameBoth joins gamers of requests to a game by JoinGame and returns their replies.
+func joinGameBoth(t *testing.T, conn *grpc.ClientConn, requests map[context.Context]*serverapi.JoinRequest) map[context.Context]*serverapi.GameStatusMessage {
+	t.Helper()
+	gameClient := api.NewGoGameClient(conn)
+	sessionClient := serverapi.NewSessionClient(conn)
+
+	type reply struct {
+		ctx    context.Context
+		status *serverapi.GameStatusMessage
+		err    error
+	}
+	results := make(chan reply, len(requests))
+	for ctx, request := range requests {
+		if _, err := gameClient.EnterTheLobby(ctx, &api.EmptyMessage{}); err != nil {
+			t.Fatalf("Unexpected EnterTheLobby err: %v", err)
+		}
+		go func(ctx context.Context, request *serverapi.JoinRequest) {
+			status, err := sessionClient.JoinGame(ctx, request)
+			results <- reply{ctx: ctx, status: status, err: err}
+		}(ctx, request)
+	}
+
+	replies := make(map[context.Context]*serverapi.GameStatusMessage)
+	for range requests {
+		result := <-results
+		if result.err != nil {
+			t.Fatalf("Unexpected JoinGame err: %v", result.err)
+		}
+		replies[result.ctx] = result.status
+	}
+	return replies
+}
+
+func testJoined(t *testing.T, got *serverapi.GameStatusMessage, colour serverapi.Colour, partner string) {
+	t.Helper()
+	if got.GetStatus() != serverapi.GameStatus_PLAYING || got.GetColour() != colour || got.GetPartner() != partner {
+		t.Errorf("Unexpected JoinGame reply:\nwant: %v as %v against %q,\ngot: %v.", serverapi.GameStatus_PLAYING, colour, partner, got)
+	}
+	if got.GetMyTurn() != (colour == serverapi.Colour_BLACK) {
+		t.Errorf("Unexpected MyTurn of %v: %v", colour, got.GetMyTurn())
+	}
+}
+
+func TestResumeMessageMarshal(t *testing.T) {
+	want := &serverapi.ResumeMessage{
+		State:            &api.State{Size: 9, Komi: 6.5, Black: &api.State_ColourState{Scores: 2}},
+		MyTurn:           true,
+		Partner:          "Nick",
+		PartnerConnected: true,
+	}
+
+	data, err := proto.Marshal(want)
+	if err != nil {
+		t.Fatalf("Unexpected Marshal err: %v", err)
+	}
+	got := &serverapi.ResumeMessage{}
+	if err := proto.Unmarshal(data, got); err != nil {
+		t.Fatalf("Unexpected Unmarshal err: %v", err)
+	}
+	if !proto.Equal(got, want) {
+		t.Errorf("Unexpected message:\nwant: %v,\ngot: %v.", want, got)
+	}
+}
+
+func TestResumeInterruptedGame(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	conn, release := startTestServer(t, 0)
+	defer release()
+	gameClient := api.NewGoGameClient(conn)
+	sessionClient := serverapi.NewSessionClient(conn)
+
+	joe := clientContext("Joe", "aaa")
+	nick := clientContext("Nick", "bbb")
+	joinBoth(t, gameClient, joe, nick)
+
+	resumed, err := sessionClient.ResumeGame(joe, &api.EmptyMessage{})
+	if err != nil {
+		t.Fatalf("Unexpected ResumeGame err: %v", err)
+	}
+	if resumed.GetPartner() != "Nick" || !resumed.GetPartnerConnected() || resumed.GetState().GetSize() != usualSize {
+		t.Fatalf("Unexpected ResumeGame result: %v", resumed)
+	}
+
+	// the gamer, who awaits his turn, is disconnected
+	watcher, waiter, waiterLogin := joe, nick, "Nick"
+	if !resumed.GetMyTurn() {
+		watcher, waiter, waiterLogin = nick, joe, "Joe"
+	}
+
+	ctx, cancel := context.WithCancel(watcher)
+	defer cancel()
+	events := watchPartner(t, sessionClient, ctx)
+	testPartnerEvent(t, recvEvent(t, events), &serverapi.PartnerEvent{Partner: waiterLogin, Connected: true})
+
+	interruptWait(t, gameClient, waiter)
+	testPartnerEvent(t, recvEvent(t, events), &serverapi.PartnerEvent{Partner: waiterLogin})
+
+	resumed, err = sessionClient.ResumeGame(waiter, &api.EmptyMessage{})
+	if err != nil {
+		t.Fatalf("Unexpected ResumeGame err: %v", err)
+	}
+	if resumed.GetMyTurn() {
+		t.Errorf("Unexpected ResumeGame MyTurn: %v", resumed.GetMyTurn())
+	}
+	testPartnerEvent(t, recvEvent(t, events), &serverapi.PartnerEvent{Partner: waiterLogin, Connected: true})
+
+	interruptWait(t, gameClient, waiter)
+	testPartnerEvent(t, recvEvent(t, events), &serverapi.PartnerEvent{Partner: waiterLogin})
+	testPartnerEvent(t, recvEvent(t, events), &serverapi.PartnerEvent{Partner: waiterLogin, Left: true})
+
+	_, err = sessionClient.ResumeGame(waiter, &api.EmptyMessage{})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Unexpected ResumeGame after grace period err:\nwant: %v,\ngot: %v.", ErrNoGame, err)
+	}
+
+	gameStatus, err := sessionClient.GetGameState(waiter, &api.EmptyMessage{})
+	if err != nil || gameStatus.GetStatus() != serverapi.GameStatus_IN_LOBBY {
+		t.Errorf("Unexpected GetGameState after grace period:\nwant: %v,\ngot: %v, %v.", serverapi.GameStatus_IN_LOBBY, gameStatus, err)
+	}
+}
+
+func startTestServer(t *testing.T, spectatorLimit int) (*grpc.ClientConn, func()) {
+	lis := bufconn.Listen(1 << 16)
+	pool := gomaster.NewGamersPool()
+	s := NewServer(dummy.New(), pool, NewGameGeter(pool))
+	s.SetGracePeriod(testGrace)
+	s.SetSpectatorLimit(spectatorLimit)
+
+	grpcServer := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryInterceptor),
+		grpc.StreamInterceptor(StreamInterceptor))
+	api.RegisterGoGameServer(grpcServer, s)
+	serverapi.RegisterSessionServer(grpcServer, s)
+	serverapi.RegisterSpectatorServer(grpcServer, s)
+	serverapi.RegisterChatServer(grpcServer, s)
+	serverapi.RegisterLobbyServer(grpcServer, s)
+	serverapi.RegisterChallengeServer(grpcServer, s)
+	serverapi.RegisterMatchmakingServer(grpcServer, s)
+	serverapi.RegisterRatingServer(grpcServer, s)
+	serverapi.RegisterTakebackServer(grpcServer, s)
+	go grpcServer.Serve(lis)
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(),
+		grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+			return lis.Dial()
+		}))
+	if err != nil {
+		t.Fatalf("Unexpected Dial err: %v", err)
+	}
+
+	return conn, func() {
+		conn.Close()
+		grpcServer.Stop()
+		s.Release()
+	}
+}
+
+func clientContext(login, password string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "login", login, "password", password)
+}
+
+func joinBoth(t *testing.T, client api.GoGameClient, ctxs ...context.Context) {
+	errs := make(chan error, len(ctxs))
+	for _, ctx := range ctxs {
+		if _, err := client.EnterTheLobby(ctx, &api.EmptyMessage{}); err != nil {
+			t.Fatalf("Unexpected EnterTheLobby err: %v", err)
+		}
+		go func(ctx context.Context) {
+			_, err := client.JoinTheGame(ctx, &api.EmptyMessage{})
+			errs <- err
+		}(ctx)
+	}
+	for range ctxs {
+		if err := <-errs; err != nil {
+			t.Fatalf("Unexpected JoinTheGame err: %v", err)
+		}
+	}
+}
+
+func interruptWait(t *testing.T, client api.GoGameClient, ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, testGrace/3)
+	defer cancel()
+	if _, err := client.WaitTheTurn(ctx, &api.EmptyMessage{}); status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("Unexpected WaitTheTurn err:\nwant: %v,\ngot: %v.", codes.DeadlineExceeded, err)
+	}
+}
+
+// watchPartner returns the channel of events of WatchPartner stream.
+func watchPartner(t *testing.T, client serverapi.SessionClient, ctx context.Context) <-chan *serverapi.PartnerEvent {
+	stream, err := client.WatchPartner(ctx, &api.EmptyMessage{})
+	if err != nil {
+		t.Fatalf("Unexpected WatchPartner err: %v", err)
+	}
+	events := make(chan *serverapi.PartnerEvent, eventsBuffer)
+	go func() {
+		defer close(events)
+		for {
+			event, err := stream.Recv()
+			if err != nil {
+				return
+			}
+			events <- event
+		}
+	}()
+	return events
+}
+
+func recvEvent(t *testing.T, events <-chan *serverapi.PartnerEvent) *serverapi.PartnerEvent {
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(3 * testGrace):
+		t.Fatalf("Unexpected absence of partner event")
+	}
+	return nil
+}
+
+func testPartnerEvent(t *testing.T, got, want *serverapi.PartnerEvent) {
+	if !proto.Equal(got, want) {
+		t.Errorf("Unexpected partner event:\nwant: %v,\ngot: %v.", want, got)
+	}
+}
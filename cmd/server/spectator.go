@@ -0,0 +1,121 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/yagoggame/api"
+	"github.com/yagoggame/grpc_server/serverapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultSpectatorLimit is a default number of spectators of one game.
+const DefaultSpectatorLimit = 16
+
+var (
+	// ErrNoSuchGame occurs when spectated game doesn't exist or is private
+	ErrNoSuchGame = status.Errorf(codes.NotFound, "no such public game")
+	// ErrSpectatorLimit occurs when game already has maximum of spectators
+	ErrSpectatorLimit = status.Errorf(codes.ResourceExhausted, "too many spectators of the game")
+)
+
+// ListGames returns public games, which have begun.
+func (s *Server) ListGames(ctx context.Context, in *api.EmptyMessage) (*serverapi.GamesMessage, error) {
+	tables := s.seats.publicTables()
+	games := make([]*serverapi.GameInfo, 0, len(tables))
+	for _, info := range tables {
+		size, err := info.game.FieldSize(info.playerID)
+		if err != nil {
+			// game has ended meanwhile
+			continue
+		}
+		games = append(games, &serverapi.GameInfo{
+			Id:         int64(info.id),
+			Players:    info.players,
+			Size:       int64(size),
+			Spectators: int64(info.spectators),
+		})
+	}
+	return &serverapi.GamesMessage{Games: games}, nil
+}
+
+// WatchGame sends state of the public game and then its new state after each change,
+// until the game ends or becomes private.
+func (s *Server) WatchGame(in *serverapi.GameIDMessage, stream serverapi.Spectator_WatchGameServer) error {
+	ctx := stream.Context()
+	id, err := idFromCtx(ctx)
+	if err != nil {
+		log.Printf("WatchGame error: %s", err)
+		return err
+	}
+
+	info, changes, cancel, err := s.seats.spectate(int(in.GetId()), s.spectatorLimit)
+	if err != nil {
+		err = extGrpcError(err, fmt.Sprintf("game %d", in.GetId()))
+		log.Printf("WatchGame error: %s", err)
+		return err
+	}
+	defer cancel()
+	defer s.presence.spectate(id)()
+	log.Printf("user with id %d watches game %d", id, info.id)
+
+	for {
+		playerID, ok := s.seats.playerOf(info.id)
+		if !ok {
+			return nil
+		}
+		state, err := s.getGameState(info.game, playerID)
+		if err != nil {
+			// the player has left meanwhile, the other one is asked after change
+			log.Printf("WatchGame error: %s", err)
+		} else if err := stream.Send(state); err != nil {
+			return err
+		}
+
+		select {
+		case _, ok := <-changes:
+			if !ok {
+				return nil
+			}
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+}
+
+// SetGamePrivate hides the game of gamer from spectators or shows it.
+// Current spectators of the game are disconnected, when it becomes private.
+func (s *Server) SetGamePrivate(ctx context.Context, in *serverapi.PrivacyMessage) (*api.EmptyMessage, error) {
+	id, err := idFromCtx(ctx)
+	if err != nil {
+		log.Printf("SetGamePrivate error: %s", err)
+		return &api.EmptyMessage{}, err
+	}
+
+	if !s.seats.setPrivate(id, in.GetPrivate()) {
+		err = extGrpcError(ErrNoGame, fmt.Sprintf("gamer with id %d", id))
+		log.Printf("SetGamePrivate error: %s", err)
+		return &api.EmptyMessage{}, err
+	}
+
+	log.Printf("gamer with id %d made his game private: %v", id, in.GetPrivate())
+	return &api.EmptyMessage{}, nil
+}
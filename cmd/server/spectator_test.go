@@ -0,0 +1,138 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"log"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/yagoggame/api"
+	"github.com/yagoggame/grpc_server/serverapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSpectateGame(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	conn, release := startTestServer(t, 1)
+	defer release()
+	gameClient := api.NewGoGameClient(conn)
+	sessionClient := serverapi.NewSessionClient(conn)
+	spectatorClient := serverapi.NewSpectatorClient(conn)
+
+	joe := clientContext("Joe", "aaa")
+	nick := clientContext("Nick", "bbb")
+	piter := clientContext("Piter", "ppp")
+	if _, err := gameClient.RegisterUser(piter, &api.EmptyMessage{}); err != nil {
+		t.Fatalf("Unexpected RegisterUser err: %v", err)
+	}
+	joinBoth(t, gameClient, joe, nick)
+
+	games, err := spectatorClient.ListGames(piter, &api.EmptyMessage{})
+	if err != nil {
+		t.Fatalf("Unexpected ListGames err: %v", err)
+	}
+	if len(games.GetGames()) != 1 {
+		t.Fatalf("Unexpected ListGames result: %v", games)
+	}
+	info := games.GetGames()[0]
+	if players := info.GetPlayers(); info.GetSize() != usualSize || len(players) != 2 ||
+		!reflect.DeepEqual(map[string]bool{players[0]: true, players[1]: true}, map[string]bool{"Joe": true, "Nick": true}) {
+		t.Errorf("Unexpected game info: %v", info)
+	}
+
+	ctx, cancel := context.WithCancel(piter)
+	defer cancel()
+	stream, err := spectatorClient.WatchGame(ctx, &serverapi.GameIDMessage{Id: info.GetId()})
+	if err != nil {
+		t.Fatalf("Unexpected WatchGame err: %v", err)
+	}
+	states := make(chan *api.State, eventsBuffer)
+	go func() {
+		defer close(states)
+		for {
+			state, err := stream.Recv()
+			if err != nil {
+				if err != io.EOF {
+					t.Errorf("Unexpected WatchGame stream err: %v", err)
+				}
+				return
+			}
+			states <- state
+		}
+	}()
+	if state := recvState(t, states); state == nil || state.GetSize() != usualSize {
+		t.Fatalf("Unexpected first state: %v", state)
+	}
+
+	overLimit, err := spectatorClient.WatchGame(joe, &serverapi.GameIDMessage{Id: info.GetId()})
+	if err == nil {
+		_, err = overLimit.Recv()
+	}
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Unexpected WatchGame over limit err:\nwant: %v,\ngot: %v.", ErrSpectatorLimit, err)
+	}
+
+	mover := joe
+	if gameStatus, err := sessionClient.GetGameState(joe, &api.EmptyMessage{}); err != nil || !gameStatus.GetMyTurn() {
+		mover = nick
+	}
+	if _, err := gameClient.MakeTurn(mover, &api.TurnMessage{X: 3, Y: 4}); err != nil {
+		t.Fatalf("Unexpected MakeTurn err: %v", err)
+	}
+	state := recvState(t, states)
+	if chips := len(state.GetBlack().GetChipsOnBoard()) + len(state.GetWhite().GetChipsOnBoard()); chips != 1 {
+		t.Errorf("Unexpected chips on board after turn:\nwant: 1,\ngot: %d.", chips)
+	}
+
+	if _, err := spectatorClient.SetGamePrivate(piter, &serverapi.PrivacyMessage{Private: true}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Unexpected SetGamePrivate by spectator err:\nwant: %v,\ngot: %v.", ErrNoGame, err)
+	}
+	if _, err := spectatorClient.SetGamePrivate(nick, &serverapi.PrivacyMessage{Private: true}); err != nil {
+		t.Fatalf("Unexpected SetGamePrivate err: %v", err)
+	}
+	if state, ok := <-states; ok {
+		t.Errorf("Unexpected state of private game: %v", state)
+	}
+
+	games, err = spectatorClient.ListGames(piter, &api.EmptyMessage{})
+	if err != nil || len(games.GetGames()) != 0 {
+		t.Errorf("Unexpected ListGames of private game: %v, %v", games, err)
+	}
+	private, err := spectatorClient.WatchGame(piter, &serverapi.GameIDMessage{Id: info.GetId()})
+	if err == nil {
+		_, err = private.Recv()
+	}
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Unexpected WatchGame of private game err:\nwant: %v,\ngot: %v.", ErrNoSuchGame, err)
+	}
+}
+
+func recvState(t *testing.T, states <-chan *api.State) *api.State {
+	select {
+	case state := <-states:
+		return state
+	case <-time.After(time.Second):
+		t.Fatalf("Unexpected absence of game state")
+	}
+	return nil
+}
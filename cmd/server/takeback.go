@@ -0,0 +1,205 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/yagoggame/api"
+	"github.com/yagoggame/gomaster/game"
+	"github.com/yagoggame/grpc_server/serverapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrNoTakeback occurs when game of gamer isn't a private one, whose moves can be taken back
+	ErrNoTakeback = status.Errorf(codes.FailedPrecondition, "game doesn't support takeback")
+	// ErrNothingToUndo occurs when the last move of game isn't a move of gamer or game is over
+	ErrNothingToUndo = status.Errorf(codes.FailedPrecondition, "no move of gamer to take back")
+	// ErrUndoPending occurs when takeback is already requested
+	ErrUndoPending = status.Errorf(codes.FailedPrecondition, "takeback is already requested")
+	// ErrNoUndoRequest occurs when partner of gamer hasn't requested takeback
+	ErrNoUndoRequest = status.Errorf(codes.FailedPrecondition, "no takeback request of partner")
+	// ErrUndoRejected occurs when partner rejects takeback, makes his move or leaves the game
+	ErrUndoRejected = status.Errorf(codes.Aborted, "takeback is rejected")
+	// ErrUndo occurs when game fails to take back the move
+	ErrUndo = status.Errorf(codes.Internal, "failed to take back the move")
+)
+
+// RequestUndo asks partner to take back the last move of gamer.
+// Partner receives the request by WatchPartner and GetGameState and answers it by AnswerUndo.
+// The call waits for the answer and returns the state of game without the move.
+// Move of partner or leaving of the game rejects the request.
+// Moves are taken back in private games and in begun games of the pool,
+// which are replaced by private ones.
+func (s *Server) RequestUndo(ctx context.Context, in *api.EmptyMessage) (*api.State, error) {
+	id, err := idFromCtx(ctx)
+	if err != nil {
+		log.Printf("RequestUndo error: %s", err)
+		return &api.State{}, err
+	}
+
+	gameManager, err := s.undoerOf(id)
+	if err != nil {
+		log.Printf("RequestUndo error: %s", err)
+		return &api.State{}, err
+	}
+	if state, err := gameManager.GameState(id); err != nil || state.GameOver {
+		err = extGrpcError(ErrNothingToUndo, fmt.Sprintf("gamer with id %d: game is over", id))
+		log.Printf("RequestUndo error: %s", err)
+		return &api.State{}, err
+	}
+	partnerID, _, _ := s.seats.partnerID(id)
+	request, done, err := s.histories.request(gameManager, id, partnerID)
+	if err != nil {
+		log.Printf("RequestUndo error: %s", err)
+		return &api.State{}, err
+	}
+	s.seats.undo(id, serverapi.UndoEvent_UNDO_REQUESTED)
+	log.Printf("gamer with id %d requested takeback", id)
+
+	accepted, err := s.histories.await(ctx, request, done)
+	if err != nil {
+		err = status.FromContextError(ctx.Err()).Err()
+		log.Printf("RequestUndo error: %s", err)
+		return &api.State{}, err
+	}
+	if !accepted {
+		err = extGrpcError(ErrUndoRejected, fmt.Sprintf("gamer with id %d", id))
+		log.Printf("RequestUndo error: %s", err)
+		return &api.State{}, err
+	}
+
+	state, err := s.getGameState(gameManager, id)
+	if err != nil {
+		log.Printf("RequestUndo error: %s", err)
+		return &api.State{}, err
+	}
+	s.setGameHeader(ctx, gameManager, id)
+	return state, nil
+}
+
+// AnswerUndo accepts or rejects the request of partner to take back his last move.
+// On acceptance the other moves are replayed on a new game, which rolls back
+// board, captures and turn order, and the clock is switched back to partner.
+func (s *Server) AnswerUndo(ctx context.Context, in *serverapi.UndoAnswer) (*api.State, error) {
+	id, err := idFromCtx(ctx)
+	if err != nil {
+		log.Printf("AnswerUndo error: %s", err)
+		return &api.State{}, err
+	}
+
+	gameManager, err := s.undoerOf(id)
+	if err != nil {
+		log.Printf("AnswerUndo error: %s", err)
+		return &api.State{}, err
+	}
+	requester, err := s.histories.answer(gameManager, id, in.GetAccept(), func(from int) error {
+		if err := gameManager.Undo(from); err != nil {
+			return undoError(err, from)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("AnswerUndo error: %s", err)
+		return &api.State{}, err
+	}
+
+	if in.GetAccept() {
+		s.invitations.undone(requester)
+		s.results.undone(gameManager)
+		s.seats.moved(id)
+		s.seats.undo(id, serverapi.UndoEvent_UNDO_ACCEPTED)
+		log.Printf("gamer with id %d accepted takeback of gamer with id %d", id, requester)
+	} else {
+		s.seats.undo(id, serverapi.UndoEvent_UNDO_REJECTED)
+		log.Printf("gamer with id %d rejected takeback of gamer with id %d", id, requester)
+	}
+
+	state, err := s.getGameState(gameManager, id)
+	if err != nil {
+		log.Printf("AnswerUndo error: %s", err)
+		return &api.State{}, err
+	}
+	s.setGameHeader(ctx, gameManager, id)
+	return state, nil
+}
+
+// ListMoves returns the move history of game of gamer,
+// where taken back moves are followed by their takebacks.
+func (s *Server) ListMoves(ctx context.Context, in *api.EmptyMessage) (*serverapi.MovesMessage, error) {
+	id, err := idFromCtx(ctx)
+	if err != nil {
+		log.Printf("ListMoves error: %s", err)
+		return &serverapi.MovesMessage{}, err
+	}
+
+	gameManager, err := s.gameGeter.GetGame(id)
+	if err == nil && gameManager == nil {
+		err = extGrpcError(ErrNoGame, fmt.Sprintf("gamer with id %d", id))
+	}
+	if err != nil {
+		log.Printf("ListMoves error: %s", err)
+		return &serverapi.MovesMessage{}, err
+	}
+
+	history := s.histories.moves(gameManager)
+	moves := make([]*serverapi.MoveMessage, len(history))
+	for i, m := range history {
+		moves[i] = toMoveMessage(m)
+	}
+	return &serverapi.MovesMessage{Moves: moves}, nil
+}
+
+// undoerOf returns the game of gamer, if its moves can be taken back.
+func (s *Server) undoerOf(id int) (*serverGame, error) {
+	gameManager, err := s.gameGeter.GetGame(id)
+	if err != nil {
+		return nil, err
+	}
+	if gameManager == nil {
+		return nil, extGrpcError(ErrNoGame, fmt.Sprintf("gamer with id %d", id))
+	}
+	g, ok := gameManager.(*serverGame)
+	if !ok {
+		return nil, extGrpcError(ErrNoTakeback, fmt.Sprintf("gamer with id %d", id))
+	}
+	return g, nil
+}
+
+// undoError converts error of takeback by game manager to the error of the server.
+func undoError(err error, id int) error {
+	if errors.Is(err, game.ErrGameOver) {
+		return extGrpcError(ErrNothingToUndo, fmt.Sprintf("gamer with id %d: %v", id, err))
+	}
+	return extGrpcError(ErrUndo, fmt.Sprintf("gamer with id %d: %v", id, err))
+}
+
+func toMoveMessage(m move) *serverapi.MoveMessage {
+	return &serverapi.MoveMessage{
+		Number: int64(m.number),
+		Colour: m.colour,
+		X:      int64(m.turn.X),
+		Y:      int64(m.turn.Y),
+		Undo:   m.undo,
+		Time:   m.time.UnixNano(),
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/yagoggame/api"
+	"github.com/yagoggame/grpc_server/serverapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var takebackMoves = []struct {
+	ctx  func(joe, nick context.Context) context.Context
+	turn *api.TurnMessage
+}{
+	{ctx: func(joe, nick context.Context) context.Context { return nick }, turn: &api.TurnMessage{X: 1, Y: 1}},
+	{ctx: func(joe, nick context.Context) context.Context { return joe }, turn: &api.TurnMessage{X: 5, Y: 5}},
+	{ctx: func(joe, nick context.Context) context.Context { return nick }, turn: &api.TurnMessage{X: 9, Y: 9}},
+	{ctx: func(joe, nick context.Context) context.Context { return joe }, turn: &api.TurnMessage{X: 2, Y: 1}},
+}
+
+var wantMoves = []*serverapi.MoveMessage{
+	{Number: 1, Colour: serverapi.Colour_BLACK, X: 1, Y: 2},
+	{Number: 2, Colour: serverapi.Colour_WHITE, X: 1, Y: 1},
+	{Number: 3, Colour: serverapi.Colour_BLACK, X: 5, Y: 5},
+	{Number: 4, Colour: serverapi.Colour_WHITE, X: 9, Y: 9},
+	{Number: 5, Colour: serverapi.Colour_BLACK, X: 2, Y: 1},
+	{Number: 5, Colour: serverapi.Colour_BLACK, X: 2, Y: 1, Undo: true},
+	{Number: 5, Colour: serverapi.Colour_BLACK, X: 3, Y: 3},
+}
+
+func TestTakeback(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	conn, release := startTestServer(t, DefaultSpectatorLimit)
+	defer release()
+	gameClient := api.NewGoGameClient(conn)
+	sessionClient := serverapi.NewSessionClient(conn)
+	challengeClient := serverapi.NewChallengeClient(conn)
+	takebackClient := serverapi.NewTakebackClient(conn)
+
+	joe := clientContext("Joe", "aaa")
+	nick := clientContext("Nick", "bbb")
+	for _, ctx := range []context.Context{joe, nick} {
+		if _, err := gameClient.EnterTheLobby(ctx, &api.EmptyMessage{}); err != nil {
+			t.Fatalf("Unexpected EnterTheLobby err: %v", err)
+		}
+	}
+	if _, err := takebackClient.RequestUndo(joe, &api.EmptyMessage{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Unexpected RequestUndo without game err:\nwant: %v,\ngot: %v.", ErrNoGame, err)
+	}
+
+	invite, err := challengeClient.Challenge(joe, &serverapi.ChallengeRequest{Login: "Nick", Size: 9, Colour: serverapi.Colour_BLACK})
+	if err != nil {
+		t.Fatalf("Unexpected Challenge err: %v", err)
+	}
+	if _, err := challengeClient.AcceptChallenge(nick, &serverapi.CodeMessage{Code: invite.GetCode()}); err != nil {
+		t.Fatalf("Unexpected AcceptChallenge err: %v", err)
+	}
+	// challenger awaits the answer by ResumeGame
+	if _, err := sessionClient.ResumeGame(joe, &api.EmptyMessage{}); err != nil {
+		t.Fatalf("Unexpected ResumeGame err: %v", err)
+	}
+	events := watchPartner(t, sessionClient, nick)
+	recvEvent(t, events)
+
+	if _, err := takebackClient.RequestUndo(joe, &api.EmptyMessage{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Unexpected RequestUndo before moves err:\nwant: %v,\ngot: %v.", ErrNothingToUndo, err)
+	}
+	if _, err := gameClient.MakeTurn(joe, &api.TurnMessage{X: 1, Y: 2}); err != nil {
+		t.Fatalf("Unexpected MakeTurn err: %v", err)
+	}
+	if _, err := takebackClient.RequestUndo(nick, &api.EmptyMessage{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Unexpected RequestUndo of partner's move err:\nwant: %v,\ngot: %v.", ErrNothingToUndo, err)
+	}
+	if _, err := takebackClient.AnswerUndo(nick, &serverapi.UndoAnswer{Accept: true}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Unexpected AnswerUndo without request err:\nwant: %v,\ngot: %v.", ErrNoUndoRequest, err)
+	}
+
+	// rejected request
+	requested := requestUndo(takebackClient, joe)
+	testPartnerEvent(t, recvEvent(t, events), &serverapi.PartnerEvent{Partner: "Joe", Connected: true, Undo: serverapi.UndoEvent_UNDO_REQUESTED})
+	if gameStatus, err := sessionClient.GetGameState(nick, &api.EmptyMessage{}); err != nil || !gameStatus.GetUndoRequested() {
+		t.Errorf("Unexpected game status of gamer asked for takeback: %v, %v", gameStatus, err)
+	}
+	if _, err := takebackClient.RequestUndo(joe, &api.EmptyMessage{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Unexpected repeated RequestUndo err:\nwant: %v,\ngot: %v.", ErrUndoPending, err)
+	}
+	if _, err := takebackClient.AnswerUndo(nick, &serverapi.UndoAnswer{}); err != nil {
+		t.Fatalf("Unexpected AnswerUndo err: %v", err)
+	}
+	if err := <-requested; status.Code(err) != codes.Aborted {
+		t.Errorf("Unexpected RequestUndo err:\nwant: %v,\ngot: %v.", ErrUndoRejected, err)
+	}
+
+	var before, last *api.State
+	for _, move := range takebackMoves {
+		before = last
+		if last, err = gameClient.MakeTurn(move.ctx(joe, nick), move.turn); err != nil {
+			t.Fatalf("Unexpected MakeTurn err: %v", err)
+		}
+	}
+
+	// accepted request wakes up the gamer waiting for partner's move
+	waited := make(chan error, 1)
+	go func() {
+		_, err := gameClient.WaitTheTurn(joe, &api.EmptyMessage{})
+		waited <- err
+	}()
+	requested = requestUndo(takebackClient, joe)
+	testPartnerEvent(t, recvEvent(t, events), &serverapi.PartnerEvent{Partner: "Joe", Connected: true, Undo: serverapi.UndoEvent_UNDO_REQUESTED})
+	state, err := takebackClient.AnswerUndo(nick, &serverapi.UndoAnswer{Accept: true})
+	if err != nil {
+		t.Fatalf("Unexpected AnswerUndo err: %v", err)
+	}
+	if !proto.Equal(state, before) {
+		t.Errorf("Unexpected state after takeback:\nbefore: %v,\ngot: %v.", before, state)
+	}
+	if err := <-requested; err != nil {
+		t.Errorf("Unexpected RequestUndo err: %v", err)
+	}
+	if err := <-waited; err != nil {
+		t.Errorf("Unexpected WaitTheTurn err: %v", err)
+	}
+	if gameStatus, err := sessionClient.GetGameState(joe, &api.EmptyMessage{}); err != nil || !gameStatus.GetMyTurn() {
+		t.Errorf("Unexpected game status of gamer after takeback: %v, %v", gameStatus, err)
+	}
+	if _, err := gameClient.MakeTurn(joe, &api.TurnMessage{X: 3, Y: 3}); err != nil {
+		t.Fatalf("Unexpected MakeTurn after takeback err: %v", err)
+	}
+
+	moves, err := takebackClient.ListMoves(nick, &api.EmptyMessage{})
+	if err != nil {
+		t.Fatalf("Unexpected ListMoves err: %v", err)
+	}
+	if len(moves.GetMoves()) != len(wantMoves) {
+		t.Fatalf("Unexpected moves:\nwant: %v,\ngot: %v.", wantMoves, moves.GetMoves())
+	}
+	for i, move := range moves.GetMoves() {
+		move.Time = 0
+		if !proto.Equal(move, wantMoves[i]) {
+			t.Errorf("Unexpected move %d:\nwant: %v,\ngot: %v.", i, wantMoves[i], move)
+		}
+	}
+
+	// leaving of partner rejects the request
+	requested = requestUndo(takebackClient, joe)
+	testPartnerEvent(t, recvEvent(t, events), &serverapi.PartnerEvent{Partner: "Joe", Connected: true, Undo: serverapi.UndoEvent_UNDO_REQUESTED})
+	if _, err := gameClient.LeaveTheGame(nick, &api.EmptyMessage{}); err != nil {
+		t.Fatalf("Unexpected LeaveTheGame err: %v", err)
+	}
+	if err := <-requested; status.Code(err) != codes.Aborted {
+		t.Errorf("Unexpected RequestUndo after leaving of partner err:\nwant: %v,\ngot: %v.", ErrUndoRejected, err)
+	}
+}
+
+func TestTakebackOfPoolGame(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	conn, release := startTestServer(t, DefaultSpectatorLimit)
+	defer release()
+	gameClient := api.NewGoGameClient(conn)
+	sessionClient := serverapi.NewSessionClient(conn)
+	takebackClient := serverapi.NewTakebackClient(conn)
+
+	joe := clientContext("Joe", "aaa")
+	nick := clientContext("Nick", "bbb")
+	joinBoth(t, gameClient, joe, nick)
+	black, white := joe, nick
+	if gameStatus, err := sessionClient.GetGameState(joe, &api.EmptyMessage{}); err != nil || gameStatus.GetColour() != serverapi.Colour_BLACK {
+		black, white = white, black
+	}
+	if _, err := gameClient.MakeTurn(black, &api.TurnMessage{X: 3, Y: 3}); err != nil {
+		t.Fatalf("Unexpected MakeTurn err: %v", err)
+	}
+
+	// begun games of the pool are replaced by private ones, which take moves back
+	events := watchPartner(t, sessionClient, white)
+	recvEvent(t, events)
+	requested := requestUndo(takebackClient, black)
+	if event := recvEvent(t, events); event.GetUndo() != serverapi.UndoEvent_UNDO_REQUESTED {
+		t.Fatalf("Unexpected partner event:\nwant: %v,\ngot: %v.", serverapi.UndoEvent_UNDO_REQUESTED, event)
+	}
+	state, err := takebackClient.AnswerUndo(white, &serverapi.UndoAnswer{Accept: true})
+	if err != nil {
+		t.Fatalf("Unexpected AnswerUndo err: %v", err)
+	}
+	if len(state.GetBlack().GetChipsOnBoard()) != 0 {
+		t.Errorf("Unexpected state after takeback: %v", state)
+	}
+	if err := <-requested; err != nil {
+		t.Errorf("Unexpected RequestUndo err: %v", err)
+	}
+	if gameStatus, err := sessionClient.GetGameState(black, &api.EmptyMessage{}); err != nil || !gameStatus.GetMyTurn() {
+		t.Errorf("Unexpected game status of gamer after takeback: %v, %v", gameStatus, err)
+	}
+
+	moves, err := takebackClient.ListMoves(white, &api.EmptyMessage{})
+	if err != nil {
+		t.Fatalf("Unexpected ListMoves err: %v", err)
+	}
+	want := []*serverapi.MoveMessage{
+		{Number: 1, Colour: serverapi.Colour_BLACK, X: 3, Y: 3},
+		{Number: 1, Colour: serverapi.Colour_BLACK, X: 3, Y: 3, Undo: true},
+	}
+	if len(moves.GetMoves()) != len(want) {
+		t.Fatalf("Unexpected moves of pool game:\nwant: %v,\ngot: %v.", want, moves.GetMoves())
+	}
+	for i, move := range moves.GetMoves() {
+		move.Time = 0
+		if !proto.Equal(move, want[i]) {
+			t.Errorf("Unexpected move %d of pool game:\nwant: %v,\ngot: %v.", i, want[i], move)
+		}
+	}
+}
+
+// requestUndo requests takeback and returns the channel of its result.
+func requestUndo(client serverapi.TakebackClient, ctx context.Context) <-chan error {
+	result := make(chan error, 1)
+	go func() {
+		_, err := client.RequestUndo(ctx, &api.EmptyMessage{})
+		result <- err
+	}()
+	return result
+}
@@ -0,0 +1,89 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package gateway
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var (
+	corsMethods = "GET, POST, PUT, DELETE, OPTIONS"
+	corsHeaders = "Authorization, Content-Type, X-Login, X-Password, login, password, " +
+		"X-Grpc-Web, X-User-Agent, Grpc-Timeout"
+	corsExposed = "Grpc-Status, Grpc-Message, " +
+		"Grpc-Metadata-Turn, Grpc-Metadata-Colour, Grpc-Metadata-Partner, Turn, Colour, Partner"
+)
+
+// CORS wraps handler to allow cross-origin requests from pages of origins.
+// Origin "*" allows any page, but without credentials, like cookies or
+// cached Basic auth, of the visitor. Credentials are allowed only
+// for origins listed explicitly.
+func CORS(handler http.Handler, origins []string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		allowed, credentials := originAllowed(origin, origins)
+		if !allowed {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		header := w.Header()
+		if credentials {
+			header.Set("Access-Control-Allow-Origin", origin)
+			header.Set("Access-Control-Allow-Credentials", "true")
+		} else {
+			header.Set("Access-Control-Allow-Origin", "*")
+		}
+		header.Set("Access-Control-Expose-Headers", corsExposed)
+		header.Add("Vary", "Origin")
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			header.Set("Access-Control-Allow-Methods", corsMethods)
+			header.Set("Access-Control-Allow-Headers", corsHeaders)
+			header.Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// originAllowed reports whether origin is allowed by origins
+// and whether it is allowed to send credentials, i.e. listed explicitly.
+func originAllowed(origin string, origins []string) (allowed, credentials bool) {
+	for _, item := range origins {
+		if strings.EqualFold(item, origin) {
+			return true, true
+		}
+		if item == "*" {
+			allowed = true
+		}
+	}
+	return allowed, false
+}
+
+// sameOrigin reports whether Origin of r matches it's Host
+func sameOrigin(r *http.Request) bool {
+	u, err := url.Parse(r.Header.Get("Origin"))
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
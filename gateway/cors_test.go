@@ -0,0 +1,77 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package gateway_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/yagoggame/grpc_server/gateway"
+)
+
+var corsTests = []struct {
+	caseName        string
+	origins         []string
+	origin          string
+	method          string
+	wantOrigin      string
+	wantCredentials string
+	wantStatus      int
+}{
+	{caseName: "allowed preflight", origins: []string{"https://board.example.org"},
+		origin: "https://board.example.org", method: http.MethodOptions,
+		wantOrigin: "https://board.example.org", wantCredentials: "true", wantStatus: http.StatusNoContent},
+	{caseName: "foreign preflight", origins: []string{"https://board.example.org"},
+		origin: "https://evil.example.org", method: http.MethodOptions,
+		wantOrigin: "", wantCredentials: "", wantStatus: http.StatusMethodNotAllowed},
+	{caseName: "allowed request", origins: []string{"https://board.example.org"},
+		origin: "https://board.example.org", method: http.MethodPost,
+		wantOrigin: "https://board.example.org", wantCredentials: "true", wantStatus: http.StatusOK},
+	{caseName: "wildcard request", origins: []string{"*"},
+		origin: "https://evil.example.org", method: http.MethodPost,
+		wantOrigin: "*", wantCredentials: "", wantStatus: http.StatusOK},
+	{caseName: "listed with wildcard", origins: []string{"*", "https://board.example.org"},
+		origin: "https://board.example.org", method: http.MethodPost,
+		wantOrigin: "https://board.example.org", wantCredentials: "true", wantStatus: http.StatusOK},
+}
+
+func TestCORS(t *testing.T) {
+	for _, test := range corsTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			handler := Handler(&fakeServer{}, interceptor, Options{Origins: test.origins})
+
+			r := httptest.NewRequest(test.method, "/v1/lobby", nil)
+			r.Header.Set("Origin", test.origin)
+			r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			r.Header.Set("Content-Type", "application/json")
+			r.SetBasicAuth("Joe", "aaa")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != test.wantOrigin {
+				t.Errorf("Unexpected allowed origin:\nwant: %q,\ngot: %q.", test.wantOrigin, got)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Credentials"); got != test.wantCredentials {
+				t.Errorf("Unexpected allowed credentials:\nwant: %q,\ngot: %q.", test.wantCredentials, got)
+			}
+			if w.Code != test.wantStatus {
+				t.Errorf("Unexpected status:\nwant: %d,\ngot: %d.", test.wantStatus, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,629 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package gateway provides HTTP/JSON gateway to api.GoGameServer.
+//
+// Routes:
+//
+//	POST   /v1/users          RegisterUser
+//	DELETE /v1/users          RemoveUser
+//	PUT    /v1/users          ChangeUserRequisits, body: {"login": ..., "password": ...}
+//	POST   /v1/lobby          EnterTheLobby
+//	DELETE /v1/lobby          LeaveTheLobby
+//	GET    /v1/lobby          ListLobby
+//	GET    /v1/game           GetGameState
+//	POST   /v1/game           JoinTheGame
+//	POST   /v1/game/join      JoinGame, body: {"colour": ..., "handicap": ..., "free_placement": ...}
+//	DELETE /v1/game           LeaveTheGame
+//	GET    /v1/game/turn      WaitTheTurn
+//	GET    /v1/game/wait      WaitTurn
+//	POST   /v1/game/turn      MakeTurn, body: {"x": ..., "y": ...}
+//	POST   /v1/game/undo      RequestUndo
+//	PUT    /v1/game/undo      AnswerUndo, body: {"accept": ...}
+//	GET    /v1/game/moves     ListMoves
+//	PUT    /v1/game/privacy   SetGamePrivate, body: {"private": ...}
+//	GET    /v1/games          ListGames
+//	POST   /v1/challenges     Challenge, body: {"login": ..., "size": ..., "komi": ..., "colour": ..., "main_time": ..., "increment": ...,
+//	                          "handicap": ..., "free_placement": ...}
+//	GET    /v1/challenges     ListChallenges
+//	PUT    /v1/challenges     AcceptChallenge, body: {"code": ...}
+//	DELETE /v1/challenges     DeclineChallenge, body: {"code": ...}
+//	GET    /v1/ratings        GetRating, query: ?login=...
+//	GET    /v1/leaderboard    Leaderboard, query: ?offset=...&limit=...
+//	GET    /v1/stats          GetStats, query: ?login=...
+//	POST   /v1/chat           SendMessage, body: {"channel": "LOBBY"|"GAME", "text": ...}
+//	GET    /v1/chat           History, query: ?channel=LOBBY|GAME&limit=...
+//
+// Requests of GET routes are decoded from query parameters named as fields
+// of the request, because browsers can't send a body with GET.
+// Requests of other routes must have "Content-Type: application/json",
+// even without a body, for protection from cross-site request forgery.
+//
+// Requisites are taken from Basic authorization
+// or from X-Login and X-Password headers.
+// Headers of gRPC calls, e.g. serverapi.ColourHeader, are sent as Grpc-Metadata-<Header>.
+// JoinGame and WaitTurn report the same colour and partner in the body.
+//
+// Routes of serverapi.SessionServer, serverapi.SpectatorServer, serverapi.ChatServer,
+// serverapi.LobbyServer, serverapi.ChallengeServer, serverapi.RatingServer
+// and serverapi.TakebackServer methods respond with Unimplemented status,
+// if the server doesn't provide them.
+//
+// Optionally the same methods are served for browsers by gRPC-Web protocol
+// at "/<service>/<method>" and by WebSocket bridge at WebSocketPath,
+// which serves streaming methods as well.
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/yagoggame/api"
+	"github.com/yagoggame/grpc_server/serverapi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// maxBodySize limits size of request body
+const maxBodySize = 1 << 16
+
+// servicePrefix is a prefix of full gRPC method names of api.GoGameServer
+const servicePrefix = "/api.GoGame/"
+
+// sessionPrefix is a prefix of full gRPC method names of serverapi.SessionServer
+const sessionPrefix = "/grpc_server.Session/"
+
+// spectatorPrefix is a prefix of full gRPC method names of serverapi.SpectatorServer
+const spectatorPrefix = "/grpc_server.Spectator/"
+
+// chatPrefix is a prefix of full gRPC method names of serverapi.ChatServer
+const chatPrefix = "/grpc_server.Chat/"
+
+// lobbyPrefix is a prefix of full gRPC method names of serverapi.LobbyServer
+const lobbyPrefix = "/grpc_server.Lobby/"
+
+// challengePrefix is a prefix of full gRPC method names of serverapi.ChallengeServer
+const challengePrefix = "/grpc_server.Challenge/"
+
+// ratingPrefix is a prefix of full gRPC method names of serverapi.RatingServer
+const ratingPrefix = "/grpc_server.Rating/"
+
+// takebackPrefix is a prefix of full gRPC method names of serverapi.TakebackServer
+const takebackPrefix = "/grpc_server.Takeback/"
+
+// matchmakingPrefix is a prefix of full gRPC method names of serverapi.MatchmakingServer
+const matchmakingPrefix = "/grpc_server.Matchmaking/"
+
+// metadataHeaderPrefix is a prefix of HTTP headers carrying gRPC header metadata
+const metadataHeaderPrefix = "Grpc-Metadata-"
+
+type route struct {
+	httpMethod string
+	path       string
+	method     string
+	newRequest func() proto.Message
+	call       func(ctx context.Context, srv api.GoGameServer, req proto.Message) (proto.Message, error)
+}
+
+func emptyRequest() proto.Message { return &api.EmptyMessage{} }
+
+var routes = []route{
+	{http.MethodPost, "/v1/users", servicePrefix + "RegisterUser", emptyRequest,
+		func(ctx context.Context, srv api.GoGameServer, req proto.Message) (proto.Message, error) {
+			return srv.RegisterUser(ctx, req.(*api.EmptyMessage))
+		}},
+	{http.MethodDelete, "/v1/users", servicePrefix + "RemoveUser", emptyRequest,
+		func(ctx context.Context, srv api.GoGameServer, req proto.Message) (proto.Message, error) {
+			return srv.RemoveUser(ctx, req.(*api.EmptyMessage))
+		}},
+	{http.MethodPut, "/v1/users", servicePrefix + "ChangeUserRequisits", func() proto.Message { return &api.RequisitsMessage{} },
+		func(ctx context.Context, srv api.GoGameServer, req proto.Message) (proto.Message, error) {
+			return srv.ChangeUserRequisits(ctx, req.(*api.RequisitsMessage))
+		}},
+	{http.MethodPost, "/v1/lobby", servicePrefix + "EnterTheLobby", emptyRequest,
+		func(ctx context.Context, srv api.GoGameServer, req proto.Message) (proto.Message, error) {
+			return srv.EnterTheLobby(ctx, req.(*api.EmptyMessage))
+		}},
+	{http.MethodDelete, "/v1/lobby", servicePrefix + "LeaveTheLobby", emptyRequest,
+		func(ctx context.Context, srv api.GoGameServer, req proto.Message) (proto.Message, error) {
+			return srv.LeaveTheLobby(ctx, req.(*api.EmptyMessage))
+		}},
+	{http.MethodGet, "/v1/lobby", lobbyPrefix + "ListLobby", emptyRequest,
+		func(ctx context.Context, srv api.GoGameServer, req proto.Message) (proto.Message, error) {
+			return lobby(srv).ListLobby(ctx, req.(*api.EmptyMessage))
+		}},
+	{http.MethodGet, "/v1/game", sessionPrefix + "GetGameState", emptyRequest,
+		func(ctx context.Context, srv api.GoGameServer, req proto.Message) (proto.Message, error) {
+			return session(srv).GetGameState(ctx, req.(*api.EmptyMessage))
+		}},
+	{http.MethodPost, "/v1/game", servicePrefix + "JoinTheGame", emptyRequest,
+		func(ctx context.Context, srv api.GoGameServer, req proto.Message) (proto.Message, error) {
+			return srv.JoinTheGame(ctx, req.(*api.EmptyMessage))
+		}},
+	{http.MethodPost, "/v1/game/join", sessionPrefix + "JoinGame", func() proto.Message { return &serverapi.JoinRequest{} },
+		func(ctx context.Context, srv api.GoGameServer, req proto.Message) (proto.Message, error) {
+			return session(srv).JoinGame(ctx, req.(*serverapi.JoinRequest))
+		}},
+	{http.MethodDelete, "/v1/game", servicePrefix + "LeaveTheGame", emptyRequest,
+		func(ctx context.Context, srv api.GoGameServer, req proto.Message) (proto.Message, error) {
+			return srv.LeaveTheGame(ctx, req.(*api.EmptyMessage))
+		}},
+	{http.MethodGet, "/v1/game/turn", servicePrefix + "WaitTheTurn", emptyRequest,
+		func(ctx context.Context, srv api.GoGameServer, req proto.Message) (proto.Message, error) {
+			return srv.WaitTheTurn(ctx, req.(*api.EmptyMessage))
+		}},
+	{http.MethodGet, "/v1/game/wait", sessionPrefix + "WaitTurn", emptyRequest,
+		func(ctx context.Context, srv api.GoGameServer, req proto.Message) (proto.Message, error) {
+			return session(srv).WaitTurn(ctx, req.(*api.EmptyMessage))
+		}},
+	{http.MethodPost, "/v1/game/turn", servicePrefix + "MakeTurn", func() proto.Message { return &api.TurnMessage{} },
+		func(ctx context.Context, srv api.GoGameServer, req proto.Message) (proto.Message, error) {
+			return srv.MakeTurn(ctx, req.(*api.TurnMessage))
+		}},
+	{http.MethodPost, "/v1/game/undo", takebackPrefix + "RequestUndo", emptyRequest,
+		func(ctx context.Context, srv api.GoGameServer, req proto.Message) (proto.Message, error) {
+			return takeback(srv).RequestUndo(ctx, req.(*api.EmptyMessage))
+		}},
+	{http.MethodPut, "/v1/game/undo", takebackPrefix + "AnswerUndo", func() proto.Message { return &serverapi.UndoAnswer{} },
+		func(ctx context.Context, srv api.GoGameServer, req proto.Message) (proto.Message, error) {
+			return takeback(srv).AnswerUndo(ctx, req.(*serverapi.UndoAnswer))
+		}},
+	{http.MethodGet, "/v1/game/moves", takebackPrefix + "ListMoves", emptyRequest,
+		func(ctx context.Context, srv api.GoGameServer, req proto.Message) (proto.Message, error) {
+			return takeback(srv).ListMoves(ctx, req.(*api.EmptyMessage))
+		}},
+	{http.MethodPut, "/v1/game/privacy", spectatorPrefix + "SetGamePrivate", func() proto.Message { return &serverapi.PrivacyMessage{} },
+		func(ctx context.Context, srv api.GoGameServer, req proto.Message) (proto.Message, error) {
+			return spectator(srv).SetGamePrivate(ctx, req.(*serverapi.PrivacyMessage))
+		}},
+	{http.MethodGet, "/v1/games", spectatorPrefix + "ListGames", emptyRequest,
+		func(ctx context.Context, srv api.GoGameServer, req proto.Message) (proto.Message, error) {
+			return spectator(srv).ListGames(ctx, req.(*api.EmptyMessage))
+		}},
+	{http.MethodPost, "/v1/challenges", challengePrefix + "Challenge", func() proto.Message { return &serverapi.ChallengeRequest{} },
+		func(ctx context.Context, srv api.GoGameServer, req proto.Message) (proto.Message, error) {
+			return challenge(srv).Challenge(ctx, req.(*serverapi.ChallengeRequest))
+		}},
+	{http.MethodGet, "/v1/challenges", challengePrefix + "ListChallenges", emptyRequest,
+		func(ctx context.Context, srv api.GoGameServer, req proto.Message) (proto.Message, error) {
+			return challenge(srv).ListChallenges(ctx, req.(*api.EmptyMessage))
+		}},
+	{http.MethodPut, "/v1/challenges", challengePrefix + "AcceptChallenge", func() proto.Message { return &serverapi.CodeMessage{} },
+		func(ctx context.Context, srv api.GoGameServer, req proto.Message) (proto.Message, error) {
+			return challenge(srv).AcceptChallenge(ctx, req.(*serverapi.CodeMessage))
+		}},
+	{http.MethodDelete, "/v1/challenges", challengePrefix + "DeclineChallenge", func() proto.Message { return &serverapi.CodeMessage{} },
+		func(ctx context.Context, srv api.GoGameServer, req proto.Message) (proto.Message, error) {
+			return challenge(srv).DeclineChallenge(ctx, req.(*serverapi.CodeMessage))
+		}},
+	{http.MethodGet, "/v1/ratings", ratingPrefix + "GetRating", func() proto.Message { return &serverapi.RatingRequest{} },
+		func(ctx context.Context, srv api.GoGameServer, req proto.Message) (proto.Message, error) {
+			return ratings(srv).GetRating(ctx, req.(*serverapi.RatingRequest))
+		}},
+	{http.MethodGet, "/v1/leaderboard", ratingPrefix + "Leaderboard", func() proto.Message { return &serverapi.LeaderboardRequest{} },
+		func(ctx context.Context, srv api.GoGameServer, req proto.Message) (proto.Message, error) {
+			return ratings(srv).Leaderboard(ctx, req.(*serverapi.LeaderboardRequest))
+		}},
+	{http.MethodGet, "/v1/stats", ratingPrefix + "GetStats", func() proto.Message { return &serverapi.RatingRequest{} },
+		func(ctx context.Context, srv api.GoGameServer, req proto.Message) (proto.Message, error) {
+			return ratings(srv).GetStats(ctx, req.(*serverapi.RatingRequest))
+		}},
+	{http.MethodPost, "/v1/chat", chatPrefix + "SendMessage", func() proto.Message { return &serverapi.ChatMessage{} },
+		func(ctx context.Context, srv api.GoGameServer, req proto.Message) (proto.Message, error) {
+			return chat(srv).SendMessage(ctx, req.(*serverapi.ChatMessage))
+		}},
+	{http.MethodGet, "/v1/chat", chatPrefix + "History", func() proto.Message { return &serverapi.HistoryRequest{} },
+		func(ctx context.Context, srv api.GoGameServer, req proto.Message) (proto.Message, error) {
+			return chat(srv).History(ctx, req.(*serverapi.HistoryRequest))
+		}},
+}
+
+// session returns srv as serverapi.SessionServer, if it is implemented
+func session(srv api.GoGameServer) serverapi.SessionServer {
+	if session, ok := srv.(serverapi.SessionServer); ok {
+		return session
+	}
+	return &serverapi.UnimplementedSessionServer{}
+}
+
+// spectator returns srv as serverapi.SpectatorServer, if it is implemented
+func spectator(srv api.GoGameServer) serverapi.SpectatorServer {
+	if spectator, ok := srv.(serverapi.SpectatorServer); ok {
+		return spectator
+	}
+	return &serverapi.UnimplementedSpectatorServer{}
+}
+
+// chat returns srv as serverapi.ChatServer, if it is implemented
+func chat(srv api.GoGameServer) serverapi.ChatServer {
+	if chat, ok := srv.(serverapi.ChatServer); ok {
+		return chat
+	}
+	return &serverapi.UnimplementedChatServer{}
+}
+
+// lobby returns srv as serverapi.LobbyServer, if it is implemented
+func lobby(srv api.GoGameServer) serverapi.LobbyServer {
+	if lobby, ok := srv.(serverapi.LobbyServer); ok {
+		return lobby
+	}
+	return &serverapi.UnimplementedLobbyServer{}
+}
+
+// matchmaking returns srv as serverapi.MatchmakingServer, if it is implemented
+func matchmaking(srv api.GoGameServer) serverapi.MatchmakingServer {
+	if matchmaking, ok := srv.(serverapi.MatchmakingServer); ok {
+		return matchmaking
+	}
+	return &serverapi.UnimplementedMatchmakingServer{}
+}
+
+// challenge returns srv as serverapi.ChallengeServer, if it is implemented
+func challenge(srv api.GoGameServer) serverapi.ChallengeServer {
+	if challenge, ok := srv.(serverapi.ChallengeServer); ok {
+		return challenge
+	}
+	return &serverapi.UnimplementedChallengeServer{}
+}
+
+// ratings returns srv as serverapi.RatingServer, if it is implemented
+func ratings(srv api.GoGameServer) serverapi.RatingServer {
+	if ratings, ok := srv.(serverapi.RatingServer); ok {
+		return ratings
+	}
+	return &serverapi.UnimplementedRatingServer{}
+}
+
+// takeback returns srv as serverapi.TakebackServer, if it is implemented
+func takeback(srv api.GoGameServer) serverapi.TakebackServer {
+	if takeback, ok := srv.(serverapi.TakebackServer); ok {
+		return takeback
+	}
+	return &serverapi.UnimplementedTakebackServer{}
+}
+
+// Gateway implements http.Handler calling methods of api.GoGameServer
+// through the same interceptor as gRPC server does
+type Gateway struct {
+	srv         api.GoGameServer
+	interceptor grpc.UnaryServerInterceptor
+	marshaler   jsonpb.Marshaler
+}
+
+// New constructs new Gateway to srv.
+// interceptor is invoked for every request as for gRPC unary call.
+func New(srv api.GoGameServer, interceptor grpc.UnaryServerInterceptor) *Gateway {
+	return &Gateway{
+		srv:         srv,
+		interceptor: interceptor,
+		marshaler:   jsonpb.Marshaler{EmitDefaults: true, OrigName: true},
+	}
+}
+
+// ServeHTTP serves HTTP request by the method of api.GoGameServer mapped to it
+func (gateway *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var (
+		allowed []string
+		found   *route
+	)
+	for i := range routes {
+		if routes[i].path != r.URL.Path {
+			continue
+		}
+		allowed = append(allowed, routes[i].httpMethod)
+		if routes[i].httpMethod == r.Method {
+			found = &routes[i]
+		}
+	}
+
+	if len(allowed) < 1 {
+		gateway.writeError(w, status.Errorf(codes.NotFound, "no route for %s", r.URL.Path))
+		return
+	}
+	if found == nil {
+		sort.Strings(allowed)
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		writeJSON(w, http.StatusMethodNotAllowed, errorBody{Code: "MethodNotAllowed", Message: r.Method + " is not allowed"})
+		return
+	}
+
+	gateway.serveRoute(w, r, found)
+}
+
+func (gateway *Gateway) serveRoute(w http.ResponseWriter, r *http.Request, rt *route) {
+	if r.Method != http.MethodGet && !jsonContent(r) {
+		writeJSON(w, http.StatusUnsupportedMediaType, errorBody{Code: "UnsupportedMediaType",
+			Message: "Content-Type of " + r.Method + " request must be application/json"})
+		return
+	}
+
+	req := rt.newRequest()
+	if err := decodeRequest(w, r, req); err != nil {
+		gateway.writeError(w, err)
+		return
+	}
+
+	ctx := metadata.NewIncomingContext(r.Context(), requisitesMetadata(r))
+	resp, header, err := invoke(ctx, gateway.srv, gateway.interceptor, rt, req)
+	if err != nil {
+		gateway.writeError(w, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := gateway.marshaler.Marshal(&buf, resp); err != nil {
+		gateway.writeError(w, status.Errorf(codes.Internal, "can't encode response: %v", err))
+		return
+	}
+	for key, values := range header {
+		for _, value := range values {
+			w.Header().Add(metadataHeaderPrefix+key, value)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// jsonContent reports whether r has JSON body. Pages of other sites can send
+// only form and plain text bodies without CORS preflight, so requiring JSON
+// protects state-changing routes from cross-site request forgery
+// with credentials, e.g. cached Basic auth, of the visitor.
+func jsonContent(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "application/json"
+}
+
+// decodeRequest decodes req from query parameters of GET request r
+// or from JSON body of other ones.
+func decodeRequest(w http.ResponseWriter, r *http.Request, req proto.Message) error {
+	if r.Method == http.MethodGet {
+		if err := decodeQuery(r.URL.Query(), req); err != nil {
+			return status.Errorf(codes.InvalidArgument, "can't decode query: %v", err)
+		}
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "can't read body: %v", err)
+	}
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := jsonpb.Unmarshal(bytes.NewReader(body), req); err != nil {
+			return status.Errorf(codes.InvalidArgument, "can't decode body: %v", err)
+		}
+	}
+	return nil
+}
+
+// decodeQuery decodes parameters of query, named as fields of req in .proto, to req.
+// Values of string and enum fields are taken as is, other values as JSON ones.
+func decodeQuery(query url.Values, req proto.Message) error {
+	if len(query) < 1 {
+		return nil
+	}
+
+	reqType := reflect.TypeOf(req).Elem()
+	props := proto.GetProperties(reqType)
+	fields := make(map[string]json.RawMessage, len(query))
+	for i, prop := range props.Prop {
+		values, ok := query[prop.OrigName]
+		if !ok || prop.OrigName == "" {
+			continue
+		}
+		value := values[len(values)-1]
+
+		fields[prop.OrigName] = json.RawMessage(value)
+		_, errNum := strconv.ParseInt(value, 10, 64)
+		if reqType.Field(i).Type.Kind() == reflect.String || (prop.Enum != "" && errNum != nil) {
+			quoted, _ := json.Marshal(value)
+			fields[prop.OrigName] = quoted
+		}
+	}
+	for name := range query {
+		if _, ok := fields[name]; !ok {
+			return fmt.Errorf("unknown parameter %q", name)
+		}
+	}
+
+	content, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return jsonpb.Unmarshal(bytes.NewReader(content), req)
+}
+
+// invoke calls method of route with req through interceptor
+// and returns the response and headers set by the method
+func invoke(ctx context.Context, srv api.GoGameServer, interceptor grpc.UnaryServerInterceptor,
+	rt *route, req proto.Message) (proto.Message, metadata.MD, error) {
+	stream := &headerStream{method: rt.method}
+	ctx = grpc.NewContextWithServerTransportStream(ctx, stream)
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: rt.method}
+	resp, err := interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return rt.call(ctx, srv, req.(proto.Message))
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp.(proto.Message), stream.header, nil
+}
+
+// headerStream collects headers set by called method
+// as grpc.ServerTransportStream of gRPC server does
+type headerStream struct {
+	method string
+	header metadata.MD
+}
+
+func (stream *headerStream) Method() string { return stream.method }
+
+func (stream *headerStream) SetHeader(md metadata.MD) error {
+	stream.header = metadata.Join(stream.header, md)
+	return nil
+}
+
+func (stream *headerStream) SendHeader(md metadata.MD) error { return stream.SetHeader(md) }
+
+func (stream *headerStream) SetTrailer(md metadata.MD) error { return nil }
+
+// routeByMethod returns route of full gRPC method name
+func routeByMethod(method string) (*route, bool) {
+	for i := range routes {
+		if routes[i].method == method {
+			return &routes[i], true
+		}
+	}
+	return nil, false
+}
+
+// routeByName returns route of gRPC method name without service
+func routeByName(name string) (*route, bool) {
+	for i := range routes {
+		if path.Base(routes[i].method) == name {
+			return &routes[i], true
+		}
+	}
+	return nil, false
+}
+
+// requisitesMetadata translates requisites of HTTP request to the gRPC metadata.
+// Requisites are taken from Basic authorization, X-Login and X-Password headers,
+// or login and password headers set by gRPC-Web clients.
+func requisitesMetadata(r *http.Request) metadata.MD {
+	login, password, ok := r.BasicAuth()
+	if !ok {
+		login, password = r.Header.Get("X-Login"), r.Header.Get("X-Password")
+	}
+	if login == "" && password == "" {
+		login, password = r.Header.Get("login"), r.Header.Get("password")
+	}
+	return metadata.Pairs("login", login, "password", password)
+}
+
+type errorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (gateway *Gateway) writeError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	if st.Code() == codes.Unauthenticated {
+		w.Header().Set("WWW-Authenticate", `Basic realm="yagogame"`)
+	}
+	writeJSON(w, HTTPStatusFromCode(st.Code()), errorBody{Code: st.Code().String(), Message: st.Message()})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("gateway response write error: %v", err)
+	}
+}
+
+// HTTPStatusFromCode converts gRPC status code to HTTP status code
+func HTTPStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		// nginx's "client closed request"
+		return 499
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.FailedPrecondition:
+		return http.StatusPreconditionFailed
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+	// Unknown, Internal, DataLoss
+	return http.StatusInternalServerError
+}
+
+// Options configures Handler
+type Options struct {
+	// GRPCWeb enables gRPC-Web calls
+	GRPCWeb bool
+	// WebSocket enables WebSocket bridge at WebSocketPath
+	WebSocket bool
+	// StreamInterceptor is invoked for streaming calls of WebSocket bridge
+	// as for gRPC streaming call
+	StreamInterceptor grpc.StreamServerInterceptor
+	// Origins of pages allowed to make cross-origin requests, "*" allows any
+	Origins []string
+}
+
+// Handler returns http.Handler serving REST routes of Gateway
+// and, depending on options, gRPC-Web calls and WebSocket bridge.
+func Handler(srv api.GoGameServer, interceptor grpc.UnaryServerInterceptor, options Options) http.Handler {
+	gateway := New(srv, interceptor)
+	var (
+		web *GRPCWeb
+		ws  *WebSocket
+	)
+	if options.GRPCWeb {
+		web = NewGRPCWeb(srv, interceptor)
+	}
+	if options.WebSocket {
+		ws = NewWebSocket(srv, interceptor, options.StreamInterceptor, options.Origins)
+	}
+
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case web != nil && IsGRPCWeb(r):
+			web.ServeHTTP(w, r)
+		case ws != nil && r.URL.Path == WebSocketPath:
+			ws.ServeHTTP(w, r)
+		default:
+			gateway.ServeHTTP(w, r)
+		}
+	})
+	if len(options.Origins) > 0 {
+		handler = CORS(handler, options.Origins)
+	}
+	return handler
+}
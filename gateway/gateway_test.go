@@ -0,0 +1,387 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package gateway_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/yagoggame/api"
+	. "github.com/yagoggame/grpc_server/gateway"
+	"github.com/yagoggame/grpc_server/serverapi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// fakeServer records the last called method and its request
+type fakeServer struct {
+	called  string
+	request interface{}
+	mutex   sync.Mutex
+}
+
+func (s *fakeServer) record(method string, in interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.called, s.request = method, in
+}
+
+func (s *fakeServer) RegisterUser(ctx context.Context, in *api.EmptyMessage) (*api.EmptyMessage, error) {
+	s.record("RegisterUser", in)
+	return &api.EmptyMessage{}, nil
+}
+
+func (s *fakeServer) RemoveUser(ctx context.Context, in *api.EmptyMessage) (*api.EmptyMessage, error) {
+	s.record("RemoveUser", in)
+	return &api.EmptyMessage{}, nil
+}
+
+func (s *fakeServer) ChangeUserRequisits(ctx context.Context, in *api.RequisitsMessage) (*api.EmptyMessage, error) {
+	s.record("ChangeUserRequisits", in)
+	return &api.EmptyMessage{}, nil
+}
+
+func (s *fakeServer) EnterTheLobby(ctx context.Context, in *api.EmptyMessage) (*api.EmptyMessage, error) {
+	s.record("EnterTheLobby", in)
+	return &api.EmptyMessage{}, nil
+}
+
+func (s *fakeServer) LeaveTheLobby(ctx context.Context, in *api.EmptyMessage) (*api.EmptyMessage, error) {
+	s.record("LeaveTheLobby", in)
+	return nil, status.Error(codes.Internal, "can't leave the lobby")
+}
+
+func (s *fakeServer) JoinTheGame(ctx context.Context, in *api.EmptyMessage) (*api.State, error) {
+	s.record("JoinTheGame", in)
+	return &api.State{Size: 9}, nil
+}
+
+func (s *fakeServer) WaitTheTurn(ctx context.Context, in *api.EmptyMessage) (*api.State, error) {
+	s.record("WaitTheTurn", in)
+	grpc.SetHeader(ctx, metadata.Pairs(serverapi.ColourHeader, "BLACK"))
+	return &api.State{Size: 9}, nil
+}
+
+func (s *fakeServer) LeaveTheGame(ctx context.Context, in *api.EmptyMessage) (*api.EmptyMessage, error) {
+	s.record("LeaveTheGame", in)
+	return &api.EmptyMessage{}, nil
+}
+
+func (s *fakeServer) MakeTurn(ctx context.Context, in *api.TurnMessage) (*api.State, error) {
+	s.record("MakeTurn", in)
+	if in.GetX() < 1 {
+		return nil, status.Error(codes.InvalidArgument, "wrong turn for a gamer")
+	}
+	return &api.State{Size: 9, Black: &api.State_ColourState{ChipsOnBoard: []*api.TurnMessage{in}}}, nil
+}
+
+func (s *fakeServer) GetGameState(ctx context.Context, in *api.EmptyMessage) (*serverapi.GameStatusMessage, error) {
+	s.record("GetGameState", in)
+	return &serverapi.GameStatusMessage{Status: serverapi.GameStatus_PLAYING, State: &api.State{Size: 9}, MyTurn: true}, nil
+}
+
+func (s *fakeServer) ResumeGame(ctx context.Context, in *api.EmptyMessage) (*serverapi.ResumeMessage, error) {
+	s.record("ResumeGame", in)
+	return &serverapi.ResumeMessage{State: &api.State{Size: 9}}, nil
+}
+
+func (s *fakeServer) JoinGame(ctx context.Context, in *serverapi.JoinRequest) (*serverapi.GameStatusMessage, error) {
+	s.record("JoinGame", in)
+	return &serverapi.GameStatusMessage{Status: serverapi.GameStatus_PLAYING, State: &api.State{Size: 9}, Colour: in.GetColour(), Partner: "Nick"}, nil
+}
+
+func (s *fakeServer) WaitTurn(ctx context.Context, in *api.EmptyMessage) (*serverapi.GameStatusMessage, error) {
+	s.record("WaitTurn", in)
+	return &serverapi.GameStatusMessage{Status: serverapi.GameStatus_PLAYING, State: &api.State{Size: 9}, MyTurn: true, Colour: serverapi.Colour_WHITE}, nil
+}
+
+func (s *fakeServer) WatchPartner(in *api.EmptyMessage, stream serverapi.Session_WatchPartnerServer) error {
+	s.record("WatchPartner", in)
+	<-stream.Context().Done()
+	return status.Error(codes.Canceled, stream.Context().Err().Error())
+}
+
+func (s *fakeServer) GetRating(ctx context.Context, in *serverapi.RatingRequest) (*serverapi.RatingMessage, error) {
+	s.record("GetRating", in)
+	return &serverapi.RatingMessage{Login: in.GetLogin()}, nil
+}
+
+func (s *fakeServer) Leaderboard(ctx context.Context, in *serverapi.LeaderboardRequest) (*serverapi.LeaderboardMessage, error) {
+	s.record("Leaderboard", in)
+	return &serverapi.LeaderboardMessage{}, nil
+}
+
+func (s *fakeServer) GetStats(ctx context.Context, in *serverapi.RatingRequest) (*serverapi.StatsMessage, error) {
+	s.record("GetStats", in)
+	return &serverapi.StatsMessage{Login: in.GetLogin()}, nil
+}
+
+func (s *fakeServer) SendMessage(ctx context.Context, in *serverapi.ChatMessage) (*api.EmptyMessage, error) {
+	s.record("SendMessage", in)
+	return &api.EmptyMessage{}, nil
+}
+
+func (s *fakeServer) Subscribe(in *serverapi.ChannelMessage, stream serverapi.Chat_SubscribeServer) error {
+	s.record("Subscribe", in)
+	grpc.SetHeader(stream.Context(), metadata.Pairs("channel", in.GetChannel().String()))
+	for _, text := range []string{"hi", "bye"} {
+		if err := stream.Send(&serverapi.ChatMessage{Channel: in.GetChannel(), Login: "Nick", Text: text}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *fakeServer) History(ctx context.Context, in *serverapi.HistoryRequest) (*serverapi.HistoryMessage, error) {
+	s.record("History", in)
+	return &serverapi.HistoryMessage{}, nil
+}
+
+// interceptor accepts only Joe with password aaa
+func interceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if strings.Join(md["login"], "") != "Joe" || strings.Join(md["password"], "") != "aaa" {
+		return nil, status.Error(codes.Unauthenticated, "wrong password")
+	}
+	if !strings.HasPrefix(info.FullMethod, "/api.GoGame/") && !strings.HasPrefix(info.FullMethod, "/grpc_server.") {
+		return nil, status.Error(codes.Internal, "wrong method "+info.FullMethod)
+	}
+	return handler(ctx, req)
+}
+
+// streamInterceptor accepts only Joe with password aaa
+func streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	_, err := interceptor(ss.Context(), nil, &grpc.UnaryServerInfo{Server: srv, FullMethod: info.FullMethod},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, handler(srv, ss)
+		})
+	return err
+}
+
+var gatewayTests = []struct {
+	caseName    string
+	method      string
+	path        string
+	body        string
+	basic       bool
+	password    string
+	contentType string
+	wantStatus  int
+	wantCalled  string
+	wantBody    string
+}{
+	{caseName: "register", method: http.MethodPost, path: "/v1/users", basic: true, password: "aaa",
+		wantStatus: http.StatusOK, wantCalled: "RegisterUser", wantBody: "{}"},
+	{caseName: "remove", method: http.MethodDelete, path: "/v1/users", basic: true, password: "aaa",
+		wantStatus: http.StatusOK, wantCalled: "RemoveUser"},
+	{caseName: "change requisites", method: http.MethodPut, path: "/v1/users", password: "aaa",
+		body:       `{"login": "Joe", "password": "bbb"}`,
+		wantStatus: http.StatusOK, wantCalled: "ChangeUserRequisits"},
+	{caseName: "enter lobby by headers", method: http.MethodPost, path: "/v1/lobby", password: "aaa",
+		wantStatus: http.StatusOK, wantCalled: "EnterTheLobby"},
+	{caseName: "leave lobby error", method: http.MethodDelete, path: "/v1/lobby", password: "aaa",
+		wantStatus: http.StatusInternalServerError, wantCalled: "LeaveTheLobby", wantBody: `"code":"Internal"`},
+	{caseName: "game state", method: http.MethodGet, path: "/v1/game", basic: true, password: "aaa",
+		wantStatus: http.StatusOK, wantCalled: "GetGameState", wantBody: `"status":"PLAYING"`},
+	{caseName: "list games not implemented", method: http.MethodGet, path: "/v1/games", basic: true, password: "aaa",
+		wantStatus: http.StatusNotImplemented, wantBody: `"code":"Unimplemented"`},
+	{caseName: "undo not implemented", method: http.MethodPost, path: "/v1/game/undo", basic: true, password: "aaa",
+		wantStatus: http.StatusNotImplemented, wantBody: `"code":"Unimplemented"`},
+	{caseName: "join", method: http.MethodPost, path: "/v1/game", basic: true, password: "aaa",
+		wantStatus: http.StatusOK, wantCalled: "JoinTheGame", wantBody: `"size":"9"`},
+	{caseName: "join with colour", method: http.MethodPost, path: "/v1/game/join", basic: true, password: "aaa",
+		body:       `{"colour": "BLACK"}`,
+		wantStatus: http.StatusOK, wantCalled: "JoinGame", wantBody: `"colour":"BLACK","partner":"Nick"`},
+	{caseName: "leave game", method: http.MethodDelete, path: "/v1/game", basic: true, password: "aaa",
+		wantStatus: http.StatusOK, wantCalled: "LeaveTheGame"},
+	{caseName: "wait turn", method: http.MethodGet, path: "/v1/game/turn", basic: true, password: "aaa",
+		wantStatus: http.StatusOK, wantCalled: "WaitTheTurn", wantBody: `"game_over":false`},
+	{caseName: "wait turn with colour", method: http.MethodGet, path: "/v1/game/wait", basic: true, password: "aaa",
+		wantStatus: http.StatusOK, wantCalled: "WaitTurn", wantBody: `"colour":"WHITE"`},
+	{caseName: "make turn", method: http.MethodPost, path: "/v1/game/turn", basic: true, password: "aaa",
+		body:       `{"x": 3, "y": 4}`,
+		wantStatus: http.StatusOK, wantCalled: "MakeTurn", wantBody: `"chips_on_board":[{"x":"3","y":"4"}]`},
+	{caseName: "wrong turn", method: http.MethodPost, path: "/v1/game/turn", basic: true, password: "aaa",
+		body:       `{"x": 0, "y": 4}`,
+		wantStatus: http.StatusBadRequest, wantCalled: "MakeTurn", wantBody: `"code":"InvalidArgument"`},
+	{caseName: "malformed body", method: http.MethodPost, path: "/v1/game/turn", basic: true, password: "aaa",
+		body:       `{"x": `,
+		wantStatus: http.StatusBadRequest},
+	{caseName: "json with charset", method: http.MethodPost, path: "/v1/lobby", basic: true, password: "aaa",
+		contentType: "application/json; charset=utf-8",
+		wantStatus:  http.StatusOK, wantCalled: "EnterTheLobby"},
+	{caseName: "form post", method: http.MethodPost, path: "/v1/lobby", basic: true, password: "aaa",
+		contentType: "application/x-www-form-urlencoded",
+		wantStatus:  http.StatusUnsupportedMediaType, wantBody: `"code":"UnsupportedMediaType"`},
+	{caseName: "plain text post", method: http.MethodPost, path: "/v1/game/turn", basic: true, password: "aaa",
+		body: `{"x": 3, "y": 4}`, contentType: "text/plain",
+		wantStatus: http.StatusUnsupportedMediaType, wantBody: `"code":"UnsupportedMediaType"`},
+	{caseName: "no content type", method: http.MethodDelete, path: "/v1/users", basic: true, password: "aaa",
+		contentType: "none",
+		wantStatus:  http.StatusUnsupportedMediaType, wantBody: `"code":"UnsupportedMediaType"`},
+	{caseName: "wrong password", method: http.MethodPost, path: "/v1/lobby", basic: true, password: "bbb",
+		wantStatus: http.StatusUnauthorized, wantBody: `"code":"Unauthenticated"`},
+	{caseName: "unknown route", method: http.MethodGet, path: "/v1/unknown", basic: true, password: "aaa",
+		wantStatus: http.StatusNotFound},
+	{caseName: "wrong method", method: http.MethodPatch, path: "/v1/users", basic: true, password: "aaa",
+		wantStatus: http.StatusMethodNotAllowed},
+}
+
+func TestGateway(t *testing.T) {
+	for _, test := range gatewayTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			srv := &fakeServer{}
+			gateway := New(srv, interceptor)
+
+			r := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+			switch {
+			case test.method == http.MethodGet || test.contentType == "none":
+			case test.contentType == "":
+				r.Header.Set("Content-Type", "application/json")
+			default:
+				r.Header.Set("Content-Type", test.contentType)
+			}
+			if test.basic {
+				r.SetBasicAuth("Joe", test.password)
+			} else {
+				r.Header.Set("X-Login", "Joe")
+				r.Header.Set("X-Password", test.password)
+			}
+			w := httptest.NewRecorder()
+			gateway.ServeHTTP(w, r)
+
+			if w.Code != test.wantStatus {
+				t.Errorf("Unexpected status:\nwant: %d,\ngot: %d.", test.wantStatus, w.Code)
+			}
+			if srv.called != test.wantCalled {
+				t.Errorf("Unexpected called method:\nwant: %q,\ngot: %q.", test.wantCalled, srv.called)
+			}
+			if body := w.Body.String(); !strings.Contains(body, test.wantBody) {
+				t.Errorf("Unexpected body:\nwant: containing %s,\ngot: %s.", test.wantBody, body)
+			}
+		})
+	}
+}
+
+func TestChangeRequisitesBody(t *testing.T) {
+	srv := &fakeServer{}
+	r := httptest.NewRequest(http.MethodPut, "/v1/users", strings.NewReader(`{"login": "Nick", "password": "bbb"}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.SetBasicAuth("Joe", "aaa")
+	New(srv, interceptor).ServeHTTP(httptest.NewRecorder(), r)
+
+	requisites, ok := srv.request.(*api.RequisitsMessage)
+	if !ok || requisites.GetLogin() != "Nick" || requisites.GetPassword() != "bbb" {
+		t.Errorf("Unexpected request:\nwant: %v,\ngot: %v.", &api.RequisitsMessage{Login: "Nick", Password: "bbb"}, srv.request)
+	}
+}
+
+var queryTests = []struct {
+	caseName   string
+	path       string
+	wantStatus int
+	want       proto.Message
+}{
+	{caseName: "rating", path: "/v1/ratings?login=Nick",
+		wantStatus: http.StatusOK, want: &serverapi.RatingRequest{Login: "Nick"}},
+	{caseName: "numeric login", path: "/v1/ratings?login=123",
+		wantStatus: http.StatusOK, want: &serverapi.RatingRequest{Login: "123"}},
+	{caseName: "escaped login", path: "/v1/ratings?login=%22Nick%22",
+		wantStatus: http.StatusOK, want: &serverapi.RatingRequest{Login: `"Nick"`}},
+	{caseName: "unknown parameter", path: "/v1/ratings?name=Nick",
+		wantStatus: http.StatusBadRequest},
+	{caseName: "leaderboard page", path: "/v1/leaderboard?offset=20&limit=10",
+		wantStatus: http.StatusOK, want: &serverapi.LeaderboardRequest{Offset: 20, Limit: 10}},
+	{caseName: "leaderboard first page", path: "/v1/leaderboard",
+		wantStatus: http.StatusOK, want: &serverapi.LeaderboardRequest{}},
+	{caseName: "wrong limit", path: "/v1/leaderboard?limit=ten",
+		wantStatus: http.StatusBadRequest},
+	{caseName: "injected field", path: "/v1/leaderboard?limit=1,%22offset%22:5",
+		wantStatus: http.StatusBadRequest},
+	{caseName: "stats", path: "/v1/stats?login=Nick",
+		wantStatus: http.StatusOK, want: &serverapi.RatingRequest{Login: "Nick"}},
+	{caseName: "game chat history", path: "/v1/chat?channel=GAME&limit=5",
+		wantStatus: http.StatusOK, want: &serverapi.HistoryRequest{Channel: serverapi.ChatChannel_GAME, Limit: 5}},
+	{caseName: "chat history by channel number", path: "/v1/chat?channel=1",
+		wantStatus: http.StatusOK, want: &serverapi.HistoryRequest{Channel: serverapi.ChatChannel_GAME}},
+	{caseName: "unknown channel", path: "/v1/chat?channel=PRIVATE",
+		wantStatus: http.StatusBadRequest},
+}
+
+func TestQuery(t *testing.T) {
+	for _, test := range queryTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			srv := &fakeServer{}
+			r := httptest.NewRequest(http.MethodGet, test.path, nil)
+			r.SetBasicAuth("Joe", "aaa")
+			w := httptest.NewRecorder()
+			New(srv, interceptor).ServeHTTP(w, r)
+
+			if w.Code != test.wantStatus {
+				t.Errorf("Unexpected status:\nwant: %d,\ngot: %d.", test.wantStatus, w.Code)
+			}
+			if test.want == nil {
+				return
+			}
+			if got, ok := srv.request.(proto.Message); !ok || !proto.Equal(got, test.want) {
+				t.Errorf("Unexpected request:\nwant: %v,\ngot: %v.", test.want, srv.request)
+			}
+		})
+	}
+}
+
+var httpStatusTests = []struct {
+	code codes.Code
+	want int
+}{
+	{codes.OK, http.StatusOK},
+	{codes.Unauthenticated, http.StatusUnauthorized},
+	{codes.PermissionDenied, http.StatusForbidden},
+	{codes.NotFound, http.StatusNotFound},
+	{codes.InvalidArgument, http.StatusBadRequest},
+	{codes.Unavailable, http.StatusServiceUnavailable},
+	{codes.Internal, http.StatusInternalServerError},
+	{codes.Unknown, http.StatusInternalServerError},
+}
+
+func TestGameHeader(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/v1/game/turn", nil)
+	r.SetBasicAuth("Joe", "aaa")
+	w := httptest.NewRecorder()
+	New(&fakeServer{}, interceptor).ServeHTTP(w, r)
+
+	if colour := w.Header().Get("Grpc-Metadata-Colour"); colour != "BLACK" {
+		t.Errorf("Unexpected colour header:\nwant: %q,\ngot: %q.", "BLACK", colour)
+	}
+}
+
+func TestHTTPStatusFromCode(t *testing.T) {
+	for _, test := range httpStatusTests {
+		t.Run(test.code.String(), func(t *testing.T) {
+			if got := HTTPStatusFromCode(test.code); got != test.want {
+				t.Errorf("Unexpected status:\nwant: %d,\ngot: %d.", test.want, got)
+			}
+		})
+	}
+}
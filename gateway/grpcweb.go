@@ -0,0 +1,179 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package gateway
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/yagoggame/api"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	grpcWebContentType     = "application/grpc-web"
+	grpcWebTextContentType = "application/grpc-web-text"
+	// frame flags of gRPC-Web protocol
+	dataFrame    byte = 0x00
+	trailerFrame byte = 0x80
+	frameHeader       = 5
+)
+
+// GRPCWeb implements http.Handler serving unary calls of api.GoGameServer
+// by gRPC-Web protocol, both binary and base64 text variants
+type GRPCWeb struct {
+	srv         api.GoGameServer
+	interceptor grpc.UnaryServerInterceptor
+}
+
+// NewGRPCWeb constructs new GRPCWeb to srv.
+// interceptor is invoked for every call as for gRPC unary call.
+func NewGRPCWeb(srv api.GoGameServer, interceptor grpc.UnaryServerInterceptor) *GRPCWeb {
+	return &GRPCWeb{srv: srv, interceptor: interceptor}
+}
+
+// IsGRPCWeb reports whether r is a gRPC-Web request
+func IsGRPCWeb(r *http.Request) bool {
+	return r.Method == http.MethodPost &&
+		strings.HasPrefix(r.Header.Get("Content-Type"), grpcWebContentType)
+}
+
+// ServeHTTP serves gRPC-Web call
+func (web *GRPCWeb) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	text := strings.HasPrefix(r.Header.Get("Content-Type"), grpcWebTextContentType)
+	contentType := grpcWebContentType + "+proto"
+	if text {
+		contentType = grpcWebTextContentType + "+proto"
+	}
+	w.Header().Set("Content-Type", contentType)
+
+	resp, header, err := web.call(r, text)
+	if err != nil {
+		writeTrailersOnly(w, status.Convert(err))
+		return
+	}
+
+	data, err := proto.Marshal(resp)
+	if err != nil {
+		writeTrailersOnly(w, status.Newf(codes.Internal, "can't encode response: %v", err))
+		return
+	}
+
+	var body bytes.Buffer
+	writeFrame(&body, dataFrame, data)
+	writeFrame(&body, trailerFrame, []byte("grpc-status: 0\r\ngrpc-message: \r\n"))
+
+	for key, values := range header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+	if text {
+		w.Write([]byte(base64.StdEncoding.EncodeToString(body.Bytes())))
+		return
+	}
+	w.Write(body.Bytes())
+}
+
+func (web *GRPCWeb) call(r *http.Request, text bool) (proto.Message, metadata.MD, error) {
+	rt, ok := routeByMethod(r.URL.Path)
+	if !ok {
+		return nil, nil, status.Errorf(codes.Unimplemented, "unknown method %s", r.URL.Path)
+	}
+
+	var body io.Reader = io.LimitReader(r.Body, maxBodySize)
+	if text {
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+	data, err := readDataFrame(body)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "can't read request: %v", err)
+	}
+
+	req := rt.newRequest()
+	if err := proto.Unmarshal(data, req); err != nil {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "can't decode request: %v", err)
+	}
+
+	ctx := metadata.NewIncomingContext(r.Context(), requisitesMetadata(r))
+	return invoke(ctx, web.srv, web.interceptor, rt, req)
+}
+
+// readDataFrame reads the single data frame of unary request
+func readDataFrame(r io.Reader) ([]byte, error) {
+	header := make([]byte, frameHeader)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if header[0] != dataFrame {
+		return nil, fmt.Errorf("unexpected frame flags %#x", header[0])
+	}
+
+	length := binary.BigEndian.Uint32(header[1:])
+	if length > maxBodySize {
+		return nil, fmt.Errorf("message of %d bytes is too large", length)
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	if extra, _ := ioutil.ReadAll(r); len(extra) > 0 {
+		return nil, fmt.Errorf("%d bytes after the message", len(extra))
+	}
+	return data, nil
+}
+
+func writeFrame(w *bytes.Buffer, flags byte, data []byte) {
+	header := make([]byte, frameHeader)
+	header[0] = flags
+	binary.BigEndian.PutUint32(header[1:], uint32(len(data)))
+	w.Write(header)
+	w.Write(data)
+}
+
+// writeTrailersOnly writes error status as HTTP headers without body
+func writeTrailersOnly(w http.ResponseWriter, st *status.Status) {
+	w.Header().Set("grpc-status", strconv.Itoa(int(st.Code())))
+	w.Header().Set("grpc-message", encodeGRPCMessage(st.Message()))
+	w.WriteHeader(http.StatusOK)
+}
+
+// encodeGRPCMessage percent-encodes message as required by gRPC protocol
+func encodeGRPCMessage(message string) string {
+	var builder strings.Builder
+	for i := 0; i < len(message); i++ {
+		c := message[i]
+		if c < ' ' || c > '~' || c == '%' {
+			fmt.Fprintf(&builder, "%%%02X", c)
+			continue
+		}
+		builder.WriteByte(c)
+	}
+	return builder.String()
+}
@@ -0,0 +1,144 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package gateway_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/yagoggame/api"
+	. "github.com/yagoggame/grpc_server/gateway"
+)
+
+var grpcWebTests = []struct {
+	caseName    string
+	contentType string
+	path        string
+	password    string
+	request     proto.Message
+	wantStatus  string
+	wantX       int64
+}{
+	{
+		caseName: "binary", contentType: "application/grpc-web+proto",
+		path: "/api.GoGame/MakeTurn", password: "aaa",
+		request:    &api.TurnMessage{X: 3, Y: 4},
+		wantStatus: "0", wantX: 3,
+	},
+	{
+		caseName: "text", contentType: "application/grpc-web-text",
+		path: "/api.GoGame/MakeTurn", password: "aaa",
+		request:    &api.TurnMessage{X: 5, Y: 4},
+		wantStatus: "0", wantX: 5,
+	},
+	{
+		caseName: "application error", contentType: "application/grpc-web+proto",
+		path: "/api.GoGame/MakeTurn", password: "aaa",
+		request:    &api.TurnMessage{X: 0, Y: 4},
+		wantStatus: "3",
+	},
+	{
+		caseName: "wrong password", contentType: "application/grpc-web+proto",
+		path: "/api.GoGame/MakeTurn", password: "bbb",
+		request:    &api.TurnMessage{X: 3, Y: 4},
+		wantStatus: "16",
+	},
+	{
+		caseName: "unknown method", contentType: "application/grpc-web+proto",
+		path: "/api.GoGame/Resign", password: "aaa",
+		request:    &api.EmptyMessage{},
+		wantStatus: "12",
+	},
+}
+
+func TestGRPCWeb(t *testing.T) {
+	handler := Handler(&fakeServer{}, interceptor, Options{GRPCWeb: true})
+
+	for _, test := range grpcWebTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			text := strings.HasPrefix(test.contentType, "application/grpc-web-text")
+			r := httptest.NewRequest(http.MethodPost, test.path, bytes.NewReader(encodeFrame(t, test.request, text)))
+			r.Header.Set("Content-Type", test.contentType)
+			r.Header.Set("login", "Joe")
+			r.Header.Set("password", test.password)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Unexpected HTTP status:\nwant: %d,\ngot: %d.", http.StatusOK, w.Code)
+			}
+			if test.wantStatus != "0" {
+				if got := w.Header().Get("grpc-status"); got != test.wantStatus {
+					t.Errorf("Unexpected grpc-status:\nwant: %s,\ngot: %s.", test.wantStatus, got)
+				}
+				return
+			}
+
+			state, trailer := decodeResponse(t, w.Body.Bytes(), text)
+			if !strings.Contains(trailer, "grpc-status: 0") {
+				t.Errorf("Unexpected trailer:\nwant: grpc-status: 0,\ngot: %q.", trailer)
+			}
+			if got := state.GetBlack().GetChipsOnBoard()[0].GetX(); got != test.wantX {
+				t.Errorf("Unexpected x:\nwant: %d,\ngot: %d.", test.wantX, got)
+			}
+		})
+	}
+}
+
+func encodeFrame(t *testing.T, msg proto.Message, text bool) []byte {
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		t.Fatalf("Unexpected Marshal err: %v.", err)
+	}
+	frame := make([]byte, 5, 5+len(data))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(data)))
+	frame = append(frame, data...)
+	if text {
+		return []byte(base64.StdEncoding.EncodeToString(frame))
+	}
+	return frame
+}
+
+func decodeResponse(t *testing.T, body []byte, text bool) (*api.State, string) {
+	if text {
+		var err error
+		if body, err = ioutil.ReadAll(base64.NewDecoder(base64.StdEncoding, bytes.NewReader(body))); err != nil {
+			t.Fatalf("Unexpected base64 err: %v.", err)
+		}
+	}
+
+	state := &api.State{}
+	var trailer string
+	for len(body) >= 5 {
+		length := binary.BigEndian.Uint32(body[1:5])
+		data := body[5 : 5+length]
+		if body[0]&0x80 != 0 {
+			trailer = string(data)
+		} else if err := proto.Unmarshal(data, state); err != nil {
+			t.Fatalf("Unexpected Unmarshal err: %v.", err)
+		}
+		body = body[5+length:]
+	}
+	return state, trailer
+}
@@ -0,0 +1,403 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"sync"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/gorilla/websocket"
+	"github.com/yagoggame/api"
+	"github.com/yagoggame/grpc_server/serverapi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// WebSocketPath is a path of WebSocket bridge
+const WebSocketPath = "/v1/ws"
+
+// maxSocketCalls is a maximum number of calls in flight of one WebSocket connection
+const maxSocketCalls = 16
+
+// WebSocket implements http.Handler bridging WebSocket connections
+// to api.GoGameServer.
+//
+// The first message of client carries requisites: {"login": ..., "password": ...}.
+// Every next one is a call: {"id": 1, "method": "WaitTheTurn", "request": {}}.
+// Calls are served concurrently and their results are pushed to the client
+// as soon as they are ready: {"id": 1, "response": {...}, "header": {"colour": [...], ...}}
+// or {"id": 1, "error": {"code": ..., "message": ...}},
+// so blocking JoinTheGame and WaitTheTurn deliver game events.
+//
+// Streaming methods WatchPartner, WatchGame, Subscribe, WatchLobby and Seek
+// push every message as a result with id of the call
+// and finish by {"id": 1, "end": true} or by an error.
+// A call in flight is cancelled by {"id": 1, "cancel": true}.
+// No more than maxSocketCalls calls may be in flight,
+// the extra ones fail with ResourceExhausted status.
+type WebSocket struct {
+	srv               api.GoGameServer
+	interceptor       grpc.UnaryServerInterceptor
+	streamInterceptor grpc.StreamServerInterceptor
+	upgrader          websocket.Upgrader
+	marshaler         jsonpb.Marshaler
+}
+
+type wsRequisites struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+type wsCall struct {
+	ID      int64           `json:"id"`
+	Method  string          `json:"method"`
+	Request json.RawMessage `json:"request"`
+	Cancel  bool            `json:"cancel"`
+}
+
+type wsResult struct {
+	ID       int64           `json:"id"`
+	Response json.RawMessage `json:"response,omitempty"`
+	Header   metadata.MD     `json:"header,omitempty"`
+	Error    *errorBody      `json:"error,omitempty"`
+	End      bool            `json:"end,omitempty"`
+}
+
+type streamRoute struct {
+	method     string
+	newRequest func() proto.Message
+	call       func(srv api.GoGameServer, req proto.Message, stream grpc.ServerStream) error
+}
+
+var streamRoutes = []streamRoute{
+	{sessionPrefix + "WatchPartner", emptyRequest,
+		func(srv api.GoGameServer, req proto.Message, stream grpc.ServerStream) error {
+			return session(srv).WatchPartner(req.(*api.EmptyMessage), partnerEvents{stream})
+		}},
+	{spectatorPrefix + "WatchGame", func() proto.Message { return &serverapi.GameIDMessage{} },
+		func(srv api.GoGameServer, req proto.Message, stream grpc.ServerStream) error {
+			return spectator(srv).WatchGame(req.(*serverapi.GameIDMessage), gameStates{stream})
+		}},
+	{chatPrefix + "Subscribe", func() proto.Message { return &serverapi.ChannelMessage{} },
+		func(srv api.GoGameServer, req proto.Message, stream grpc.ServerStream) error {
+			return chat(srv).Subscribe(req.(*serverapi.ChannelMessage), chatMessages{stream})
+		}},
+	{lobbyPrefix + "WatchLobby", emptyRequest,
+		func(srv api.GoGameServer, req proto.Message, stream grpc.ServerStream) error {
+			return lobby(srv).WatchLobby(req.(*api.EmptyMessage), lobbyEvents{stream})
+		}},
+	{matchmakingPrefix + "Seek", func() proto.Message { return &serverapi.SeekRequest{} },
+		func(srv api.GoGameServer, req proto.Message, stream grpc.ServerStream) error {
+			return matchmaking(srv).Seek(req.(*serverapi.SeekRequest), seekEvents{stream})
+		}},
+}
+
+// streamRouteByName returns streaming route of gRPC method name without service
+func streamRouteByName(name string) (*streamRoute, bool) {
+	for i := range streamRoutes {
+		if path.Base(streamRoutes[i].method) == name {
+			return &streamRoutes[i], true
+		}
+	}
+	return nil, false
+}
+
+type partnerEvents struct{ grpc.ServerStream }
+
+func (stream partnerEvents) Send(m *serverapi.PartnerEvent) error { return stream.SendMsg(m) }
+
+type gameStates struct{ grpc.ServerStream }
+
+func (stream gameStates) Send(m *api.State) error { return stream.SendMsg(m) }
+
+type chatMessages struct{ grpc.ServerStream }
+
+func (stream chatMessages) Send(m *serverapi.ChatMessage) error { return stream.SendMsg(m) }
+
+type lobbyEvents struct{ grpc.ServerStream }
+
+func (stream lobbyEvents) Send(m *serverapi.LobbyMessage) error { return stream.SendMsg(m) }
+
+type seekEvents struct{ grpc.ServerStream }
+
+func (stream seekEvents) Send(m *serverapi.SeekMessage) error { return stream.SendMsg(m) }
+
+// NewWebSocket constructs new WebSocket bridge to srv accepting connections
+// from pages of origins. interceptor is invoked for every unary call
+// as for gRPC unary call, streamInterceptor for every streaming one.
+// Streaming calls are refused, if streamInterceptor is nil.
+func NewWebSocket(srv api.GoGameServer, interceptor grpc.UnaryServerInterceptor,
+	streamInterceptor grpc.StreamServerInterceptor, origins []string) *WebSocket {
+	return &WebSocket{
+		srv:               srv,
+		interceptor:       interceptor,
+		streamInterceptor: streamInterceptor,
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				// requisites are sent by the page itself, not by browser,
+				// so any allowed origin may connect
+				allowed, _ := originAllowed(origin, origins)
+				// non browser clients send no origin
+				return origin == "" || allowed || sameOrigin(r)
+			},
+		},
+		marshaler: jsonpb.Marshaler{EmitDefaults: true, OrigName: true},
+	}
+}
+
+// ServeHTTP upgrades connection to WebSocket and serves calls of it
+func (ws *WebSocket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := ws.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade replies with error itself
+		return
+	}
+	defer conn.Close()
+	conn.SetReadLimit(maxBodySize)
+
+	var requisites wsRequisites
+	if err := conn.ReadJSON(&requisites); err != nil {
+		conn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "requisites expected"))
+		return
+	}
+	md := metadata.Pairs("login", requisites.Login, "password", requisites.Password)
+
+	ctx, cancel := context.WithCancel(metadata.NewIncomingContext(r.Context(), md))
+	var (
+		wg    sync.WaitGroup
+		mutex sync.Mutex
+	)
+	defer wg.Wait()
+	defer cancel()
+
+	write := func(result *wsResult) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if err := conn.WriteJSON(result); err != nil {
+			log.Printf("WebSocket write error: %v", err)
+			cancel()
+		}
+	}
+	calls := newSocketCalls()
+
+	for {
+		var call wsCall
+		if err := conn.ReadJSON(&call); err != nil {
+			if _, ok := err.(*websocket.CloseError); !ok && ctx.Err() == nil {
+				log.Printf("WebSocket read error: %v", err)
+			}
+			return
+		}
+
+		if call.Cancel {
+			calls.cancel(call.ID)
+			continue
+		}
+		callCtx, err := calls.start(ctx, call.ID)
+		if err != nil {
+			write(errorResult(call.ID, err))
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := ws.call(callCtx, &call, write)
+			// the call leaves flight before its result, so the client may reuse id at once
+			calls.finish(call.ID)
+			write(result)
+		}()
+	}
+}
+
+// socketCalls tracks cancel functions of calls in flight of a connection
+type socketCalls struct {
+	mutex   sync.Mutex
+	cancels map[int64]context.CancelFunc
+}
+
+func newSocketCalls() *socketCalls {
+	return &socketCalls{cancels: make(map[int64]context.CancelFunc)}
+}
+
+// start returns context of the call with id,
+// if it isn't in flight yet and there is a room for it
+func (calls *socketCalls) start(ctx context.Context, id int64) (context.Context, error) {
+	calls.mutex.Lock()
+	defer calls.mutex.Unlock()
+	if _, ok := calls.cancels[id]; ok {
+		return nil, status.Errorf(codes.InvalidArgument, "call %d is in flight", id)
+	}
+	if len(calls.cancels) >= maxSocketCalls {
+		return nil, status.Errorf(codes.ResourceExhausted, "more than %d calls in flight", maxSocketCalls)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	calls.cancels[id] = cancel
+	return ctx, nil
+}
+
+// cancel cancels the call with id, if it is in flight
+func (calls *socketCalls) cancel(id int64) {
+	calls.mutex.Lock()
+	defer calls.mutex.Unlock()
+	if cancel, ok := calls.cancels[id]; ok {
+		cancel()
+	}
+}
+
+// finish releases the call with id
+func (calls *socketCalls) finish(id int64) {
+	calls.mutex.Lock()
+	defer calls.mutex.Unlock()
+	if cancel, ok := calls.cancels[id]; ok {
+		cancel()
+		delete(calls.cancels, id)
+	}
+}
+
+// call serves the call and returns its final result.
+// Messages of streaming calls are written by write as they are sent.
+func (ws *WebSocket) call(ctx context.Context, call *wsCall, write func(*wsResult)) *wsResult {
+	if rt, ok := streamRouteByName(call.Method); ok {
+		header, err := ws.stream(ctx, call, rt, write)
+		if err != nil {
+			return errorResult(call.ID, err)
+		}
+		return &wsResult{ID: call.ID, Header: header, End: true}
+	}
+
+	resp, header, err := ws.invoke(ctx, call)
+	if err != nil {
+		return errorResult(call.ID, err)
+	}
+	result, err := ws.result(call.ID, resp, header)
+	if err != nil {
+		return errorResult(call.ID, err)
+	}
+	return result
+}
+
+func (ws *WebSocket) result(id int64, resp proto.Message, header metadata.MD) (*wsResult, error) {
+	var buf bytes.Buffer
+	if err := ws.marshaler.Marshal(&buf, resp); err != nil {
+		return nil, status.Errorf(codes.Internal, "can't encode response: %v", err)
+	}
+	return &wsResult{ID: id, Response: buf.Bytes(), Header: header}, nil
+}
+
+func errorResult(id int64, err error) *wsResult {
+	st := status.Convert(err)
+	return &wsResult{ID: id, Error: &errorBody{Code: st.Code().String(), Message: st.Message()}}
+}
+
+func decodeCall(call *wsCall, req proto.Message) error {
+	if len(bytes.TrimSpace(call.Request)) > 0 {
+		if err := jsonpb.Unmarshal(bytes.NewReader(call.Request), req); err != nil {
+			return status.Errorf(codes.InvalidArgument, "can't decode request: %v", err)
+		}
+	}
+	return nil
+}
+
+func (ws *WebSocket) invoke(ctx context.Context, call *wsCall) (proto.Message, metadata.MD, error) {
+	rt, ok := routeByName(call.Method)
+	if !ok {
+		return nil, nil, status.Errorf(codes.Unimplemented, "unknown method %q", call.Method)
+	}
+
+	req := rt.newRequest()
+	if err := decodeCall(call, req); err != nil {
+		return nil, nil, err
+	}
+	return invoke(ctx, ws.srv, ws.interceptor, rt, req)
+}
+
+// stream calls streaming method of rt through streamInterceptor
+// and returns headers set by the method, which aren't sent with a message yet
+func (ws *WebSocket) stream(ctx context.Context, call *wsCall, rt *streamRoute, write func(*wsResult)) (metadata.MD, error) {
+	if ws.streamInterceptor == nil {
+		return nil, status.Errorf(codes.Unimplemented, "streaming method %q is not enabled", call.Method)
+	}
+
+	req := rt.newRequest()
+	if err := decodeCall(call, req); err != nil {
+		return nil, err
+	}
+
+	header := &headerStream{method: rt.method}
+	stream := &socketStream{
+		ctx:    grpc.NewContextWithServerTransportStream(ctx, header),
+		id:     call.ID,
+		ws:     ws,
+		header: header,
+		write:  write,
+	}
+	info := &grpc.StreamServerInfo{FullMethod: rt.method, IsServerStream: true}
+	err := ws.streamInterceptor(ws.srv, stream, info, func(srv interface{}, ss grpc.ServerStream) error {
+		return rt.call(srv.(api.GoGameServer), req, ss)
+	})
+	return header.header, err
+}
+
+// socketStream is grpc.ServerStream pushing sent messages to WebSocket
+type socketStream struct {
+	ctx    context.Context
+	id     int64
+	ws     *WebSocket
+	header *headerStream
+	write  func(*wsResult)
+}
+
+func (stream *socketStream) Context() context.Context { return stream.ctx }
+
+func (stream *socketStream) SetHeader(md metadata.MD) error { return stream.header.SetHeader(md) }
+
+func (stream *socketStream) SendHeader(md metadata.MD) error { return stream.header.SetHeader(md) }
+
+func (stream *socketStream) SetTrailer(md metadata.MD) {}
+
+// SendMsg writes m with headers set since previous message
+func (stream *socketStream) SendMsg(m interface{}) error {
+	if err := stream.ctx.Err(); err != nil {
+		return status.Error(codes.Canceled, err.Error())
+	}
+
+	header := stream.header.header
+	stream.header.header = nil
+
+	result, err := stream.ws.result(stream.id, m.(proto.Message), header)
+	if err != nil {
+		return err
+	}
+	stream.write(result)
+	return nil
+}
+
+// RecvMsg reports end of client messages, the only request is decoded by bridge itself
+func (stream *socketStream) RecvMsg(m interface{}) error { return io.EOF }
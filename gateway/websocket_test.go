@@ -0,0 +1,214 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package gateway_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	. "github.com/yagoggame/grpc_server/gateway"
+)
+
+type wsResult struct {
+	ID       int64               `json:"id"`
+	Response json.RawMessage     `json:"response"`
+	Header   map[string][]string `json:"header"`
+	Error    *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+	End bool `json:"end"`
+}
+
+func TestWebSocket(t *testing.T) {
+	server := httptest.NewServer(Handler(&fakeServer{}, interceptor,
+		Options{WebSocket: true, Origins: []string{"https://board.example.org"}}))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + WebSocketPath
+
+	header := http.Header{"Origin": {"https://evil.example.org"}}
+	if _, _, err := websocket.DefaultDialer.Dial(url, header); err == nil {
+		t.Errorf("Unexpected Dial from foreign origin success.")
+	}
+
+	header = http.Header{"Origin": {"https://board.example.org"}}
+	conn, _, err := websocket.DefaultDialer.Dial(url, header)
+	if err != nil {
+		t.Fatalf("Unexpected Dial err: %v.", err)
+	}
+	defer conn.Close()
+
+	calls := []string{
+		`{"login": "Joe", "password": "aaa"}`,
+		`{"id": 1, "method": "JoinTheGame"}`,
+		`{"id": 2, "method": "MakeTurn", "request": {"x": 3, "y": 4}}`,
+		`{"id": 3, "method": "Resign"}`,
+		`{"id": 4, "method": "WatchPartner"}`,
+	}
+	for _, call := range calls {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(call)); err != nil {
+			t.Fatalf("Unexpected WriteMessage err: %v.", err)
+		}
+	}
+
+	results := make(map[int64]*wsResult)
+	for len(results) < len(calls)-1 {
+		result := &wsResult{}
+		if err := conn.ReadJSON(result); err != nil {
+			t.Fatalf("Unexpected ReadJSON err: %v.", err)
+		}
+		results[result.ID] = result
+	}
+
+	if r := results[1]; r.Error != nil || !strings.Contains(string(r.Response), `"size":"9"`) {
+		t.Errorf("Unexpected JoinTheGame result: %s, %v.", r.Response, r.Error)
+	}
+	if r := results[2]; r.Error != nil || !strings.Contains(string(r.Response), `"x":"3"`) {
+		t.Errorf("Unexpected MakeTurn result: %s, %v.", r.Response, r.Error)
+	}
+	if r := results[3]; r.Error == nil || r.Error.Code != "Unimplemented" {
+		t.Errorf("Unexpected Resign result: %s, %v.", r.Response, r.Error)
+	}
+	if r := results[4]; r.Error == nil || r.Error.Code != "Unimplemented" {
+		t.Errorf("Unexpected WatchPartner without stream interceptor result: %s, %v.", r.Response, r.Error)
+	}
+}
+
+func TestWebSocketWrongPassword(t *testing.T) {
+	server := httptest.NewServer(Handler(&fakeServer{}, interceptor, Options{WebSocket: true}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+WebSocketPath, nil)
+	if err != nil {
+		t.Fatalf("Unexpected Dial err: %v.", err)
+	}
+	defer conn.Close()
+
+	conn.WriteJSON(map[string]string{"login": "Joe", "password": "bbb"})
+	conn.WriteJSON(map[string]interface{}{"id": 1, "method": "EnterTheLobby"})
+
+	result := &wsResult{}
+	if err := conn.ReadJSON(result); err != nil {
+		t.Fatalf("Unexpected ReadJSON err: %v.", err)
+	}
+	if result.Error == nil || result.Error.Code != "Unauthenticated" {
+		t.Errorf("Unexpected result:\nwant: Unauthenticated error,\ngot: %s, %v.", result.Response, result.Error)
+	}
+}
+
+// dialSocket connects to WebSocket bridge of server as Joe
+func dialSocket(t *testing.T, server *httptest.Server) *websocket.Conn {
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+WebSocketPath, nil)
+	if err != nil {
+		t.Fatalf("Unexpected Dial err: %v.", err)
+	}
+	if err := conn.WriteJSON(map[string]string{"login": "Joe", "password": "aaa"}); err != nil {
+		t.Fatalf("Unexpected WriteJSON err: %v.", err)
+	}
+	return conn
+}
+
+// readResult reads the next result of conn
+func readResult(t *testing.T, conn *websocket.Conn) *wsResult {
+	result := &wsResult{}
+	if err := conn.ReadJSON(result); err != nil {
+		t.Fatalf("Unexpected ReadJSON err: %v.", err)
+	}
+	return result
+}
+
+func TestWebSocketStream(t *testing.T) {
+	server := httptest.NewServer(Handler(&fakeServer{}, interceptor,
+		Options{WebSocket: true, StreamInterceptor: streamInterceptor}))
+	defer server.Close()
+	conn := dialSocket(t, server)
+	defer conn.Close()
+
+	conn.WriteJSON(map[string]interface{}{"id": 1, "method": "Subscribe", "request": map[string]string{"channel": "GAME"}})
+	for i, text := range []string{"hi", "bye"} {
+		result := readResult(t, conn)
+		if result.ID != 1 || result.Error != nil || !strings.Contains(string(result.Response), `"text":"`+text+`"`) {
+			t.Fatalf("Unexpected message %d of stream:\nwant: %q,\ngot: %d: %s, %v.", i, text, result.ID, result.Response, result.Error)
+		}
+		if wantHeader := i == 0; (len(result.Header["channel"]) > 0) != wantHeader {
+			t.Errorf("Unexpected header of message %d:\nwant: %v,\ngot: %v.", i, wantHeader, result.Header)
+		}
+	}
+	if result := readResult(t, conn); result.ID != 1 || !result.End || result.Error != nil {
+		t.Errorf("Unexpected end of stream: %d: %s, %v.", result.ID, result.Response, result.Error)
+	}
+
+	conn.WriteJSON(map[string]interface{}{"id": 2, "method": "WatchPartner"})
+	conn.WriteJSON(map[string]interface{}{"id": 2, "cancel": true})
+	if result := readResult(t, conn); result.ID != 2 || result.Error == nil || result.Error.Code != "Canceled" {
+		t.Errorf("Unexpected result of cancelled stream:\nwant: Canceled error,\ngot: %d: %s, %v.", result.ID, result.Response, result.Error)
+	}
+}
+
+func TestWebSocketStreamWrongPassword(t *testing.T) {
+	server := httptest.NewServer(Handler(&fakeServer{}, interceptor,
+		Options{WebSocket: true, StreamInterceptor: streamInterceptor}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+WebSocketPath, nil)
+	if err != nil {
+		t.Fatalf("Unexpected Dial err: %v.", err)
+	}
+	defer conn.Close()
+
+	conn.WriteJSON(map[string]string{"login": "Joe", "password": "bbb"})
+	conn.WriteJSON(map[string]interface{}{"id": 1, "method": "WatchPartner"})
+
+	if result := readResult(t, conn); result.Error == nil || result.Error.Code != "Unauthenticated" {
+		t.Errorf("Unexpected result:\nwant: Unauthenticated error,\ngot: %s, %v.", result.Response, result.Error)
+	}
+}
+
+func TestWebSocketCallsLimit(t *testing.T) {
+	const maxCalls = 16
+
+	server := httptest.NewServer(Handler(&fakeServer{}, interceptor,
+		Options{WebSocket: true, StreamInterceptor: streamInterceptor}))
+	defer server.Close()
+	conn := dialSocket(t, server)
+	defer conn.Close()
+
+	for id := 1; id <= maxCalls; id++ {
+		conn.WriteJSON(map[string]interface{}{"id": id, "method": "WatchPartner"})
+	}
+	conn.WriteJSON(map[string]interface{}{"id": 1, "method": "JoinTheGame"})
+	if result := readResult(t, conn); result.ID != 1 || result.Error == nil || result.Error.Code != "InvalidArgument" {
+		t.Errorf("Unexpected result of duplicated id:\nwant: InvalidArgument error,\ngot: %d: %s, %v.", result.ID, result.Response, result.Error)
+	}
+	conn.WriteJSON(map[string]interface{}{"id": maxCalls + 1, "method": "JoinTheGame"})
+	if result := readResult(t, conn); result.ID != maxCalls+1 || result.Error == nil || result.Error.Code != "ResourceExhausted" {
+		t.Errorf("Unexpected result of extra call:\nwant: ResourceExhausted error,\ngot: %d: %s, %v.", result.ID, result.Response, result.Error)
+	}
+
+	conn.WriteJSON(map[string]interface{}{"id": 1, "cancel": true})
+	if result := readResult(t, conn); result.ID != 1 || result.Error == nil || result.Error.Code != "Canceled" {
+		t.Errorf("Unexpected result of cancelled call:\nwant: Canceled error,\ngot: %d: %s, %v.", result.ID, result.Response, result.Error)
+	}
+	conn.WriteJSON(map[string]interface{}{"id": 1, "method": "JoinTheGame"})
+	if result := readResult(t, conn); result.ID != 1 || result.Error != nil {
+		t.Errorf("Unexpected result of call after cancel: %d: %s, %v.", result.ID, result.Response, result.Error)
+	}
+}
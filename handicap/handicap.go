@@ -0,0 +1,92 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package handicap provides placement of handicap stones and komi of handicap games.
+//
+// Black gets from MinStones to MaxStones stones before the game,
+// then White makes the first move.
+// Coordinates start with 1 from the top left corner as in api.TurnMessage.
+package handicap
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/yagoggame/gomaster/game/igame"
+)
+
+const (
+	// MinStones is the minimum number of handicap stones
+	MinStones = 2
+	// MaxStones is the maximum number of handicap stones
+	MaxStones = 9
+	// Komi is komi of handicap games: White only wins a tie,
+	// as Black is already compensated by stones.
+	Komi = 0.5
+)
+
+var (
+	// ErrStones occurs when number of handicap stones is out of range
+	ErrStones = errors.New("number of handicap stones is out of range")
+	// ErrSize occurs when there are no fixed star points for a board size
+	ErrSize = errors.New("no fixed handicap placement for board size")
+)
+
+// lines are numbers of lines with star points: near edge, middle and far edge.
+var lines = map[int][3]int{
+	9:  {3, 5, 7},
+	13: {4, 7, 10},
+	19: {4, 10, 16},
+}
+
+// Check checks the number of handicap stones.
+func Check(stones int) error {
+	if stones < MinStones || stones > MaxStones {
+		return fmt.Errorf("%w: got %d, expected from %d to %d", ErrStones, stones, MinStones, MaxStones)
+	}
+	return nil
+}
+
+// Fixed returns star points for stones on the board of size
+// in the traditional order: the opposite corners, the other corners,
+// then the centre for an odd number of stones, sides and the centre again.
+func Fixed(size, stones int) ([]igame.TurnData, error) {
+	if err := Check(stones); err != nil {
+		return nil, err
+	}
+	line, ok := lines[size]
+	if !ok {
+		return nil, fmt.Errorf("%w: %dx%[2]d, fixed placement is for 9x9, 13x13 and 19x19", ErrSize, size)
+	}
+	near, middle, far := line[0], line[1], line[2]
+
+	points := []igame.TurnData{
+		{X: far, Y: near},
+		{X: near, Y: far},
+		{X: far, Y: far},
+		{X: near, Y: near},
+	}
+	if stones >= 6 {
+		points = append(points, igame.TurnData{X: near, Y: middle}, igame.TurnData{X: far, Y: middle})
+	}
+	if stones >= 8 {
+		points = append(points, igame.TurnData{X: middle, Y: near}, igame.TurnData{X: middle, Y: far})
+	}
+	if stones%2 == 1 {
+		points = append(points, igame.TurnData{X: middle, Y: middle})
+	}
+	return points[:stones], nil
+}
@@ -0,0 +1,66 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package handicap_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/yagoggame/gomaster/game/igame"
+	. "github.com/yagoggame/grpc_server/handicap"
+)
+
+var fixedTests = []struct {
+	caseName string
+	size     int
+	stones   int
+	want     []igame.TurnData
+	wantErr  error
+}{
+	{caseName: "two on 19", size: 19, stones: 2,
+		want: []igame.TurnData{{X: 16, Y: 4}, {X: 4, Y: 16}}},
+	{caseName: "three on 13", size: 13, stones: 3,
+		want: []igame.TurnData{{X: 10, Y: 4}, {X: 4, Y: 10}, {X: 10, Y: 10}}},
+	{caseName: "five on 9", size: 9, stones: 5,
+		want: []igame.TurnData{{X: 7, Y: 3}, {X: 3, Y: 7}, {X: 7, Y: 7}, {X: 3, Y: 3}, {X: 5, Y: 5}}},
+	{caseName: "six on 19", size: 19, stones: 6,
+		want: []igame.TurnData{{X: 16, Y: 4}, {X: 4, Y: 16}, {X: 16, Y: 16}, {X: 4, Y: 4}, {X: 4, Y: 10}, {X: 16, Y: 10}}},
+	{caseName: "seven on 19", size: 19, stones: 7,
+		want: []igame.TurnData{{X: 16, Y: 4}, {X: 4, Y: 16}, {X: 16, Y: 16}, {X: 4, Y: 4}, {X: 4, Y: 10}, {X: 16, Y: 10},
+			{X: 10, Y: 10}}},
+	{caseName: "nine on 13", size: 13, stones: 9,
+		want: []igame.TurnData{{X: 10, Y: 4}, {X: 4, Y: 10}, {X: 10, Y: 10}, {X: 4, Y: 4}, {X: 4, Y: 7}, {X: 10, Y: 7},
+			{X: 7, Y: 4}, {X: 7, Y: 10}, {X: 7, Y: 7}}},
+	{caseName: "one stone", size: 19, stones: 1, wantErr: ErrStones},
+	{caseName: "ten stones", size: 19, stones: 10, wantErr: ErrStones},
+	{caseName: "no star points", size: 11, stones: 2, wantErr: ErrSize},
+}
+
+func TestFixed(t *testing.T) {
+	for _, test := range fixedTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			got, err := Fixed(test.size, test.stones)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Unexpected Fixed err:\nwant: %v,\ngot: %v.", test.wantErr, err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Unexpected Fixed:\nwant: %v,\ngot: %v.", test.want, got)
+			}
+		})
+	}
+}
@@ -25,4 +25,6 @@ var (
 	ErrPassword = errors.New("wrong password")
 	// ErrLoginOccupied occurs occurs when registering a user with a name that is already occupied.
 	ErrLoginOccupied = errors.New("login occupied")
+	// ErrNotIdentifier occurs when Authorizator can't identify users without password
+	ErrNotIdentifier = errors.New("authorizator doesn't support identification without password")
 )
@@ -37,6 +37,16 @@ type Authorizator interface {
 	ChangeRequisites(requisitesOld, requisitesNew *Requisites) error
 }
 
+// Identifier is the interface that wraps Identify method.
+// It is an optional extension of Authorizator.
+//
+// Identify returns id of user by login without password check.
+// It is used for users authenticated by other means,
+// e.g. by TLS client certificate
+type Identifier interface {
+	Identify(login string) (id int, err error)
+}
+
 // Requisites contains login and password of user
 type Requisites struct {
 	Login    string
@@ -44,7 +54,7 @@ type Requisites struct {
 }
 
 // Pooler is the interface that groups the AddGamer, RmGamer, JoinGame,
-// ReleaseGame, GetGamer, Release methods.
+// ReleaseGame, GetGamer, ListGamers, Release methods.
 //
 // AddGamer adds the gamer to a game
 //
@@ -56,6 +66,8 @@ type Requisites struct {
 //
 // GetGamer gets the gamer with specified id from the pool
 //
+// ListGamers gets all gamers of the pool
+//
 // Release releases the pool of gamer
 // Release should be invoked after last use of this interface
 type Pooler interface {
@@ -64,20 +76,27 @@ type Pooler interface {
 	JoinGame(id int, size int, komi float64) error
 	ReleaseGame(id int) error
 	GetGamer(id int) (*game.Gamer, error)
+	ListGamers() []*game.Gamer
 	Release()
 }
 
-// GameManager is the interface that groups the WaitBegin, WaitTurn,
-// MakeTurn methods.
+// GameManager is the interface that groups the WaitBegin, IsGameBegun,
+// WaitTurn, IsMyTurn, MakeTurn methods.
 //
 // WaitBegin awaits of game begin for the gamer with specified id
 //
+// IsGameBegun reports whether all gamers joined the game
+//
 // WaitTurn awaits of turn begin for the gamer with specified id
 //
+// IsMyTurn reports whether it is a turn of the gamer with specified id
+//
 // MakeTurn performs a move for the gamer with specified id
 type GameManager interface {
 	WaitBegin(ctx context.Context, id int) (err error)
+	IsGameBegun(id int) (igb bool, err error)
 	WaitTurn(ctx context.Context, id int) (err error)
+	IsMyTurn(id int) (imt bool, err error)
 	MakeTurn(id int, turn *igame.TurnData) (err error)
 	FieldSize(id int) (size int, err error)
 	GameState(id int) (state *igame.FieldState, err error)
@@ -89,3 +108,10 @@ type GameManager interface {
 type GameGeter interface {
 	GetGame(id int) (GameManager, error)
 }
+
+// Rater is the interface that wraps the Rating method.
+//
+// Rating returns rating of the user with specified id
+type Rater interface {
+	Rating(id int) (rating float64, err error)
+}
@@ -160,6 +160,20 @@ func (mr *MockPoolerMockRecorder) JoinGame(arg0, arg1, arg2 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinGame", reflect.TypeOf((*MockPooler)(nil).JoinGame), arg0, arg1, arg2)
 }
 
+// ListGamers mocks base method
+func (m *MockPooler) ListGamers() []*game.Gamer {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListGamers")
+	ret0, _ := ret[0].([]*game.Gamer)
+	return ret0
+}
+
+// ListGamers indicates an expected call of ListGamers
+func (mr *MockPoolerMockRecorder) ListGamers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListGamers", reflect.TypeOf((*MockPooler)(nil).ListGamers))
+}
+
 // Release mocks base method
 func (m *MockPooler) Release() {
 	m.ctrl.T.Helper()
@@ -254,6 +268,36 @@ func (mr *MockGameManagerMockRecorder) GameState(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GameState", reflect.TypeOf((*MockGameManager)(nil).GameState), arg0)
 }
 
+// IsGameBegun mocks base method
+func (m *MockGameManager) IsGameBegun(arg0 int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsGameBegun", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsGameBegun indicates an expected call of IsGameBegun
+func (mr *MockGameManagerMockRecorder) IsGameBegun(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsGameBegun", reflect.TypeOf((*MockGameManager)(nil).IsGameBegun), arg0)
+}
+
+// IsMyTurn mocks base method
+func (m *MockGameManager) IsMyTurn(arg0 int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsMyTurn", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsMyTurn indicates an expected call of IsMyTurn
+func (mr *MockGameManagerMockRecorder) IsMyTurn(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsMyTurn", reflect.TypeOf((*MockGameManager)(nil).IsMyTurn), arg0)
+}
+
 // MakeTurn mocks base method
 func (m *MockGameManager) MakeTurn(arg0 int, arg1 *interfaces.TurnData) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,232 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package tlsconfig provides TLS configuration of server: with certificate
+// Package listener provides listeners on tcp and unix socket endpoints.
+package listener
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	unixScheme = "unix://"
+	tcpScheme  = "tcp://"
+	// MaxSocketPath is the longest path of unix socket:
+	// sun_path of sockaddr_un is 108 bytes including the terminating NUL.
+	MaxSocketPath = 107
+)
+
+var (
+	// ErrNotSocket error occurs when a file, which is not a socket, exists at socket path
+	ErrNotSocket = errors.New("exists and is not a socket")
+	// ErrPathTooLong error occurs when socket path doesn't fit into sun_path
+	ErrPathTooLong = errors.New("socket path is too long")
+)
+
+// Endpoint is a network address to listen on
+type Endpoint struct {
+	Network string
+	Address string
+}
+
+func (e Endpoint) String() string {
+	if e.Network == "unix" {
+		return unixScheme + e.Address
+	}
+	return e.Address
+}
+
+// Parse parses "unix:///path", "tcp://host:port" or "host:port" item.
+// IPv6 literals are written in brackets: "[::1]:7777".
+func Parse(item string) (Endpoint, error) {
+	if strings.HasPrefix(item, unixScheme) {
+		path := strings.TrimPrefix(item, unixScheme)
+		if path == "" {
+			return Endpoint{}, fmt.Errorf("empty path of listen endpoint %q", item)
+		}
+		return Endpoint{Network: "unix", Address: path}, nil
+	}
+
+	address := strings.TrimPrefix(item, tcpScheme)
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return Endpoint{}, fmt.Errorf("wrong listen endpoint %q: %v", item, err)
+	}
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return Endpoint{}, fmt.Errorf("wrong port of listen endpoint %q: %v", item, err)
+	}
+	return Endpoint{Network: "tcp", Address: net.JoinHostPort(host, port)}, nil
+}
+
+// ParseList parses every item of list with Parse.
+func ParseList(list []string) ([]Endpoint, error) {
+	endpoints := make([]Endpoint, len(list))
+	for i, item := range list {
+		e, err := Parse(item)
+		if err != nil {
+			return nil, err
+		}
+		endpoints[i] = e
+	}
+	return endpoints, nil
+}
+
+// TCPHosts returns hosts of tcp endpoints
+func TCPHosts(list []Endpoint) []string {
+	var hosts []string
+	for _, e := range list {
+		if e.Network != "tcp" {
+			continue
+		}
+		host, _, _ := net.SplitHostPort(e.Address)
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+// Options are access options of unix socket file:
+// octal permissions Mode and "user:group" Owner,
+// empty values and parts are left unchanged.
+type Options struct {
+	Mode  string
+	Owner string
+}
+
+// Listen listens on endpoint. Stale unix socket file is replaced,
+// permissions and ownership of the new one are set by options.
+func Listen(e Endpoint, options Options) (net.Listener, error) {
+	if e.Network != "unix" {
+		return net.Listen(e.Network, e.Address)
+	}
+
+	if len(e.Address) > MaxSocketPath {
+		return nil, fmt.Errorf("%w: %q is %d bytes, at most %d allowed",
+			ErrPathTooLong, e.Address, len(e.Address), MaxSocketPath)
+	}
+	if info, err := os.Lstat(e.Address); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%q %w", e.Address, ErrNotSocket)
+		}
+		if err := os.Remove(e.Address); err != nil {
+			return nil, err
+		}
+	}
+
+	return listenUnix(e.Address, options)
+}
+
+// listenUnix listens on socket at path. The socket is created in a private
+// directory next to path and moved to path only after it's access is set,
+// so clients can't connect to it with default permissions meanwhile.
+func listenUnix(path string, options Options) (net.Listener, error) {
+	dir, err := ioutil.TempDir(filepath.Dir(path), ".socket")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	tmpPath := filepath.Join(dir, filepath.Base(path))
+	if len(tmpPath) > MaxSocketPath {
+		return nil, fmt.Errorf("%w: %q is %d bytes in temporary directory %q, at most %d allowed",
+			ErrPathTooLong, path, len(tmpPath), filepath.Base(dir), MaxSocketPath)
+	}
+	lis, err := net.ListenUnix("unix", &net.UnixAddr{Name: tmpPath, Net: "unix"})
+	if err != nil {
+		return nil, err
+	}
+	// the socket file is removed by unixListener from it's final path
+	lis.SetUnlinkOnClose(false)
+
+	if err := setSocketAccess(tmpPath, options); err != nil {
+		lis.Close()
+		return nil, err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		lis.Close()
+		return nil, err
+	}
+	return &unixListener{UnixListener: lis, path: path}, nil
+}
+
+// unixListener removes socket file at path on Close.
+type unixListener struct {
+	*net.UnixListener
+	path string
+}
+
+func (lis *unixListener) Addr() net.Addr {
+	return &net.UnixAddr{Name: lis.path, Net: "unix"}
+}
+
+func (lis *unixListener) Close() error {
+	err := lis.UnixListener.Close()
+	os.Remove(lis.path)
+	return err
+}
+
+// setSocketAccess sets permissions mode and owner of socket file
+func setSocketAccess(path string, options Options) error {
+	mode, owner := options.Mode, options.Owner
+	if mode != "" {
+		perm, err := strconv.ParseUint(mode, 8, 32)
+		if err != nil || perm > 0777 {
+			return fmt.Errorf("wrong socket mode %q", mode)
+		}
+		if err := os.Chmod(path, os.FileMode(perm)); err != nil {
+			return err
+		}
+	}
+
+	if owner == "" {
+		return nil
+	}
+	parts := strings.SplitN(owner, ":", 2)
+	uid, gid := -1, -1
+	if parts[0] != "" {
+		u, err := user.Lookup(parts[0])
+		if err != nil {
+			u, err = user.LookupId(parts[0])
+		}
+		if err != nil {
+			return fmt.Errorf("wrong socket owner %q: %v", owner, err)
+		}
+		if uid, err = strconv.Atoi(u.Uid); err != nil {
+			return fmt.Errorf("wrong socket owner %q: %v", owner, err)
+		}
+	}
+	if len(parts) > 1 && parts[1] != "" {
+		g, err := user.LookupGroup(parts[1])
+		if err != nil {
+			g, err = user.LookupGroupId(parts[1])
+		}
+		if err != nil {
+			return fmt.Errorf("wrong socket group %q: %v", owner, err)
+		}
+		if gid, err = strconv.Atoi(g.Gid); err != nil {
+			return fmt.Errorf("wrong socket group %q: %v", owner, err)
+		}
+	}
+	return os.Chown(path, uid, gid)
+}
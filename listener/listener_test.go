@@ -0,0 +1,261 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package listener_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	. "github.com/yagoggame/grpc_server/listener"
+)
+
+var parseTests = []struct {
+	caseName string
+	item     string
+	want     Endpoint
+	wantErr  bool
+}{
+	{caseName: "unix", item: "unix:///run/go.sock",
+		want: Endpoint{Network: "unix", Address: "/run/go.sock"}},
+	{caseName: "empty unix path", item: "unix://", wantErr: true},
+	{caseName: "tcp", item: "tcp://localhost:7777",
+		want: Endpoint{Network: "tcp", Address: "localhost:7777"}},
+	{caseName: "no scheme", item: "127.0.0.1:7777",
+		want: Endpoint{Network: "tcp", Address: "127.0.0.1:7777"}},
+	{caseName: "ipv6", item: "[::1]:7777",
+		want: Endpoint{Network: "tcp", Address: "[::1]:7777"}},
+	{caseName: "all addresses", item: ":7777",
+		want: Endpoint{Network: "tcp", Address: ":7777"}},
+	{caseName: "no port", item: "localhost", wantErr: true},
+	{caseName: "wrong port", item: "localhost:77777", wantErr: true},
+}
+
+func TestParse(t *testing.T) {
+	for _, test := range parseTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			e, err := Parse(test.item)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Unexpected err:\nwant error: %v,\ngot: %v.", test.wantErr, err)
+			}
+			if e != test.want {
+				t.Errorf("Unexpected endpoint:\nwant: %v,\ngot: %v.", test.want, e)
+			}
+		})
+	}
+}
+
+func TestParseList(t *testing.T) {
+	list, err := ParseList([]string{"unix:///run/go.sock", "10.0.0.1:7777", "[::1]:7778"})
+	if err != nil {
+		t.Fatalf("Unexpected ParseList err: %v.", err)
+	}
+	want := []string{"10.0.0.1", "::1"}
+	if hosts := TCPHosts(list); !reflect.DeepEqual(hosts, want) {
+		t.Errorf("Unexpected hosts:\nwant: %v,\ngot: %v.", want, hosts)
+	}
+
+	if _, err := ParseList([]string{"localhost:7777", "unix://"}); err == nil {
+		t.Errorf("Unexpected ParseList success with empty unix path.")
+	}
+}
+
+func TestListenUnix(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "go.sock")
+
+	lis, err := Listen(Endpoint{Network: "unix", Address: path}, Options{Mode: "0600"})
+	if err != nil {
+		t.Fatalf("Unexpected Listen err: %v.", err)
+	}
+	if addr := lis.Addr().String(); addr != path {
+		t.Errorf("Unexpected Addr:\nwant: %v,\ngot: %v.", path, addr)
+	}
+	assertDirContent(t, dir, []string{"go.sock"})
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Unexpected Stat err: %v.", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("Unexpected socket mode:\nwant: %v,\ngot: %v.", os.FileMode(0600), perm)
+	}
+
+	go func() {
+		conn, err := lis.Accept()
+		if err == nil {
+			conn.Write([]byte("ok"))
+			conn.Close()
+		}
+	}()
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("Unexpected Dial err: %v.", err)
+	}
+	reply, err := ioutil.ReadAll(conn)
+	conn.Close()
+	if err != nil || string(reply) != "ok" {
+		t.Errorf("Unexpected reply:\nwant: %v,\ngot: %q, %v.", "ok", reply, err)
+	}
+
+	if err := lis.Close(); err != nil {
+		t.Errorf("Unexpected Close err: %v.", err)
+	}
+	assertDirContent(t, dir, nil)
+}
+
+func TestListenStaleSocket(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "go.sock")
+
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		t.Fatalf("Unexpected ListenUnix err: %v.", err)
+	}
+	stale.SetUnlinkOnClose(false)
+	stale.Close()
+
+	lis, err := Listen(Endpoint{Network: "unix", Address: path}, Options{})
+	if err != nil {
+		t.Fatalf("Unexpected Listen err: %v.", err)
+	}
+	lis.Close()
+}
+
+func TestListenNotSocket(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "go.sock")
+	if err := ioutil.WriteFile(path, []byte("data"), 0644); err != nil {
+		t.Fatalf("Unexpected WriteFile err: %v.", err)
+	}
+
+	_, err := Listen(Endpoint{Network: "unix", Address: path}, Options{})
+	if !errors.Is(err, ErrNotSocket) {
+		t.Errorf("Unexpected err:\nwant: %v,\ngot: %v.", ErrNotSocket, err)
+	}
+	assertDirContent(t, dir, []string{"go.sock"})
+}
+
+func TestListenPathTooLong(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	// the path fits itself, but not in the temporary directory
+	fits := filepath.Join(dir, strings.Repeat("s", MaxSocketPath-len(dir)-1))
+	tooLong := fits + "s"
+	for _, path := range []string{fits, tooLong} {
+		_, err := Listen(Endpoint{Network: "unix", Address: path}, Options{})
+		if !errors.Is(err, ErrPathTooLong) {
+			t.Errorf("Unexpected err of %d bytes path:\nwant: %v,\ngot: %v.", len(path), ErrPathTooLong, err)
+		}
+	}
+	assertDirContent(t, dir, nil)
+}
+
+func TestListenOwner(t *testing.T) {
+	u, err := user.Current()
+	if err != nil {
+		t.Skipf("No current user: %v.", err)
+	}
+	g, err := user.LookupGroupId(u.Gid)
+	if err != nil {
+		t.Skipf("No current group: %v.", err)
+	}
+
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "go.sock")
+
+	owners := []string{u.Username, u.Uid, ":" + g.Name, u.Username + ":" + u.Gid}
+	for _, owner := range owners {
+		lis, err := Listen(Endpoint{Network: "unix", Address: path}, Options{Owner: owner})
+		if err != nil {
+			t.Errorf("Unexpected Listen err with owner %q: %v.", owner, err)
+			continue
+		}
+		lis.Close()
+	}
+}
+
+var accessErrorTests = []struct {
+	caseName string
+	options  Options
+}{
+	{caseName: "not octal mode", options: Options{Mode: "0800"}},
+	{caseName: "too large mode", options: Options{Mode: "01777"}},
+	{caseName: "unknown owner", options: Options{Owner: "no-such-user-of-test"}},
+	{caseName: "unknown group", options: Options{Owner: ":no-such-group-of-test"}},
+}
+
+func TestListenAccessError(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "go.sock")
+
+	for _, test := range accessErrorTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			lis, err := Listen(Endpoint{Network: "unix", Address: path}, test.options)
+			if err == nil {
+				lis.Close()
+				t.Fatalf("Unexpected Listen success.")
+			}
+			assertDirContent(t, dir, nil)
+		})
+	}
+}
+
+func TestListenTCP(t *testing.T) {
+	lis, err := Listen(Endpoint{Network: "tcp", Address: "127.0.0.1:0"}, Options{Mode: "0800"})
+	if err != nil {
+		t.Fatalf("Unexpected Listen err: %v.", err)
+	}
+	lis.Close()
+}
+
+// assertDirContent checks names of files in dir, the private
+// temporary directory of socket must be removed
+func assertDirContent(t *testing.T, dir string, want []string) {
+	t.Helper()
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Unexpected ReadDir err: %v.", err)
+	}
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name())
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Unexpected files in %q:\nwant: %v,\ngot: %v.", dir, want, names)
+	}
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "listener")
+	if err != nil {
+		t.Fatalf("Unexpected TempDir err: %v.", err)
+	}
+	return dir
+}
@@ -0,0 +1,325 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package matchmaking provides a queue of gamers seeking a game,
+// which pairs them by proximity of ratings.
+//
+// Seekers are paired only with seekers of the same Request.
+// Two seekers are paired, when difference of their ratings fits
+// the windows of both of them. Window of a seeker widens with time
+// from Options.Window by Options.Widening points per second
+// up to Options.MaxWindow. Preferred colours of seekers don't affect pairing,
+// they are resolved by Pair.Colours.
+package matchmaking
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// waitSmoothing is a weight of the last wait in estimated wait of Request.
+const waitSmoothing = 0.3
+
+var (
+	// ErrQueued occurs when seeker is already in the queue
+	ErrQueued = errors.New("already in the queue")
+)
+
+// Request is a kind of game a seeker wants to play.
+// Handicap is a number of stones of Black, Free means,
+// that Black places them himself.
+type Request struct {
+	Size      int
+	MainTime  time.Duration
+	Increment time.Duration
+	Handicap  int
+	Free      bool
+}
+
+// Colour is a colour of stones preferred by a seeker.
+type Colour int
+
+// Colours of stones.
+const (
+	Random Colour = iota
+	Black
+	White
+)
+
+// Opposite returns the colour of partner.
+func (colour Colour) Opposite() Colour {
+	switch colour {
+	case Black:
+		return White
+	case White:
+		return Black
+	}
+	return Random
+}
+
+// Seeker is a gamer in the queue.
+type Seeker struct {
+	ID      int
+	Login   string
+	Rating  float64
+	Request Request
+	Since   time.Time
+	// Colour is a preferred colour, it doesn't affect pairing
+	Colour Colour
+}
+
+// Pair is a pair of seekers to play a game.
+type Pair [2]Seeker
+
+// Colours returns colours of both seekers of pair.
+// Preferred colours are given, unless both seekers prefer the same colour
+// or both of them don't care. Then the colour of the first seeker
+// is chosen by nigiri, which must return Black or White.
+func (pair Pair) Colours(nigiri func() Colour) [2]Colour {
+	first, second := pair[0].Colour, pair[1].Colour
+	switch {
+	case first == second:
+	case first != Random:
+		return [2]Colour{first, first.Opposite()}
+	default:
+		return [2]Colour{second.Opposite(), second}
+	}
+	colour := nigiri()
+	return [2]Colour{colour, colour.Opposite()}
+}
+
+// Status is a place of seeker in the queue.
+type Status struct {
+	// Position is a 1-based position among seekers of the same Request ordered by time
+	Position int
+	// Window is a current maximal difference of ratings of seeker and his partner
+	Window float64
+	// EstimatedWait is an estimated time until pairing, 0 if it is unknown
+	EstimatedWait time.Duration
+}
+
+// Options configures Queue.
+type Options struct {
+	// Window is an initial maximal difference of ratings
+	Window float64
+	// Widening is a number of points per second the window widens by
+	Widening float64
+	// MaxWindow limits the window, 0 means no limit
+	MaxWindow float64
+}
+
+// Queue pairs seekers by their ratings.
+type Queue struct {
+	options Options
+	mutex   sync.Mutex
+	seekers []*Seeker
+	waits   map[Request]time.Duration
+	now     func() time.Time
+}
+
+// New constructs new Queue.
+func New(options Options) *Queue {
+	return &Queue{
+		options: options,
+		waits:   make(map[Request]time.Duration),
+		now:     time.Now,
+	}
+}
+
+// Add places seeker to the queue, Since is set by Queue.
+func (q *Queue) Add(seeker Seeker) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.find(seeker.ID) >= 0 {
+		return ErrQueued
+	}
+	seeker.Since = q.now()
+	q.seekers = append(q.seekers, &seeker)
+	return nil
+}
+
+// Remove removes seeker with id from the queue.
+// It returns false, if there is no such seeker.
+func (q *Queue) Remove(id int) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	i := q.find(id)
+	if i < 0 {
+		return false
+	}
+	q.seekers = append(q.seekers[:i], q.seekers[i+1:]...)
+	return true
+}
+
+// Contains reports whether seeker with id is in the queue.
+func (q *Queue) Contains(id int) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return q.find(id) >= 0
+}
+
+// Match removes pairs of seekers, whose ratings are close enough, from the queue.
+// The longest waiting seekers are paired first with the closest partners.
+func (q *Queue) Match() []Pair {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	now := q.now()
+	var pairs []Pair
+	paired := make(map[*Seeker]bool)
+	for i, seeker := range q.seekers {
+		if paired[seeker] {
+			continue
+		}
+		var best *Seeker
+		for _, other := range q.seekers[i+1:] {
+			if paired[other] || other.Request != seeker.Request {
+				continue
+			}
+			diff := math.Abs(seeker.Rating - other.Rating)
+			if diff > q.window(seeker, now) || diff > q.window(other, now) {
+				continue
+			}
+			if best == nil || diff < math.Abs(seeker.Rating-best.Rating) {
+				best = other
+			}
+		}
+		if best == nil {
+			continue
+		}
+		paired[seeker], paired[best] = true, true
+		pairs = append(pairs, Pair{*seeker, *best})
+		q.recordWait(seeker.Request, now.Sub(seeker.Since))
+		q.recordWait(best.Request, now.Sub(best.Since))
+	}
+
+	rest := q.seekers[:0]
+	for _, seeker := range q.seekers {
+		if !paired[seeker] {
+			rest = append(rest, seeker)
+		}
+	}
+	q.seekers = rest
+	return pairs
+}
+
+// Status returns place of seeker with id in the queue.
+// It returns false, if there is no such seeker.
+func (q *Queue) Status(id int) (Status, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	i := q.find(id)
+	if i < 0 {
+		return Status{}, false
+	}
+	seeker := q.seekers[i]
+	now := q.now()
+
+	status := Status{Window: q.window(seeker, now)}
+	var compatible []*Seeker
+	for _, other := range q.seekers {
+		if other.Request != seeker.Request {
+			continue
+		}
+		if !other.Since.After(seeker.Since) {
+			status.Position++
+		}
+		if other != seeker {
+			compatible = append(compatible, other)
+		}
+	}
+
+	status.EstimatedWait = q.waits[seeker.Request]
+	if wait, ok := q.untilReachable(seeker, compatible, now); ok {
+		status.EstimatedWait = wait
+	}
+	return status, true
+}
+
+// untilReachable returns time until window of seeker and of the closest
+// of others widen enough to pair them. It must be called with locked mutex.
+func (q *Queue) untilReachable(seeker *Seeker, others []*Seeker, now time.Time) (time.Duration, bool) {
+	sort.Slice(others, func(i, j int) bool {
+		return math.Abs(seeker.Rating-others[i].Rating) < math.Abs(seeker.Rating-others[j].Rating)
+	})
+	for _, other := range others {
+		diff := math.Abs(seeker.Rating - other.Rating)
+		if q.options.MaxWindow > 0 && diff > q.options.MaxWindow {
+			return 0, false
+		}
+		mine, ok := q.untilWindow(seeker, diff, now)
+		if !ok {
+			return 0, false
+		}
+		theirs, ok := q.untilWindow(other, diff, now)
+		if !ok {
+			continue
+		}
+		if theirs > mine {
+			return theirs, true
+		}
+		return mine, true
+	}
+	return 0, false
+}
+
+// untilWindow returns time until window of seeker widens to diff.
+func (q *Queue) untilWindow(seeker *Seeker, diff float64, now time.Time) (time.Duration, bool) {
+	if diff <= q.window(seeker, now) {
+		return 0, true
+	}
+	if q.options.Widening <= 0 {
+		return 0, false
+	}
+	seconds := (diff-q.options.Window)/q.options.Widening - now.Sub(seeker.Since).Seconds()
+	return time.Duration(seconds * float64(time.Second)), true
+}
+
+// window returns current window of seeker.
+func (q *Queue) window(seeker *Seeker, now time.Time) float64 {
+	window := q.options.Window + q.options.Widening*now.Sub(seeker.Since).Seconds()
+	if q.options.MaxWindow > 0 && window > q.options.MaxWindow {
+		window = q.options.MaxWindow
+	}
+	return window
+}
+
+// recordWait updates estimated wait of request, it must be called with locked mutex.
+func (q *Queue) recordWait(request Request, wait time.Duration) {
+	last, ok := q.waits[request]
+	if !ok {
+		q.waits[request] = wait
+		return
+	}
+	q.waits[request] = time.Duration(waitSmoothing*float64(wait) + (1-waitSmoothing)*float64(last))
+}
+
+// find returns index of seeker with id or -1, it must be called with locked mutex.
+func (q *Queue) find(id int) int {
+	for i, seeker := range q.seekers {
+		if seeker.ID == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,226 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package matchmaking_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/yagoggame/grpc_server/matchmaking"
+)
+
+var (
+	quick  = Request{Size: 9}
+	blitz  = Request{Size: 9, MainTime: time.Minute, Increment: time.Second}
+	larger = Request{Size: 13}
+)
+
+var matchTests = []struct {
+	caseName string
+	options  Options
+	seekers  []Seeker
+	want     [][2]int
+}{
+	{caseName: "same rating",
+		options: Options{Window: 100},
+		seekers: []Seeker{{ID: 1, Rating: 1500, Request: quick}, {ID: 2, Rating: 1500, Request: quick}},
+		want:    [][2]int{{1, 2}}},
+	{caseName: "other size",
+		options: Options{Window: 100},
+		seekers: []Seeker{{ID: 1, Rating: 1500, Request: quick}, {ID: 2, Rating: 1500, Request: larger}}},
+	{caseName: "other time control",
+		options: Options{Window: 100},
+		seekers: []Seeker{{ID: 1, Rating: 1500, Request: quick}, {ID: 2, Rating: 1500, Request: blitz}}},
+	{caseName: "out of window",
+		options: Options{Window: 100},
+		seekers: []Seeker{{ID: 1, Rating: 1500, Request: quick}, {ID: 2, Rating: 1700, Request: quick}}},
+	{caseName: "closest partner",
+		options: Options{Window: 300},
+		seekers: []Seeker{
+			{ID: 1, Rating: 1500, Request: quick},
+			{ID: 2, Rating: 1750, Request: quick},
+			{ID: 3, Rating: 1520, Request: quick},
+			{ID: 4, Rating: 1800, Request: quick}},
+		want: [][2]int{{1, 3}, {2, 4}}},
+	{caseName: "widened window",
+		options: Options{Window: 0, Widening: 100000},
+		seekers: []Seeker{{ID: 1, Rating: 1500, Request: quick}, {ID: 2, Rating: 1700, Request: quick}},
+		want:    [][2]int{{1, 2}}},
+	{caseName: "limited window",
+		options: Options{Window: 0, Widening: 100000, MaxWindow: 100},
+		seekers: []Seeker{{ID: 1, Rating: 1500, Request: quick}, {ID: 2, Rating: 1700, Request: quick}}},
+}
+
+func TestMatch(t *testing.T) {
+	for _, test := range matchTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			queue := New(test.options)
+			for _, seeker := range test.seekers {
+				if err := queue.Add(seeker); err != nil {
+					t.Fatalf("Unexpected Add err:\nwant: %v,\ngot: %v.", nil, err)
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+
+			pairs := queue.Match()
+			if len(pairs) != len(test.want) {
+				t.Fatalf("Unexpected number of pairs:\nwant: %d,\ngot: %d (%v).", len(test.want), len(pairs), pairs)
+			}
+			for i, pair := range pairs {
+				if pair[0].ID != test.want[i][0] || pair[1].ID != test.want[i][1] {
+					t.Errorf("Unexpected pair %d:\nwant: %v,\ngot: %d and %d.", i, test.want[i], pair[0].ID, pair[1].ID)
+				}
+				for _, seeker := range pair {
+					if queue.Contains(seeker.ID) {
+						t.Errorf("Paired seeker with id %d is still in the queue.", seeker.ID)
+					}
+				}
+			}
+			if len(queue.Match()) != 0 {
+				t.Errorf("Unexpected pairs of repeated Match.")
+			}
+		})
+	}
+}
+
+func TestAddRemove(t *testing.T) {
+	queue := New(Options{Window: 100})
+
+	if err := queue.Add(Seeker{ID: 1, Request: quick}); err != nil {
+		t.Fatalf("Unexpected Add err:\nwant: %v,\ngot: %v.", nil, err)
+	}
+	if err := queue.Add(Seeker{ID: 1, Request: blitz}); !errors.Is(err, ErrQueued) {
+		t.Errorf("Unexpected repeated Add err:\nwant: %v,\ngot: %v.", ErrQueued, err)
+	}
+	if !queue.Remove(1) {
+		t.Errorf("Unexpected Remove result:\nwant: %v,\ngot: %v.", true, false)
+	}
+	if queue.Remove(1) || queue.Contains(1) {
+		t.Errorf("Removed seeker is still in the queue.")
+	}
+	if _, ok := queue.Status(1); ok {
+		t.Errorf("Unexpected Status of removed seeker.")
+	}
+}
+
+var statusTests = []struct {
+	caseName     string
+	options      Options
+	seekers      []Seeker
+	wantPosition int
+	wantWait     time.Duration
+}{
+	{caseName: "alone",
+		options:      Options{Window: 100, Widening: 10},
+		seekers:      []Seeker{{ID: 1, Rating: 1500, Request: quick}},
+		wantPosition: 1},
+	{caseName: "after others",
+		options: Options{Window: 100, Widening: 10},
+		seekers: []Seeker{
+			{ID: 2, Rating: 1000, Request: quick},
+			{ID: 3, Rating: 1500, Request: blitz},
+			{ID: 1, Rating: 1500, Request: quick}},
+		wantPosition: 2, wantWait: 40 * time.Second},
+	{caseName: "unreachable",
+		options: Options{Window: 100, Widening: 10, MaxWindow: 200},
+		seekers: []Seeker{
+			{ID: 2, Rating: 1000, Request: quick},
+			{ID: 1, Rating: 1500, Request: quick}},
+		wantPosition: 2},
+	{caseName: "no widening",
+		options: Options{Window: 100},
+		seekers: []Seeker{
+			{ID: 2, Rating: 1000, Request: quick},
+			{ID: 1, Rating: 1500, Request: quick}},
+		wantPosition: 2},
+}
+
+func TestStatus(t *testing.T) {
+	for _, test := range statusTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			queue := New(test.options)
+			for _, seeker := range test.seekers {
+				if err := queue.Add(seeker); err != nil {
+					t.Fatalf("Unexpected Add err:\nwant: %v,\ngot: %v.", nil, err)
+				}
+			}
+
+			status, ok := queue.Status(1)
+			if !ok {
+				t.Fatalf("Unexpected Status result:\nwant: %v,\ngot: %v.", true, ok)
+			}
+			if status.Position != test.wantPosition {
+				t.Errorf("Unexpected position:\nwant: %d,\ngot: %d.", test.wantPosition, status.Position)
+			}
+			if status.Window < test.options.Window {
+				t.Errorf("Unexpected window:\nwant: at least %g,\ngot: %g.", test.options.Window, status.Window)
+			}
+			// the time of test run is deducted from the estimation.
+			if status.EstimatedWait > test.wantWait || status.EstimatedWait < test.wantWait-time.Second {
+				t.Errorf("Unexpected estimated wait:\nwant: about %s,\ngot: %s.", test.wantWait, status.EstimatedWait)
+			}
+		})
+	}
+}
+
+func TestEstimatedWaitOfMatched(t *testing.T) {
+	queue := New(Options{Window: 100})
+	for _, seeker := range []Seeker{{ID: 1, Request: quick}, {ID: 2, Request: quick}} {
+		if err := queue.Add(seeker); err != nil {
+			t.Fatalf("Unexpected Add err:\nwant: %v,\ngot: %v.", nil, err)
+		}
+	}
+	wait := 20 * time.Millisecond
+	time.Sleep(wait)
+	if pairs := queue.Match(); len(pairs) != 1 {
+		t.Fatalf("Unexpected number of pairs:\nwant: %d,\ngot: %d.", 1, len(pairs))
+	}
+
+	if err := queue.Add(Seeker{ID: 3, Request: quick}); err != nil {
+		t.Fatalf("Unexpected Add err:\nwant: %v,\ngot: %v.", nil, err)
+	}
+	status, _ := queue.Status(3)
+	if status.EstimatedWait < wait {
+		t.Errorf("Unexpected estimated wait:\nwant: at least %s,\ngot: %s.", wait, status.EstimatedWait)
+	}
+}
+
+var coloursTests = []struct {
+	caseName string
+	first    Colour
+	second   Colour
+	nigiri   Colour
+	want     [2]Colour
+}{
+	{caseName: "compatible", first: White, second: Black, nigiri: Black, want: [2]Colour{White, Black}},
+	{caseName: "first prefers", first: Black, second: Random, nigiri: White, want: [2]Colour{Black, White}},
+	{caseName: "second prefers", first: Random, second: Black, nigiri: Black, want: [2]Colour{White, Black}},
+	{caseName: "conflict", first: Black, second: Black, nigiri: White, want: [2]Colour{White, Black}},
+	{caseName: "both random", first: Random, second: Random, nigiri: Black, want: [2]Colour{Black, White}},
+}
+
+func TestColours(t *testing.T) {
+	for _, test := range coloursTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			pair := Pair{{ID: 1, Colour: test.first}, {ID: 2, Colour: test.second}}
+			if got := pair.Colours(func() Colour { return test.nigiri }); got != test.want {
+				t.Errorf("Unexpected Colours:\nwant: %v,\ngot: %v.", test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package rating
+
+import "math"
+
+// Parameters of Glicko-2 system.
+const (
+	// DefaultRating is a rating of a new player
+	DefaultRating = 1500.0
+	// DefaultDeviation is a rating deviation of a new player
+	DefaultDeviation = 350.0
+	// DefaultVolatility is a volatility of a new player
+	DefaultVolatility = 0.06
+	// DefaultTau constrains the change of volatility over time
+	DefaultTau = 0.5
+
+	// scale converts Glicko ratings to Glicko-2 scale
+	scale = 173.7178
+	// epsilon is a tolerance of volatility iteration
+	epsilon = 0.000001
+)
+
+// Glicko is a rating of a player in Glicko-2 system.
+type Glicko struct {
+	Rating     float64 `json:"rating"`
+	Deviation  float64 `json:"deviation"`
+	Volatility float64 `json:"volatility"`
+}
+
+// NewGlicko returns the rating of a new player.
+func NewGlicko() Glicko {
+	return Glicko{Rating: DefaultRating, Deviation: DefaultDeviation, Volatility: DefaultVolatility}
+}
+
+// Update returns rating of player after one game with opponent,
+// which is treated as a rating period.
+// Score is 1 for a win, 0.5 for a draw and 0 for a loss.
+func Update(player, opponent Glicko, score, tau float64) Glicko {
+	mu, phi := toGlicko2(player)
+	muJ, phiJ := toGlicko2(opponent)
+
+	g := gFunc(phiJ)
+	e := 1 / (1 + math.Exp(-g*(mu-muJ)))
+	v := 1 / (g * g * e * (1 - e))
+	delta := v * g * (score - e)
+
+	sigma := volatility(phi, v, delta, player.Volatility, tau)
+	phiStar := math.Sqrt(phi*phi + sigma*sigma)
+	phiNew := 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	muNew := mu + phiNew*phiNew*g*(score-e)
+
+	updated := Glicko{
+		Rating:     muNew*scale + DefaultRating,
+		Deviation:  phiNew * scale,
+		Volatility: sigma,
+	}
+	if updated.Deviation > DefaultDeviation {
+		updated.Deviation = DefaultDeviation
+	}
+	return updated
+}
+
+func toGlicko2(rating Glicko) (mu, phi float64) {
+	return (rating.Rating - DefaultRating) / scale, rating.Deviation / scale
+}
+
+func gFunc(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+// volatility computes new volatility by the Illinois algorithm.
+func volatility(phi, v, delta, sigma, tau float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + v + ex
+		return ex*(delta*delta-phi*phi-v-ex)/(2*d*d) - (x-a)/(tau*tau)
+	}
+
+	A := a
+	var B float64
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*tau) < 0 {
+			k++
+		}
+		B = a - k*tau
+	}
+
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > epsilon {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+		B, fB = C, fC
+	}
+	return math.Exp(A / 2)
+}
@@ -0,0 +1,80 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package rating_test
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/yagoggame/grpc_server/rating"
+)
+
+var updateTests = []struct {
+	caseName string
+	player   Glicko
+	opponent Glicko
+	score    float64
+	change   int // sign of the change of rating
+}{
+	{caseName: "win of equal", player: NewGlicko(), opponent: NewGlicko(), score: 1, change: 1},
+	{caseName: "loss of equal", player: NewGlicko(), opponent: NewGlicko(), score: 0, change: -1},
+	{caseName: "draw of equal", player: NewGlicko(), opponent: NewGlicko(), score: 0.5, change: 0},
+	{caseName: "draw with stronger",
+		player:   Glicko{Rating: 1400, Deviation: 100, Volatility: DefaultVolatility},
+		opponent: Glicko{Rating: 1800, Deviation: 100, Volatility: DefaultVolatility},
+		score:    0.5, change: 1},
+	{caseName: "draw with weaker",
+		player:   Glicko{Rating: 1800, Deviation: 100, Volatility: DefaultVolatility},
+		opponent: Glicko{Rating: 1400, Deviation: 100, Volatility: DefaultVolatility},
+		score:    0.5, change: -1},
+}
+
+func TestUpdate(t *testing.T) {
+	for _, test := range updateTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			got := Update(test.player, test.opponent, test.score, DefaultTau)
+
+			change := got.Rating - test.player.Rating
+			switch {
+			case test.change > 0 && change <= 0,
+				test.change < 0 && change >= 0,
+				test.change == 0 && math.Abs(change) > 0.001:
+				t.Errorf("Unexpected change of rating:\nwant sign: %d,\ngot: %g.", test.change, change)
+			}
+			if got.Deviation >= test.player.Deviation {
+				t.Errorf("Unexpected deviation:\nwant: less than %g,\ngot: %g.", test.player.Deviation, got.Deviation)
+			}
+			if math.Abs(got.Volatility-test.player.Volatility) > 0.01 {
+				t.Errorf("Unexpected volatility:\nwant: about %g,\ngot: %g.", test.player.Volatility, got.Volatility)
+			}
+		})
+	}
+}
+
+// TestUpdateExample checks the first game of example of Glickman's paper
+// "Example of the Glicko-2 system", which is treated as a rating period.
+func TestUpdateExample(t *testing.T) {
+	player := Glicko{Rating: 1500, Deviation: 200, Volatility: 0.06}
+	opponent := Glicko{Rating: 1400, Deviation: 30, Volatility: 0.06}
+
+	got := Update(player, opponent, 1, DefaultTau)
+	want := Glicko{Rating: 1563.56, Deviation: 175.40, Volatility: 0.06}
+	if math.Abs(got.Rating-want.Rating) > 0.1 || math.Abs(got.Deviation-want.Deviation) > 0.1 ||
+		math.Abs(got.Volatility-want.Volatility) > 0.0001 {
+		t.Errorf("Unexpected rating:\nwant: %+v,\ngot: %+v.", want, got)
+	}
+}
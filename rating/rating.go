@@ -0,0 +1,339 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package rating keeps records of finished games and Glicko-2 ratings
+// of players, which are updated by each recorded game.
+//
+// Records and ratings are kept by a Store, so ratings
+// can be recomputed from the history of games at any time.
+package rating
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Result is a result of a game.
+type Result string
+
+// Results of a game.
+const (
+	BlackWins Result = "black"
+	WhiteWins Result = "white"
+	Draw      Result = "draw"
+)
+
+// Reason is a reason of the end of a game.
+type Reason string
+
+// Reasons of the end of a game.
+const (
+	// ByScore means the game is over by the rules and is resolved by scores
+	ByScore Reason = "score"
+	// ByLeaving means the loser left the game
+	ByLeaving Reason = "leaving"
+	// ByTimeout means the loser's time is over
+	ByTimeout Reason = "timeout"
+	// Removed marks a record of removal of user Black, not a game:
+	// his rating and statistics are dropped, so a new user,
+	// who gets the same id, starts afresh
+	Removed Reason = "removed"
+)
+
+var (
+	// ErrResult occurs when record of game has unknown result
+	ErrResult = errors.New("unknown result of game")
+	// ErrSamePlayer occurs when record of game has the same player for both colours
+	ErrSamePlayer = errors.New("player can't play with himself")
+	// ErrRemoval occurs when record of removal of player is recorded as a game
+	ErrRemoval = errors.New("removal of player is not a game")
+)
+
+// Record is a finished game.
+type Record struct {
+	Black      int       `json:"black"`
+	White      int       `json:"white"`
+	BlackLogin string    `json:"black_login"`
+	WhiteLogin string    `json:"white_login"`
+	Size       int       `json:"size"`
+	Komi       float64   `json:"komi"`
+	BlackScore float64   `json:"black_score"`
+	WhiteScore float64   `json:"white_score"`
+	Result     Result    `json:"result"`
+	Reason     Reason    `json:"reason"`
+	Moves      int       `json:"moves"`
+	Begun      time.Time `json:"begun"`
+	Ended      time.Time `json:"ended"`
+}
+
+// Player is a rated player.
+type Player struct {
+	ID    int    `json:"id"`
+	Login string `json:"login"`
+	Glicko
+	Games int `json:"games"`
+}
+
+// Store keeps records of games and ratings of players.
+type Store interface {
+	// AddRecord appends record to the history of games
+	AddRecord(record Record) error
+	// Records returns the history of games in order of recording
+	Records() ([]Record, error)
+	// Players returns stored ratings of players
+	Players() ([]Player, error)
+	// SavePlayers replaces stored ratings of players
+	SavePlayers(players []Player) error
+}
+
+// Ratings updates ratings of players by results of games.
+// Leaderboard and statistics of players are kept in memory
+// and updated by each recorded game too.
+type Ratings struct {
+	mutex   sync.Mutex
+	store   Store
+	tau     float64
+	players map[int]*Player
+	// board is players ordered by rating from the highest one
+	board   []*Player
+	tallies map[int]*tally
+}
+
+// New loads ratings from the store. If the store has no ratings,
+// but has records of games, ratings are recomputed from them.
+// Tau constrains the change of volatility, DefaultTau is used if it's 0.
+func New(store Store, tau float64) (*Ratings, error) {
+	if tau == 0 {
+		tau = DefaultTau
+	}
+	ratings := &Ratings{
+		store:   store,
+		tau:     tau,
+		players: make(map[int]*Player),
+		tallies: make(map[int]*tally),
+	}
+
+	players, err := store.Players()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ratings: %w", err)
+	}
+	if len(players) == 0 {
+		if err := ratings.Recompute(); err != nil {
+			return nil, err
+		}
+		return ratings, nil
+	}
+	for i := range players {
+		ratings.players[players[i].ID] = &players[i]
+		ratings.rank(&players[i])
+	}
+
+	records, err := store.Records()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load records of games: %w", err)
+	}
+	for _, record := range records {
+		ratings.count(record)
+	}
+	return ratings, nil
+}
+
+// Rating returns rating of player with id, new players have DefaultRating.
+func (ratings *Ratings) Rating(id int) (float64, error) {
+	return ratings.Player(id).Rating, nil
+}
+
+// Player returns rating of player with id.
+func (ratings *Ratings) Player(id int) Player {
+	ratings.mutex.Lock()
+	defer ratings.mutex.Unlock()
+
+	if player, ok := ratings.players[id]; ok {
+		return *player
+	}
+	return Player{ID: id, Glicko: NewGlicko()}
+}
+
+// PlayerByLogin returns rating of player with login.
+// It returns false, if player with login has no rated games.
+func (ratings *Ratings) PlayerByLogin(login string) (Player, bool) {
+	ratings.mutex.Lock()
+	defer ratings.mutex.Unlock()
+
+	for _, player := range ratings.players {
+		if player.Login == login {
+			return *player, true
+		}
+	}
+	return Player{}, false
+}
+
+// Record stores the record of game and updates ratings of its players.
+func (ratings *Ratings) Record(record Record) (black, white Player, err error) {
+	if err := check(record); err != nil {
+		return Player{}, Player{}, err
+	}
+
+	ratings.mutex.Lock()
+	defer ratings.mutex.Unlock()
+
+	if err := ratings.store.AddRecord(record); err != nil {
+		return Player{}, Player{}, fmt.Errorf("failed to store record of game: %w", err)
+	}
+	black, white = ratings.apply(record)
+	if err := ratings.save(); err != nil {
+		return Player{}, Player{}, err
+	}
+	return black, white, nil
+}
+
+// Remove drops rating of player with id, when his user is removed.
+// The removal is recorded to the history of games,
+// so it survives recomputation of ratings.
+func (ratings *Ratings) Remove(id int) error {
+	ratings.mutex.Lock()
+	defer ratings.mutex.Unlock()
+
+	player, ok := ratings.players[id]
+	if !ok {
+		return nil
+	}
+	record := Record{Black: id, BlackLogin: player.Login, Reason: Removed, Ended: time.Now()}
+	if err := ratings.store.AddRecord(record); err != nil {
+		return fmt.Errorf("failed to store removal of player: %w", err)
+	}
+	ratings.drop(id)
+	return ratings.save()
+}
+
+// Recompute recomputes ratings of all players from the stored history of games.
+func (ratings *Ratings) Recompute() error {
+	records, err := ratings.store.Records()
+	if err != nil {
+		return fmt.Errorf("failed to load records of games: %w", err)
+	}
+
+	ratings.mutex.Lock()
+	defer ratings.mutex.Unlock()
+
+	ratings.players = make(map[int]*Player)
+	ratings.board = nil
+	ratings.tallies = make(map[int]*tally)
+	for _, record := range records {
+		if record.Reason == Removed {
+			ratings.drop(record.Black)
+			continue
+		}
+		if err := check(record); err != nil {
+			return fmt.Errorf("wrong record of game %v: %w", record, err)
+		}
+		ratings.apply(record)
+	}
+	return ratings.save()
+}
+
+// apply updates ratings and statistics of players of record,
+// it must be called with locked mutex.
+func (ratings *Ratings) apply(record Record) (black, white Player) {
+	blackPlayer := ratings.player(record.Black, record.BlackLogin)
+	whitePlayer := ratings.player(record.White, record.WhiteLogin)
+	ratings.unrank(blackPlayer)
+	ratings.unrank(whitePlayer)
+
+	blackScore := 0.5
+	switch record.Result {
+	case BlackWins:
+		blackScore = 1
+	case WhiteWins:
+		blackScore = 0
+	}
+	blackGlicko := Update(blackPlayer.Glicko, whitePlayer.Glicko, blackScore, ratings.tau)
+	whitePlayer.Glicko = Update(whitePlayer.Glicko, blackPlayer.Glicko, 1-blackScore, ratings.tau)
+	blackPlayer.Glicko = blackGlicko
+	blackPlayer.Games++
+	whitePlayer.Games++
+	ratings.rank(blackPlayer)
+	ratings.rank(whitePlayer)
+	ratings.count(record)
+	return *blackPlayer, *whitePlayer
+}
+
+// count updates statistics of players of record, it must be called with locked mutex.
+func (ratings *Ratings) count(record Record) {
+	if record.Reason == Removed {
+		// a new user with the same id starts afresh.
+		delete(ratings.tallies, record.Black)
+		return
+	}
+	for _, id := range []int{record.Black, record.White} {
+		player, ok := ratings.tallies[id]
+		if !ok {
+			player = newTally()
+			ratings.tallies[id] = player
+		}
+		player.add(record, id)
+	}
+}
+
+// drop removes player with id with his statistics, it must be called with locked mutex.
+func (ratings *Ratings) drop(id int) {
+	if player, ok := ratings.players[id]; ok {
+		ratings.unrank(player)
+		delete(ratings.players, id)
+	}
+	delete(ratings.tallies, id)
+}
+
+// player returns player with id, creating him if needed.
+// It must be called with locked mutex.
+func (ratings *Ratings) player(id int, login string) *Player {
+	player, ok := ratings.players[id]
+	if !ok {
+		player = &Player{ID: id, Glicko: NewGlicko()}
+		ratings.players[id] = player
+	}
+	// login of user may be changed, the last one is kept.
+	player.Login = login
+	return player
+}
+
+// save stores ratings ordered by id, it must be called with locked mutex.
+func (ratings *Ratings) save() error {
+	players := make([]Player, 0, len(ratings.players))
+	for _, player := range ratings.players {
+		players = append(players, *player)
+	}
+	sort.Slice(players, func(i, j int) bool { return players[i].ID < players[j].ID })
+	if err := ratings.store.SavePlayers(players); err != nil {
+		return fmt.Errorf("failed to store ratings: %w", err)
+	}
+	return nil
+}
+
+func check(record Record) error {
+	switch {
+	case record.Reason == Removed:
+		return ErrRemoval
+	case record.Black == record.White:
+		return ErrSamePlayer
+	case record.Result != BlackWins && record.Result != WhiteWins && record.Result != Draw:
+		return fmt.Errorf("%w: %q", ErrResult, record.Result)
+	}
+	return nil
+}
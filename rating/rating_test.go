@@ -0,0 +1,240 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package rating_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/yagoggame/grpc_server/rating"
+)
+
+var games = []Record{
+	{Black: 2, White: 3, BlackLogin: "Joe", WhiteLogin: "Nick", Size: 9, Result: BlackWins, Reason: ByScore, Moves: 40},
+	{Black: 3, White: 2, BlackLogin: "Nick", WhiteLogin: "Joe", Size: 9, Result: BlackWins, Reason: ByLeaving, Moves: 12},
+	{Black: 2, White: 4, BlackLogin: "Joe", WhiteLogin: "Piter", Size: 13, Result: Draw, Reason: ByScore, Moves: 60},
+	{Black: 4, White: 3, BlackLogin: "Piter", WhiteLogin: "Nick", Size: 9, Result: WhiteWins, Reason: ByTimeout, Moves: 7},
+}
+
+var recordErrTests = []struct {
+	caseName string
+	record   Record
+	want     error
+}{
+	{caseName: "same player", record: Record{Black: 2, White: 2, Result: Draw}, want: ErrSamePlayer},
+	{caseName: "unknown result", record: Record{Black: 2, White: 3, Result: "lost"}, want: ErrResult},
+	{caseName: "removal", record: Record{Black: 2, Reason: Removed}, want: ErrRemoval},
+}
+
+func TestRecord(t *testing.T) {
+	ratings, err := New(NewMemoryStore(), 0)
+	if err != nil {
+		t.Fatalf("Unexpected New err: %v", err)
+	}
+	if rating, err := ratings.Rating(2); err != nil || rating != DefaultRating {
+		t.Errorf("Unexpected rating of new player:\nwant: %g,\ngot: %g, %v.", DefaultRating, rating, err)
+	}
+
+	black, white, err := ratings.Record(games[0])
+	if err != nil {
+		t.Fatalf("Unexpected Record err: %v", err)
+	}
+	if black.Rating <= DefaultRating || white.Rating >= DefaultRating || black.Games != 1 || white.Games != 1 {
+		t.Errorf("Unexpected ratings after win of black:\nblack: %+v,\nwhite: %+v.", black, white)
+	}
+	if got := ratings.Player(2); got != black {
+		t.Errorf("Unexpected Player:\nwant: %+v,\ngot: %+v.", black, got)
+	}
+	if got, ok := ratings.PlayerByLogin("Nick"); !ok || got != white {
+		t.Errorf("Unexpected PlayerByLogin:\nwant: %+v,\ngot: %+v, %v.", white, got, ok)
+	}
+	if _, ok := ratings.PlayerByLogin("Piter"); ok {
+		t.Errorf("Unexpected PlayerByLogin of unrated player.")
+	}
+
+	for _, test := range recordErrTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			if _, _, err := ratings.Record(test.record); !errors.Is(err, test.want) {
+				t.Errorf("Unexpected Record err:\nwant: %v,\ngot: %v.", test.want, err)
+			}
+		})
+	}
+}
+
+func TestRecompute(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rating")
+	if err != nil {
+		t.Fatalf("Unexpected TempDir err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "games")
+
+	ratings, err := New(NewFileStore(fileName), 0)
+	if err != nil {
+		t.Fatalf("Unexpected New err: %v", err)
+	}
+	for _, record := range games {
+		record.Ended = time.Now().Round(0)
+		if _, _, err := ratings.Record(record); err != nil {
+			t.Fatalf("Unexpected Record err: %v", err)
+		}
+	}
+	want := make(map[int]Player)
+	for _, id := range []int{2, 3, 4} {
+		want[id] = ratings.Player(id)
+	}
+
+	records, err := NewFileStore(fileName).Records()
+	if err != nil || len(records) != len(games) {
+		t.Fatalf("Unexpected Records result: %d records, %v", len(records), err)
+	}
+	if records[1].Reason != ByLeaving || records[1].WhiteLogin != "Joe" || records[1].Ended.IsZero() {
+		t.Errorf("Unexpected stored record: %+v", records[1])
+	}
+
+	loaded, err := New(NewFileStore(fileName), 0)
+	if err != nil {
+		t.Fatalf("Unexpected New err of stored ratings: %v", err)
+	}
+	if err := os.Remove(fileName + ".ratings"); err != nil {
+		t.Fatalf("Unexpected Remove err: %v", err)
+	}
+	recomputed, err := New(NewFileStore(fileName), 0)
+	if err != nil {
+		t.Fatalf("Unexpected New err of recomputed ratings: %v", err)
+	}
+	for id, player := range want {
+		if got := loaded.Player(id); got != player {
+			t.Errorf("Unexpected loaded rating:\nwant: %+v,\ngot: %+v.", player, got)
+		}
+		if got := recomputed.Player(id); got != player {
+			t.Errorf("Unexpected recomputed rating:\nwant: %+v,\ngot: %+v.", player, got)
+		}
+	}
+}
+
+func TestPartlyWrittenRecord(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	dir, err := ioutil.TempDir("", "rating")
+	if err != nil {
+		t.Fatalf("Unexpected TempDir err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "games")
+
+	store := NewFileStore(fileName)
+	for _, record := range games[:2] {
+		if err := store.AddRecord(record); err != nil {
+			t.Fatalf("Unexpected AddRecord err: %v", err)
+		}
+	}
+	// AddRecord interrupted in the middle of the line.
+	file, err := os.OpenFile(fileName, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatalf("Unexpected OpenFile err: %v", err)
+	}
+	file.WriteString(`{"black":2,"white":4,"bla`)
+	file.Close()
+
+	ratings, err := New(store, 0)
+	if err != nil {
+		t.Fatalf("Unexpected New err: %v", err)
+	}
+	if _, _, err := ratings.Record(games[2]); err != nil {
+		t.Fatalf("Unexpected Record err: %v", err)
+	}
+	records, err := store.Records()
+	if err != nil || len(records) != 3 {
+		t.Fatalf("Unexpected Records result: %d records, %v", len(records), err)
+	}
+	if records[2] != games[2] {
+		t.Errorf("Unexpected record after truncated one:\nwant: %+v,\ngot: %+v.", games[2], records[2])
+	}
+}
+
+func TestCorruptedRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rating")
+	if err != nil {
+		t.Fatalf("Unexpected TempDir err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "games")
+
+	if err := ioutil.WriteFile(fileName, []byte("{\"black\":2,\"wh\n{\"black\":3}\n"), 0600); err != nil {
+		t.Fatalf("Unexpected WriteFile err: %v", err)
+	}
+	if _, err := New(NewFileStore(fileName), 0); err == nil {
+		t.Errorf("Unexpected New success with corrupted record.")
+	}
+}
+
+func TestRemove(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rating")
+	if err != nil {
+		t.Fatalf("Unexpected TempDir err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "games")
+
+	ratings, err := New(NewFileStore(fileName), 0)
+	if err != nil {
+		t.Fatalf("Unexpected New err: %v", err)
+	}
+	for _, record := range games {
+		if _, _, err := ratings.Record(record); err != nil {
+			t.Fatalf("Unexpected Record err: %v", err)
+		}
+	}
+	if err := ratings.Remove(2); err != nil {
+		t.Fatalf("Unexpected Remove err: %v", err)
+	}
+	if err := ratings.Remove(5); err != nil {
+		t.Fatalf("Unexpected Remove err of unrated player: %v", err)
+	}
+	// new user gets id of the removed one.
+	if _, _, err := ratings.Record(Record{Black: 2, White: 4, BlackLogin: "Ann", WhiteLogin: "Piter",
+		Size: 9, Result: WhiteWins, Reason: ByScore}); err != nil {
+		t.Fatalf("Unexpected Record err: %v", err)
+	}
+	want := ratings.Player(2)
+	if want.Login != "Ann" || want.Games != 1 || want.Rating >= DefaultRating {
+		t.Errorf("Unexpected Player of new user: %+v", want)
+	}
+	if _, ok := ratings.PlayerByLogin("Joe"); ok {
+		t.Errorf("Unexpected PlayerByLogin of removed player.")
+	}
+	stats, err := ratings.Stats(2)
+	if err != nil || stats.Games() != 1 || stats.Losses != 1 {
+		t.Errorf("Unexpected Stats of new user: %+v, %v", stats, err)
+	}
+
+	if err := os.Remove(fileName + ".ratings"); err != nil {
+		t.Fatalf("Unexpected Remove err: %v", err)
+	}
+	recomputed, err := New(NewFileStore(fileName), 0)
+	if err != nil {
+		t.Fatalf("Unexpected New err of recomputed ratings: %v", err)
+	}
+	if got := recomputed.Player(2); got != want {
+		t.Errorf("Unexpected recomputed Player:\nwant: %+v,\ngot: %+v.", want, got)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package rating
+
+import (
+	"sort"
+	"time"
+)
+
+// Stats are statistics of player's games.
+type Stats struct {
+	Wins   int
+	Losses int
+	Draws  int
+	// BySize is a number of games on each board size
+	BySize map[int]int
+	// AverageMoves is an average number of moves in games
+	AverageMoves float64
+	// AverageDuration is an average duration of games
+	AverageDuration time.Duration
+	// LongestWinStreak is the longest sequence of wins
+	LongestWinStreak int
+}
+
+// Games returns the number of player's games.
+func (stats Stats) Games() int {
+	return stats.Wins + stats.Losses + stats.Draws
+}
+
+// Leaderboard returns players ordered by rating from the highest one,
+// skipping offset players, at most limit players are returned.
+// It returns the number of rated players too.
+func (ratings *Ratings) Leaderboard(offset, limit int) ([]Player, int) {
+	ratings.mutex.Lock()
+	defer ratings.mutex.Unlock()
+
+	total := len(ratings.board)
+	if offset > total {
+		offset = total
+	}
+	board := ratings.board[offset:]
+	if limit < len(board) {
+		board = board[:limit]
+	}
+	players := make([]Player, 0, len(board))
+	for _, player := range board {
+		players = append(players, *player)
+	}
+	return players, total
+}
+
+// Stats returns statistics of player with id.
+func (ratings *Ratings) Stats(id int) (Stats, error) {
+	ratings.mutex.Lock()
+	defer ratings.mutex.Unlock()
+
+	tally, ok := ratings.tallies[id]
+	if !ok {
+		return Stats{BySize: make(map[int]int)}, nil
+	}
+	return tally.stats(), nil
+}
+
+// rank inserts player into the leaderboard, it must be called with locked mutex.
+func (ratings *Ratings) rank(player *Player) {
+	i := ratings.position(player)
+	ratings.board = append(ratings.board, nil)
+	copy(ratings.board[i+1:], ratings.board[i:])
+	ratings.board[i] = player
+}
+
+// unrank removes player from the leaderboard, it must be called with locked mutex
+// before the change of his rating.
+func (ratings *Ratings) unrank(player *Player) {
+	i := ratings.position(player)
+	if i < len(ratings.board) && ratings.board[i] == player {
+		ratings.board = append(ratings.board[:i], ratings.board[i+1:]...)
+	}
+}
+
+// position returns the place of player in the leaderboard.
+func (ratings *Ratings) position(player *Player) int {
+	return sort.Search(len(ratings.board), func(i int) bool {
+		return !higher(ratings.board[i], player)
+	})
+}
+
+// higher reports whether player a is placed above player b.
+func higher(a, b *Player) bool {
+	if a.Rating != b.Rating {
+		return a.Rating > b.Rating
+	}
+	return a.ID < b.ID
+}
+
+// tally accumulates statistics of player's games.
+type tally struct {
+	Stats
+	moves    int
+	duration time.Duration
+	streak   int
+}
+
+func newTally() *tally {
+	return &tally{Stats: Stats{BySize: make(map[int]int)}}
+}
+
+// add counts game of record, played by player with id.
+func (tally *tally) add(record Record, id int) {
+	tally.BySize[record.Size]++
+	tally.moves += record.Moves
+	tally.duration += record.Ended.Sub(record.Begun)
+
+	switch {
+	case record.Result == Draw:
+		tally.Draws++
+		tally.streak = 0
+	case (record.Result == BlackWins) == (record.Black == id):
+		tally.Wins++
+		tally.streak++
+		if tally.streak > tally.LongestWinStreak {
+			tally.LongestWinStreak = tally.streak
+		}
+	default:
+		tally.Losses++
+		tally.streak = 0
+	}
+}
+
+// stats returns a copy of accumulated statistics with averages.
+func (tally *tally) stats() Stats {
+	stats := tally.Stats
+	stats.BySize = make(map[int]int, len(tally.BySize))
+	for size, games := range tally.BySize {
+		stats.BySize[size] = games
+	}
+	if games := stats.Games(); games > 0 {
+		stats.AverageMoves = float64(tally.moves) / float64(games)
+		stats.AverageDuration = tally.duration / time.Duration(games)
+	}
+	return stats
+}
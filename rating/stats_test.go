@@ -0,0 +1,160 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package rating_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/yagoggame/grpc_server/rating"
+)
+
+var statsTests = []struct {
+	caseName string
+	id       int
+	want     Stats
+}{
+	{caseName: "Joe", id: 2, want: Stats{Wins: 1, Losses: 1, Draws: 1, BySize: map[int]int{9: 2, 13: 1},
+		AverageMoves: 112.0 / 3, AverageDuration: 20 * time.Minute, LongestWinStreak: 1}},
+	{caseName: "Nick", id: 3, want: Stats{Wins: 2, Losses: 1, BySize: map[int]int{9: 3},
+		AverageMoves: 59.0 / 3, AverageDuration: 20 * time.Minute, LongestWinStreak: 2}},
+	{caseName: "Piter", id: 4, want: Stats{Losses: 1, Draws: 1, BySize: map[int]int{9: 1, 13: 1},
+		AverageMoves: 67.0 / 2, AverageDuration: 20 * time.Minute}},
+	{caseName: "no games", id: 5, want: Stats{BySize: map[int]int{}}},
+}
+
+func TestStats(t *testing.T) {
+	ratings := recordedRatings(t)
+
+	for _, test := range statsTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			got, err := ratings.Stats(test.id)
+			if err != nil {
+				t.Fatalf("Unexpected Stats err: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Unexpected Stats:\nwant: %+v,\ngot: %+v.", test.want, got)
+			}
+			if got.Games() != test.want.Wins+test.want.Losses+test.want.Draws {
+				t.Errorf("Unexpected Games: %d.", got.Games())
+			}
+		})
+	}
+}
+
+var leaderboardTests = []struct {
+	caseName string
+	offset   int
+	limit    int
+	want     []int
+}{
+	{caseName: "all", offset: 0, limit: 10, want: []int{3, 2, 4}},
+	{caseName: "first page", offset: 0, limit: 2, want: []int{3, 2}},
+	{caseName: "second page", offset: 2, limit: 2, want: []int{4}},
+	{caseName: "after the end", offset: 5, limit: 2, want: []int{}},
+	{caseName: "zero limit", offset: 0, limit: 0, want: []int{}},
+}
+
+func TestLeaderboard(t *testing.T) {
+	ratings := recordedRatings(t)
+
+	for _, test := range leaderboardTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			players, total := ratings.Leaderboard(test.offset, test.limit)
+			if total != 3 {
+				t.Errorf("Unexpected total:\nwant: %d,\ngot: %d.", 3, total)
+			}
+			got := make([]int, 0, len(players))
+			for _, player := range players {
+				got = append(got, player.ID)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Unexpected Leaderboard:\nwant: %v,\ngot: %v.", test.want, got)
+			}
+		})
+	}
+}
+
+// countingStore counts reading of records.
+type countingStore struct {
+	*MemoryStore
+	reads int
+}
+
+func (store *countingStore) Records() ([]Record, error) {
+	store.reads++
+	return store.MemoryStore.Records()
+}
+
+func TestStatsCached(t *testing.T) {
+	store := &countingStore{MemoryStore: NewMemoryStore()}
+	recorded := recordedRatingsOf(t, store)
+
+	// ratings are loaded from the store, statistics are read from records once.
+	store.reads = 0
+	ratings, err := New(store, 0)
+	if err != nil {
+		t.Fatalf("Unexpected New err: %v", err)
+	}
+	if store.reads != 1 {
+		t.Errorf("Unexpected reads of records by New:\nwant: %d,\ngot: %d.", 1, store.reads)
+	}
+
+	store.reads = 0
+	for _, test := range statsTests {
+		got, err := ratings.Stats(test.id)
+		if err != nil {
+			t.Fatalf("Unexpected Stats err: %v", err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Unexpected Stats of %s:\nwant: %+v,\ngot: %+v.", test.caseName, test.want, got)
+		}
+	}
+	want, _ := recorded.Leaderboard(0, 10)
+	if got, _ := ratings.Leaderboard(0, 10); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected Leaderboard:\nwant: %+v,\ngot: %+v.", want, got)
+	}
+	if store.reads != 0 {
+		t.Errorf("Unexpected reads of records by Stats and Leaderboard: %d.", store.reads)
+	}
+}
+
+// recordedRatings returns ratings with recorded games of 20 minutes.
+func recordedRatings(t *testing.T) *Ratings {
+	t.Helper()
+
+	return recordedRatingsOf(t, NewMemoryStore())
+}
+
+// recordedRatingsOf returns ratings with recorded games of 20 minutes kept by store.
+func recordedRatingsOf(t *testing.T, store Store) *Ratings {
+	t.Helper()
+
+	ratings, err := New(store, 0)
+	if err != nil {
+		t.Fatalf("Unexpected New err: %v", err)
+	}
+	begun := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, record := range games {
+		record.Begun, record.Ended = begun, begun.Add(20*time.Minute)
+		if _, _, err := ratings.Record(record); err != nil {
+			t.Fatalf("Unexpected Record err: %v", err)
+		}
+	}
+	return ratings
+}
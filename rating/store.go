@@ -0,0 +1,196 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package rating
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"sync"
+
+	"github.com/yagoggame/grpc_server/atomicfile"
+)
+
+// ratingsSuffix is appended to the name of records file to get the name of ratings file.
+const ratingsSuffix = ".ratings"
+
+// MemoryStore keeps records and ratings in memory.
+type MemoryStore struct {
+	mutex   sync.Mutex
+	records []Record
+	players []Player
+}
+
+// NewMemoryStore creates empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// AddRecord appends record to the history of games.
+func (store *MemoryStore) AddRecord(record Record) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.records = append(store.records, record)
+	return nil
+}
+
+// Records returns the history of games.
+func (store *MemoryStore) Records() ([]Record, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	return append([]Record(nil), store.records...), nil
+}
+
+// Players returns stored ratings of players.
+func (store *MemoryStore) Players() ([]Player, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	return append([]Player(nil), store.players...), nil
+}
+
+// SavePlayers replaces stored ratings of players.
+func (store *MemoryStore) SavePlayers(players []Player) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.players = append([]Player(nil), players...)
+	return nil
+}
+
+// FileStore keeps records of games in a file, one JSON object per line,
+// and ratings of players in JSON file with the same name and ".ratings" suffix.
+// Records are only appended, ratings are replaced atomically.
+type FileStore struct {
+	mutex    sync.Mutex
+	fileName string
+}
+
+// NewFileStore creates FileStore, files are created on the first write.
+func NewFileStore(fileName string) *FileStore {
+	return &FileStore{fileName: fileName}
+}
+
+// AddRecord appends record to the file of games.
+func (store *FileStore) AddRecord(record Record) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(store.fileName, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Records reads the file of games. The last line without line feed
+// is partly written by interrupted AddRecord, so it is truncated
+// to let the next record be appended after the complete ones.
+func (store *FileStore) Records() ([]Record, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	file, err := os.Open(store.fileName)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var (
+		records []Record
+		offset  int64
+	)
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) > 0 {
+				log.Printf("partly written record at the end of %q is truncated: %q", store.fileName, line)
+				return records, os.Truncate(store.fileName, offset)
+			}
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		offset += int64(len(line))
+
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal(line, &record); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+}
+
+// Players reads the file of ratings.
+func (store *FileStore) Players() ([]Player, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	content, err := ioutil.ReadFile(store.fileName + ratingsSuffix)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var players []Player
+	if err := json.Unmarshal(content, &players); err != nil {
+		return nil, err
+	}
+	return players, nil
+}
+
+// SavePlayers atomically replaces the file of ratings.
+func (store *FileStore) SavePlayers(players []Player) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	content, err := json.MarshalIndent(players, "", "\t")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(store.fileName+ratingsSuffix, func(writer io.Writer) error {
+		_, err := writer.Write(content)
+		return err
+	})
+}
@@ -0,0 +1,3532 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: grpc_server.proto
+
+package serverapi
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	api "github.com/yagoggame/api"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// UndoEvent is a step of takeback of a move.
+type UndoEvent int32
+
+const (
+	// NO_UNDO is set for events, which aren't about takeback
+	UndoEvent_NO_UNDO UndoEvent = 0
+	// UNDO_REQUESTED is set, when partner asks to take back his last move
+	UndoEvent_UNDO_REQUESTED UndoEvent = 1
+	// UNDO_ACCEPTED is set, when partner accepts the takeback
+	UndoEvent_UNDO_ACCEPTED UndoEvent = 2
+	// UNDO_REJECTED is set, when partner rejects the takeback
+	UndoEvent_UNDO_REJECTED UndoEvent = 3
+)
+
+var UndoEvent_name = map[int32]string{
+	0: "NO_UNDO",
+	1: "UNDO_REQUESTED",
+	2: "UNDO_ACCEPTED",
+	3: "UNDO_REJECTED",
+}
+
+var UndoEvent_value = map[string]int32{
+	"NO_UNDO":        0,
+	"UNDO_REQUESTED": 1,
+	"UNDO_ACCEPTED":  2,
+	"UNDO_REJECTED":  3,
+}
+
+func (x UndoEvent) String() string {
+	return proto.EnumName(UndoEvent_name, int32(x))
+}
+
+func (UndoEvent) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{0}
+}
+
+// GameStatus is a place of gamer on the server.
+type GameStatus int32
+
+const (
+	// NOT_IN_LOBBY is a status of gamer, who hasn't entered the lobby
+	GameStatus_NOT_IN_LOBBY GameStatus = 0
+	// IN_LOBBY is a status of gamer in the lobby without a game
+	GameStatus_IN_LOBBY GameStatus = 1
+	// AWAITING_PARTNER is a status of gamer, whose game hasn't begun
+	GameStatus_AWAITING_PARTNER GameStatus = 2
+	// PLAYING is a status of gamer in begun game
+	GameStatus_PLAYING GameStatus = 3
+	// GAME_OVER is a status of gamer, whose game is over
+	GameStatus_GAME_OVER GameStatus = 4
+)
+
+var GameStatus_name = map[int32]string{
+	0: "NOT_IN_LOBBY",
+	1: "IN_LOBBY",
+	2: "AWAITING_PARTNER",
+	3: "PLAYING",
+	4: "GAME_OVER",
+}
+
+var GameStatus_value = map[string]int32{
+	"NOT_IN_LOBBY":     0,
+	"IN_LOBBY":         1,
+	"AWAITING_PARTNER": 2,
+	"PLAYING":          3,
+	"GAME_OVER":        4,
+}
+
+func (x GameStatus) String() string {
+	return proto.EnumName(GameStatus_name, int32(x))
+}
+
+func (GameStatus) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{1}
+}
+
+// ChatChannel is a kind of chat channel.
+type ChatChannel int32
+
+const (
+	// LOBBY is a channel of all gamers in the lobby
+	ChatChannel_LOBBY ChatChannel = 0
+	// GAME is a channel of gamers of one game
+	ChatChannel_GAME ChatChannel = 1
+)
+
+var ChatChannel_name = map[int32]string{
+	0: "LOBBY",
+	1: "GAME",
+}
+
+var ChatChannel_value = map[string]int32{
+	"LOBBY": 0,
+	"GAME":  1,
+}
+
+func (x ChatChannel) String() string {
+	return proto.EnumName(ChatChannel_name, int32(x))
+}
+
+func (ChatChannel) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{2}
+}
+
+// LobbyStatus is an activity of gamer in the lobby.
+type LobbyStatus int32
+
+const (
+	// IDLE is a status of gamer without a game
+	LobbyStatus_IDLE LobbyStatus = 0
+	// SEEKING is a status of gamer, who awaits a partner
+	LobbyStatus_SEEKING LobbyStatus = 1
+	// IN_GAME is a status of gamer in begun game,
+	// it isn't named PLAYING as enum values share the scope of package
+	LobbyStatus_IN_GAME LobbyStatus = 2
+	// SPECTATING is a status of gamer, who watches a game of others
+	LobbyStatus_SPECTATING LobbyStatus = 3
+)
+
+var LobbyStatus_name = map[int32]string{
+	0: "IDLE",
+	1: "SEEKING",
+	2: "IN_GAME",
+	3: "SPECTATING",
+}
+
+var LobbyStatus_value = map[string]int32{
+	"IDLE":       0,
+	"SEEKING":    1,
+	"IN_GAME":    2,
+	"SPECTATING": 3,
+}
+
+func (x LobbyStatus) String() string {
+	return proto.EnumName(LobbyStatus_name, int32(x))
+}
+
+func (LobbyStatus) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{3}
+}
+
+// Colour is a colour of stones chosen by gamer.
+type Colour int32
+
+const (
+	// RANDOM lets server to choose colour
+	Colour_RANDOM Colour = 0
+	// BLACK is a colour of the first mover
+	Colour_BLACK Colour = 1
+	// WHITE is a colour of the second mover
+	Colour_WHITE Colour = 2
+	// UNKNOWN is reported, when the colour of gamer isn't known yet,
+	// it isn't accepted as a choice of gamer
+	Colour_UNKNOWN Colour = 3
+)
+
+var Colour_name = map[int32]string{
+	0: "RANDOM",
+	1: "BLACK",
+	2: "WHITE",
+	3: "UNKNOWN",
+}
+
+var Colour_value = map[string]int32{
+	"RANDOM":  0,
+	"BLACK":   1,
+	"WHITE":   2,
+	"UNKNOWN": 3,
+}
+
+func (x Colour) String() string {
+	return proto.EnumName(Colour_name, int32(x))
+}
+
+func (Colour) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{4}
+}
+
+// ResumeMessage is a reply of ResumeGame.
+type ResumeMessage struct {
+	State                *api.State `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"`
+	MyTurn               bool       `protobuf:"varint,2,opt,name=my_turn,json=myTurn,proto3" json:"my_turn,omitempty"`
+	Partner              string     `protobuf:"bytes,3,opt,name=partner,proto3" json:"partner,omitempty"`
+	PartnerConnected     bool       `protobuf:"varint,4,opt,name=partner_connected,json=partnerConnected,proto3" json:"partner_connected,omitempty"`
+	Colour               Colour     `protobuf:"varint,5,opt,name=colour,proto3,enum=grpc_server.Colour" json:"colour,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *ResumeMessage) Reset()         { *m = ResumeMessage{} }
+func (m *ResumeMessage) String() string { return proto.CompactTextString(m) }
+func (*ResumeMessage) ProtoMessage()    {}
+func (*ResumeMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{0}
+}
+
+func (m *ResumeMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResumeMessage.Unmarshal(m, b)
+}
+func (m *ResumeMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResumeMessage.Marshal(b, m, deterministic)
+}
+func (m *ResumeMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResumeMessage.Merge(m, src)
+}
+func (m *ResumeMessage) XXX_Size() int {
+	return xxx_messageInfo_ResumeMessage.Size(m)
+}
+func (m *ResumeMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResumeMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResumeMessage proto.InternalMessageInfo
+
+func (m *ResumeMessage) GetState() *api.State {
+	if m != nil {
+		return m.State
+	}
+	return nil
+}
+
+func (m *ResumeMessage) GetMyTurn() bool {
+	if m != nil {
+		return m.MyTurn
+	}
+	return false
+}
+
+func (m *ResumeMessage) GetPartner() string {
+	if m != nil {
+		return m.Partner
+	}
+	return ""
+}
+
+func (m *ResumeMessage) GetPartnerConnected() bool {
+	if m != nil {
+		return m.PartnerConnected
+	}
+	return false
+}
+
+func (m *ResumeMessage) GetColour() Colour {
+	if m != nil {
+		return m.Colour
+	}
+	return Colour_RANDOM
+}
+
+// PartnerEvent informs a gamer about connection of his partner.
+// Left is set, when partner left the game or his grace period expired.
+// Undo is set, when partner asks to take back his last move or answers such request.
+type PartnerEvent struct {
+	Partner              string    `protobuf:"bytes,1,opt,name=partner,proto3" json:"partner,omitempty"`
+	Connected            bool      `protobuf:"varint,2,opt,name=connected,proto3" json:"connected,omitempty"`
+	Left                 bool      `protobuf:"varint,3,opt,name=left,proto3" json:"left,omitempty"`
+	Undo                 UndoEvent `protobuf:"varint,4,opt,name=undo,proto3,enum=grpc_server.UndoEvent" json:"undo,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *PartnerEvent) Reset()         { *m = PartnerEvent{} }
+func (m *PartnerEvent) String() string { return proto.CompactTextString(m) }
+func (*PartnerEvent) ProtoMessage()    {}
+func (*PartnerEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{1}
+}
+
+func (m *PartnerEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PartnerEvent.Unmarshal(m, b)
+}
+func (m *PartnerEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PartnerEvent.Marshal(b, m, deterministic)
+}
+func (m *PartnerEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PartnerEvent.Merge(m, src)
+}
+func (m *PartnerEvent) XXX_Size() int {
+	return xxx_messageInfo_PartnerEvent.Size(m)
+}
+func (m *PartnerEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_PartnerEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PartnerEvent proto.InternalMessageInfo
+
+func (m *PartnerEvent) GetPartner() string {
+	if m != nil {
+		return m.Partner
+	}
+	return ""
+}
+
+func (m *PartnerEvent) GetConnected() bool {
+	if m != nil {
+		return m.Connected
+	}
+	return false
+}
+
+func (m *PartnerEvent) GetLeft() bool {
+	if m != nil {
+		return m.Left
+	}
+	return false
+}
+
+func (m *PartnerEvent) GetUndo() UndoEvent {
+	if m != nil {
+		return m.Undo
+	}
+	return UndoEvent_NO_UNDO
+}
+
+// GameStatusMessage is a reply of GetGameState, JoinGame and WaitTurn.
+// State is set for AWAITING_PARTNER, PLAYING and GAME_OVER statuses.
+// TimeLeft and PartnerTimeLeft are in milliseconds, they are set for games with time control.
+// Colour is the colour of gamer or UNKNOWN, Partner is the login of his partner, if he is known.
+// UndoRequested is set, when partner asks to take back his last move.
+type GameStatusMessage struct {
+	Status               GameStatus `protobuf:"varint,1,opt,name=status,proto3,enum=grpc_server.GameStatus" json:"status,omitempty"`
+	State                *api.State `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	MyTurn               bool       `protobuf:"varint,3,opt,name=my_turn,json=myTurn,proto3" json:"my_turn,omitempty"`
+	TimeLeft             int64      `protobuf:"varint,4,opt,name=time_left,json=timeLeft,proto3" json:"time_left,omitempty"`
+	PartnerTimeLeft      int64      `protobuf:"varint,5,opt,name=partner_time_left,json=partnerTimeLeft,proto3" json:"partner_time_left,omitempty"`
+	Colour               Colour     `protobuf:"varint,6,opt,name=colour,proto3,enum=grpc_server.Colour" json:"colour,omitempty"`
+	Partner              string     `protobuf:"bytes,7,opt,name=partner,proto3" json:"partner,omitempty"`
+	UndoRequested        bool       `protobuf:"varint,8,opt,name=undo_requested,json=undoRequested,proto3" json:"undo_requested,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *GameStatusMessage) Reset()         { *m = GameStatusMessage{} }
+func (m *GameStatusMessage) String() string { return proto.CompactTextString(m) }
+func (*GameStatusMessage) ProtoMessage()    {}
+func (*GameStatusMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{2}
+}
+
+func (m *GameStatusMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GameStatusMessage.Unmarshal(m, b)
+}
+func (m *GameStatusMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GameStatusMessage.Marshal(b, m, deterministic)
+}
+func (m *GameStatusMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GameStatusMessage.Merge(m, src)
+}
+func (m *GameStatusMessage) XXX_Size() int {
+	return xxx_messageInfo_GameStatusMessage.Size(m)
+}
+func (m *GameStatusMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_GameStatusMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GameStatusMessage proto.InternalMessageInfo
+
+func (m *GameStatusMessage) GetStatus() GameStatus {
+	if m != nil {
+		return m.Status
+	}
+	return GameStatus_NOT_IN_LOBBY
+}
+
+func (m *GameStatusMessage) GetState() *api.State {
+	if m != nil {
+		return m.State
+	}
+	return nil
+}
+
+func (m *GameStatusMessage) GetMyTurn() bool {
+	if m != nil {
+		return m.MyTurn
+	}
+	return false
+}
+
+func (m *GameStatusMessage) GetTimeLeft() int64 {
+	if m != nil {
+		return m.TimeLeft
+	}
+	return 0
+}
+
+func (m *GameStatusMessage) GetPartnerTimeLeft() int64 {
+	if m != nil {
+		return m.PartnerTimeLeft
+	}
+	return 0
+}
+
+func (m *GameStatusMessage) GetColour() Colour {
+	if m != nil {
+		return m.Colour
+	}
+	return Colour_RANDOM
+}
+
+func (m *GameStatusMessage) GetPartner() string {
+	if m != nil {
+		return m.Partner
+	}
+	return ""
+}
+
+func (m *GameStatusMessage) GetUndoRequested() bool {
+	if m != nil {
+		return m.UndoRequested
+	}
+	return false
+}
+
+// JoinRequest is a request of JoinGame.
+// Gamers with RANDOM colour and no handicap join games of the pool,
+// others are paired by matchmaking with gamers of the same handicap.
+type JoinRequest struct {
+	Colour               Colour   `protobuf:"varint,1,opt,name=colour,proto3,enum=grpc_server.Colour" json:"colour,omitempty"`
+	Handicap             int64    `protobuf:"varint,2,opt,name=handicap,proto3" json:"handicap,omitempty"`
+	FreePlacement        bool     `protobuf:"varint,3,opt,name=free_placement,json=freePlacement,proto3" json:"free_placement,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *JoinRequest) Reset()         { *m = JoinRequest{} }
+func (m *JoinRequest) String() string { return proto.CompactTextString(m) }
+func (*JoinRequest) ProtoMessage()    {}
+func (*JoinRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{3}
+}
+
+func (m *JoinRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JoinRequest.Unmarshal(m, b)
+}
+func (m *JoinRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JoinRequest.Marshal(b, m, deterministic)
+}
+func (m *JoinRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JoinRequest.Merge(m, src)
+}
+func (m *JoinRequest) XXX_Size() int {
+	return xxx_messageInfo_JoinRequest.Size(m)
+}
+func (m *JoinRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_JoinRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_JoinRequest proto.InternalMessageInfo
+
+func (m *JoinRequest) GetColour() Colour {
+	if m != nil {
+		return m.Colour
+	}
+	return Colour_RANDOM
+}
+
+func (m *JoinRequest) GetHandicap() int64 {
+	if m != nil {
+		return m.Handicap
+	}
+	return 0
+}
+
+func (m *JoinRequest) GetFreePlacement() bool {
+	if m != nil {
+		return m.FreePlacement
+	}
+	return false
+}
+
+// GameInfo describes a game, which can be watched by spectators.
+type GameInfo struct {
+	Id                   int64    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Players              []string `protobuf:"bytes,2,rep,name=players,proto3" json:"players,omitempty"`
+	Size                 int64    `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	Spectators           int64    `protobuf:"varint,4,opt,name=spectators,proto3" json:"spectators,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GameInfo) Reset()         { *m = GameInfo{} }
+func (m *GameInfo) String() string { return proto.CompactTextString(m) }
+func (*GameInfo) ProtoMessage()    {}
+func (*GameInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{4}
+}
+
+func (m *GameInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GameInfo.Unmarshal(m, b)
+}
+func (m *GameInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GameInfo.Marshal(b, m, deterministic)
+}
+func (m *GameInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GameInfo.Merge(m, src)
+}
+func (m *GameInfo) XXX_Size() int {
+	return xxx_messageInfo_GameInfo.Size(m)
+}
+func (m *GameInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_GameInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GameInfo proto.InternalMessageInfo
+
+func (m *GameInfo) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *GameInfo) GetPlayers() []string {
+	if m != nil {
+		return m.Players
+	}
+	return nil
+}
+
+func (m *GameInfo) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *GameInfo) GetSpectators() int64 {
+	if m != nil {
+		return m.Spectators
+	}
+	return 0
+}
+
+// GamesMessage is a reply of ListGames.
+type GamesMessage struct {
+	Games                []*GameInfo `protobuf:"bytes,1,rep,name=games,proto3" json:"games,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *GamesMessage) Reset()         { *m = GamesMessage{} }
+func (m *GamesMessage) String() string { return proto.CompactTextString(m) }
+func (*GamesMessage) ProtoMessage()    {}
+func (*GamesMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{5}
+}
+
+func (m *GamesMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GamesMessage.Unmarshal(m, b)
+}
+func (m *GamesMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GamesMessage.Marshal(b, m, deterministic)
+}
+func (m *GamesMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GamesMessage.Merge(m, src)
+}
+func (m *GamesMessage) XXX_Size() int {
+	return xxx_messageInfo_GamesMessage.Size(m)
+}
+func (m *GamesMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_GamesMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GamesMessage proto.InternalMessageInfo
+
+func (m *GamesMessage) GetGames() []*GameInfo {
+	if m != nil {
+		return m.Games
+	}
+	return nil
+}
+
+// GameIDMessage identifies a game.
+type GameIDMessage struct {
+	Id                   int64    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GameIDMessage) Reset()         { *m = GameIDMessage{} }
+func (m *GameIDMessage) String() string { return proto.CompactTextString(m) }
+func (*GameIDMessage) ProtoMessage()    {}
+func (*GameIDMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{6}
+}
+
+func (m *GameIDMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GameIDMessage.Unmarshal(m, b)
+}
+func (m *GameIDMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GameIDMessage.Marshal(b, m, deterministic)
+}
+func (m *GameIDMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GameIDMessage.Merge(m, src)
+}
+func (m *GameIDMessage) XXX_Size() int {
+	return xxx_messageInfo_GameIDMessage.Size(m)
+}
+func (m *GameIDMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_GameIDMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GameIDMessage proto.InternalMessageInfo
+
+func (m *GameIDMessage) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+// PrivacyMessage is a request of SetGamePrivate.
+type PrivacyMessage struct {
+	Private              bool     `protobuf:"varint,1,opt,name=private,proto3" json:"private,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PrivacyMessage) Reset()         { *m = PrivacyMessage{} }
+func (m *PrivacyMessage) String() string { return proto.CompactTextString(m) }
+func (*PrivacyMessage) ProtoMessage()    {}
+func (*PrivacyMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{7}
+}
+
+func (m *PrivacyMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PrivacyMessage.Unmarshal(m, b)
+}
+func (m *PrivacyMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PrivacyMessage.Marshal(b, m, deterministic)
+}
+func (m *PrivacyMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PrivacyMessage.Merge(m, src)
+}
+func (m *PrivacyMessage) XXX_Size() int {
+	return xxx_messageInfo_PrivacyMessage.Size(m)
+}
+func (m *PrivacyMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_PrivacyMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PrivacyMessage proto.InternalMessageInfo
+
+func (m *PrivacyMessage) GetPrivate() bool {
+	if m != nil {
+		return m.Private
+	}
+	return false
+}
+
+// ChatMessage is a message in a chat channel.
+// UserId, Login and Time are set by server, Time is in nanoseconds since Unix epoch.
+type ChatMessage struct {
+	Channel              ChatChannel `protobuf:"varint,1,opt,name=channel,proto3,enum=grpc_server.ChatChannel" json:"channel,omitempty"`
+	UserId               int64       `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Login                string      `protobuf:"bytes,3,opt,name=login,proto3" json:"login,omitempty"`
+	Text                 string      `protobuf:"bytes,4,opt,name=text,proto3" json:"text,omitempty"`
+	Time                 int64       `protobuf:"varint,5,opt,name=time,proto3" json:"time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *ChatMessage) Reset()         { *m = ChatMessage{} }
+func (m *ChatMessage) String() string { return proto.CompactTextString(m) }
+func (*ChatMessage) ProtoMessage()    {}
+func (*ChatMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{8}
+}
+
+func (m *ChatMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ChatMessage.Unmarshal(m, b)
+}
+func (m *ChatMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ChatMessage.Marshal(b, m, deterministic)
+}
+func (m *ChatMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ChatMessage.Merge(m, src)
+}
+func (m *ChatMessage) XXX_Size() int {
+	return xxx_messageInfo_ChatMessage.Size(m)
+}
+func (m *ChatMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_ChatMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ChatMessage proto.InternalMessageInfo
+
+func (m *ChatMessage) GetChannel() ChatChannel {
+	if m != nil {
+		return m.Channel
+	}
+	return ChatChannel_LOBBY
+}
+
+func (m *ChatMessage) GetUserId() int64 {
+	if m != nil {
+		return m.UserId
+	}
+	return 0
+}
+
+func (m *ChatMessage) GetLogin() string {
+	if m != nil {
+		return m.Login
+	}
+	return ""
+}
+
+func (m *ChatMessage) GetText() string {
+	if m != nil {
+		return m.Text
+	}
+	return ""
+}
+
+func (m *ChatMessage) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+// ChannelMessage is a request of Subscribe.
+type ChannelMessage struct {
+	Channel              ChatChannel `protobuf:"varint,1,opt,name=channel,proto3,enum=grpc_server.ChatChannel" json:"channel,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *ChannelMessage) Reset()         { *m = ChannelMessage{} }
+func (m *ChannelMessage) String() string { return proto.CompactTextString(m) }
+func (*ChannelMessage) ProtoMessage()    {}
+func (*ChannelMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{9}
+}
+
+func (m *ChannelMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ChannelMessage.Unmarshal(m, b)
+}
+func (m *ChannelMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ChannelMessage.Marshal(b, m, deterministic)
+}
+func (m *ChannelMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ChannelMessage.Merge(m, src)
+}
+func (m *ChannelMessage) XXX_Size() int {
+	return xxx_messageInfo_ChannelMessage.Size(m)
+}
+func (m *ChannelMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_ChannelMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ChannelMessage proto.InternalMessageInfo
+
+func (m *ChannelMessage) GetChannel() ChatChannel {
+	if m != nil {
+		return m.Channel
+	}
+	return ChatChannel_LOBBY
+}
+
+// HistoryRequest is a request of History.
+// Limit is a number of last messages, 0 means all kept messages.
+type HistoryRequest struct {
+	Channel              ChatChannel `protobuf:"varint,1,opt,name=channel,proto3,enum=grpc_server.ChatChannel" json:"channel,omitempty"`
+	Limit                int64       `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *HistoryRequest) Reset()         { *m = HistoryRequest{} }
+func (m *HistoryRequest) String() string { return proto.CompactTextString(m) }
+func (*HistoryRequest) ProtoMessage()    {}
+func (*HistoryRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{10}
+}
+
+func (m *HistoryRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HistoryRequest.Unmarshal(m, b)
+}
+func (m *HistoryRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HistoryRequest.Marshal(b, m, deterministic)
+}
+func (m *HistoryRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HistoryRequest.Merge(m, src)
+}
+func (m *HistoryRequest) XXX_Size() int {
+	return xxx_messageInfo_HistoryRequest.Size(m)
+}
+func (m *HistoryRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_HistoryRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HistoryRequest proto.InternalMessageInfo
+
+func (m *HistoryRequest) GetChannel() ChatChannel {
+	if m != nil {
+		return m.Channel
+	}
+	return ChatChannel_LOBBY
+}
+
+func (m *HistoryRequest) GetLimit() int64 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+// HistoryMessage is a reply of History.
+type HistoryMessage struct {
+	Messages             []*ChatMessage `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *HistoryMessage) Reset()         { *m = HistoryMessage{} }
+func (m *HistoryMessage) String() string { return proto.CompactTextString(m) }
+func (*HistoryMessage) ProtoMessage()    {}
+func (*HistoryMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{11}
+}
+
+func (m *HistoryMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HistoryMessage.Unmarshal(m, b)
+}
+func (m *HistoryMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HistoryMessage.Marshal(b, m, deterministic)
+}
+func (m *HistoryMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HistoryMessage.Merge(m, src)
+}
+func (m *HistoryMessage) XXX_Size() int {
+	return xxx_messageInfo_HistoryMessage.Size(m)
+}
+func (m *HistoryMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_HistoryMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HistoryMessage proto.InternalMessageInfo
+
+func (m *HistoryMessage) GetMessages() []*ChatMessage {
+	if m != nil {
+		return m.Messages
+	}
+	return nil
+}
+
+// LobbyGamer describes a gamer in the lobby.
+type LobbyGamer struct {
+	Id                   int64       `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Login                string      `protobuf:"bytes,2,opt,name=login,proto3" json:"login,omitempty"`
+	Status               LobbyStatus `protobuf:"varint,3,opt,name=status,proto3,enum=grpc_server.LobbyStatus" json:"status,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *LobbyGamer) Reset()         { *m = LobbyGamer{} }
+func (m *LobbyGamer) String() string { return proto.CompactTextString(m) }
+func (*LobbyGamer) ProtoMessage()    {}
+func (*LobbyGamer) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{12}
+}
+
+func (m *LobbyGamer) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LobbyGamer.Unmarshal(m, b)
+}
+func (m *LobbyGamer) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LobbyGamer.Marshal(b, m, deterministic)
+}
+func (m *LobbyGamer) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LobbyGamer.Merge(m, src)
+}
+func (m *LobbyGamer) XXX_Size() int {
+	return xxx_messageInfo_LobbyGamer.Size(m)
+}
+func (m *LobbyGamer) XXX_DiscardUnknown() {
+	xxx_messageInfo_LobbyGamer.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LobbyGamer proto.InternalMessageInfo
+
+func (m *LobbyGamer) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *LobbyGamer) GetLogin() string {
+	if m != nil {
+		return m.Login
+	}
+	return ""
+}
+
+func (m *LobbyGamer) GetStatus() LobbyStatus {
+	if m != nil {
+		return m.Status
+	}
+	return LobbyStatus_IDLE
+}
+
+// LobbyMessage is a reply of ListLobby and a message of WatchLobby.
+type LobbyMessage struct {
+	Gamers               []*LobbyGamer `protobuf:"bytes,1,rep,name=gamers,proto3" json:"gamers,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *LobbyMessage) Reset()         { *m = LobbyMessage{} }
+func (m *LobbyMessage) String() string { return proto.CompactTextString(m) }
+func (*LobbyMessage) ProtoMessage()    {}
+func (*LobbyMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{13}
+}
+
+func (m *LobbyMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LobbyMessage.Unmarshal(m, b)
+}
+func (m *LobbyMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LobbyMessage.Marshal(b, m, deterministic)
+}
+func (m *LobbyMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LobbyMessage.Merge(m, src)
+}
+func (m *LobbyMessage) XXX_Size() int {
+	return xxx_messageInfo_LobbyMessage.Size(m)
+}
+func (m *LobbyMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_LobbyMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LobbyMessage proto.InternalMessageInfo
+
+func (m *LobbyMessage) GetGamers() []*LobbyGamer {
+	if m != nil {
+		return m.Gamers
+	}
+	return nil
+}
+
+// ChallengeRequest is a request of Challenge.
+// Size and Komi are standard ones, if they are 0.
+// MainTime and Increment are in seconds, 0 MainTime means no time control.
+// Handicap is a number of stones of Black, which are placed on star points
+// or by Black himself, if FreePlacement is true. Handicap games have komi handicap.Komi.
+type ChallengeRequest struct {
+	Login                string   `protobuf:"bytes,1,opt,name=login,proto3" json:"login,omitempty"`
+	Size                 int64    `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	Komi                 float64  `protobuf:"fixed64,3,opt,name=komi,proto3" json:"komi,omitempty"`
+	Colour               Colour   `protobuf:"varint,4,opt,name=colour,proto3,enum=grpc_server.Colour" json:"colour,omitempty"`
+	MainTime             int64    `protobuf:"varint,5,opt,name=main_time,json=mainTime,proto3" json:"main_time,omitempty"`
+	Increment            int64    `protobuf:"varint,6,opt,name=increment,proto3" json:"increment,omitempty"`
+	Handicap             int64    `protobuf:"varint,7,opt,name=handicap,proto3" json:"handicap,omitempty"`
+	FreePlacement        bool     `protobuf:"varint,8,opt,name=free_placement,json=freePlacement,proto3" json:"free_placement,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ChallengeRequest) Reset()         { *m = ChallengeRequest{} }
+func (m *ChallengeRequest) String() string { return proto.CompactTextString(m) }
+func (*ChallengeRequest) ProtoMessage()    {}
+func (*ChallengeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{14}
+}
+
+func (m *ChallengeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ChallengeRequest.Unmarshal(m, b)
+}
+func (m *ChallengeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ChallengeRequest.Marshal(b, m, deterministic)
+}
+func (m *ChallengeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ChallengeRequest.Merge(m, src)
+}
+func (m *ChallengeRequest) XXX_Size() int {
+	return xxx_messageInfo_ChallengeRequest.Size(m)
+}
+func (m *ChallengeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ChallengeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ChallengeRequest proto.InternalMessageInfo
+
+func (m *ChallengeRequest) GetLogin() string {
+	if m != nil {
+		return m.Login
+	}
+	return ""
+}
+
+func (m *ChallengeRequest) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *ChallengeRequest) GetKomi() float64 {
+	if m != nil {
+		return m.Komi
+	}
+	return 0
+}
+
+func (m *ChallengeRequest) GetColour() Colour {
+	if m != nil {
+		return m.Colour
+	}
+	return Colour_RANDOM
+}
+
+func (m *ChallengeRequest) GetMainTime() int64 {
+	if m != nil {
+		return m.MainTime
+	}
+	return 0
+}
+
+func (m *ChallengeRequest) GetIncrement() int64 {
+	if m != nil {
+		return m.Increment
+	}
+	return 0
+}
+
+func (m *ChallengeRequest) GetHandicap() int64 {
+	if m != nil {
+		return m.Handicap
+	}
+	return 0
+}
+
+func (m *ChallengeRequest) GetFreePlacement() bool {
+	if m != nil {
+		return m.FreePlacement
+	}
+	return false
+}
+
+// ChallengeMessage describes a challenge.
+// Code identifies the private game of challenge, Colour is a colour of challenger,
+// Expires is in nanoseconds since Unix epoch.
+type ChallengeMessage struct {
+	Code                 string   `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	From                 string   `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To                   string   `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	Size                 int64    `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	Komi                 float64  `protobuf:"fixed64,5,opt,name=komi,proto3" json:"komi,omitempty"`
+	Colour               Colour   `protobuf:"varint,6,opt,name=colour,proto3,enum=grpc_server.Colour" json:"colour,omitempty"`
+	MainTime             int64    `protobuf:"varint,7,opt,name=main_time,json=mainTime,proto3" json:"main_time,omitempty"`
+	Increment            int64    `protobuf:"varint,8,opt,name=increment,proto3" json:"increment,omitempty"`
+	Expires              int64    `protobuf:"varint,9,opt,name=expires,proto3" json:"expires,omitempty"`
+	Handicap             int64    `protobuf:"varint,10,opt,name=handicap,proto3" json:"handicap,omitempty"`
+	FreePlacement        bool     `protobuf:"varint,11,opt,name=free_placement,json=freePlacement,proto3" json:"free_placement,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ChallengeMessage) Reset()         { *m = ChallengeMessage{} }
+func (m *ChallengeMessage) String() string { return proto.CompactTextString(m) }
+func (*ChallengeMessage) ProtoMessage()    {}
+func (*ChallengeMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{15}
+}
+
+func (m *ChallengeMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ChallengeMessage.Unmarshal(m, b)
+}
+func (m *ChallengeMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ChallengeMessage.Marshal(b, m, deterministic)
+}
+func (m *ChallengeMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ChallengeMessage.Merge(m, src)
+}
+func (m *ChallengeMessage) XXX_Size() int {
+	return xxx_messageInfo_ChallengeMessage.Size(m)
+}
+func (m *ChallengeMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_ChallengeMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ChallengeMessage proto.InternalMessageInfo
+
+func (m *ChallengeMessage) GetCode() string {
+	if m != nil {
+		return m.Code
+	}
+	return ""
+}
+
+func (m *ChallengeMessage) GetFrom() string {
+	if m != nil {
+		return m.From
+	}
+	return ""
+}
+
+func (m *ChallengeMessage) GetTo() string {
+	if m != nil {
+		return m.To
+	}
+	return ""
+}
+
+func (m *ChallengeMessage) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *ChallengeMessage) GetKomi() float64 {
+	if m != nil {
+		return m.Komi
+	}
+	return 0
+}
+
+func (m *ChallengeMessage) GetColour() Colour {
+	if m != nil {
+		return m.Colour
+	}
+	return Colour_RANDOM
+}
+
+func (m *ChallengeMessage) GetMainTime() int64 {
+	if m != nil {
+		return m.MainTime
+	}
+	return 0
+}
+
+func (m *ChallengeMessage) GetIncrement() int64 {
+	if m != nil {
+		return m.Increment
+	}
+	return 0
+}
+
+func (m *ChallengeMessage) GetExpires() int64 {
+	if m != nil {
+		return m.Expires
+	}
+	return 0
+}
+
+func (m *ChallengeMessage) GetHandicap() int64 {
+	if m != nil {
+		return m.Handicap
+	}
+	return 0
+}
+
+func (m *ChallengeMessage) GetFreePlacement() bool {
+	if m != nil {
+		return m.FreePlacement
+	}
+	return false
+}
+
+// ChallengesMessage is a reply of ListChallenges.
+type ChallengesMessage struct {
+	Challenges           []*ChallengeMessage `protobuf:"bytes,1,rep,name=challenges,proto3" json:"challenges,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
+}
+
+func (m *ChallengesMessage) Reset()         { *m = ChallengesMessage{} }
+func (m *ChallengesMessage) String() string { return proto.CompactTextString(m) }
+func (*ChallengesMessage) ProtoMessage()    {}
+func (*ChallengesMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{16}
+}
+
+func (m *ChallengesMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ChallengesMessage.Unmarshal(m, b)
+}
+func (m *ChallengesMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ChallengesMessage.Marshal(b, m, deterministic)
+}
+func (m *ChallengesMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ChallengesMessage.Merge(m, src)
+}
+func (m *ChallengesMessage) XXX_Size() int {
+	return xxx_messageInfo_ChallengesMessage.Size(m)
+}
+func (m *ChallengesMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_ChallengesMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ChallengesMessage proto.InternalMessageInfo
+
+func (m *ChallengesMessage) GetChallenges() []*ChallengeMessage {
+	if m != nil {
+		return m.Challenges
+	}
+	return nil
+}
+
+// CodeMessage identifies a challenge.
+type CodeMessage struct {
+	Code                 string   `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CodeMessage) Reset()         { *m = CodeMessage{} }
+func (m *CodeMessage) String() string { return proto.CompactTextString(m) }
+func (*CodeMessage) ProtoMessage()    {}
+func (*CodeMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{17}
+}
+
+func (m *CodeMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CodeMessage.Unmarshal(m, b)
+}
+func (m *CodeMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CodeMessage.Marshal(b, m, deterministic)
+}
+func (m *CodeMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CodeMessage.Merge(m, src)
+}
+func (m *CodeMessage) XXX_Size() int {
+	return xxx_messageInfo_CodeMessage.Size(m)
+}
+func (m *CodeMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_CodeMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CodeMessage proto.InternalMessageInfo
+
+func (m *CodeMessage) GetCode() string {
+	if m != nil {
+		return m.Code
+	}
+	return ""
+}
+
+// SeekRequest is a kind of game to seek by matchmaking.
+// Times are in seconds, zero MainTime means no time control.
+// Colour is a preferred colour, conflicting preferences of partners are resolved by nigiri.
+// Handicap and FreePlacement are the same as in ChallengeRequest,
+// seekers are paired only with seekers of the same handicap.
+type SeekRequest struct {
+	Size                 int64    `protobuf:"varint,1,opt,name=size,proto3" json:"size,omitempty"`
+	MainTime             int64    `protobuf:"varint,2,opt,name=main_time,json=mainTime,proto3" json:"main_time,omitempty"`
+	Increment            int64    `protobuf:"varint,3,opt,name=increment,proto3" json:"increment,omitempty"`
+	Colour               Colour   `protobuf:"varint,4,opt,name=colour,proto3,enum=grpc_server.Colour" json:"colour,omitempty"`
+	Handicap             int64    `protobuf:"varint,5,opt,name=handicap,proto3" json:"handicap,omitempty"`
+	FreePlacement        bool     `protobuf:"varint,6,opt,name=free_placement,json=freePlacement,proto3" json:"free_placement,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SeekRequest) Reset()         { *m = SeekRequest{} }
+func (m *SeekRequest) String() string { return proto.CompactTextString(m) }
+func (*SeekRequest) ProtoMessage()    {}
+func (*SeekRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{18}
+}
+
+func (m *SeekRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SeekRequest.Unmarshal(m, b)
+}
+func (m *SeekRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SeekRequest.Marshal(b, m, deterministic)
+}
+func (m *SeekRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SeekRequest.Merge(m, src)
+}
+func (m *SeekRequest) XXX_Size() int {
+	return xxx_messageInfo_SeekRequest.Size(m)
+}
+func (m *SeekRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SeekRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SeekRequest proto.InternalMessageInfo
+
+func (m *SeekRequest) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *SeekRequest) GetMainTime() int64 {
+	if m != nil {
+		return m.MainTime
+	}
+	return 0
+}
+
+func (m *SeekRequest) GetIncrement() int64 {
+	if m != nil {
+		return m.Increment
+	}
+	return 0
+}
+
+func (m *SeekRequest) GetColour() Colour {
+	if m != nil {
+		return m.Colour
+	}
+	return Colour_RANDOM
+}
+
+func (m *SeekRequest) GetHandicap() int64 {
+	if m != nil {
+		return m.Handicap
+	}
+	return 0
+}
+
+func (m *SeekRequest) GetFreePlacement() bool {
+	if m != nil {
+		return m.FreePlacement
+	}
+	return false
+}
+
+// SeekMessage reports place of gamer in matchmaking queue
+// or, when Matched is set, the partner, the colour of gamer and the state of begun game.
+// EstimatedWait is in milliseconds, 0 if it is unknown.
+type SeekMessage struct {
+	Position             int64      `protobuf:"varint,1,opt,name=position,proto3" json:"position,omitempty"`
+	EstimatedWait        int64      `protobuf:"varint,2,opt,name=estimated_wait,json=estimatedWait,proto3" json:"estimated_wait,omitempty"`
+	Rating               float64    `protobuf:"fixed64,3,opt,name=rating,proto3" json:"rating,omitempty"`
+	Window               float64    `protobuf:"fixed64,4,opt,name=window,proto3" json:"window,omitempty"`
+	Matched              bool       `protobuf:"varint,5,opt,name=matched,proto3" json:"matched,omitempty"`
+	Partner              string     `protobuf:"bytes,6,opt,name=partner,proto3" json:"partner,omitempty"`
+	State                *api.State `protobuf:"bytes,7,opt,name=state,proto3" json:"state,omitempty"`
+	Colour               Colour     `protobuf:"varint,8,opt,name=colour,proto3,enum=grpc_server.Colour" json:"colour,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *SeekMessage) Reset()         { *m = SeekMessage{} }
+func (m *SeekMessage) String() string { return proto.CompactTextString(m) }
+func (*SeekMessage) ProtoMessage()    {}
+func (*SeekMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{19}
+}
+
+func (m *SeekMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SeekMessage.Unmarshal(m, b)
+}
+func (m *SeekMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SeekMessage.Marshal(b, m, deterministic)
+}
+func (m *SeekMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SeekMessage.Merge(m, src)
+}
+func (m *SeekMessage) XXX_Size() int {
+	return xxx_messageInfo_SeekMessage.Size(m)
+}
+func (m *SeekMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_SeekMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SeekMessage proto.InternalMessageInfo
+
+func (m *SeekMessage) GetPosition() int64 {
+	if m != nil {
+		return m.Position
+	}
+	return 0
+}
+
+func (m *SeekMessage) GetEstimatedWait() int64 {
+	if m != nil {
+		return m.EstimatedWait
+	}
+	return 0
+}
+
+func (m *SeekMessage) GetRating() float64 {
+	if m != nil {
+		return m.Rating
+	}
+	return 0
+}
+
+func (m *SeekMessage) GetWindow() float64 {
+	if m != nil {
+		return m.Window
+	}
+	return 0
+}
+
+func (m *SeekMessage) GetMatched() bool {
+	if m != nil {
+		return m.Matched
+	}
+	return false
+}
+
+func (m *SeekMessage) GetPartner() string {
+	if m != nil {
+		return m.Partner
+	}
+	return ""
+}
+
+func (m *SeekMessage) GetState() *api.State {
+	if m != nil {
+		return m.State
+	}
+	return nil
+}
+
+func (m *SeekMessage) GetColour() Colour {
+	if m != nil {
+		return m.Colour
+	}
+	return Colour_RANDOM
+}
+
+// RatingRequest identifies a gamer by login.
+type RatingRequest struct {
+	Login                string   `protobuf:"bytes,1,opt,name=login,proto3" json:"login,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RatingRequest) Reset()         { *m = RatingRequest{} }
+func (m *RatingRequest) String() string { return proto.CompactTextString(m) }
+func (*RatingRequest) ProtoMessage()    {}
+func (*RatingRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{20}
+}
+
+func (m *RatingRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RatingRequest.Unmarshal(m, b)
+}
+func (m *RatingRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RatingRequest.Marshal(b, m, deterministic)
+}
+func (m *RatingRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RatingRequest.Merge(m, src)
+}
+func (m *RatingRequest) XXX_Size() int {
+	return xxx_messageInfo_RatingRequest.Size(m)
+}
+func (m *RatingRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RatingRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RatingRequest proto.InternalMessageInfo
+
+func (m *RatingRequest) GetLogin() string {
+	if m != nil {
+		return m.Login
+	}
+	return ""
+}
+
+// RatingMessage is a Glicko-2 rating of a gamer.
+type RatingMessage struct {
+	Login      string  `protobuf:"bytes,1,opt,name=login,proto3" json:"login,omitempty"`
+	Rating     float64 `protobuf:"fixed64,2,opt,name=rating,proto3" json:"rating,omitempty"`
+	Deviation  float64 `protobuf:"fixed64,3,opt,name=deviation,proto3" json:"deviation,omitempty"`
+	Volatility float64 `protobuf:"fixed64,4,opt,name=volatility,proto3" json:"volatility,omitempty"`
+	Games      int64   `protobuf:"varint,5,opt,name=games,proto3" json:"games,omitempty"`
+	// Rank is a place of gamer in leaderboard, it is set only by Leaderboard
+	Rank                 int64    `protobuf:"varint,6,opt,name=rank,proto3" json:"rank,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RatingMessage) Reset()         { *m = RatingMessage{} }
+func (m *RatingMessage) String() string { return proto.CompactTextString(m) }
+func (*RatingMessage) ProtoMessage()    {}
+func (*RatingMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{21}
+}
+
+func (m *RatingMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RatingMessage.Unmarshal(m, b)
+}
+func (m *RatingMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RatingMessage.Marshal(b, m, deterministic)
+}
+func (m *RatingMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RatingMessage.Merge(m, src)
+}
+func (m *RatingMessage) XXX_Size() int {
+	return xxx_messageInfo_RatingMessage.Size(m)
+}
+func (m *RatingMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_RatingMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RatingMessage proto.InternalMessageInfo
+
+func (m *RatingMessage) GetLogin() string {
+	if m != nil {
+		return m.Login
+	}
+	return ""
+}
+
+func (m *RatingMessage) GetRating() float64 {
+	if m != nil {
+		return m.Rating
+	}
+	return 0
+}
+
+func (m *RatingMessage) GetDeviation() float64 {
+	if m != nil {
+		return m.Deviation
+	}
+	return 0
+}
+
+func (m *RatingMessage) GetVolatility() float64 {
+	if m != nil {
+		return m.Volatility
+	}
+	return 0
+}
+
+func (m *RatingMessage) GetGames() int64 {
+	if m != nil {
+		return m.Games
+	}
+	return 0
+}
+
+func (m *RatingMessage) GetRank() int64 {
+	if m != nil {
+		return m.Rank
+	}
+	return 0
+}
+
+// LeaderboardRequest is a page of leaderboard.
+type LeaderboardRequest struct {
+	Offset               int64    `protobuf:"varint,1,opt,name=offset,proto3" json:"offset,omitempty"`
+	Limit                int64    `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LeaderboardRequest) Reset()         { *m = LeaderboardRequest{} }
+func (m *LeaderboardRequest) String() string { return proto.CompactTextString(m) }
+func (*LeaderboardRequest) ProtoMessage()    {}
+func (*LeaderboardRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{22}
+}
+
+func (m *LeaderboardRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LeaderboardRequest.Unmarshal(m, b)
+}
+func (m *LeaderboardRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LeaderboardRequest.Marshal(b, m, deterministic)
+}
+func (m *LeaderboardRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LeaderboardRequest.Merge(m, src)
+}
+func (m *LeaderboardRequest) XXX_Size() int {
+	return xxx_messageInfo_LeaderboardRequest.Size(m)
+}
+func (m *LeaderboardRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_LeaderboardRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LeaderboardRequest proto.InternalMessageInfo
+
+func (m *LeaderboardRequest) GetOffset() int64 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
+func (m *LeaderboardRequest) GetLimit() int64 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+// LeaderboardMessage is a page of players ordered by rating.
+type LeaderboardMessage struct {
+	Players []*RatingMessage `protobuf:"bytes,1,rep,name=players,proto3" json:"players,omitempty"`
+	// Total is a number of rated players
+	Total                int64    `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LeaderboardMessage) Reset()         { *m = LeaderboardMessage{} }
+func (m *LeaderboardMessage) String() string { return proto.CompactTextString(m) }
+func (*LeaderboardMessage) ProtoMessage()    {}
+func (*LeaderboardMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{23}
+}
+
+func (m *LeaderboardMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LeaderboardMessage.Unmarshal(m, b)
+}
+func (m *LeaderboardMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LeaderboardMessage.Marshal(b, m, deterministic)
+}
+func (m *LeaderboardMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LeaderboardMessage.Merge(m, src)
+}
+func (m *LeaderboardMessage) XXX_Size() int {
+	return xxx_messageInfo_LeaderboardMessage.Size(m)
+}
+func (m *LeaderboardMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_LeaderboardMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LeaderboardMessage proto.InternalMessageInfo
+
+func (m *LeaderboardMessage) GetPlayers() []*RatingMessage {
+	if m != nil {
+		return m.Players
+	}
+	return nil
+}
+
+func (m *LeaderboardMessage) GetTotal() int64 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
+}
+
+// SizeGames is a number of games on a board size.
+type SizeGames struct {
+	Size                 int64    `protobuf:"varint,1,opt,name=size,proto3" json:"size,omitempty"`
+	Games                int64    `protobuf:"varint,2,opt,name=games,proto3" json:"games,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SizeGames) Reset()         { *m = SizeGames{} }
+func (m *SizeGames) String() string { return proto.CompactTextString(m) }
+func (*SizeGames) ProtoMessage()    {}
+func (*SizeGames) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{24}
+}
+
+func (m *SizeGames) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SizeGames.Unmarshal(m, b)
+}
+func (m *SizeGames) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SizeGames.Marshal(b, m, deterministic)
+}
+func (m *SizeGames) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SizeGames.Merge(m, src)
+}
+func (m *SizeGames) XXX_Size() int {
+	return xxx_messageInfo_SizeGames.Size(m)
+}
+func (m *SizeGames) XXX_DiscardUnknown() {
+	xxx_messageInfo_SizeGames.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SizeGames proto.InternalMessageInfo
+
+func (m *SizeGames) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *SizeGames) GetGames() int64 {
+	if m != nil {
+		return m.Games
+	}
+	return 0
+}
+
+// StatsMessage is statistics of gamer's rated games.
+type StatsMessage struct {
+	Login  string       `protobuf:"bytes,1,opt,name=login,proto3" json:"login,omitempty"`
+	Wins   int64        `protobuf:"varint,2,opt,name=wins,proto3" json:"wins,omitempty"`
+	Losses int64        `protobuf:"varint,3,opt,name=losses,proto3" json:"losses,omitempty"`
+	Draws  int64        `protobuf:"varint,4,opt,name=draws,proto3" json:"draws,omitempty"`
+	Games  int64        `protobuf:"varint,5,opt,name=games,proto3" json:"games,omitempty"`
+	BySize []*SizeGames `protobuf:"bytes,6,rep,name=by_size,json=bySize,proto3" json:"by_size,omitempty"`
+	// AverageMoves is an average number of moves in a game
+	AverageMoves float64 `protobuf:"fixed64,7,opt,name=average_moves,json=averageMoves,proto3" json:"average_moves,omitempty"`
+	// AverageDuration is an average duration of a game in milliseconds
+	AverageDuration      int64    `protobuf:"varint,8,opt,name=average_duration,json=averageDuration,proto3" json:"average_duration,omitempty"`
+	LongestWinStreak     int64    `protobuf:"varint,9,opt,name=longest_win_streak,json=longestWinStreak,proto3" json:"longest_win_streak,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StatsMessage) Reset()         { *m = StatsMessage{} }
+func (m *StatsMessage) String() string { return proto.CompactTextString(m) }
+func (*StatsMessage) ProtoMessage()    {}
+func (*StatsMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{25}
+}
+
+func (m *StatsMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StatsMessage.Unmarshal(m, b)
+}
+func (m *StatsMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StatsMessage.Marshal(b, m, deterministic)
+}
+func (m *StatsMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StatsMessage.Merge(m, src)
+}
+func (m *StatsMessage) XXX_Size() int {
+	return xxx_messageInfo_StatsMessage.Size(m)
+}
+func (m *StatsMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_StatsMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StatsMessage proto.InternalMessageInfo
+
+func (m *StatsMessage) GetLogin() string {
+	if m != nil {
+		return m.Login
+	}
+	return ""
+}
+
+func (m *StatsMessage) GetWins() int64 {
+	if m != nil {
+		return m.Wins
+	}
+	return 0
+}
+
+func (m *StatsMessage) GetLosses() int64 {
+	if m != nil {
+		return m.Losses
+	}
+	return 0
+}
+
+func (m *StatsMessage) GetDraws() int64 {
+	if m != nil {
+		return m.Draws
+	}
+	return 0
+}
+
+func (m *StatsMessage) GetGames() int64 {
+	if m != nil {
+		return m.Games
+	}
+	return 0
+}
+
+func (m *StatsMessage) GetBySize() []*SizeGames {
+	if m != nil {
+		return m.BySize
+	}
+	return nil
+}
+
+func (m *StatsMessage) GetAverageMoves() float64 {
+	if m != nil {
+		return m.AverageMoves
+	}
+	return 0
+}
+
+func (m *StatsMessage) GetAverageDuration() int64 {
+	if m != nil {
+		return m.AverageDuration
+	}
+	return 0
+}
+
+func (m *StatsMessage) GetLongestWinStreak() int64 {
+	if m != nil {
+		return m.LongestWinStreak
+	}
+	return 0
+}
+
+// UndoAnswer is a request of AnswerUndo.
+// Accept is set to take back the last move of partner.
+type UndoAnswer struct {
+	Accept               bool     `protobuf:"varint,1,opt,name=accept,proto3" json:"accept,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UndoAnswer) Reset()         { *m = UndoAnswer{} }
+func (m *UndoAnswer) String() string { return proto.CompactTextString(m) }
+func (*UndoAnswer) ProtoMessage()    {}
+func (*UndoAnswer) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{26}
+}
+
+func (m *UndoAnswer) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UndoAnswer.Unmarshal(m, b)
+}
+func (m *UndoAnswer) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UndoAnswer.Marshal(b, m, deterministic)
+}
+func (m *UndoAnswer) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UndoAnswer.Merge(m, src)
+}
+func (m *UndoAnswer) XXX_Size() int {
+	return xxx_messageInfo_UndoAnswer.Size(m)
+}
+func (m *UndoAnswer) XXX_DiscardUnknown() {
+	xxx_messageInfo_UndoAnswer.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UndoAnswer proto.InternalMessageInfo
+
+func (m *UndoAnswer) GetAccept() bool {
+	if m != nil {
+		return m.Accept
+	}
+	return false
+}
+
+// MoveMessage is an entry of the move history of a game.
+// Number is the number of move in the game, Colour is the colour of gamer, who made it.
+// Undo is set for takeback of the move with Number by gamer of Colour,
+// X and Y are the position of taken back move then.
+// Time is in nanoseconds since Unix epoch.
+type MoveMessage struct {
+	Number               int64    `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	Colour               Colour   `protobuf:"varint,2,opt,name=colour,proto3,enum=grpc_server.Colour" json:"colour,omitempty"`
+	X                    int64    `protobuf:"varint,3,opt,name=x,proto3" json:"x,omitempty"`
+	Y                    int64    `protobuf:"varint,4,opt,name=y,proto3" json:"y,omitempty"`
+	Undo                 bool     `protobuf:"varint,5,opt,name=undo,proto3" json:"undo,omitempty"`
+	Time                 int64    `protobuf:"varint,6,opt,name=time,proto3" json:"time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MoveMessage) Reset()         { *m = MoveMessage{} }
+func (m *MoveMessage) String() string { return proto.CompactTextString(m) }
+func (*MoveMessage) ProtoMessage()    {}
+func (*MoveMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{27}
+}
+
+func (m *MoveMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MoveMessage.Unmarshal(m, b)
+}
+func (m *MoveMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MoveMessage.Marshal(b, m, deterministic)
+}
+func (m *MoveMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MoveMessage.Merge(m, src)
+}
+func (m *MoveMessage) XXX_Size() int {
+	return xxx_messageInfo_MoveMessage.Size(m)
+}
+func (m *MoveMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_MoveMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MoveMessage proto.InternalMessageInfo
+
+func (m *MoveMessage) GetNumber() int64 {
+	if m != nil {
+		return m.Number
+	}
+	return 0
+}
+
+func (m *MoveMessage) GetColour() Colour {
+	if m != nil {
+		return m.Colour
+	}
+	return Colour_RANDOM
+}
+
+func (m *MoveMessage) GetX() int64 {
+	if m != nil {
+		return m.X
+	}
+	return 0
+}
+
+func (m *MoveMessage) GetY() int64 {
+	if m != nil {
+		return m.Y
+	}
+	return 0
+}
+
+func (m *MoveMessage) GetUndo() bool {
+	if m != nil {
+		return m.Undo
+	}
+	return false
+}
+
+func (m *MoveMessage) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+// MovesMessage is a reply of ListMoves.
+type MovesMessage struct {
+	Moves                []*MoveMessage `protobuf:"bytes,1,rep,name=moves,proto3" json:"moves,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *MovesMessage) Reset()         { *m = MovesMessage{} }
+func (m *MovesMessage) String() string { return proto.CompactTextString(m) }
+func (*MovesMessage) ProtoMessage()    {}
+func (*MovesMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7c5e5ea25e832b23, []int{28}
+}
+
+func (m *MovesMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MovesMessage.Unmarshal(m, b)
+}
+func (m *MovesMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MovesMessage.Marshal(b, m, deterministic)
+}
+func (m *MovesMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MovesMessage.Merge(m, src)
+}
+func (m *MovesMessage) XXX_Size() int {
+	return xxx_messageInfo_MovesMessage.Size(m)
+}
+func (m *MovesMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_MovesMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MovesMessage proto.InternalMessageInfo
+
+func (m *MovesMessage) GetMoves() []*MoveMessage {
+	if m != nil {
+		return m.Moves
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("grpc_server.UndoEvent", UndoEvent_name, UndoEvent_value)
+	proto.RegisterEnum("grpc_server.GameStatus", GameStatus_name, GameStatus_value)
+	proto.RegisterEnum("grpc_server.ChatChannel", ChatChannel_name, ChatChannel_value)
+	proto.RegisterEnum("grpc_server.LobbyStatus", LobbyStatus_name, LobbyStatus_value)
+	proto.RegisterEnum("grpc_server.Colour", Colour_name, Colour_value)
+	proto.RegisterType((*ResumeMessage)(nil), "grpc_server.ResumeMessage")
+	proto.RegisterType((*PartnerEvent)(nil), "grpc_server.PartnerEvent")
+	proto.RegisterType((*GameStatusMessage)(nil), "grpc_server.GameStatusMessage")
+	proto.RegisterType((*JoinRequest)(nil), "grpc_server.JoinRequest")
+	proto.RegisterType((*GameInfo)(nil), "grpc_server.GameInfo")
+	proto.RegisterType((*GamesMessage)(nil), "grpc_server.GamesMessage")
+	proto.RegisterType((*GameIDMessage)(nil), "grpc_server.GameIDMessage")
+	proto.RegisterType((*PrivacyMessage)(nil), "grpc_server.PrivacyMessage")
+	proto.RegisterType((*ChatMessage)(nil), "grpc_server.ChatMessage")
+	proto.RegisterType((*ChannelMessage)(nil), "grpc_server.ChannelMessage")
+	proto.RegisterType((*HistoryRequest)(nil), "grpc_server.HistoryRequest")
+	proto.RegisterType((*HistoryMessage)(nil), "grpc_server.HistoryMessage")
+	proto.RegisterType((*LobbyGamer)(nil), "grpc_server.LobbyGamer")
+	proto.RegisterType((*LobbyMessage)(nil), "grpc_server.LobbyMessage")
+	proto.RegisterType((*ChallengeRequest)(nil), "grpc_server.ChallengeRequest")
+	proto.RegisterType((*ChallengeMessage)(nil), "grpc_server.ChallengeMessage")
+	proto.RegisterType((*ChallengesMessage)(nil), "grpc_server.ChallengesMessage")
+	proto.RegisterType((*CodeMessage)(nil), "grpc_server.CodeMessage")
+	proto.RegisterType((*SeekRequest)(nil), "grpc_server.SeekRequest")
+	proto.RegisterType((*SeekMessage)(nil), "grpc_server.SeekMessage")
+	proto.RegisterType((*RatingRequest)(nil), "grpc_server.RatingRequest")
+	proto.RegisterType((*RatingMessage)(nil), "grpc_server.RatingMessage")
+	proto.RegisterType((*LeaderboardRequest)(nil), "grpc_server.LeaderboardRequest")
+	proto.RegisterType((*LeaderboardMessage)(nil), "grpc_server.LeaderboardMessage")
+	proto.RegisterType((*SizeGames)(nil), "grpc_server.SizeGames")
+	proto.RegisterType((*StatsMessage)(nil), "grpc_server.StatsMessage")
+	proto.RegisterType((*UndoAnswer)(nil), "grpc_server.UndoAnswer")
+	proto.RegisterType((*MoveMessage)(nil), "grpc_server.MoveMessage")
+	proto.RegisterType((*MovesMessage)(nil), "grpc_server.MovesMessage")
+}
+
+func init() {
+	proto.RegisterFile("grpc_server.proto", fileDescriptor_7c5e5ea25e832b23)
+}
+
+var fileDescriptor_7c5e5ea25e832b23 = []byte{
+	// 2031 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x58, 0x4f, 0x73, 0xe3, 0x58,
+	0x11, 0xb7, 0x64, 0x5b, 0x96, 0xda, 0x8e, 0xc7, 0x79, 0x0c, 0x33, 0xc6, 0xb3, 0xec, 0x06, 0xc1,
+	0x56, 0x85, 0x0c, 0x95, 0x9d, 0xf2, 0x42, 0x6d, 0x51, 0x0b, 0xb3, 0x38, 0x8e, 0x37, 0xeb, 0x9d,
+	0xc4, 0xc9, 0xca, 0x1e, 0x52, 0xbb, 0x07, 0x84, 0x6c, 0xbd, 0x24, 0xaa, 0xd8, 0x92, 0x91, 0x9e,
+	0x93, 0xf1, 0x56, 0x71, 0x82, 0xe2, 0x23, 0x50, 0x5c, 0x38, 0x72, 0x82, 0x33, 0xdc, 0xb8, 0x71,
+	0xe3, 0xc6, 0x85, 0x6f, 0xc0, 0x87, 0xe0, 0x42, 0xbd, 0x7f, 0xf2, 0x93, 0xed, 0xc4, 0x33, 0xc3,
+	0xed, 0x75, 0xbf, 0x56, 0xbf, 0xee, 0x5f, 0xf7, 0xeb, 0xd7, 0x2d, 0xd8, 0xbe, 0x8c, 0xa7, 0x23,
+	0x37, 0xc1, 0xf1, 0x0d, 0x8e, 0xf7, 0xa7, 0x71, 0x44, 0x22, 0x54, 0x56, 0x58, 0x0d, 0xcb, 0x9b,
+	0x06, 0x9c, 0x6f, 0xff, 0x5d, 0x83, 0x2d, 0x07, 0x27, 0xb3, 0x09, 0x3e, 0xc1, 0x49, 0xe2, 0x5d,
+	0x62, 0xb4, 0x03, 0xc5, 0x84, 0x78, 0x04, 0xd7, 0xb5, 0x1d, 0x6d, 0xb7, 0xdc, 0x84, 0x7d, 0x2a,
+	0xdc, 0xa7, 0x1c, 0x87, 0x6f, 0xa0, 0xc7, 0x50, 0x9a, 0xcc, 0x5d, 0x32, 0x8b, 0xc3, 0xba, 0xbe,
+	0xa3, 0xed, 0x9a, 0x8e, 0x31, 0x99, 0x0f, 0x66, 0x71, 0x88, 0xea, 0x50, 0x9a, 0x7a, 0x31, 0x09,
+	0x71, 0x5c, 0xcf, 0xef, 0x68, 0xbb, 0x96, 0x23, 0x49, 0xf4, 0x14, 0xb6, 0xc5, 0xd2, 0x1d, 0x45,
+	0x61, 0x88, 0x47, 0x04, 0xfb, 0xf5, 0x02, 0xfb, 0xb8, 0x26, 0x36, 0xda, 0x92, 0x8f, 0x9e, 0x82,
+	0x31, 0x8a, 0xc6, 0xd1, 0x2c, 0xae, 0x17, 0x77, 0xb4, 0xdd, 0x6a, 0xf3, 0x1b, 0xfb, 0xaa, 0x3f,
+	0x6d, 0xb6, 0xe5, 0x08, 0x11, 0xfb, 0x77, 0x1a, 0x54, 0xce, 0xb8, 0x86, 0xce, 0x0d, 0x0e, 0x89,
+	0x6a, 0x84, 0x96, 0x35, 0xe2, 0x1d, 0xb0, 0x16, 0x87, 0x73, 0xcb, 0x17, 0x0c, 0x84, 0xa0, 0x30,
+	0xc6, 0x17, 0x84, 0x59, 0x6e, 0x3a, 0x6c, 0x8d, 0xf6, 0xa0, 0x30, 0x0b, 0xfd, 0x88, 0x59, 0x5a,
+	0x6d, 0x3e, 0xca, 0xd8, 0xf1, 0x32, 0xf4, 0x23, 0x76, 0xa2, 0xc3, 0x64, 0xec, 0xbf, 0xea, 0xb0,
+	0x7d, 0xe4, 0x4d, 0x30, 0x85, 0x6a, 0x96, 0x48, 0x34, 0x3f, 0x00, 0x23, 0x61, 0x0c, 0x66, 0x4c,
+	0xb5, 0xf9, 0x38, 0xa3, 0x63, 0x21, 0xef, 0x08, 0xb1, 0x05, 0xfc, 0xfa, 0x6b, 0xc0, 0x9f, 0xcf,
+	0xc0, 0xff, 0x04, 0x2c, 0x12, 0x4c, 0xb0, 0xcb, 0xdc, 0xa0, 0x26, 0xe7, 0x1d, 0x93, 0x32, 0x8e,
+	0xb9, 0x2b, 0x69, 0x04, 0x16, 0x42, 0x45, 0x26, 0xf4, 0x40, 0x6c, 0x0c, 0xa4, 0xec, 0x22, 0x00,
+	0xc6, 0xc6, 0x00, 0xa8, 0x78, 0x97, 0xb2, 0x78, 0xbf, 0x0f, 0x55, 0x8a, 0x8c, 0x1b, 0xe3, 0x5f,
+	0xcd, 0x70, 0x42, 0x41, 0x37, 0x99, 0xbd, 0x5b, 0x94, 0xeb, 0x48, 0xa6, 0xfd, 0x6b, 0x28, 0x7f,
+	0x1e, 0x05, 0xa1, 0x60, 0x28, 0x87, 0x6b, 0x9b, 0x0f, 0x6f, 0x80, 0x79, 0xe5, 0x85, 0x7e, 0x30,
+	0xf2, 0xa6, 0x0c, 0xb0, 0xbc, 0x93, 0xd2, 0xf4, 0xf8, 0x8b, 0x18, 0x63, 0x77, 0x3a, 0xf6, 0x46,
+	0x78, 0x82, 0x43, 0x19, 0xda, 0x2d, 0xca, 0x3d, 0x93, 0x4c, 0xfb, 0x0a, 0x4c, 0x1a, 0x86, 0x6e,
+	0x78, 0x11, 0xa1, 0x2a, 0xe8, 0x81, 0xcf, 0xce, 0xcd, 0x3b, 0x7a, 0xe0, 0x33, 0xdf, 0xc6, 0xde,
+	0x1c, 0xc7, 0x49, 0x5d, 0xdf, 0xc9, 0x33, 0xdf, 0x38, 0x49, 0xb3, 0x25, 0x09, 0xbe, 0xc6, 0x4c,
+	0x65, 0xde, 0x61, 0x6b, 0xf4, 0x2e, 0x40, 0x32, 0xc5, 0x23, 0xe2, 0x91, 0x28, 0x4e, 0x44, 0x00,
+	0x14, 0x8e, 0xfd, 0x31, 0x54, 0xe8, 0x49, 0x69, 0x6e, 0x3c, 0x85, 0xe2, 0x25, 0xa5, 0xeb, 0xda,
+	0x4e, 0x7e, 0xb7, 0xdc, 0xfc, 0xe6, 0x4a, 0x6a, 0x50, 0x9b, 0x1c, 0x2e, 0x63, 0xbf, 0x07, 0x5b,
+	0x8c, 0x75, 0x28, 0xbf, 0x5e, 0xb2, 0xd5, 0xde, 0x83, 0xea, 0x59, 0x1c, 0xdc, 0x78, 0xa3, 0xb9,
+	0x94, 0xa0, 0xd6, 0x53, 0x8e, 0xb8, 0xcb, 0xa6, 0x23, 0x49, 0xfb, 0x0f, 0x1a, 0x94, 0xdb, 0x57,
+	0x1e, 0x91, 0x92, 0x4d, 0x28, 0x8d, 0xae, 0xbc, 0x30, 0xc4, 0x63, 0x01, 0x7a, 0x3d, 0x0b, 0xfa,
+	0x95, 0x47, 0xda, 0x7c, 0xdf, 0x91, 0x82, 0x34, 0x0d, 0x67, 0x09, 0x8e, 0xdd, 0xc0, 0x17, 0xc8,
+	0x1b, 0x94, 0xec, 0xfa, 0xe8, 0x21, 0x14, 0xc7, 0xd1, 0x65, 0x10, 0x8a, 0x1a, 0xc0, 0x09, 0x0a,
+	0x18, 0xc1, 0xaf, 0x78, 0x5e, 0x5a, 0x0e, 0x5b, 0x33, 0x5e, 0x30, 0xc1, 0x22, 0x0d, 0xd9, 0xda,
+	0x3e, 0x84, 0xaa, 0x38, 0xea, 0xff, 0x30, 0xce, 0xfe, 0x0a, 0xaa, 0x9f, 0x05, 0x09, 0x89, 0xe2,
+	0xb9, 0x4c, 0xab, 0xb7, 0x71, 0x91, 0x7a, 0x12, 0x4c, 0x02, 0x22, 0x1c, 0xe4, 0x84, 0xfd, 0x69,
+	0xaa, 0x5b, 0x5a, 0xf8, 0x43, 0x30, 0x27, 0x7c, 0x29, 0x63, 0xb9, 0xaa, 0x5c, 0xc8, 0x3a, 0xa9,
+	0xa4, 0xed, 0x03, 0x1c, 0x47, 0xc3, 0xe1, 0x9c, 0x86, 0x35, 0x5e, 0x49, 0xbd, 0x14, 0x45, 0x5d,
+	0x45, 0xf1, 0x59, 0x5a, 0x4e, 0xf2, 0x6b, 0x9c, 0x60, 0xea, 0xb2, 0xf5, 0xc4, 0xfe, 0x04, 0x2a,
+	0x8c, 0xad, 0x14, 0x24, 0x9a, 0x50, 0xb1, 0xb4, 0xf4, 0xf1, 0xaa, 0x06, 0x66, 0x90, 0x23, 0xc4,
+	0xec, 0xff, 0x6a, 0x50, 0x6b, 0x5f, 0x79, 0xe3, 0x31, 0x0e, 0x2f, 0xb1, 0x44, 0x33, 0xb5, 0x4e,
+	0x5b, 0x8a, 0x31, 0xbb, 0x14, 0xba, 0x72, 0x29, 0x10, 0x14, 0xae, 0xa3, 0x49, 0xc0, 0xec, 0xd5,
+	0x1c, 0xb6, 0x56, 0xae, 0x78, 0x61, 0xf3, 0x15, 0x7f, 0x02, 0xd6, 0xc4, 0x0b, 0x42, 0x57, 0xc9,
+	0x14, 0x93, 0x32, 0x68, 0xb5, 0xa2, 0x25, 0x3d, 0x08, 0x47, 0x31, 0xbf, 0xde, 0x06, 0xdb, 0x5c,
+	0x30, 0x32, 0xd5, 0xa1, 0xb4, 0xb1, 0x3a, 0x98, 0xeb, 0xaa, 0xc3, 0xdf, 0x74, 0xc5, 0x7b, 0x89,
+	0x21, 0x82, 0xc2, 0x28, 0xf2, 0xb1, 0x70, 0x9e, 0xad, 0x29, 0xef, 0x22, 0x8e, 0x26, 0x22, 0x5c,
+	0x6c, 0x4d, 0x63, 0x4a, 0x22, 0x71, 0x0d, 0x74, 0x12, 0xa5, 0xf8, 0x14, 0xd6, 0xe0, 0x53, 0x5c,
+	0x8b, 0x8f, 0xf1, 0x86, 0xf8, 0x94, 0xee, 0xc3, 0xc7, 0x5c, 0xc6, 0xa7, 0x0e, 0x25, 0xfc, 0x6a,
+	0x1a, 0xc4, 0x38, 0xa9, 0x5b, 0x6c, 0x4f, 0x92, 0x19, 0xe4, 0x60, 0x23, 0x72, 0xe5, 0x75, 0xc8,
+	0x39, 0xb0, 0x9d, 0x02, 0x97, 0x96, 0xbc, 0x9f, 0x02, 0x8c, 0x52, 0xa6, 0xc8, 0xc0, 0x6f, 0x2f,
+	0xdf, 0x95, 0x0c, 0xd8, 0x8e, 0xf2, 0x81, 0xfd, 0x1d, 0x28, 0xb7, 0x23, 0xff, 0xbe, 0x38, 0xd8,
+	0xff, 0xd4, 0xa0, 0xdc, 0xc7, 0xf8, 0x5a, 0x66, 0xaa, 0xc4, 0x5c, 0x53, 0x30, 0xcf, 0x40, 0xa6,
+	0xdf, 0x07, 0x59, 0x7e, 0x19, 0xb2, 0x37, 0x4a, 0x5d, 0x15, 0xc5, 0xe2, 0x46, 0x14, 0x8d, 0x75,
+	0x28, 0xfe, 0x46, 0xe7, 0xee, 0x48, 0x97, 0x1b, 0x60, 0x4e, 0xa3, 0x24, 0x20, 0x41, 0x14, 0x0a,
+	0x97, 0x52, 0x9a, 0xaa, 0xc4, 0x09, 0x09, 0x26, 0x1e, 0xc1, 0xbe, 0x7b, 0xeb, 0xa5, 0x75, 0x6b,
+	0x2b, 0xe5, 0x9e, 0x7b, 0x01, 0x41, 0x8f, 0xc0, 0x88, 0x3d, 0x12, 0x84, 0x97, 0xe2, 0x4e, 0x0a,
+	0x8a, 0xf2, 0x6f, 0x83, 0xd0, 0x8f, 0x6e, 0x99, 0x6b, 0x9a, 0x23, 0x28, 0x9a, 0x25, 0x13, 0x8f,
+	0x8c, 0xae, 0xb0, 0xcf, 0x9c, 0x30, 0x1d, 0x49, 0xaa, 0x4f, 0xbf, 0x91, 0x7d, 0xfa, 0xd3, 0x2e,
+	0xa6, 0x74, 0x57, 0x17, 0xb3, 0x00, 0xd2, 0xdc, 0xdc, 0xe4, 0xbd, 0x0f, 0x5b, 0x0e, 0x33, 0xf2,
+	0xde, 0xfa, 0x63, 0xff, 0x49, 0x93, 0x72, 0x12, 0xae, 0xb5, 0x72, 0x0a, 0x02, 0x7a, 0x06, 0x81,
+	0x77, 0xc0, 0xf2, 0xf1, 0x4d, 0xe0, 0x31, 0x74, 0x39, 0x38, 0x0b, 0x06, 0x7d, 0xde, 0x6f, 0xa2,
+	0xb1, 0x47, 0x82, 0x71, 0x40, 0xe6, 0x02, 0x23, 0x85, 0x43, 0xcf, 0xe2, 0xcf, 0x39, 0x0f, 0x35,
+	0x27, 0x68, 0xfe, 0xc5, 0x5e, 0x78, 0x2d, 0x8a, 0x13, 0x5b, 0xdb, 0x07, 0x80, 0x8e, 0xb1, 0xe7,
+	0xe3, 0x78, 0x18, 0x79, 0xb1, 0x2f, 0x7d, 0x7a, 0x04, 0x46, 0x74, 0x71, 0x91, 0x60, 0x22, 0x02,
+	0x2b, 0xa8, 0x3b, 0x5e, 0xa1, 0x5f, 0x66, 0x74, 0x2c, 0x5e, 0xa2, 0xb4, 0x61, 0xe1, 0x97, 0xab,
+	0x91, 0x81, 0x35, 0x03, 0xce, 0xa2, 0x99, 0x79, 0x08, 0x45, 0x12, 0x11, 0x6f, 0x2c, 0x4f, 0x60,
+	0x84, 0xfd, 0x23, 0xb0, 0xfa, 0xc1, 0xd7, 0xf8, 0x48, 0xba, 0xb1, 0x72, 0x8d, 0x52, 0x87, 0x75,
+	0xc5, 0x61, 0xfb, 0xcf, 0x3a, 0x54, 0x68, 0xa4, 0x93, 0xfb, 0x63, 0x80, 0xa0, 0x70, 0x1b, 0x84,
+	0xf2, 0x5b, 0xb6, 0xa6, 0x08, 0x8c, 0xa3, 0x24, 0xc1, 0x89, 0xb8, 0x77, 0x82, 0xa2, 0x1a, 0xfc,
+	0xd8, 0xbb, 0x95, 0x3d, 0x15, 0x27, 0xee, 0xc0, 0xfb, 0x03, 0x28, 0x0d, 0xe7, 0x2e, 0xb3, 0xd5,
+	0x60, 0x08, 0x64, 0xbb, 0xf6, 0xd4, 0x23, 0xc7, 0x18, 0xce, 0x29, 0x81, 0xbe, 0x0b, 0x5b, 0xde,
+	0x0d, 0x8e, 0xbd, 0x4b, 0xec, 0x4e, 0xa2, 0x1b, 0x9c, 0xb0, 0x94, 0xd5, 0x9c, 0x8a, 0x60, 0x9e,
+	0x50, 0x1e, 0xfa, 0x3e, 0xd4, 0xa4, 0x90, 0x3f, 0x8b, 0x79, 0x82, 0xf0, 0x72, 0xfa, 0x40, 0xf0,
+	0x0f, 0x05, 0x1b, 0xfd, 0x00, 0xd0, 0x38, 0xa2, 0xd5, 0x8a, 0xb8, 0xb7, 0x41, 0xe8, 0x26, 0x24,
+	0xc6, 0xde, 0xb5, 0xa8, 0xaf, 0x35, 0xb1, 0x73, 0x1e, 0x84, 0x7d, 0xc6, 0xb7, 0xbf, 0x07, 0x40,
+	0x07, 0x89, 0x56, 0x98, 0xdc, 0xe2, 0x98, 0x02, 0xe0, 0x8d, 0x46, 0x78, 0x4a, 0x44, 0xc3, 0x26,
+	0x28, 0xfb, 0xf7, 0x1a, 0x94, 0xa9, 0x21, 0x12, 0xd2, 0x47, 0x60, 0x84, 0xb3, 0xc9, 0x50, 0x8c,
+	0x38, 0x79, 0x47, 0x50, 0xca, 0xa5, 0xd2, 0x37, 0x57, 0xa7, 0x0a, 0x68, 0xaf, 0x04, 0xd0, 0xda,
+	0x2b, 0x4a, 0xcd, 0x05, 0xbe, 0xda, 0x9c, 0x46, 0x87, 0x0d, 0x3e, 0xfc, 0xc2, 0xb3, 0x75, 0xda,
+	0xad, 0x19, 0x4a, 0xb7, 0xf6, 0x1c, 0x2a, 0x0c, 0x20, 0x69, 0xd8, 0x3e, 0x14, 0x39, 0x88, 0xeb,
+	0xda, 0x20, 0xc5, 0x03, 0x87, 0x8b, 0xed, 0x39, 0x60, 0xa5, 0x73, 0x14, 0x2a, 0x43, 0xa9, 0x77,
+	0xea, 0xbe, 0xec, 0x1d, 0x9e, 0xd6, 0x72, 0x08, 0x41, 0x95, 0xae, 0x5c, 0xa7, 0xf3, 0xc5, 0xcb,
+	0x4e, 0x7f, 0xd0, 0x39, 0xac, 0x69, 0x68, 0x1b, 0xb6, 0x18, 0xaf, 0xd5, 0x6e, 0x77, 0xce, 0x28,
+	0x4b, 0x4f, 0x59, 0x4e, 0xe7, 0xf3, 0x4e, 0x9b, 0xb2, 0xf2, 0x7b, 0xbf, 0x00, 0x58, 0xcc, 0x55,
+	0xa8, 0x06, 0x95, 0xde, 0xe9, 0xc0, 0xed, 0xf6, 0xdc, 0xe3, 0xd3, 0x83, 0x83, 0x2f, 0x6b, 0x39,
+	0x54, 0x01, 0x33, 0xa5, 0x34, 0xf4, 0x10, 0x6a, 0xad, 0xf3, 0x56, 0x77, 0xd0, 0xed, 0x1d, 0xb9,
+	0x67, 0x2d, 0x67, 0xd0, 0xeb, 0x38, 0x35, 0x9d, 0x9a, 0x72, 0x76, 0xdc, 0xfa, 0xb2, 0xdb, 0x3b,
+	0xaa, 0xe5, 0xd1, 0x16, 0x58, 0x47, 0xad, 0x93, 0x8e, 0x7b, 0xfa, 0xf3, 0x8e, 0x53, 0x2b, 0xec,
+	0xd9, 0x50, 0x56, 0xba, 0x45, 0x64, 0x41, 0x51, 0x6a, 0x36, 0xa1, 0x40, 0x05, 0x6b, 0xda, 0x5e,
+	0x0b, 0xca, 0x4a, 0x33, 0x46, 0x37, 0xba, 0x87, 0xc7, 0x9d, 0x5a, 0x8e, 0x2a, 0xee, 0x77, 0x3a,
+	0x2f, 0xa8, 0x62, 0x8d, 0x12, 0xdd, 0x9e, 0xcb, 0x3e, 0xd1, 0x51, 0x15, 0xa0, 0x7f, 0xd6, 0x69,
+	0x0f, 0x5a, 0x03, 0x76, 0xea, 0xde, 0x47, 0x60, 0xf0, 0x80, 0x21, 0x00, 0xc3, 0x69, 0xf5, 0x0e,
+	0x4f, 0x4f, 0x6a, 0x39, 0x7a, 0xda, 0xc1, 0x71, 0xab, 0xfd, 0xa2, 0xa6, 0xd1, 0xe5, 0xf9, 0x67,
+	0xdd, 0x41, 0x87, 0x9b, 0xfb, 0xb2, 0xf7, 0xa2, 0x77, 0x7a, 0xde, 0xab, 0xe5, 0x9b, 0xff, 0xd6,
+	0xa1, 0xd4, 0xc7, 0x49, 0x42, 0x93, 0xb1, 0x0d, 0x95, 0x23, 0x4c, 0x24, 0x1c, 0x18, 0x6d, 0xb3,
+	0x42, 0xdc, 0x99, 0x4c, 0x89, 0x6c, 0x08, 0x1b, 0xef, 0xde, 0x31, 0x91, 0x8a, 0x7d, 0x3b, 0x47,
+	0x1f, 0x6d, 0xfe, 0x8b, 0x80, 0x6e, 0xae, 0x53, 0xb1, 0x54, 0x64, 0xd4, 0xdf, 0x09, 0x76, 0x0e,
+	0x7d, 0x0a, 0x26, 0x9d, 0xef, 0xd8, 0xc7, 0xd9, 0x84, 0x50, 0xc6, 0xbe, 0xd7, 0x30, 0xe3, 0x13,
+	0x30, 0xe9, 0xfb, 0xc5, 0x46, 0xdd, 0xb7, 0xf2, 0xe3, 0x67, 0x50, 0x39, 0xa7, 0x2f, 0x97, 0xf8,
+	0x5d, 0xb0, 0x4e, 0xc9, 0xb7, 0x32, 0x4a, 0xd4, 0xff, 0x0a, 0x76, 0xee, 0x99, 0xd6, 0xfc, 0x87,
+	0x06, 0x56, 0x5f, 0x0e, 0x74, 0xe8, 0x63, 0xb0, 0x8e, 0x83, 0x84, 0xf0, 0x02, 0xb9, 0x51, 0x99,
+	0x3a, 0xfa, 0xd9, 0x39, 0xf4, 0x11, 0x58, 0xcc, 0x18, 0x06, 0x4b, 0x63, 0x75, 0xf4, 0x93, 0x73,
+	0x5e, 0x43, 0x79, 0x3b, 0xa9, 0x0d, 0xe8, 0x00, 0xaa, 0x7d, 0x1e, 0xd2, 0x33, 0x3e, 0xcd, 0xa1,
+	0x27, 0x59, 0xa3, 0x33, 0x43, 0x60, 0x63, 0xd5, 0x2e, 0x3b, 0xd7, 0xfc, 0x97, 0x06, 0x05, 0x9a,
+	0xc3, 0xe8, 0x27, 0xb4, 0xbb, 0x08, 0xd3, 0xe7, 0xe3, 0xce, 0xb1, 0x65, 0xad, 0x1a, 0xd4, 0x81,
+	0x92, 0x98, 0x84, 0x96, 0x6c, 0xc8, 0xce, 0x5e, 0x8d, 0xb5, 0x9b, 0x6a, 0x82, 0x58, 0xfd, 0xd9,
+	0x30, 0x19, 0xc5, 0xc1, 0x70, 0xd9, 0x99, 0xec, 0x28, 0xd8, 0xb8, 0xd3, 0x3e, 0x16, 0x9d, 0xdf,
+	0x6a, 0x50, 0x64, 0xb7, 0x4e, 0x46, 0x86, 0x13, 0x1b, 0x23, 0xa3, 0xce, 0x47, 0x76, 0x0e, 0x3d,
+	0x07, 0x60, 0x91, 0x79, 0xab, 0xaf, 0x9f, 0x69, 0xcd, 0x3f, 0xea, 0x60, 0xa5, 0x5d, 0x2c, 0x7a,
+	0xa1, 0x12, 0x77, 0xb4, 0xba, 0x12, 0xa7, 0xfb, 0x3b, 0x61, 0x06, 0x78, 0x95, 0xfa, 0x95, 0xee,
+	0x24, 0x9b, 0x2f, 0xc2, 0x4a, 0x0f, 0x6e, 0xe7, 0xd0, 0x8f, 0xe1, 0x41, 0x8b, 0x3d, 0x2c, 0x0b,
+	0xcb, 0x96, 0x90, 0x5d, 0x34, 0xd9, 0xd9, 0xfc, 0x43, 0x2d, 0xa8, 0x1d, 0xe2, 0xd1, 0x38, 0x08,
+	0xf1, 0xeb, 0x7c, 0xbb, 0x36, 0xf9, 0x4e, 0xa0, 0x7c, 0x42, 0xf1, 0x9d, 0x78, 0xd7, 0xb4, 0xe9,
+	0x7a, 0x0e, 0x05, 0xda, 0xe0, 0x2e, 0x69, 0x51, 0x5a, 0xf8, 0xc6, 0xea, 0x8e, 0x0a, 0xf7, 0x7f,
+	0x34, 0x30, 0x78, 0x5f, 0x83, 0x3a, 0x60, 0x1d, 0x61, 0x22, 0x88, 0x75, 0x9d, 0x8f, 0xd4, 0x78,
+	0x4f, 0x57, 0x64, 0xe7, 0xd0, 0x17, 0x50, 0x56, 0x5a, 0x2b, 0xf4, 0x5e, 0x36, 0xdc, 0x2b, 0x8d,
+	0x5b, 0xe3, 0x4e, 0x81, 0x85, 0xca, 0x36, 0x98, 0x47, 0x98, 0xb0, 0xb6, 0xe8, 0x5e, 0xc3, 0xb2,
+	0xa9, 0xa5, 0xb6, 0x51, 0x76, 0xae, 0xf9, 0x17, 0x0d, 0xcc, 0x81, 0x77, 0x8d, 0x87, 0xde, 0xe8,
+	0x1a, 0xed, 0x43, 0x59, 0x7c, 0x44, 0x1f, 0xd0, 0x75, 0x79, 0x90, 0x0d, 0xdc, 0x87, 0x00, 0xbc,
+	0xc9, 0x60, 0xe2, 0x8f, 0x57, 0x7e, 0x65, 0xf2, 0xcd, 0xa5, 0x8f, 0xc4, 0x3d, 0xe2, 0x3d, 0xd0,
+	0xc6, 0x9b, 0xa0, 0x76, 0x02, 0x76, 0xee, 0xa0, 0xfc, 0x95, 0xc5, 0x37, 0xbc, 0x69, 0x30, 0x34,
+	0xd8, 0xef, 0xe6, 0x0f, 0xff, 0x37, 0x00, 0x20, 0xf3, 0xe8, 0x0a, 0x9b, 0x16, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// SessionClient is the client API for Session service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type SessionClient interface {
+	// GetGameState returns current status and game state of authenticated gamer without waiting.
+	GetGameState(ctx context.Context, in *api.EmptyMessage, opts ...grpc.CallOption) (*GameStatusMessage, error)
+	// ResumeGame awaits the game begin, if needed,
+	// and returns current state of the game of authenticated gamer.
+	ResumeGame(ctx context.Context, in *api.EmptyMessage, opts ...grpc.CallOption) (*ResumeMessage, error)
+	// JoinGame does the same as JoinTheGame, but gamer chooses his colour
+	// and handicap, and the reply reports his colour and partner.
+	JoinGame(ctx context.Context, in *JoinRequest, opts ...grpc.CallOption) (*GameStatusMessage, error)
+	// WaitTurn does the same as WaitTheTurn, but the reply reports
+	// the colour and the partner of gamer.
+	WaitTurn(ctx context.Context, in *api.EmptyMessage, opts ...grpc.CallOption) (*GameStatusMessage, error)
+	// WatchPartner streams disconnections and reconnections of gamer's partner
+	// and his requests and answers of takeback.
+	WatchPartner(ctx context.Context, in *api.EmptyMessage, opts ...grpc.CallOption) (Session_WatchPartnerClient, error)
+}
+
+type sessionClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSessionClient(cc grpc.ClientConnInterface) SessionClient {
+	return &sessionClient{cc}
+}
+
+func (c *sessionClient) GetGameState(ctx context.Context, in *api.EmptyMessage, opts ...grpc.CallOption) (*GameStatusMessage, error) {
+	out := new(GameStatusMessage)
+	err := c.cc.Invoke(ctx, "/grpc_server.Session/GetGameState", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionClient) ResumeGame(ctx context.Context, in *api.EmptyMessage, opts ...grpc.CallOption) (*ResumeMessage, error) {
+	out := new(ResumeMessage)
+	err := c.cc.Invoke(ctx, "/grpc_server.Session/ResumeGame", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionClient) JoinGame(ctx context.Context, in *JoinRequest, opts ...grpc.CallOption) (*GameStatusMessage, error) {
+	out := new(GameStatusMessage)
+	err := c.cc.Invoke(ctx, "/grpc_server.Session/JoinGame", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionClient) WaitTurn(ctx context.Context, in *api.EmptyMessage, opts ...grpc.CallOption) (*GameStatusMessage, error) {
+	out := new(GameStatusMessage)
+	err := c.cc.Invoke(ctx, "/grpc_server.Session/WaitTurn", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionClient) WatchPartner(ctx context.Context, in *api.EmptyMessage, opts ...grpc.CallOption) (Session_WatchPartnerClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Session_serviceDesc.Streams[0], "/grpc_server.Session/WatchPartner", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sessionWatchPartnerClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Session_WatchPartnerClient interface {
+	Recv() (*PartnerEvent, error)
+	grpc.ClientStream
+}
+
+type sessionWatchPartnerClient struct {
+	grpc.ClientStream
+}
+
+func (x *sessionWatchPartnerClient) Recv() (*PartnerEvent, error) {
+	m := new(PartnerEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SessionServer is the server API for Session service.
+type SessionServer interface {
+	// GetGameState returns current status and game state of authenticated gamer without waiting.
+	GetGameState(context.Context, *api.EmptyMessage) (*GameStatusMessage, error)
+	// ResumeGame awaits the game begin, if needed,
+	// and returns current state of the game of authenticated gamer.
+	ResumeGame(context.Context, *api.EmptyMessage) (*ResumeMessage, error)
+	// JoinGame does the same as JoinTheGame, but gamer chooses his colour
+	// and handicap, and the reply reports his colour and partner.
+	JoinGame(context.Context, *JoinRequest) (*GameStatusMessage, error)
+	// WaitTurn does the same as WaitTheTurn, but the reply reports
+	// the colour and the partner of gamer.
+	WaitTurn(context.Context, *api.EmptyMessage) (*GameStatusMessage, error)
+	// WatchPartner streams disconnections and reconnections of gamer's partner
+	// and his requests and answers of takeback.
+	WatchPartner(*api.EmptyMessage, Session_WatchPartnerServer) error
+}
+
+// UnimplementedSessionServer can be embedded to have forward compatible implementations.
+type UnimplementedSessionServer struct {
+}
+
+func (*UnimplementedSessionServer) GetGameState(ctx context.Context, req *api.EmptyMessage) (*GameStatusMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGameState not implemented")
+}
+func (*UnimplementedSessionServer) ResumeGame(ctx context.Context, req *api.EmptyMessage) (*ResumeMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumeGame not implemented")
+}
+func (*UnimplementedSessionServer) JoinGame(ctx context.Context, req *JoinRequest) (*GameStatusMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method JoinGame not implemented")
+}
+func (*UnimplementedSessionServer) WaitTurn(ctx context.Context, req *api.EmptyMessage) (*GameStatusMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WaitTurn not implemented")
+}
+func (*UnimplementedSessionServer) WatchPartner(req *api.EmptyMessage, srv Session_WatchPartnerServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchPartner not implemented")
+}
+
+func RegisterSessionServer(s *grpc.Server, srv SessionServer) {
+	s.RegisterService(&_Session_serviceDesc, srv)
+}
+
+func _Session_GetGameState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(api.EmptyMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionServer).GetGameState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc_server.Session/GetGameState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionServer).GetGameState(ctx, req.(*api.EmptyMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Session_ResumeGame_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(api.EmptyMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionServer).ResumeGame(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc_server.Session/ResumeGame",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionServer).ResumeGame(ctx, req.(*api.EmptyMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Session_JoinGame_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JoinRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionServer).JoinGame(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc_server.Session/JoinGame",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionServer).JoinGame(ctx, req.(*JoinRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Session_WaitTurn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(api.EmptyMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionServer).WaitTurn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc_server.Session/WaitTurn",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionServer).WaitTurn(ctx, req.(*api.EmptyMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Session_WatchPartner_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(api.EmptyMessage)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SessionServer).WatchPartner(m, &sessionWatchPartnerServer{stream})
+}
+
+type Session_WatchPartnerServer interface {
+	Send(*PartnerEvent) error
+	grpc.ServerStream
+}
+
+type sessionWatchPartnerServer struct {
+	grpc.ServerStream
+}
+
+func (x *sessionWatchPartnerServer) Send(m *PartnerEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _Session_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "grpc_server.Session",
+	HandlerType: (*SessionServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetGameState",
+			Handler:    _Session_GetGameState_Handler,
+		},
+		{
+			MethodName: "ResumeGame",
+			Handler:    _Session_ResumeGame_Handler,
+		},
+		{
+			MethodName: "JoinGame",
+			Handler:    _Session_JoinGame_Handler,
+		},
+		{
+			MethodName: "WaitTurn",
+			Handler:    _Session_WaitTurn_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchPartner",
+			Handler:       _Session_WatchPartner_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "grpc_server.proto",
+}
+
+// SpectatorClient is the client API for Spectator service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type SpectatorClient interface {
+	// ListGames returns public games in progress.
+	ListGames(ctx context.Context, in *api.EmptyMessage, opts ...grpc.CallOption) (*GamesMessage, error)
+	// WatchGame streams states of the public game until it ends.
+	WatchGame(ctx context.Context, in *GameIDMessage, opts ...grpc.CallOption) (Spectator_WatchGameClient, error)
+	// SetGamePrivate hides the game of authenticated gamer from spectators or shows it.
+	SetGamePrivate(ctx context.Context, in *PrivacyMessage, opts ...grpc.CallOption) (*api.EmptyMessage, error)
+}
+
+type spectatorClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSpectatorClient(cc grpc.ClientConnInterface) SpectatorClient {
+	return &spectatorClient{cc}
+}
+
+func (c *spectatorClient) ListGames(ctx context.Context, in *api.EmptyMessage, opts ...grpc.CallOption) (*GamesMessage, error) {
+	out := new(GamesMessage)
+	err := c.cc.Invoke(ctx, "/grpc_server.Spectator/ListGames", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *spectatorClient) WatchGame(ctx context.Context, in *GameIDMessage, opts ...grpc.CallOption) (Spectator_WatchGameClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Spectator_serviceDesc.Streams[0], "/grpc_server.Spectator/WatchGame", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &spectatorWatchGameClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Spectator_WatchGameClient interface {
+	Recv() (*api.State, error)
+	grpc.ClientStream
+}
+
+type spectatorWatchGameClient struct {
+	grpc.ClientStream
+}
+
+func (x *spectatorWatchGameClient) Recv() (*api.State, error) {
+	m := new(api.State)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *spectatorClient) SetGamePrivate(ctx context.Context, in *PrivacyMessage, opts ...grpc.CallOption) (*api.EmptyMessage, error) {
+	out := new(api.EmptyMessage)
+	err := c.cc.Invoke(ctx, "/grpc_server.Spectator/SetGamePrivate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SpectatorServer is the server API for Spectator service.
+type SpectatorServer interface {
+	// ListGames returns public games in progress.
+	ListGames(context.Context, *api.EmptyMessage) (*GamesMessage, error)
+	// WatchGame streams states of the public game until it ends.
+	WatchGame(*GameIDMessage, Spectator_WatchGameServer) error
+	// SetGamePrivate hides the game of authenticated gamer from spectators or shows it.
+	SetGamePrivate(context.Context, *PrivacyMessage) (*api.EmptyMessage, error)
+}
+
+// UnimplementedSpectatorServer can be embedded to have forward compatible implementations.
+type UnimplementedSpectatorServer struct {
+}
+
+func (*UnimplementedSpectatorServer) ListGames(ctx context.Context, req *api.EmptyMessage) (*GamesMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListGames not implemented")
+}
+func (*UnimplementedSpectatorServer) WatchGame(req *GameIDMessage, srv Spectator_WatchGameServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchGame not implemented")
+}
+func (*UnimplementedSpectatorServer) SetGamePrivate(ctx context.Context, req *PrivacyMessage) (*api.EmptyMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetGamePrivate not implemented")
+}
+
+func RegisterSpectatorServer(s *grpc.Server, srv SpectatorServer) {
+	s.RegisterService(&_Spectator_serviceDesc, srv)
+}
+
+func _Spectator_ListGames_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(api.EmptyMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SpectatorServer).ListGames(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc_server.Spectator/ListGames",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SpectatorServer).ListGames(ctx, req.(*api.EmptyMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Spectator_WatchGame_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GameIDMessage)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SpectatorServer).WatchGame(m, &spectatorWatchGameServer{stream})
+}
+
+type Spectator_WatchGameServer interface {
+	Send(*api.State) error
+	grpc.ServerStream
+}
+
+type spectatorWatchGameServer struct {
+	grpc.ServerStream
+}
+
+func (x *spectatorWatchGameServer) Send(m *api.State) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Spectator_SetGamePrivate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PrivacyMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SpectatorServer).SetGamePrivate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc_server.Spectator/SetGamePrivate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SpectatorServer).SetGamePrivate(ctx, req.(*PrivacyMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Spectator_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "grpc_server.Spectator",
+	HandlerType: (*SpectatorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListGames",
+			Handler:    _Spectator_ListGames_Handler,
+		},
+		{
+			MethodName: "SetGamePrivate",
+			Handler:    _Spectator_SetGamePrivate_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchGame",
+			Handler:       _Spectator_WatchGame_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "grpc_server.proto",
+}
+
+// ChatClient is the client API for Chat service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ChatClient interface {
+	// SendMessage sends text of the message to the channel.
+	SendMessage(ctx context.Context, in *ChatMessage, opts ...grpc.CallOption) (*api.EmptyMessage, error)
+	// History returns last messages of the channel.
+	History(ctx context.Context, in *HistoryRequest, opts ...grpc.CallOption) (*HistoryMessage, error)
+	// Subscribe streams new messages of the channel.
+	Subscribe(ctx context.Context, in *ChannelMessage, opts ...grpc.CallOption) (Chat_SubscribeClient, error)
+}
+
+type chatClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewChatClient(cc grpc.ClientConnInterface) ChatClient {
+	return &chatClient{cc}
+}
+
+func (c *chatClient) SendMessage(ctx context.Context, in *ChatMessage, opts ...grpc.CallOption) (*api.EmptyMessage, error) {
+	out := new(api.EmptyMessage)
+	err := c.cc.Invoke(ctx, "/grpc_server.Chat/SendMessage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatClient) History(ctx context.Context, in *HistoryRequest, opts ...grpc.CallOption) (*HistoryMessage, error) {
+	out := new(HistoryMessage)
+	err := c.cc.Invoke(ctx, "/grpc_server.Chat/History", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatClient) Subscribe(ctx context.Context, in *ChannelMessage, opts ...grpc.CallOption) (Chat_SubscribeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Chat_serviceDesc.Streams[0], "/grpc_server.Chat/Subscribe", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &chatSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Chat_SubscribeClient interface {
+	Recv() (*ChatMessage, error)
+	grpc.ClientStream
+}
+
+type chatSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *chatSubscribeClient) Recv() (*ChatMessage, error) {
+	m := new(ChatMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ChatServer is the server API for Chat service.
+type ChatServer interface {
+	// SendMessage sends text of the message to the channel.
+	SendMessage(context.Context, *ChatMessage) (*api.EmptyMessage, error)
+	// History returns last messages of the channel.
+	History(context.Context, *HistoryRequest) (*HistoryMessage, error)
+	// Subscribe streams new messages of the channel.
+	Subscribe(*ChannelMessage, Chat_SubscribeServer) error
+}
+
+// UnimplementedChatServer can be embedded to have forward compatible implementations.
+type UnimplementedChatServer struct {
+}
+
+func (*UnimplementedChatServer) SendMessage(ctx context.Context, req *ChatMessage) (*api.EmptyMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendMessage not implemented")
+}
+func (*UnimplementedChatServer) History(ctx context.Context, req *HistoryRequest) (*HistoryMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method History not implemented")
+}
+func (*UnimplementedChatServer) Subscribe(req *ChannelMessage, srv Chat_SubscribeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+
+func RegisterChatServer(s *grpc.Server, srv ChatServer) {
+	s.RegisterService(&_Chat_serviceDesc, srv)
+}
+
+func _Chat_SendMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChatMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServer).SendMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc_server.Chat/SendMessage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServer).SendMessage(ctx, req.(*ChatMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Chat_History_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServer).History(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc_server.Chat/History",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServer).History(ctx, req.(*HistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Chat_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ChannelMessage)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ChatServer).Subscribe(m, &chatSubscribeServer{stream})
+}
+
+type Chat_SubscribeServer interface {
+	Send(*ChatMessage) error
+	grpc.ServerStream
+}
+
+type chatSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *chatSubscribeServer) Send(m *ChatMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _Chat_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "grpc_server.Chat",
+	HandlerType: (*ChatServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SendMessage",
+			Handler:    _Chat_SendMessage_Handler,
+		},
+		{
+			MethodName: "History",
+			Handler:    _Chat_History_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _Chat_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "grpc_server.proto",
+}
+
+// LobbyClient is the client API for Lobby service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type LobbyClient interface {
+	// ListLobby returns gamers in the lobby with their activities.
+	ListLobby(ctx context.Context, in *api.EmptyMessage, opts ...grpc.CallOption) (*LobbyMessage, error)
+	// WatchLobby streams gamers in the lobby after each change.
+	WatchLobby(ctx context.Context, in *api.EmptyMessage, opts ...grpc.CallOption) (Lobby_WatchLobbyClient, error)
+}
+
+type lobbyClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLobbyClient(cc grpc.ClientConnInterface) LobbyClient {
+	return &lobbyClient{cc}
+}
+
+func (c *lobbyClient) ListLobby(ctx context.Context, in *api.EmptyMessage, opts ...grpc.CallOption) (*LobbyMessage, error) {
+	out := new(LobbyMessage)
+	err := c.cc.Invoke(ctx, "/grpc_server.Lobby/ListLobby", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lobbyClient) WatchLobby(ctx context.Context, in *api.EmptyMessage, opts ...grpc.CallOption) (Lobby_WatchLobbyClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Lobby_serviceDesc.Streams[0], "/grpc_server.Lobby/WatchLobby", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &lobbyWatchLobbyClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Lobby_WatchLobbyClient interface {
+	Recv() (*LobbyMessage, error)
+	grpc.ClientStream
+}
+
+type lobbyWatchLobbyClient struct {
+	grpc.ClientStream
+}
+
+func (x *lobbyWatchLobbyClient) Recv() (*LobbyMessage, error) {
+	m := new(LobbyMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// LobbyServer is the server API for Lobby service.
+type LobbyServer interface {
+	// ListLobby returns gamers in the lobby with their activities.
+	ListLobby(context.Context, *api.EmptyMessage) (*LobbyMessage, error)
+	// WatchLobby streams gamers in the lobby after each change.
+	WatchLobby(*api.EmptyMessage, Lobby_WatchLobbyServer) error
+}
+
+// UnimplementedLobbyServer can be embedded to have forward compatible implementations.
+type UnimplementedLobbyServer struct {
+}
+
+func (*UnimplementedLobbyServer) ListLobby(ctx context.Context, req *api.EmptyMessage) (*LobbyMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLobby not implemented")
+}
+func (*UnimplementedLobbyServer) WatchLobby(req *api.EmptyMessage, srv Lobby_WatchLobbyServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchLobby not implemented")
+}
+
+func RegisterLobbyServer(s *grpc.Server, srv LobbyServer) {
+	s.RegisterService(&_Lobby_serviceDesc, srv)
+}
+
+func _Lobby_ListLobby_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(api.EmptyMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LobbyServer).ListLobby(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc_server.Lobby/ListLobby",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LobbyServer).ListLobby(ctx, req.(*api.EmptyMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lobby_WatchLobby_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(api.EmptyMessage)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LobbyServer).WatchLobby(m, &lobbyWatchLobbyServer{stream})
+}
+
+type Lobby_WatchLobbyServer interface {
+	Send(*LobbyMessage) error
+	grpc.ServerStream
+}
+
+type lobbyWatchLobbyServer struct {
+	grpc.ServerStream
+}
+
+func (x *lobbyWatchLobbyServer) Send(m *LobbyMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _Lobby_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "grpc_server.Lobby",
+	HandlerType: (*LobbyServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListLobby",
+			Handler:    _Lobby_ListLobby_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchLobby",
+			Handler:       _Lobby_WatchLobby_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "grpc_server.proto",
+}
+
+// ChallengeClient is the client API for Challenge service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ChallengeClient interface {
+	// Challenge creates private game of authenticated gamer and invites other gamer to it.
+	Challenge(ctx context.Context, in *ChallengeRequest, opts ...grpc.CallOption) (*ChallengeMessage, error)
+	// ListChallenges returns challenges of authenticated gamer and to him.
+	ListChallenges(ctx context.Context, in *api.EmptyMessage, opts ...grpc.CallOption) (*ChallengesMessage, error)
+	// AcceptChallenge joins invited gamer to the private game.
+	AcceptChallenge(ctx context.Context, in *CodeMessage, opts ...grpc.CallOption) (*api.State, error)
+	// DeclineChallenge declines or cancels the challenge.
+	DeclineChallenge(ctx context.Context, in *CodeMessage, opts ...grpc.CallOption) (*api.EmptyMessage, error)
+}
+
+type challengeClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewChallengeClient(cc grpc.ClientConnInterface) ChallengeClient {
+	return &challengeClient{cc}
+}
+
+func (c *challengeClient) Challenge(ctx context.Context, in *ChallengeRequest, opts ...grpc.CallOption) (*ChallengeMessage, error) {
+	out := new(ChallengeMessage)
+	err := c.cc.Invoke(ctx, "/grpc_server.Challenge/Challenge", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *challengeClient) ListChallenges(ctx context.Context, in *api.EmptyMessage, opts ...grpc.CallOption) (*ChallengesMessage, error) {
+	out := new(ChallengesMessage)
+	err := c.cc.Invoke(ctx, "/grpc_server.Challenge/ListChallenges", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *challengeClient) AcceptChallenge(ctx context.Context, in *CodeMessage, opts ...grpc.CallOption) (*api.State, error) {
+	out := new(api.State)
+	err := c.cc.Invoke(ctx, "/grpc_server.Challenge/AcceptChallenge", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *challengeClient) DeclineChallenge(ctx context.Context, in *CodeMessage, opts ...grpc.CallOption) (*api.EmptyMessage, error) {
+	out := new(api.EmptyMessage)
+	err := c.cc.Invoke(ctx, "/grpc_server.Challenge/DeclineChallenge", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ChallengeServer is the server API for Challenge service.
+type ChallengeServer interface {
+	// Challenge creates private game of authenticated gamer and invites other gamer to it.
+	Challenge(context.Context, *ChallengeRequest) (*ChallengeMessage, error)
+	// ListChallenges returns challenges of authenticated gamer and to him.
+	ListChallenges(context.Context, *api.EmptyMessage) (*ChallengesMessage, error)
+	// AcceptChallenge joins invited gamer to the private game.
+	AcceptChallenge(context.Context, *CodeMessage) (*api.State, error)
+	// DeclineChallenge declines or cancels the challenge.
+	DeclineChallenge(context.Context, *CodeMessage) (*api.EmptyMessage, error)
+}
+
+// UnimplementedChallengeServer can be embedded to have forward compatible implementations.
+type UnimplementedChallengeServer struct {
+}
+
+func (*UnimplementedChallengeServer) Challenge(ctx context.Context, req *ChallengeRequest) (*ChallengeMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Challenge not implemented")
+}
+func (*UnimplementedChallengeServer) ListChallenges(ctx context.Context, req *api.EmptyMessage) (*ChallengesMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListChallenges not implemented")
+}
+func (*UnimplementedChallengeServer) AcceptChallenge(ctx context.Context, req *CodeMessage) (*api.State, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AcceptChallenge not implemented")
+}
+func (*UnimplementedChallengeServer) DeclineChallenge(ctx context.Context, req *CodeMessage) (*api.EmptyMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeclineChallenge not implemented")
+}
+
+func RegisterChallengeServer(s *grpc.Server, srv ChallengeServer) {
+	s.RegisterService(&_Challenge_serviceDesc, srv)
+}
+
+func _Challenge_Challenge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChallengeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChallengeServer).Challenge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc_server.Challenge/Challenge",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChallengeServer).Challenge(ctx, req.(*ChallengeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Challenge_ListChallenges_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(api.EmptyMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChallengeServer).ListChallenges(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc_server.Challenge/ListChallenges",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChallengeServer).ListChallenges(ctx, req.(*api.EmptyMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Challenge_AcceptChallenge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CodeMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChallengeServer).AcceptChallenge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc_server.Challenge/AcceptChallenge",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChallengeServer).AcceptChallenge(ctx, req.(*CodeMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Challenge_DeclineChallenge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CodeMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChallengeServer).DeclineChallenge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc_server.Challenge/DeclineChallenge",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChallengeServer).DeclineChallenge(ctx, req.(*CodeMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Challenge_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "grpc_server.Challenge",
+	HandlerType: (*ChallengeServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Challenge",
+			Handler:    _Challenge_Challenge_Handler,
+		},
+		{
+			MethodName: "ListChallenges",
+			Handler:    _Challenge_ListChallenges_Handler,
+		},
+		{
+			MethodName: "AcceptChallenge",
+			Handler:    _Challenge_AcceptChallenge_Handler,
+		},
+		{
+			MethodName: "DeclineChallenge",
+			Handler:    _Challenge_DeclineChallenge_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "grpc_server.proto",
+}
+
+// MatchmakingClient is the client API for Matchmaking service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type MatchmakingClient interface {
+	// Seek queues authenticated gamer, streams his place in the queue
+	// and then the partner and the state of begun game.
+	Seek(ctx context.Context, in *SeekRequest, opts ...grpc.CallOption) (Matchmaking_SeekClient, error)
+}
+
+type matchmakingClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMatchmakingClient(cc grpc.ClientConnInterface) MatchmakingClient {
+	return &matchmakingClient{cc}
+}
+
+func (c *matchmakingClient) Seek(ctx context.Context, in *SeekRequest, opts ...grpc.CallOption) (Matchmaking_SeekClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Matchmaking_serviceDesc.Streams[0], "/grpc_server.Matchmaking/Seek", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &matchmakingSeekClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Matchmaking_SeekClient interface {
+	Recv() (*SeekMessage, error)
+	grpc.ClientStream
+}
+
+type matchmakingSeekClient struct {
+	grpc.ClientStream
+}
+
+func (x *matchmakingSeekClient) Recv() (*SeekMessage, error) {
+	m := new(SeekMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// MatchmakingServer is the server API for Matchmaking service.
+type MatchmakingServer interface {
+	// Seek queues authenticated gamer, streams his place in the queue
+	// and then the partner and the state of begun game.
+	Seek(*SeekRequest, Matchmaking_SeekServer) error
+}
+
+// UnimplementedMatchmakingServer can be embedded to have forward compatible implementations.
+type UnimplementedMatchmakingServer struct {
+}
+
+func (*UnimplementedMatchmakingServer) Seek(req *SeekRequest, srv Matchmaking_SeekServer) error {
+	return status.Errorf(codes.Unimplemented, "method Seek not implemented")
+}
+
+func RegisterMatchmakingServer(s *grpc.Server, srv MatchmakingServer) {
+	s.RegisterService(&_Matchmaking_serviceDesc, srv)
+}
+
+func _Matchmaking_Seek_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SeekRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MatchmakingServer).Seek(m, &matchmakingSeekServer{stream})
+}
+
+type Matchmaking_SeekServer interface {
+	Send(*SeekMessage) error
+	grpc.ServerStream
+}
+
+type matchmakingSeekServer struct {
+	grpc.ServerStream
+}
+
+func (x *matchmakingSeekServer) Send(m *SeekMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _Matchmaking_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "grpc_server.Matchmaking",
+	HandlerType: (*MatchmakingServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Seek",
+			Handler:       _Matchmaking_Seek_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "grpc_server.proto",
+}
+
+// RatingClient is the client API for Rating service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type RatingClient interface {
+	// GetRating returns rating of gamer with login or of authenticated gamer, if login is empty.
+	GetRating(ctx context.Context, in *RatingRequest, opts ...grpc.CallOption) (*RatingMessage, error)
+	// Leaderboard returns a page of players ordered by rating.
+	Leaderboard(ctx context.Context, in *LeaderboardRequest, opts ...grpc.CallOption) (*LeaderboardMessage, error)
+	// GetStats returns statistics of games of gamer with login or of authenticated gamer, if login is empty.
+	GetStats(ctx context.Context, in *RatingRequest, opts ...grpc.CallOption) (*StatsMessage, error)
+}
+
+type ratingClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRatingClient(cc grpc.ClientConnInterface) RatingClient {
+	return &ratingClient{cc}
+}
+
+func (c *ratingClient) GetRating(ctx context.Context, in *RatingRequest, opts ...grpc.CallOption) (*RatingMessage, error) {
+	out := new(RatingMessage)
+	err := c.cc.Invoke(ctx, "/grpc_server.Rating/GetRating", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratingClient) Leaderboard(ctx context.Context, in *LeaderboardRequest, opts ...grpc.CallOption) (*LeaderboardMessage, error) {
+	out := new(LeaderboardMessage)
+	err := c.cc.Invoke(ctx, "/grpc_server.Rating/Leaderboard", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratingClient) GetStats(ctx context.Context, in *RatingRequest, opts ...grpc.CallOption) (*StatsMessage, error) {
+	out := new(StatsMessage)
+	err := c.cc.Invoke(ctx, "/grpc_server.Rating/GetStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RatingServer is the server API for Rating service.
+type RatingServer interface {
+	// GetRating returns rating of gamer with login or of authenticated gamer, if login is empty.
+	GetRating(context.Context, *RatingRequest) (*RatingMessage, error)
+	// Leaderboard returns a page of players ordered by rating.
+	Leaderboard(context.Context, *LeaderboardRequest) (*LeaderboardMessage, error)
+	// GetStats returns statistics of games of gamer with login or of authenticated gamer, if login is empty.
+	GetStats(context.Context, *RatingRequest) (*StatsMessage, error)
+}
+
+// UnimplementedRatingServer can be embedded to have forward compatible implementations.
+type UnimplementedRatingServer struct {
+}
+
+func (*UnimplementedRatingServer) GetRating(ctx context.Context, req *RatingRequest) (*RatingMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRating not implemented")
+}
+func (*UnimplementedRatingServer) Leaderboard(ctx context.Context, req *LeaderboardRequest) (*LeaderboardMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Leaderboard not implemented")
+}
+func (*UnimplementedRatingServer) GetStats(ctx context.Context, req *RatingRequest) (*StatsMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
+
+func RegisterRatingServer(s *grpc.Server, srv RatingServer) {
+	s.RegisterService(&_Rating_serviceDesc, srv)
+}
+
+func _Rating_GetRating_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RatingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatingServer).GetRating(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc_server.Rating/GetRating",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatingServer).GetRating(ctx, req.(*RatingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rating_Leaderboard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LeaderboardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatingServer).Leaderboard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc_server.Rating/Leaderboard",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatingServer).Leaderboard(ctx, req.(*LeaderboardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rating_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RatingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatingServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc_server.Rating/GetStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatingServer).GetStats(ctx, req.(*RatingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Rating_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "grpc_server.Rating",
+	HandlerType: (*RatingServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetRating",
+			Handler:    _Rating_GetRating_Handler,
+		},
+		{
+			MethodName: "Leaderboard",
+			Handler:    _Rating_Leaderboard_Handler,
+		},
+		{
+			MethodName: "GetStats",
+			Handler:    _Rating_GetStats_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "grpc_server.proto",
+}
+
+// TakebackClient is the client API for Takeback service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type TakebackClient interface {
+	// RequestUndo asks partner to take back the last move of gamer and waits for the answer.
+	RequestUndo(ctx context.Context, in *api.EmptyMessage, opts ...grpc.CallOption) (*api.State, error)
+	// AnswerUndo accepts or rejects the request of partner to take back his last move.
+	AnswerUndo(ctx context.Context, in *UndoAnswer, opts ...grpc.CallOption) (*api.State, error)
+	// ListMoves returns the move history of game of gamer.
+	ListMoves(ctx context.Context, in *api.EmptyMessage, opts ...grpc.CallOption) (*MovesMessage, error)
+}
+
+type takebackClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTakebackClient(cc grpc.ClientConnInterface) TakebackClient {
+	return &takebackClient{cc}
+}
+
+func (c *takebackClient) RequestUndo(ctx context.Context, in *api.EmptyMessage, opts ...grpc.CallOption) (*api.State, error) {
+	out := new(api.State)
+	err := c.cc.Invoke(ctx, "/grpc_server.Takeback/RequestUndo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *takebackClient) AnswerUndo(ctx context.Context, in *UndoAnswer, opts ...grpc.CallOption) (*api.State, error) {
+	out := new(api.State)
+	err := c.cc.Invoke(ctx, "/grpc_server.Takeback/AnswerUndo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *takebackClient) ListMoves(ctx context.Context, in *api.EmptyMessage, opts ...grpc.CallOption) (*MovesMessage, error) {
+	out := new(MovesMessage)
+	err := c.cc.Invoke(ctx, "/grpc_server.Takeback/ListMoves", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TakebackServer is the server API for Takeback service.
+type TakebackServer interface {
+	// RequestUndo asks partner to take back the last move of gamer and waits for the answer.
+	RequestUndo(context.Context, *api.EmptyMessage) (*api.State, error)
+	// AnswerUndo accepts or rejects the request of partner to take back his last move.
+	AnswerUndo(context.Context, *UndoAnswer) (*api.State, error)
+	// ListMoves returns the move history of game of gamer.
+	ListMoves(context.Context, *api.EmptyMessage) (*MovesMessage, error)
+}
+
+// UnimplementedTakebackServer can be embedded to have forward compatible implementations.
+type UnimplementedTakebackServer struct {
+}
+
+func (*UnimplementedTakebackServer) RequestUndo(ctx context.Context, req *api.EmptyMessage) (*api.State, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestUndo not implemented")
+}
+func (*UnimplementedTakebackServer) AnswerUndo(ctx context.Context, req *UndoAnswer) (*api.State, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AnswerUndo not implemented")
+}
+func (*UnimplementedTakebackServer) ListMoves(ctx context.Context, req *api.EmptyMessage) (*MovesMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMoves not implemented")
+}
+
+func RegisterTakebackServer(s *grpc.Server, srv TakebackServer) {
+	s.RegisterService(&_Takeback_serviceDesc, srv)
+}
+
+func _Takeback_RequestUndo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(api.EmptyMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TakebackServer).RequestUndo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc_server.Takeback/RequestUndo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TakebackServer).RequestUndo(ctx, req.(*api.EmptyMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Takeback_AnswerUndo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UndoAnswer)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TakebackServer).AnswerUndo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc_server.Takeback/AnswerUndo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TakebackServer).AnswerUndo(ctx, req.(*UndoAnswer))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Takeback_ListMoves_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(api.EmptyMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TakebackServer).ListMoves(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc_server.Takeback/ListMoves",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TakebackServer).ListMoves(ctx, req.(*api.EmptyMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Takeback_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "grpc_server.Takeback",
+	HandlerType: (*TakebackServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RequestUndo",
+			Handler:    _Takeback_RequestUndo_Handler,
+		},
+		{
+			MethodName: "AnswerUndo",
+			Handler:    _Takeback_AnswerUndo_Handler,
+		},
+		{
+			MethodName: "ListMoves",
+			Handler:    _Takeback_ListMoves_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "grpc_server.proto",
+}
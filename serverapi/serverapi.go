@@ -0,0 +1,36 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+//go:generate sh -c "protoc -I . -I $DOLLAR(go list -m -f {{.Dir}} github.com/yagoggame/api) --go_out=plugins=grpc,Mapi.proto=github.com/yagoggame/api:. grpc_server.proto"
+
+// Package serverapi provides services and messages of the server, which are
+// not provided by github.com/yagoggame/api. They are generated from grpc_server.proto,
+// so the server and its clients, like the gateway, share them.
+package serverapi
+
+// Headers of calls, which return the state of a game.
+// Values of colours are names of Colour. Messages of api package
+// can't be extended, so the calls of api.GoGameServer report colours
+// and the partner by headers, and JoinGame and WaitTurn of Session
+// report them in GameStatusMessage.
+const (
+	// TurnHeader is the colour, whose turn is now
+	TurnHeader = "turn"
+	// ColourHeader is the colour of gamer
+	ColourHeader = "colour"
+	// PartnerHeader is the login of partner
+	PartnerHeader = "partner"
+)
@@ -0,0 +1,236 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package tlsconfig provides TLS configuration of server: with certificate
+// of files, with generated self-signed certificate or no TLS at all,
+// and with optional verification of client certificates.
+package tlsconfig
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net"
+	"time"
+
+	"github.com/yagoggame/grpc_server/certreload"
+)
+
+// selfSignedValidity is a validity period of generated certificate
+const selfSignedValidity = 365 * 24 * time.Hour
+
+var (
+	// ErrInsecureConflict occurs when insecure mode is combined with other TLS settings
+	ErrInsecureConflict = errors.New("insecure mode can't be combined with self-signed certificate, client CA or certificate authentication")
+	// ErrNotLoopback occurs when insecure server listens on non loopback address without forcing
+	ErrNotLoopback = errors.New("insecure mode refuses non loopback address")
+	// ErrSelfSignedConflict occurs when self-signed certificate is combined with certificate files
+	ErrSelfSignedConflict = errors.New("self-signed certificate can't be combined with certificate and key files")
+	// ErrNoClientCA occurs when verification of client certificates is requested without client CA file
+	ErrNoClientCA = errors.New("client certificates verification requires client CA file")
+	// ErrNoCACertificates occurs when client CA file contains no certificates
+	ErrNoCACertificates = errors.New("no CA certificates found")
+)
+
+// Options configures TLS of server
+type Options struct {
+	// Insecure disables TLS, it is allowed for loopback addresses only, unless ForceInsecure is set
+	Insecure      bool
+	ForceInsecure bool
+	// SelfSigned generates certificate and writes it to SelfSignedFile
+	SelfSigned     bool
+	SelfSignedFile string
+	// CertFile and KeyFile are files of certificate, which are watched for changes, if CertReload is set
+	CertFile   string
+	KeyFile    string
+	CertReload bool
+	// ClientCAFile contains CA certificates to verify certificates of clients
+	ClientCAFile string
+	// ClientCertRequired refuses clients without certificate
+	ClientCertRequired bool
+	// CertAuth authenticates clients by their certificates
+	CertAuth bool
+}
+
+// Server returns TLS configuration of server listening on hosts,
+// or nil in insecure mode. If options.ClientCAFile is set,
+// CA certificates are loaded to verify TLS certificates of clients.
+// Reloader of certificate files is returned, if they are used.
+func Server(options Options, hosts []string) (*tls.Config, *certreload.Reloader, error) {
+	if options.Insecure {
+		return nil, nil, checkInsecure(options, hosts)
+	}
+
+	config, reloader, err := serverConfig(options, hosts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if options.ClientCAFile == "" {
+		if options.ClientCertRequired || options.CertAuth {
+			return nil, nil, ErrNoClientCA
+		}
+		return config, reloader, nil
+	}
+
+	pem, err := ioutil.ReadFile(options.ClientCAFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	config.ClientCAs = x509.NewCertPool()
+	if !config.ClientCAs.AppendCertsFromPEM(pem) {
+		return nil, nil, fmt.Errorf("%w in %q", ErrNoCACertificates, options.ClientCAFile)
+	}
+
+	config.ClientAuth = tls.VerifyClientCertIfGiven
+	if options.ClientCertRequired {
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, reloader, nil
+}
+
+// checkInsecure checks that insecure mode is compatible with other settings,
+// and that server listens on loopback hosts only, unless it is forced.
+func checkInsecure(options Options, hosts []string) error {
+	if options.SelfSigned || options.ClientCAFile != "" || options.CertAuth {
+		return ErrInsecureConflict
+	}
+	if options.ForceInsecure {
+		return nil
+	}
+	for _, host := range hosts {
+		if !isLoopback(host) {
+			return fmt.Errorf("%w %q", ErrNotLoopback, host)
+		}
+	}
+	return nil
+}
+
+// isLoopback reports whether all addresses of host are loopback ones.
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.IsLoopback()
+	}
+	if host == "" {
+		return false
+	}
+
+	ips, err := net.LookupIP(host)
+	if err != nil || len(ips) < 1 {
+		return false
+	}
+	for _, ip := range ips {
+		if !ip.IsLoopback() {
+			return false
+		}
+	}
+	return true
+}
+
+// serverConfig returns TLS configuration with certificate of server,
+// reloaded on changes of files if options.CertReload is set,
+// or with generated self-signed one.
+func serverConfig(options Options, hosts []string) (*tls.Config, *certreload.Reloader, error) {
+	if options.SelfSigned {
+		if options.CertFile != "" || options.KeyFile != "" {
+			return nil, nil, ErrSelfSignedConflict
+		}
+		cert, err := SelfSigned(hosts, options.SelfSignedFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &tls.Config{Certificates: []tls.Certificate{cert}}, nil, nil
+	}
+
+	reloader, err := certreload.New(options.CertFile, options.KeyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	if options.CertReload {
+		if err := reloader.Watch(); err != nil {
+			return nil, nil, err
+		}
+	}
+	return &tls.Config{GetCertificate: reloader.GetCertificate}, reloader, nil
+}
+
+// SelfSigned generates in-memory certificate for hosts and loopback
+// addresses, and writes its PEM, preceded by SHA-256 fingerprint, to fileName.
+func SelfSigned(hosts []string, fileName string) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "grpc_server self-signed"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			if !ip.IsLoopback() && !ip.IsUnspecified() {
+				template.IPAddresses = append(template.IPAddresses, ip)
+			}
+		} else if host != "" && host != "localhost" {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	fingerprint := fmt.Sprintf("%X", sha256.Sum256(der))
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "SHA-256 fingerprint: %s\n", fingerprint)
+	if err := pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: der}); err != nil {
+		return tls.Certificate{}, err
+	}
+	if err := ioutil.WriteFile(fileName, buf.Bytes(), 0644); err != nil {
+		return tls.Certificate{}, err
+	}
+	log.Printf("self-signed certificate with SHA-256 fingerprint %s written to %q", fingerprint, fileName)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
@@ -0,0 +1,179 @@
+// Copyright ©2020 BlinnikovAA. All rights reserved.
+// This file is part of yagogame.
+//
+// yagogame is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// yagogame is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with yagogame.  If not, see <https://www.gnu.org/licenses/>.
+
+package tlsconfig_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/yagoggame/grpc_server/tlsconfig"
+)
+
+var insecureTests = []struct {
+	caseName string
+	options  Options
+	hosts    []string
+	want     error
+}{
+	{caseName: "loopback", options: Options{Insecure: true},
+		hosts: []string{"127.0.0.1", "::1", "localhost"}, want: nil},
+	{caseName: "not loopback", options: Options{Insecure: true},
+		hosts: []string{"127.0.0.1", "10.0.0.1"}, want: ErrNotLoopback},
+	{caseName: "all addresses", options: Options{Insecure: true},
+		hosts: []string{""}, want: ErrNotLoopback},
+	{caseName: "forced", options: Options{Insecure: true, ForceInsecure: true},
+		hosts: []string{"10.0.0.1"}, want: nil},
+	{caseName: "with self-signed", options: Options{Insecure: true, SelfSigned: true},
+		hosts: []string{"127.0.0.1"}, want: ErrInsecureConflict},
+	{caseName: "with client CA", options: Options{Insecure: true, ClientCAFile: "ca.pem"},
+		hosts: []string{"127.0.0.1"}, want: ErrInsecureConflict},
+	{caseName: "with certificate authentication", options: Options{Insecure: true, CertAuth: true},
+		hosts: []string{"127.0.0.1"}, want: ErrInsecureConflict},
+}
+
+func TestInsecure(t *testing.T) {
+	for _, test := range insecureTests {
+		t.Run(test.caseName, func(t *testing.T) {
+			config, reloader, err := Server(test.options, test.hosts)
+			if !errors.Is(err, test.want) {
+				t.Errorf("Unexpected err:\nwant: %v,\ngot: %v.", test.want, err)
+			}
+			if config != nil || reloader != nil {
+				t.Errorf("Unexpected TLS in insecure mode: %v, %v.", config, reloader)
+			}
+		})
+	}
+}
+
+func TestSelfSigned(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "cert.pem")
+
+	cert, err := SelfSigned([]string{"game.example.org", "10.0.0.1", "0.0.0.0", ""}, fileName)
+	if err != nil {
+		t.Fatalf("Unexpected SelfSigned err: %v.", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("Unexpected ParseCertificate err: %v.", err)
+	}
+	for _, host := range []string{"localhost", "127.0.0.1", "::1", "game.example.org", "10.0.0.1"} {
+		if err := leaf.VerifyHostname(host); err != nil {
+			t.Errorf("Unexpected VerifyHostname(%q) err: %v.", host, err)
+		}
+	}
+
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("Unexpected ReadFile err: %v.", err)
+	}
+	if !strings.HasPrefix(string(content), "SHA-256 fingerprint: ") {
+		t.Errorf("Unexpected file content without fingerprint:\n%s", content)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(content) {
+		t.Errorf("Unexpected file content without certificate:\n%s", content)
+	}
+}
+
+func TestSelfSignedWithFiles(t *testing.T) {
+	_, _, err := Server(Options{SelfSigned: true, CertFile: "cert.pem", KeyFile: "key.pem"}, nil)
+	if !errors.Is(err, ErrSelfSignedConflict) {
+		t.Errorf("Unexpected err:\nwant: %v,\ngot: %v.", ErrSelfSignedConflict, err)
+	}
+}
+
+var clientCATests = []struct {
+	caseName string
+	required bool
+	certAuth bool
+	// caFile is "ca", "missing", "invalid" or "" for no file
+	caFile   string
+	want     error
+	wantAuth tls.ClientAuthType
+}{
+	{caseName: "no client CA", caFile: "", want: nil, wantAuth: tls.NoClientCert},
+	{caseName: "required without client CA", required: true, caFile: "", want: ErrNoClientCA},
+	{caseName: "authentication without client CA", certAuth: true, caFile: "", want: ErrNoClientCA},
+	{caseName: "missing client CA", caFile: "missing", want: os.ErrNotExist},
+	{caseName: "invalid client CA", caFile: "invalid", want: ErrNoCACertificates},
+	{caseName: "optional", caFile: "ca", want: nil, wantAuth: tls.VerifyClientCertIfGiven},
+	{caseName: "required", required: true, caFile: "ca", want: nil, wantAuth: tls.RequireAndVerifyClientCert},
+	{caseName: "authentication", certAuth: true, caFile: "ca", want: nil, wantAuth: tls.VerifyClientCertIfGiven},
+}
+
+func TestClientCA(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	caFile := filepath.Join(dir, "ca.pem")
+	if _, err := SelfSigned(nil, caFile); err != nil {
+		t.Fatalf("Unexpected SelfSigned err: %v.", err)
+	}
+	invalidFile := filepath.Join(dir, "invalid.pem")
+	if err := ioutil.WriteFile(invalidFile, []byte("not a certificate"), 0644); err != nil {
+		t.Fatalf("Unexpected WriteFile err: %v.", err)
+	}
+	files := map[string]string{
+		"ca":      caFile,
+		"missing": filepath.Join(dir, "missing.pem"),
+		"invalid": invalidFile,
+	}
+
+	for _, test := range clientCATests {
+		t.Run(test.caseName, func(t *testing.T) {
+			options := Options{
+				SelfSigned:         true,
+				SelfSignedFile:     filepath.Join(dir, "cert.pem"),
+				ClientCAFile:       files[test.caFile],
+				ClientCertRequired: test.required,
+				CertAuth:           test.certAuth,
+			}
+			config, _, err := Server(options, nil)
+			if !errors.Is(err, test.want) {
+				t.Fatalf("Unexpected err:\nwant: %v,\ngot: %v.", test.want, err)
+			}
+			if err != nil {
+				return
+			}
+			if config.ClientAuth != test.wantAuth {
+				t.Errorf("Unexpected ClientAuth:\nwant: %v,\ngot: %v.", test.wantAuth, config.ClientAuth)
+			}
+			if len(config.Certificates) != 1 {
+				t.Errorf("Unexpected number of certificates:\nwant: 1,\ngot: %d.", len(config.Certificates))
+			}
+		})
+	}
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "tlsconfig")
+	if err != nil {
+		t.Fatalf("Unexpected TempDir err: %v.", err)
+	}
+	return dir
+}